@@ -0,0 +1,68 @@
+package user
+
+import (
+	"fmt"
+	listGroup "omniactl/artifactory/list/group"
+	listUser "omniactl/artifactory/list/user"
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+
+	"github.com/fatih/color"
+)
+
+// AddUsers adds the users to the Artifactory group. Users who are members already are skipped.
+func AddUsers(svc *service.Service, group string, users []string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Add users to Artifactory group")
+
+	if group == "" || len(users) == 0 {
+		return errs.New(errs.Validation, "an Artifactory group and at least one user are required")
+	}
+	g, err := listGroup.GetGroup(svc, group)
+	if err != nil {
+		return err
+	}
+	if err := listUser.CheckUsers(svc, users); err != nil {
+		return err
+	}
+
+	members := append([]string{}, g.UserNames...)
+	added := []string{}
+	for _, u := range users {
+		if contains(members, u) {
+			color.New(color.FgYellow).Printf("User '%v' is already a member of Artifactory group '%v'.\n", u, group)
+			continue
+		}
+		members = append(members, u)
+		added = append(added, u)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if err := UpdateMembers(svc, group, members); err != nil {
+		return err
+	}
+	fmt.Println("")
+	for _, u := range added {
+		color.New(color.FgHiWhite, color.Bold).Printf("User '%v' added to Artifactory group '%v'.\n", u, group)
+	}
+	return nil
+}
+
+// UpdateMembers sends an HTTP Post request replacing the members of the group
+func UpdateMembers(svc *service.Service, group string, members []string) error {
+	body := map[string][]string{"userNames": members}
+	if err := svc.Do("POST", "security/groups/"+group, body, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error updating members of Artifactory group '%v'", group)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package user_test
+
+import (
+	addUser "omniactl/artifactory/add/user"
+	createGroup "omniactl/artifactory/create/group"
+	"omniactl/artifactory/fake"
+	removeUser "omniactl/artifactory/remove/user"
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var (
+	// server is the fake Artifactory instance used by all tests in this package
+	server *fake.Server
+	// svc is the Artifactory service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddUser("e950001")
+	server.AddUser("e950002")
+	server.AddUser("e950003")
+	svc = service.New(server.URL, server.Client())
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestCreateGroup(t *testing.T) {
+	if err := createGroup.CreateGroup(svc, "msf-devs", "MSF developers"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if g := server.Group("msf-devs"); g == nil || g.Description != "MSF developers" {
+		t.Errorf("Group 'msf-devs' was not created as expected: %+v", g)
+	}
+
+	type test struct {
+		name string
+		want errs.Kind
+	}
+	tests := []test{
+		{"msf-devs", errs.AlreadyExists},
+		{"msf devs", errs.Validation},
+	}
+	for _, tc := range tests {
+		if err := createGroup.CreateGroup(svc, tc.name, ""); !errs.Is(err, tc.want) {
+			t.Errorf("Creating group '%v': expected '%v' error Got '%v'", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestAddAndRemoveUsers(t *testing.T) {
+	server.AddGroup("ops", "e950001")
+	if err := addUser.AddUsers(svc, "ops", []string{"e950001", "e950002", "e950003"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	want := []string{"e950001", "e950002", "e950003"}
+	if got := server.Group("ops").Users; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected members %v Got %v", want, got)
+	}
+
+	if err := removeUser.RemoveUsers(svc, "ops", []string{"e950002", "e999999"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	want = []string{"e950001", "e950003"}
+	if got := server.Group("ops").Users; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected members %v Got %v", want, got)
+	}
+}
+
+func TestAddUsersErrors(t *testing.T) {
+	server.AddGroup("qa")
+	type test struct {
+		name  string
+		group string
+		users []string
+		want  errs.Kind
+	}
+	tests := []test{
+		{"unknown group", "nope", []string{"e950001"}, errs.NotFound},
+		{"unknown user", "qa", []string{"e950001", "e999999"}, errs.NotFound},
+		{"no users", "qa", nil, errs.Validation},
+	}
+	for _, tc := range tests {
+		if err := addUser.AddUsers(svc, tc.group, tc.users); !errs.Is(err, tc.want) {
+			t.Errorf("Adding users with %v: expected '%v' error Got '%v'", tc.name, tc.want, err)
+		}
+	}
+	if got := server.Group("qa").Users; len(got) != 0 {
+		t.Errorf("Users were added despite an unknown user: %v", got)
+	}
+}
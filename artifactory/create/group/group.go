@@ -0,0 +1,74 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	listGroup "omniactl/artifactory/list/group"
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"regexp"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// nameFormat is the format of Artifactory group names
+var nameFormat = regexp.MustCompile(`^[A-Za-z0-9._\-]+$`)
+
+// Group is the body of a request to create an Artifactory group
+type Group struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CreateGroup creates an Artifactory group, prompting for the name if none or an invalid one was provided
+func CreateGroup(svc *service.Service, name string, description string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Create new Artifactory group")
+
+	name, err := CheckName(svc, name)
+	if err != nil {
+		return err
+	}
+	return CreateArtifactoryGroup(svc, name, description)
+}
+
+// CheckName checks flag input, prompting if none or an invalid name was set,
+// and that the group does not exist yet
+func CheckName(svc *service.Service, name string) (string, error) {
+	validate := func(input string) error {
+		if !nameFormat.MatchString(input) {
+			return errors.New("Group name can only contain the following characters: A-Z, a-z, 0-9, ., -, _")
+		}
+		return nil
+	}
+	if validate(name) != nil {
+		prompt := promptui.Prompt{
+			Label:    "Artifactory group name",
+			Validate: validate,
+		}
+		var err error
+		if name, err = interactive.Prompt(prompt); err != nil {
+			return "", err
+		}
+	}
+	exists, err := listGroup.CheckIfGroupExists(svc, name)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", errs.New(errs.AlreadyExists, "Artifactory group '%v' already exists", name)
+	}
+	return name, nil
+}
+
+// CreateArtifactoryGroup sends an HTTP Put request to create the group
+func CreateArtifactoryGroup(svc *service.Service, name string, description string) error {
+	if err := svc.Do("PUT", "security/groups/"+name, Group{Name: name, Description: description}, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error creating Artifactory group '%v'", name)
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Artifactory group '%v' created.\n", name)
+	return nil
+}
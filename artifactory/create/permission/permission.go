@@ -0,0 +1,139 @@
+package permission
+
+import (
+	"fmt"
+	listGroup "omniactl/artifactory/list/group"
+	listRepo "omniactl/artifactory/list/repo"
+	listUser "omniactl/artifactory/list/user"
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+	"omniactl/glob"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Actions are the actions a permission target can grant on its repositories
+var Actions = []string{"read", "annotate", "write", "delete", "manage"}
+
+// DefaultActions are granted if no actions are provided
+var DefaultActions = []string{"read", "annotate", "write"}
+
+// Permission is the body of a request to create a permission target
+type Permission struct {
+	Name string `json:"name"`
+	Repo Target `json:"repo"`
+}
+
+// Target holds the repositories of a permission target and the actions of each user and group on them
+type Target struct {
+	Repositories    []string `json:"repositories"`
+	IncludePatterns []string `json:"include-patterns"`
+	ExcludePatterns []string `json:"exclude-patterns"`
+	Actions         struct {
+		Users  map[string][]string `json:"users,omitempty"`
+		Groups map[string][]string `json:"groups,omitempty"`
+	} `json:"actions"`
+}
+
+// CreatePermission creates a permission target granting the users and groups the actions
+// on the repositories. If no repositories are provided, the local and remote repositories
+// named after the permission target, e.g. msf-maven-local for msf, are used.
+func CreatePermission(svc *service.Service, name string, repos []string, users []string, groups []string, actions []string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Create Artifactory permission target")
+
+	if name == "" {
+		return errs.New(errs.Validation, "no permission target name provided")
+	}
+	if len(users) == 0 && len(groups) == 0 {
+		return errs.New(errs.Validation, "permission target '%v' needs at least one user or group", name)
+	}
+	if len(actions) == 0 {
+		actions = DefaultActions
+	}
+	for _, a := range actions {
+		if !contains(Actions, a) {
+			return errs.New(errs.Validation, "unknown action '%v', use %v", a, strings.Join(Actions, ", "))
+		}
+	}
+	err := svc.Do("GET", "v2/security/permissions/"+name, nil, nil)
+	if err == nil {
+		return errs.New(errs.AlreadyExists, "Artifactory permission target '%v' already exists", name)
+	}
+	if !errs.Is(err, errs.NotFound) {
+		return errs.Wrap(errs.KindOf(err), err, "error getting Artifactory permission target '%v'", name)
+	}
+	repos, err = CheckRepos(svc, name, repos)
+	if err != nil {
+		return err
+	}
+	if err := listUser.CheckUsers(svc, users); err != nil {
+		return err
+	}
+	if err := listGroup.CheckGroups(svc, groups); err != nil {
+		return err
+	}
+
+	body := Permission{Name: name, Repo: Target{Repositories: repos, IncludePatterns: []string{"**"}, ExcludePatterns: []string{}}}
+	if len(users) > 0 {
+		body.Repo.Actions.Users = make(map[string][]string)
+		for _, u := range users {
+			body.Repo.Actions.Users[u] = actions
+		}
+	}
+	if len(groups) > 0 {
+		body.Repo.Actions.Groups = make(map[string][]string)
+		for _, g := range groups {
+			body.Repo.Actions.Groups[g] = actions
+		}
+	}
+	return CreatePermissionTarget(svc, body)
+}
+
+// CheckRepos returns the repositories if they all exist, or the local and remote
+// repositories named after the permission target if none were provided
+func CheckRepos(svc *service.Service, name string, repos []string) ([]string, error) {
+	existing, err := listRepo.GetRepoKeys(svc)
+	if err != nil {
+		return nil, err
+	}
+	if len(repos) > 0 {
+		for _, r := range repos {
+			if _, ok := existing[r]; !ok {
+				return nil, errs.New(errs.NotFound, "Artifactory repository '%v' does not exist", r)
+			}
+		}
+		return repos, nil
+	}
+	for key, class := range existing {
+		if class != "virtual" && glob.Match(name+"-*", key) {
+			repos = append(repos, key)
+		}
+	}
+	if len(repos) == 0 {
+		return nil, errs.New(errs.NotFound, "no Artifactory repositories named '%v-*' found, create them first or provide the repositories", name)
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+// CreatePermissionTarget sends an HTTP Post request to create the permission target
+func CreatePermissionTarget(svc *service.Service, p Permission) error {
+	if err := svc.Do("POST", "v2/security/permissions/"+p.Name, p, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error creating Artifactory permission target '%v'", p.Name)
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Artifactory permission target '%v' created on '%v'.\n", p.Name, strings.Join(p.Repo.Repositories, "', '"))
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"fmt"
+	listRepo "omniactl/artifactory/list/repo"
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Repository classes
+const (
+	Local   = "local"
+	Remote  = "remote"
+	Virtual = "virtual"
+)
+
+// Classes are the repository classes created by default, in the order they are created
+var Classes = []string{Local, Remote, Virtual}
+
+// PackageTypes maps the supported package types to the upstream their remote
+// repositories proxy by default. Types without a default need --remote-url.
+var PackageTypes = map[string]string{
+	"maven":   "https://repo1.maven.org/maven2/",
+	"gradle":  "https://repo1.maven.org/maven2/",
+	"npm":     "https://registry.npmjs.org",
+	"pypi":    "https://files.pythonhosted.org",
+	"docker":  "https://registry-1.docker.io/",
+	"go":      "https://proxy.golang.org",
+	"helm":    "",
+	"generic": "",
+}
+
+// projectFormat is the format of the project prefix of repository keys
+var projectFormat = regexp.MustCompile(`^[a-z0-9][a-z0-9\-]*$`)
+
+// Repo is the body of a request to create an Artifactory repository
+type Repo struct {
+	Key                   string   `json:"key"`
+	RClass                string   `json:"rclass"`
+	PackageType           string   `json:"packageType"`
+	Description           string   `json:"description,omitempty"`
+	URL                   string   `json:"url,omitempty"`
+	Repositories          []string `json:"repositories,omitempty"`
+	DefaultDeploymentRepo string   `json:"defaultDeploymentRepo,omitempty"`
+}
+
+// Key returns the key of the project's repository of the package type and class
+// following the naming convention, e.g. msf-maven-local, msf-maven-remote and msf-maven
+func Key(project string, packageType string, class string) string {
+	if class == Virtual {
+		return fmt.Sprintf("%v-%v", project, packageType)
+	}
+	return fmt.Sprintf("%v-%v-%v", project, packageType, class)
+}
+
+// CreateRepos creates the project's repositories of the package type in each class,
+// named following the naming convention. The virtual repository aggregates the local
+// and remote repositories and deploys to the local one. Repositories which exist
+// already are left as they are.
+func CreateRepos(svc *service.Service, project string, packageType string, classes []string, remoteURL string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Create Artifactory repositories")
+
+	project = strings.ToLower(project)
+	if !projectFormat.MatchString(project) {
+		return errs.New(errs.Validation, "project '%v' can only contain the following characters: a-z, 0-9, -", project)
+	}
+	upstream, ok := PackageTypes[packageType]
+	if !ok {
+		types := []string{}
+		for t := range PackageTypes {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		return errs.New(errs.Validation, "unsupported package type '%v', use one of %v", packageType, strings.Join(types, ", "))
+	}
+	if len(classes) == 0 {
+		classes = Classes
+	}
+	for _, c := range classes {
+		if c != Local && c != Remote && c != Virtual {
+			return errs.New(errs.Validation, "unknown repository class '%v', use %v, %v or %v", c, Local, Remote, Virtual)
+		}
+	}
+	if remoteURL == "" {
+		remoteURL = upstream
+	}
+	if contains(classes, Remote) && remoteURL == "" {
+		return errs.New(errs.Validation, "no default upstream for '%v' repositories, set the URL of the remote repository", packageType)
+	}
+
+	existing, err := listRepo.GetRepoKeys(svc)
+	if err != nil {
+		return err
+	}
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	yellow := color.New(color.FgYellow)
+	fmt.Println("")
+	// create in the order local, remote, virtual, as the virtual repository refers to the others
+	for _, class := range Classes {
+		if !contains(classes, class) {
+			continue
+		}
+		key := Key(project, packageType, class)
+		if _, ok := existing[key]; ok {
+			yellow.Printf("Artifactory repository '%v' already exists.\n", key)
+			continue
+		}
+		repo := Repo{Key: key, RClass: class, PackageType: packageType, Description: fmt.Sprintf("%v %v %v repository", project, packageType, class)}
+		switch class {
+		case Remote:
+			repo.URL = remoteURL
+		case Virtual:
+			for _, c := range []string{Local, Remote} {
+				if _, ok := existing[Key(project, packageType, c)]; ok {
+					repo.Repositories = append(repo.Repositories, Key(project, packageType, c))
+				}
+			}
+			if _, ok := existing[Key(project, packageType, Local)]; ok {
+				repo.DefaultDeploymentRepo = Key(project, packageType, Local)
+			}
+		}
+		if err := CreateRepo(svc, repo); err != nil {
+			return err
+		}
+		existing[key] = class
+		whiteBold.Printf("Artifactory %v repository '%v' created.\n", class, key)
+	}
+	return nil
+}
+
+// CreateRepo sends an HTTP Put request to create the repository
+func CreateRepo(svc *service.Service, repo Repo) error {
+	if err := svc.Do("PUT", "repositories/"+repo.Key, repo, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error creating Artifactory repository '%v'", repo.Key)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
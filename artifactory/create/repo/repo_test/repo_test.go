@@ -0,0 +1,126 @@
+package repo_test
+
+import (
+	createPermission "omniactl/artifactory/create/permission"
+	createRepo "omniactl/artifactory/create/repo"
+	"omniactl/artifactory/fake"
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var (
+	// server is the fake Artifactory instance used by all tests in this package
+	server *fake.Server
+	// svc is the Artifactory service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddUser("e940001")
+	server.AddGroup("msf-devs")
+	server.AddRepo("gal-npm-local", "local", "npm")
+	svc = service.New(server.URL, server.Client())
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestCreateRepos(t *testing.T) {
+	if err := createRepo.CreateRepos(svc, "MSF", "maven", nil, ""); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	local, remote, virtual := server.Repo("msf-maven-local"), server.Repo("msf-maven-remote"), server.Repo("msf-maven")
+	if local == nil || local.Class != "local" || local.PackageType != "maven" {
+		t.Errorf("Local repository was not created as expected: %+v", local)
+	}
+	if remote == nil || remote.Class != "remote" || remote.URL != createRepo.PackageTypes["maven"] {
+		t.Errorf("Remote repository was not created as expected: %+v", remote)
+	}
+	if virtual == nil || !reflect.DeepEqual(virtual.Repositories, []string{"msf-maven-local", "msf-maven-remote"}) || virtual.DefaultDeploymentRepo != "msf-maven-local" {
+		t.Errorf("Virtual repository was not created as expected: %+v", virtual)
+	}
+}
+
+func TestCreateReposExisting(t *testing.T) {
+	// gal-npm-local exists, so only the virtual repository is created and aggregates it
+	if err := createRepo.CreateRepos(svc, "gal", "npm", []string{"local", "virtual"}, ""); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if server.Repo("gal-npm-remote") != nil {
+		t.Error("Remote repository was created although not requested")
+	}
+	if virtual := server.Repo("gal-npm"); virtual == nil || !reflect.DeepEqual(virtual.Repositories, []string{"gal-npm-local"}) {
+		t.Errorf("Virtual repository was not created as expected: %+v", virtual)
+	}
+}
+
+func TestCreateReposErrors(t *testing.T) {
+	type test struct {
+		name        string
+		project     string
+		packageType string
+		classes     []string
+		want        errs.Kind
+	}
+	tests := []test{
+		{"invalid project", "m s f", "maven", nil, errs.Validation},
+		{"unknown package type", "msf", "cobol", nil, errs.Validation},
+		{"unknown class", "msf", "maven", []string{"cloud"}, errs.Validation},
+		{"remote without upstream", "msf", "generic", nil, errs.Validation},
+	}
+	for _, tc := range tests {
+		err := createRepo.CreateRepos(svc, tc.project, tc.packageType, tc.classes, "")
+		if !errs.Is(err, tc.want) {
+			t.Errorf("Creating repositories with %v: expected '%v' error Got '%v'", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestCreatePermission(t *testing.T) {
+	server.AddRepo("ops-docker-local", "local", "docker")
+	server.AddRepo("ops-docker-remote", "remote", "docker")
+	server.AddRepo("ops-docker", "virtual", "docker")
+	if err := createPermission.CreatePermission(svc, "ops", nil, []string{"e940001"}, []string{"msf-devs"}, nil); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	p := server.Permission("ops")
+	if p == nil {
+		t.Fatal("Permission target 'ops' was not created")
+	}
+	if !reflect.DeepEqual(p.Repositories, []string{"ops-docker-local", "ops-docker-remote"}) {
+		t.Errorf("Expected the local and remote repositories Got %v", p.Repositories)
+	}
+	if !reflect.DeepEqual(p.Users["e940001"], createPermission.DefaultActions) || !reflect.DeepEqual(p.Groups["msf-devs"], createPermission.DefaultActions) {
+		t.Errorf("Expected the default actions for user and group Got %v and %v", p.Users, p.Groups)
+	}
+
+	type test struct {
+		name    string
+		target  string
+		repos   []string
+		users   []string
+		actions []string
+		want    errs.Kind
+	}
+	tests := []test{
+		{"existing target", "ops", nil, []string{"e940001"}, nil, errs.AlreadyExists},
+		{"no users or groups", "new", nil, nil, nil, errs.Validation},
+		{"unknown action", "new", nil, []string{"e940001"}, []string{"deploy"}, errs.Validation},
+		{"no matching repositories", "new", nil, []string{"e940001"}, nil, errs.NotFound},
+		{"unknown repository", "new", []string{"nope-local"}, []string{"e940001"}, nil, errs.NotFound},
+		{"unknown user", "new", []string{"ops-docker-local"}, []string{"e999999"}, nil, errs.NotFound},
+	}
+	for _, tc := range tests {
+		err := createPermission.CreatePermission(svc, tc.target, tc.repos, tc.users, nil, tc.actions)
+		if !errs.Is(err, tc.want) {
+			t.Errorf("Creating permission target with %v: expected '%v' error Got '%v'", tc.name, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+// Package fake provides an in-process fake of the Artifactory REST API for tests.
+// It keeps users, groups, repositories and permission targets in memory, so tests
+// can run against it without network access.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Credentials of the administrator the fake client authenticates as
+const (
+	AdminLogin    = "admin"
+	AdminPassword = "secret"
+)
+
+// AccessToken is the access token issued to the administrator
+const AccessToken = "fake-artifactory-token"
+
+// Version is the Artifactory version reported by the fake server
+const Version = "6.9.0"
+
+// Group is an Artifactory group held by the fake server
+type Group struct {
+	Name        string
+	Description string
+	Users       []string
+}
+
+// Repo is an Artifactory repository held by the fake server
+type Repo struct {
+	Key                   string
+	Class                 string
+	PackageType           string
+	URL                   string
+	Repositories          []string
+	DefaultDeploymentRepo string
+}
+
+// Permission is an Artifactory permission target held by the fake server, with the
+// actions of each user and group on its repositories
+type Permission struct {
+	Name            string
+	Repositories    []string
+	IncludePatterns []string
+	Users           map[string][]string
+	Groups          map[string][]string
+}
+
+// Server is an in-process fake Artifactory instance
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	users       map[string]bool
+	groups      map[string]*Group
+	repos       map[string]*Repo
+	permissions map[string]*Permission
+}
+
+// NewServer starts a fake Artifactory server containing the administrator.
+// The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		users:       map[string]bool{AdminLogin: true},
+		groups:      make(map[string]*Group),
+		repos:       make(map[string]*Repo),
+		permissions: make(map[string]*Permission),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an HTTP client authenticating as the administrator
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: &basicAuth{AdminLogin, AdminPassword}}
+}
+
+// basicAuth sets the credentials of every request it sends
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (b *basicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	clone.SetBasicAuth(b.username, b.password)
+	return http.DefaultTransport.RoundTrip(clone)
+}
+
+// AddUser adds a user to the fake server
+func (s *Server) AddUser(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[name] = true
+}
+
+// AddGroup adds a group with the provided members to the fake server
+func (s *Server) AddGroup(name string, users ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[name] = &Group{Name: name, Users: users}
+}
+
+// AddRepo adds a repository of the class, i.e. local, remote or virtual, to the fake server
+func (s *Server) AddRepo(key string, class string, packageType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos[key] = &Repo{Key: key, Class: class, PackageType: packageType}
+}
+
+// Group returns a copy of the group with the provided name, or nil if it does not exist
+func (s *Server) Group(name string) *Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.groups[name]
+	if !ok {
+		return nil
+	}
+	c := *g
+	c.Users = append([]string{}, g.Users...)
+	return &c
+}
+
+// Repo returns a copy of the repository with the provided key, or nil if it does not exist
+func (s *Server) Repo(key string) *Repo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.repos[key]
+	if !ok {
+		return nil
+	}
+	c := *r
+	c.Repositories = append([]string{}, r.Repositories...)
+	return &c
+}
+
+// Permission returns the permission target with the provided name, or nil if it does not exist
+func (s *Server) Permission(name string) *Permission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.permissions[name]
+	if !ok {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format of the Artifactory REST API
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"errors": []interface{}{map[string]interface{}{"status": status, "message": message}}})
+}
+
+// writeText writes a plain text response, as Artifactory does for many updates
+func writeText(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	fmt.Fprint(w, text)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if auth != "Bearer "+AccessToken {
+			writeError(w, http.StatusUnauthorized, "Bad credentials")
+			return
+		}
+	} else if username, password, ok := r.BasicAuth(); !ok || username != AdminLogin || password != AdminPassword {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/artifactory/api"), "/")
+	segments := strings.Split(path, "/")
+	name := segments[len(segments)-1]
+
+	switch {
+	case r.Method == "GET" && len(segments) == 3 && strings.HasPrefix(path, "security/users/"):
+		if !s.users[name] {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("User '%v' does not exist", name))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"name": name, "admin": name == AdminLogin})
+
+	case r.Method == "POST" && path == "security/token":
+		r.ParseForm()
+		if r.PostForm.Get("username") != AdminLogin || r.PostForm.Get("scope") == "" {
+			writeError(w, http.StatusBadRequest, "Only the administrator's own tokens are supported")
+			return
+		}
+		expiresIn, _ := strconv.Atoi(r.PostForm.Get("expires_in"))
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": AccessToken, "expires_in": expiresIn, "scope": r.PostForm.Get("scope"), "token_type": "Bearer",
+		})
+
+	case r.Method == "GET" && path == "system/version":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"version": Version, "revision": "60900900"})
+
+	case r.Method == "GET" && path == "security/groups":
+		names := []string{}
+		for name := range s.groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		items := []interface{}{}
+		for _, name := range names {
+			items = append(items, map[string]interface{}{"name": name, "uri": s.URL + "/artifactory/api/security/groups/" + name})
+		}
+		writeJSON(w, http.StatusOK, items)
+
+	case r.Method == "GET" && len(segments) == 3 && strings.HasPrefix(path, "security/groups/"):
+		g, ok := s.groups[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Group '%v' does not exist", name))
+			return
+		}
+		v := map[string]interface{}{"name": g.Name, "description": g.Description, "autoJoin": false, "realm": "internal"}
+		if r.URL.Query().Get("includeUsers") == "true" {
+			v["userNames"] = g.Users
+		}
+		writeJSON(w, http.StatusOK, v)
+
+	case r.Method == "PUT" && len(segments) == 3 && strings.HasPrefix(path, "security/groups/"):
+		body := struct {
+			Description string   `json:"description"`
+			UserNames   []string `json:"userNames"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.groups[name] = &Group{Name: name, Description: body.Description, Users: body.UserNames}
+		writeText(w, http.StatusCreated, "")
+
+	case r.Method == "POST" && len(segments) == 3 && strings.HasPrefix(path, "security/groups/"):
+		g, ok := s.groups[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Group '%v' does not exist", name))
+			return
+		}
+		body := struct {
+			Description *string   `json:"description"`
+			UserNames   *[]string `json:"userNames"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Description != nil {
+			g.Description = *body.Description
+		}
+		if body.UserNames != nil {
+			for _, u := range *body.UserNames {
+				if !s.users[u] {
+					writeError(w, http.StatusBadRequest, fmt.Sprintf("User '%v' does not exist", u))
+					return
+				}
+			}
+			g.Users = *body.UserNames
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"name": g.Name, "description": g.Description, "userNames": g.Users})
+
+	case r.Method == "GET" && path == "repositories":
+		keys := []string{}
+		for key, repo := range s.repos {
+			if t := r.URL.Query().Get("type"); t == "" || t == repo.Class {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		items := []interface{}{}
+		for _, key := range keys {
+			repo := s.repos[key]
+			items = append(items, map[string]interface{}{"key": key, "type": strings.ToUpper(repo.Class), "packageType": repo.PackageType, "url": s.URL + "/artifactory/" + key})
+		}
+		writeJSON(w, http.StatusOK, items)
+
+	case r.Method == "PUT" && len(segments) == 2 && segments[0] == "repositories":
+		body := struct {
+			Key                   string   `json:"key"`
+			RClass                string   `json:"rclass"`
+			PackageType           string   `json:"packageType"`
+			URL                   string   `json:"url"`
+			Repositories          []string `json:"repositories"`
+			DefaultDeploymentRepo string   `json:"defaultDeploymentRepo"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		for key := range s.repos {
+			if strings.EqualFold(key, name) {
+				writeError(w, http.StatusBadRequest, "Case insensitive repository key already exists")
+				return
+			}
+		}
+		switch {
+		case body.RClass != "local" && body.RClass != "remote" && body.RClass != "virtual":
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid repository class '%v'", body.RClass))
+			return
+		case body.RClass == "remote" && body.URL == "":
+			writeError(w, http.StatusBadRequest, "Remote repositories need a URL")
+			return
+		}
+		for _, key := range body.Repositories {
+			if _, ok := s.repos[key]; !ok {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Repository '%v' does not exist", key))
+				return
+			}
+		}
+		s.repos[name] = &Repo{Key: name, Class: body.RClass, PackageType: body.PackageType, URL: body.URL, Repositories: body.Repositories, DefaultDeploymentRepo: body.DefaultDeploymentRepo}
+		writeText(w, http.StatusOK, fmt.Sprintf("Successfully created repository '%v'", name))
+
+	case r.Method == "GET" && len(segments) == 4 && strings.HasPrefix(path, "v2/security/permissions/"):
+		p, ok := s.permissions[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Permission target '%v' does not exist", name))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"name": p.Name, "repo": map[string]interface{}{
+			"repositories":     p.Repositories,
+			"include-patterns": p.IncludePatterns,
+			"actions":          map[string]interface{}{"users": p.Users, "groups": p.Groups},
+		}})
+
+	case r.Method == "POST" && len(segments) == 4 && strings.HasPrefix(path, "v2/security/permissions/"):
+		if _, ok := s.permissions[name]; ok {
+			writeError(w, http.StatusConflict, fmt.Sprintf("Permission target '%v' already exists", name))
+			return
+		}
+		body := struct {
+			Repo struct {
+				Repositories    []string `json:"repositories"`
+				IncludePatterns []string `json:"include-patterns"`
+				Actions         struct {
+					Users  map[string][]string `json:"users"`
+					Groups map[string][]string `json:"groups"`
+				} `json:"actions"`
+			} `json:"repo"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, key := range body.Repo.Repositories {
+			if _, ok := s.repos[key]; !ok {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Repository '%v' does not exist", key))
+				return
+			}
+		}
+		for u := range body.Repo.Actions.Users {
+			if !s.users[u] {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("User '%v' does not exist", u))
+				return
+			}
+		}
+		for g := range body.Repo.Actions.Groups {
+			if _, ok := s.groups[g]; !ok {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Group '%v' does not exist", g))
+				return
+			}
+		}
+		s.permissions[name] = &Permission{
+			Name:            name,
+			Repositories:    body.Repo.Repositories,
+			IncludePatterns: body.Repo.IncludePatterns,
+			Users:           body.Repo.Actions.Users,
+			Groups:          body.Repo.Actions.Groups,
+		}
+		writeText(w, http.StatusCreated, "")
+
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
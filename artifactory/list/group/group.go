@@ -0,0 +1,46 @@
+package group
+
+import (
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+)
+
+// Group is a group as returned by the Artifactory REST API
+type Group struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	UserNames   []string `json:"userNames"`
+}
+
+// GetGroup returns the group with the provided name and its members, or a NotFound error
+func GetGroup(svc *service.Service, name string) (*Group, error) {
+	g := &Group{}
+	path := service.Query("security/groups/"+name, map[string]string{"includeUsers": "true"})
+	if err := svc.Do("GET", path, nil, g); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting Artifactory group '%v'", name)
+	}
+	return g, nil
+}
+
+// CheckIfGroupExists checks if an Artifactory group with the provided name exists
+func CheckIfGroupExists(svc *service.Service, name string) (bool, error) {
+	_, err := GetGroup(svc, name)
+	if errs.Is(err, errs.NotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CheckGroups returns a NotFound error naming the first group which does not exist
+func CheckGroups(svc *service.Service, names []string) error {
+	for _, name := range names {
+		exists, err := CheckIfGroupExists(svc, name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errs.New(errs.NotFound, "Artifactory group '%v' does not exist", name)
+		}
+	}
+	return nil
+}
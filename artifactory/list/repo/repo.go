@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+	"strings"
+)
+
+// Repo is a repository as returned by the Artifactory REST API
+type Repo struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	PackageType string `json:"packageType"`
+	URL         string `json:"url"`
+}
+
+// Class returns the class of the repository, i.e. local, remote or virtual
+func (r Repo) Class() string {
+	return strings.ToLower(r.Type)
+}
+
+// GetRepos returns all repositories
+func GetRepos(svc *service.Service) ([]Repo, error) {
+	repos := []Repo{}
+	if err := svc.Do("GET", "repositories", nil, &repos); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting Artifactory repositories")
+	}
+	return repos, nil
+}
+
+// GetRepoKeys returns the keys of all repositories, mapped to their class
+func GetRepoKeys(svc *service.Service) (map[string]string, error) {
+	repos, err := GetRepos(svc)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]string)
+	for _, r := range repos {
+		keys[r.Key] = r.Class()
+	}
+	return keys, nil
+}
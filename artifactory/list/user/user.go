@@ -0,0 +1,32 @@
+package user
+
+import (
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+)
+
+// CheckIfUserExists checks if an Artifactory user with the provided name exists
+func CheckIfUserExists(svc *service.Service, name string) (bool, error) {
+	err := svc.Do("GET", "security/users/"+name, nil, nil)
+	if errs.Is(err, errs.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errs.Wrap(errs.KindOf(err), err, "error getting Artifactory user '%v'", name)
+	}
+	return true, nil
+}
+
+// CheckUsers returns a NotFound error naming the first user which does not exist
+func CheckUsers(svc *service.Service, names []string) error {
+	for _, name := range names {
+		exists, err := CheckIfUserExists(svc, name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errs.New(errs.NotFound, "Artifactory user '%v' does not exist", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"fmt"
+	addUser "omniactl/artifactory/add/user"
+	listGroup "omniactl/artifactory/list/group"
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// RemoveUsers removes the users from the Artifactory group once confirmed.
+// Users who are not members are skipped.
+func RemoveUsers(svc *service.Service, group string, users []string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Remove users from Artifactory group")
+
+	if group == "" || len(users) == 0 {
+		return errs.New(errs.Validation, "an Artifactory group and at least one user are required")
+	}
+	g, err := listGroup.GetGroup(svc, group)
+	if err != nil {
+		return err
+	}
+
+	members := []string{}
+	removed := []string{}
+	for _, m := range g.UserNames {
+		if contains(users, m) {
+			removed = append(removed, m)
+			continue
+		}
+		members = append(members, m)
+	}
+	for _, u := range users {
+		if !contains(removed, u) {
+			color.New(color.FgYellow).Printf("User '%v' is not a member of Artifactory group '%v'.\n", u, group)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	check, err := PromptRemove(group, removed)
+	if err != nil {
+		return err
+	}
+	if check != "yes" {
+		return nil
+	}
+	if err := addUser.UpdateMembers(svc, group, members); err != nil {
+		return err
+	}
+	fmt.Println("")
+	for _, u := range removed {
+		color.New(color.FgHiWhite, color.Bold).Printf("User '%v' removed from Artifactory group '%v'.\n", u, group)
+	}
+	return nil
+}
+
+// PromptRemove asks for confirmation before the users are removed
+func PromptRemove(group string, users []string) (string, error) {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Remove '%v' from Artifactory group '%v'?", strings.Join(users, "', '"), group),
+		Items: []string{"yes", "no"},
+	}
+	return interactive.Select(prompt, "yes")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"omniactl/errs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API is the path of the Artifactory REST API below the Artifactory host
+const API = "artifactory/api/"
+
+// TokenPath is the path access tokens are created at below the Artifactory host
+const TokenPath = API + "security/token"
+
+// Service holds the Artifactory client shared by the artifactory sub-packages.
+// It is created once by the cobra layer and passed to every function
+// which interacts with the Artifactory API.
+type Service struct {
+	// Client sends the requests, authenticating them
+	Client *http.Client
+	// URL is the Artifactory host from the config file, e.g. https://artifactory.example.com
+	URL string
+	// Username is the login requests are authenticated as
+	Username string
+}
+
+// New returns a Service sending requests to the Artifactory instance at artifactoryURL
+// with the provided client. The URL may include the /artifactory context path.
+func New(artifactoryURL string, client *http.Client) *Service {
+	u := strings.TrimSuffix(strings.TrimSuffix(artifactoryURL, "/"), "/artifactory")
+	return &Service{Client: client, URL: u}
+}
+
+// Token creates an access token for the user with the username and password, which
+// authenticates later requests instead of the password until it expires after ttl.
+// The token has the permissions of the groups of the user.
+func Token(client *http.Client, artifactoryURL string, username string, password string, ttl time.Duration) (string, time.Time, error) {
+	form := url.Values{}
+	form.Set("username", username)
+	form.Set("scope", "member-of-groups:*")
+	form.Set("expires_in", strconv.FormatInt(int64(ttl/time.Second), 10))
+	req, err := http.NewRequest("POST", New(artifactoryURL, client).URL+"/"+TokenPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	req.SetBasicAuth(username, password)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, errs.Wrap(errs.Unavailable, err, "error sending request to Artifactory")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, errs.Wrap(errs.Unavailable, err, "error reading response from Artifactory")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := errorMessage(data)
+		if message == "" {
+			message = resp.Status
+		}
+		return "", time.Time{}, errs.New(errs.FromStatus(resp.StatusCode, message), "Artifactory login as '%v' failed: %v", username, message)
+	}
+	token := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+	if err := json.Unmarshal(data, &token); err != nil || token.AccessToken == "" {
+		return "", time.Time{}, errs.New(errs.Internal, "Artifactory login as '%v' returned no access token", username)
+	}
+	expiry := time.Now().Add(ttl)
+	if token.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token.AccessToken, expiry, nil
+}
+
+// Do sends a request to the Artifactory REST API, encoding body as JSON if it is not nil,
+// and decodes the JSON response into result if it is not nil. path is relative to
+// the REST API, e.g. "security/groups/msf-devs".
+func (s *Service) Do(method string, path string, body interface{}, result interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return errs.Wrap(errs.Internal, err, "error encoding request")
+		}
+	}
+	req, err := http.NewRequest(method, s.URL+"/"+API+path, &buf)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return errs.Wrap(errs.Unavailable, err, "error sending request to Artifactory")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errs.Wrap(errs.Unavailable, err, "error reading response from Artifactory")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := errorMessage(data)
+		if message == "" {
+			message = resp.Status
+		}
+		return errs.New(errs.FromStatus(resp.StatusCode, message), "%v", message)
+	}
+	if result == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return errs.Wrap(errs.Internal, err, "error decoding response from Artifactory")
+	}
+	return nil
+}
+
+// errorMessage returns the messages of an Artifactory error response, e.g.
+// {"errors": [{"status": 404, "message": "..."}]}, or the body if it is plain text
+func errorMessage(data []byte) string {
+	artErr := struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	if err := json.Unmarshal(data, &artErr); err != nil {
+		return strings.TrimSpace(string(data))
+	}
+	messages := []string{}
+	for _, e := range artErr.Errors {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Query returns path with the values appended as an escaped query string
+func Query(path string, values map[string]string) string {
+	v := url.Values{}
+	for key, value := range values {
+		v.Set(key, value)
+	}
+	return path + "?" + v.Encode()
+}
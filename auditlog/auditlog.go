@@ -0,0 +1,483 @@
+// Package auditlog keeps an append-only log of every request which changes anything
+// on the systems omniactl administers. Each entry is a line of JSON holding the
+// operator, i.e. the local user running omniactl, the account the request is
+// authenticated as, time, target, a summary of the request and its outcome. A pending entry
+// is written before a request is sent and an entry with its outcome once it was
+// answered, so that a request is logged even if its outcome cannot be. Entries are
+// chained by hashing each entry together with the hash of the previous one, so that
+// editing or removing an entry breaks the chain from that entry on.
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"omniactl/output"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// EnvFile overrides the file the audit log is written to, by default ~/.omniactl.d/audit.log
+const EnvFile = "OMNIACTL_AUDIT_LOG"
+
+// Outcomes of a request. Pending is the outcome of the entry written before the
+// request is sent.
+const (
+	Pending = "pending"
+	Success = "success"
+	Failure = "failure"
+)
+
+// redacted are the keys of request bodies which are never written to the log, in
+// lower case. Keys are matched exactly, so that identifiers such as the "key" of a
+// Jira project or Artifactory repository are still logged.
+var redacted = map[string]bool{
+	"password":     true,
+	"token":        true,
+	"access_token": true,
+	"client_token": true,
+	"secret":       true,
+	"secret_id":    true,
+	"apikey":       true,
+	"private_key":  true,
+}
+
+// Entry is a single administrative action
+type Entry struct {
+	Time     time.Time `json:"time" yaml:"time"`
+	Operator string    `json:"operator" yaml:"operator"`
+	// Account is the login of the system the request was authenticated as
+	Account string `json:"account,omitempty" yaml:"account,omitempty"`
+	System  string `json:"system" yaml:"system"`
+	Action  string `json:"action" yaml:"action"`
+	Target  string `json:"target" yaml:"target"`
+	Request string `json:"request" yaml:"request"`
+	Status  int    `json:"status" yaml:"status"`
+	Outcome string `json:"outcome" yaml:"outcome"`
+	Error   string `json:"error" yaml:"error"`
+	// Completes is the hash of the pending entry whose outcome this entry records
+	Completes string `json:"completes,omitempty" yaml:"completes,omitempty"`
+	PrevHash  string `json:"prev_hash" yaml:"prev_hash"`
+	Hash      string `json:"hash" yaml:"hash"`
+}
+
+// Sum returns the hash of the entry, covering every field but Hash itself
+func (e Entry) Sum() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Transport is an http.RoundTripper which writes entries to the audit log for every
+// request that changes anything, i.e. any method but GET and HEAD which is not marked
+// with dryrun.ReadOnly: a pending entry before the request is sent and one with its
+// outcome afterwards. If the pending entry cannot be written, the request is not sent.
+// Requests printed with --dry-run are not logged.
+type Transport struct {
+	// Base is the transport requests are sent with, http.DefaultTransport if nil
+	Base http.RoundTripper
+	// System is the name of the system requests are sent to, e.g. github
+	System string
+	// Operator is the person sending the requests, the local user running omniactl if empty
+	Operator string
+	// Account is the login of the system requests are authenticated as, e.g. the
+	// shared admin account from Vault
+	Account string
+}
+
+// RoundTrip sends the request and logs its outcome
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if dryrun.IsReadOnly(req) || dryrun.Enabled {
+		return base.RoundTrip(req)
+	}
+	body := []byte{}
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		clone := req.Clone(req.Context())
+		clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req = clone
+	}
+
+	operator := t.Operator
+	if operator == "" {
+		operator = Operator()
+	}
+	pending := &Entry{
+		Time:     time.Now().UTC(),
+		Operator: operator,
+		Account:  t.Account,
+		System:   t.System,
+		Action:   req.Method + " " + req.URL.Path,
+		Target:   req.URL.RequestURI(),
+		Request:  Summarise(body, req.Header.Get("Content-Type")),
+		Outcome:  Pending,
+	}
+	if err := Append(pending); err != nil {
+		return nil, err
+	}
+
+	resp, err := base.RoundTrip(req)
+	entry := *pending
+	entry.Time = time.Now().UTC()
+	entry.Completes = pending.Hash
+	switch {
+	case err != nil:
+		entry.Outcome = Failure
+		entry.Error = err.Error()
+	case resp.StatusCode >= 300:
+		entry.Status = resp.StatusCode
+		entry.Outcome = Failure
+		entry.Error = resp.Status
+	default:
+		entry.Status = resp.StatusCode
+		entry.Outcome = Success
+	}
+	// The request was sent and is logged as pending, so its outcome is returned
+	// even if it cannot be logged
+	if logErr := Append(&entry); logErr != nil {
+		color.New(color.FgYellow, color.Bold).Fprintf(os.Stderr, "Warning: outcome of %v was not written to the audit log: %v\n", entry.Action, logErr)
+	}
+	return resp, err
+}
+
+// Operator returns the login of the local user running omniactl, so that entries name
+// the person who ran a command rather than the shared account it authenticates as
+func Operator() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// Summarise returns the compact JSON of a request body, or the encoded form of a
+// form-encoded one, with the values of passwords, tokens, secrets and keys replaced.
+// Of any other body only the length and content type are returned, as what it holds
+// cannot be redacted.
+func Summarise(body []byte, contentType string) string {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		data, _ := json.Marshal(redact(v))
+		return string(data)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for k := range form {
+				if redacted[strings.ToLower(k)] {
+					form[k] = []string{"REDACTED"}
+				}
+			}
+			return form.Encode()
+		}
+	}
+	if contentType == "" {
+		contentType = "unknown content type"
+	}
+	return fmt.Sprintf("%v bytes of %v", len(body), contentType)
+}
+
+func redact(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range value {
+			value[k] = redact(vv)
+			if redacted[strings.ToLower(k)] {
+				value[k] = "REDACTED"
+			}
+		}
+	case []interface{}:
+		for i, vv := range value {
+			value[i] = redact(vv)
+		}
+	}
+	return v
+}
+
+// File returns the path of the audit log
+func File() (string, error) {
+	if file := os.Getenv(EnvFile); file != "" {
+		return file, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", errs.Wrap(errs.Config, err, "error finding home directory")
+	}
+	return filepath.Join(home, ".omniactl.d", "audit.log"), nil
+}
+
+// mu serialises writes to the audit log, as requests may be sent concurrently.
+// Writes of other omniactl processes are serialised by locking the file.
+var mu sync.Mutex
+
+// Append chains the entry to the last entry of the audit log and writes it.
+// The log is locked while its last hash is read and the entry is written, so
+// that concurrent omniactl processes cannot fork the chain.
+func Append(entry *Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+	file, err := File()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return errs.Wrap(errs.Config, err, "error creating audit log directory")
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return errs.Wrap(errs.Config, err, "audit log '%v' cannot be written, no changes are made without it", file)
+	}
+	defer f.Close()
+	if err := lock(f); err != nil {
+		return errs.Wrap(errs.Internal, err, "error locking audit log '%v'", file)
+	}
+	defer unlock(f)
+
+	last, err := lastLine(f)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error reading audit log '%v'", file)
+	}
+	entry.PrevHash = ""
+	if len(last) > 0 {
+		prev := Entry{}
+		if err := json.Unmarshal(last, &prev); err != nil {
+			return errs.Wrap(errs.Validation, err, "error parsing the last line of audit log '%v'", file)
+		}
+		entry.PrevHash = prev.Hash
+	}
+	entry.Hash = entry.Sum()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error encoding audit log entry")
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return errs.Wrap(errs.Internal, err, "error writing audit log")
+	}
+	return nil
+}
+
+// lastLine returns the last line of f without its newline, reading f backwards
+// from its end, or nothing if f is empty
+func lastLine(f *os.File) ([]byte, error) {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	line := []byte{}
+	chunk := make([]byte, 4096)
+	for offset := size; offset > 0; {
+		n := int64(len(chunk))
+		if offset < n {
+			n = offset
+		}
+		offset -= n
+		if _, err := f.ReadAt(chunk[:n], offset); err != nil {
+			return nil, err
+		}
+		line = append(append([]byte{}, chunk[:n]...), line...)
+		trimmed := bytes.TrimRight(line, "\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+	}
+	return bytes.TrimRight(line, "\n"), nil
+}
+
+// Read returns the entries of the audit log, or none if it does not exist
+func Read(file string) ([]Entry, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, errs.Wrap(errs.Config, err, "error opening audit log")
+	}
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errs.Wrap(errs.Validation, err, "error parsing line %v of audit log '%v'", line, file)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errs.Wrap(errs.Internal, err, "error reading audit log")
+	}
+	return entries, nil
+}
+
+// Verify checks the hash chain of the entries and returns a validation error naming
+// the first line which was edited, or after which entries were inserted or removed
+func Verify(entries []Entry) error {
+	prev := ""
+	for i, entry := range entries {
+		if entry.PrevHash != prev {
+			return errs.New(errs.Validation, "audit log chain broken at line %v: entries before it were changed, inserted or removed", i+1)
+		}
+		if entry.Sum() != entry.Hash {
+			return errs.New(errs.Validation, "audit log entry on line %v was changed", i+1)
+		}
+		prev = entry.Hash
+	}
+	return nil
+}
+
+// Query selects entries of the audit log. Empty fields match every entry.
+type Query struct {
+	// User matches the operator, the account or a user named in the target, e.g.
+	// users/e123456 or user?username=e123456
+	User string
+	// Action matches entries whose action contains it, case insensitively
+	Action string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Filter returns the entries matching the query. Pending entries are left out once
+// an entry with their outcome follows, so that each request is listed once.
+func Filter(entries []Entry, q Query) []Entry {
+	completed := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Completes != "" {
+			completed[entry.Completes] = true
+		}
+	}
+	matches := []Entry{}
+	for _, entry := range entries {
+		if entry.Outcome == Pending && completed[entry.Hash] {
+			continue
+		}
+		if q.User != "" && entry.Operator != q.User && entry.Account != q.User && !contains(strings.FieldsFunc(entry.Target, isSeparator), q.User) {
+			continue
+		}
+		if q.Action != "" && !strings.Contains(strings.ToLower(entry.Action), strings.ToLower(q.Action)) {
+			continue
+		}
+		if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+			continue
+		}
+		if !q.Until.IsZero() && !entry.Time.Before(q.Until) {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+	return matches
+}
+
+// isSeparator splits the path and query of a target into its segments
+func isSeparator(r rune) bool {
+	return strings.ContainsRune("/?&=", r)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTime parses a date, e.g. 2019-03-01, or a time in RFC 3339 format, e.g.
+// 2019-03-01T12:00:00Z. If end is set, a date is taken as the end of that day.
+func ParseTime(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, errs.New(errs.Validation, "'%v' is neither a date, e.g. 2019-03-01, nor a time, e.g. 2019-03-01T12:00:00Z", s)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// ShowLog prints the entries of the audit log matching the query, after checking
+// the hash chain of the whole log if verify is set
+func ShowLog(q Query, verify bool) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Show audit log")
+	file, err := File()
+	if err != nil {
+		return err
+	}
+	entries, err := Read(file)
+	if err != nil {
+		return err
+	}
+	if verify {
+		if err := Verify(entries); err != nil {
+			return err
+		}
+		color.New(color.FgGreen, color.Bold).Printf("Hash chain of %v entries in '%v' verified.\n", len(entries), file)
+	}
+
+	matches := Filter(entries, q)
+	if !output.IsText() {
+		return output.Print(matches)
+	}
+	fmt.Println("")
+	red := color.New(color.FgRed)
+	for _, e := range matches {
+		operator := e.Operator
+		if e.Account != "" {
+			operator = fmt.Sprintf("%v (as %v)", e.Operator, e.Account)
+		}
+		line := fmt.Sprintf("%v  %-20v %-8v %v", e.Time.Format(time.RFC3339), operator, e.System, e.Action)
+		if e.Outcome == Success {
+			fmt.Printf("%v  %v %v\n", line, e.Outcome, e.Status)
+			continue
+		}
+		if e.Outcome == Pending {
+			color.New(color.FgYellow).Printf("%v  %v, no outcome was logged\n", line, e.Outcome)
+			continue
+		}
+		red.Printf("%v  %v %v\n", line, e.Outcome, e.Error)
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("%v of %v entries match.\n", len(matches), len(entries))
+	return nil
+}
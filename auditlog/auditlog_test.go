@@ -0,0 +1,283 @@
+package auditlog_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"omniactl/auditlog"
+	"omniactl/dryrun"
+	createUser "omniactl/github/create/user"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	suspendUser "omniactl/github/suspend/user"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package, logging requests to the audit log
+	svc *service.Service
+	// dir holds the audit logs written by the tests
+	dir string
+)
+
+// envAppend makes the test binary append that many entries to the audit log and exit,
+// so that tests can append from several processes at once
+const envAppend = "AUDITLOG_TEST_APPEND"
+
+func TestMain(m *testing.M) {
+	if n, err := strconv.Atoi(os.Getenv(envAppend)); err == nil {
+		for i := 0; i < n; i++ {
+			if err := auditlog.Append(&auditlog.Entry{Time: time.Now().UTC(), Action: "PUT /api/v3/users/e400001/suspended"}); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		os.Exit(0)
+	}
+
+	server = fake.NewServer()
+	server.AddUser("e400001", "e400001@statestreet.com")
+
+	transport := &auditlog.Transport{System: "github", Account: fake.AdminLogin}
+	client, err := github.NewEnterpriseClient(server.URL+"/api/v3/", server.URL+"/api/uploads/", &http.Client{Transport: transport})
+	if err != nil {
+		panic(err)
+	}
+	svc = service.New(client)
+
+	dir, err = ioutil.TempDir("", "auditlog_test")
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// useLog points the audit log at a new file in the test directory and returns its path
+func useLog(t *testing.T, name string) string {
+	file := filepath.Join(dir, name)
+	os.Setenv(auditlog.EnvFile, file)
+	return file
+}
+
+func TestTransportLogsMutatingRequests(t *testing.T) {
+	file := useLog(t, "mutating.log")
+	if _, _, err := createUser.CreateUser(svc, "e400002", "e400002@statestreet.com"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := suspendUser.SuspendFromGithub(svc, "e400001", "left the company"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	// Lookups are not logged, failed changes are
+	if _, _, err := svc.Client.Users.Get(context.Background(), "e400001"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := suspendUser.SuspendFromGithub(svc, "e499999", "no such user"); err == nil {
+		t.Fatal("Expected error suspending a missing user")
+	}
+
+	entries, err := auditlog.Read(file)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	type test struct {
+		action  string
+		outcome string
+		request string
+	}
+	tests := []test{
+		{"POST /api/v3/admin/users", auditlog.Success, `{"email":"e400002@statestreet.com","id":0,"login":"e400002"}`},
+		{"PUT /api/v3/users/e400001/suspended", auditlog.Success, `{"reason":"left the company"}`},
+		{"PUT /api/v3/users/e499999/suspended", auditlog.Failure, `{"reason":"no such user"}`},
+	}
+	// Each request is logged as pending before it is sent, then with its outcome
+	if len(entries) != 2*len(tests) {
+		t.Fatalf("Got %v entries, want %v: %v", len(entries), 2*len(tests), entries)
+	}
+	for i, tc := range tests {
+		pending, e := entries[2*i], entries[2*i+1]
+		if pending.Action != tc.action || pending.Outcome != auditlog.Pending || pending.Request != tc.request {
+			t.Errorf("Entry %v got %+v, want pending action '%v' and request '%v'", 2*i+1, pending, tc.action, tc.request)
+		}
+		if e.Action != tc.action || e.Outcome != tc.outcome || e.Request != tc.request || e.System != "github" || e.Completes != pending.Hash {
+			t.Errorf("Entry %v got %+v, want action '%v', outcome '%v' and request '%v' completing entry %v", 2*i+2, e, tc.action, tc.outcome, tc.request, 2*i+1)
+		}
+		// The local user is recorded as the operator, the login of the system as the account
+		if e.Operator == "" || e.Operator != auditlog.Operator() || e.Account != fake.AdminLogin {
+			t.Errorf("Entry %v got operator '%v' as '%v', want '%v' as '%v'", 2*i+2, e.Operator, e.Account, auditlog.Operator(), fake.AdminLogin)
+		}
+	}
+	if err := auditlog.Verify(entries); err != nil {
+		t.Error("Verify() got error:", err)
+	}
+	if got := auditlog.Filter(entries, auditlog.Query{}); len(got) != len(tests) {
+		t.Errorf("Filter() got %v entries, want one per request: %v", len(got), got)
+	}
+}
+
+func TestDryRunIsNotLogged(t *testing.T) {
+	file := useLog(t, "dryrun.log")
+	dryrun.Enabled = true
+	defer func() { dryrun.Enabled = false }()
+	// The audit transport sends the request itself, so the fake server receives it,
+	// but nothing is logged
+	if err := suspendUser.SuspendFromGithub(svc, "e400001", "dry run"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	entries, err := auditlog.Read(file)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Got %v entries for a dry run, want none", len(entries))
+	}
+}
+
+func TestUnwritableLogStopsRequest(t *testing.T) {
+	useLog(t, filepath.Join("missing.log", "audit.log"))
+	if err := ioutil.WriteFile(filepath.Join(dir, "missing.log"), nil, 0600); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, _, err := createUser.CreateUser(svc, "e400003", "e400003@statestreet.com"); err == nil {
+		t.Error("Expected error creating a user without an audit log")
+	}
+	if server.User("e400003") != nil {
+		t.Error("User 'e400003' was created without an audit log")
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	file := useLog(t, "tamper.log")
+	for _, reason := range []string{"first", "second", "third"} {
+		if err := auditlog.Append(&auditlog.Entry{Time: time.Now().UTC(), Action: "PUT /api/v3/users/e400001/suspended", Request: reason}); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+	}
+	entries, err := auditlog.Read(file)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := auditlog.Verify(entries); err != nil {
+		t.Fatal("Verify() got error:", err)
+	}
+
+	edited := append([]auditlog.Entry{}, entries...)
+	edited[1].Request = "edited"
+	if err := auditlog.Verify(edited); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Verify() of edited entry got error %v, want one naming line 2", err)
+	}
+	removed := []auditlog.Entry{entries[0], entries[2]}
+	if err := auditlog.Verify(removed); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Verify() with removed entry got error %v, want one naming line 2", err)
+	}
+}
+
+func TestConcurrentProcessesKeepChain(t *testing.T) {
+	file := useLog(t, "concurrent.log")
+	const processes, appends = 4, 25
+	cmds := []*exec.Cmd{}
+	for i := 0; i < processes; i++ {
+		cmd := exec.Command(os.Args[0])
+		cmd.Env = append(os.Environ(), fmt.Sprintf("%v=%v", envAppend, appends))
+		cmd.Stderr = os.Stderr
+		if err := cmd.Start(); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatal("Appending process failed:", err)
+		}
+	}
+
+	entries, err := auditlog.Read(file)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(entries) != processes*appends {
+		t.Errorf("Got %v entries, want %v", len(entries), processes*appends)
+	}
+	if err := auditlog.Verify(entries); err != nil {
+		t.Error("Verify() got error:", err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2019, 3, d, 12, 0, 0, 0, time.UTC) }
+	entries := []auditlog.Entry{
+		{Time: day(1), Operator: "admin", Action: "DELETE /api/v3/admin/users/e400001", Target: "/api/v3/admin/users/e400001"},
+		{Time: day(2), Operator: "admin", Action: "PUT /api/v3/users/e400002/site_admin", Target: "/api/v3/users/e400002/site_admin"},
+		{Time: day(3), Operator: "e400002", Action: "POST /api/v3/admin/organizations", Target: "/api/v3/admin/organizations"},
+		{Time: day(4), Operator: "alice", Account: "jira-admin", Action: "PUT /rest/api/2/user", Target: "/rest/api/2/user?username=e400001"},
+		// A request whose outcome was logged is listed once, one whose outcome was not is listed as pending
+		{Time: day(5), Operator: "admin", Action: "DELETE /api/v3/admin/users/e400003", Target: "/api/v3/admin/users/e400003", Outcome: auditlog.Pending, Hash: "a"},
+		{Time: day(5), Operator: "admin", Action: "DELETE /api/v3/admin/users/e400003", Target: "/api/v3/admin/users/e400003", Outcome: auditlog.Success, Completes: "a"},
+		{Time: day(6), Operator: "admin", Action: "DELETE /api/v3/admin/users/e400004", Target: "/api/v3/admin/users/e400004", Outcome: auditlog.Pending, Hash: "b"},
+	}
+	until, _ := auditlog.ParseTime("2019-03-02", true)
+
+	type test struct {
+		name  string
+		query auditlog.Query
+		want  int
+	}
+	tests := []test{
+		{"all", auditlog.Query{}, 6},
+		{"target user in path or query", auditlog.Query{User: "e400001"}, 2},
+		{"operator or target user", auditlog.Query{User: "e400002"}, 2},
+		{"pending without outcome", auditlog.Query{User: "e400004"}, 1},
+		{"account", auditlog.Query{User: "jira-admin"}, 1},
+		{"action", auditlog.Query{Action: "delete"}, 3},
+		{"since", auditlog.Query{Since: day(2)}, 5},
+		{"until end of day", auditlog.Query{Until: until}, 2},
+	}
+	for _, tc := range tests {
+		if got := auditlog.Filter(entries, tc.query); len(got) != tc.want {
+			t.Errorf("Filter() by %v got %v entries, want %v", tc.name, len(got), tc.want)
+		}
+	}
+}
+
+func TestSummariseRedactsSecrets(t *testing.T) {
+	got := auditlog.Summarise([]byte(`{"name": "jira", "password": "hunter2", "auth": {"token": "abc", "apiKey": "def"}}`), "application/json")
+	want := `{"auth":{"apiKey":"REDACTED","token":"REDACTED"},"name":"jira","password":"REDACTED"}`
+	if got != want {
+		t.Errorf("Summarise() got %v, want %v", got, want)
+	}
+
+	// Identifiers whose names contain "key" are kept
+	got = auditlog.Summarise([]byte(`{"key": "MSF", "projectTypeKey": "software", "projectTemplateKey": "scrum"}`), "application/json")
+	want = `{"key":"MSF","projectTemplateKey":"scrum","projectTypeKey":"software"}`
+	if got != want {
+		t.Errorf("Summarise() got %v, want %v", got, want)
+	}
+
+	// Form fields are redacted like JSON keys
+	got = auditlog.Summarise([]byte("username=e400001&password=hunter2"), "application/x-www-form-urlencoded; charset=utf-8")
+	want = "password=REDACTED&username=e400001"
+	if got != want {
+		t.Errorf("Summarise() got %v, want %v", got, want)
+	}
+
+	// Bodies which cannot be redacted are not logged
+	got = auditlog.Summarise([]byte("password hunter2"), "text/plain")
+	want = "16 bytes of text/plain"
+	if got != want {
+		t.Errorf("Summarise() got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package auditlog
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive lock on f, waiting for other processes to release theirs
+func lock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+// unlock releases the lock on f
+func unlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,13 @@
+package auditlog
+
+import "os"
+
+// lock does nothing on Windows, where writes are only serialised within a process
+func lock(f *os.File) error {
+	return nil
+}
+
+// unlock does nothing on Windows
+func unlock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,156 @@
+package artifactory
+
+import (
+	addUser "omniactl/artifactory/add/user"
+	createGroup "omniactl/artifactory/create/group"
+	createPermission "omniactl/artifactory/create/permission"
+	createRepo "omniactl/artifactory/create/repo"
+	removeUser "omniactl/artifactory/remove/user"
+	"omniactl/artifactory/service"
+	artifactoryLogin "omniactl/login/artifactory"
+
+	"github.com/spf13/cobra"
+)
+
+// svc is the Artifactory service shared by all artifactory subcommands
+var svc *service.Service
+
+var (
+	groupName         string
+	groupDescription  string
+	addGroup          string
+	addUsers          []string
+	removeGroup       string
+	removeUsers       []string
+	repoProject       string
+	repoPackageType   string
+	repoClasses       []string
+	repoRemoteURL     string
+	permissionName    string
+	permissionRepos   []string
+	permissionUsers   []string
+	permissionGroups  []string
+	permissionActions []string
+)
+
+// artifactoryCmd represents the artifactory command
+var artifactoryCmd = &cobra.Command{
+	Use:   "artifactory",
+	Short: "Subcommand for interacting with Artifactory API.",
+	Long: "'omniactl artifactory' command allows for interacting with the Artifactory API." +
+		"For instance, run the subcommand 'omniactl artifactory create repos' to create the repositories of a new project.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		svc, err = artifactoryLogin.CreateClient()
+		return err
+	},
+}
+
+// createCmd represents the artifactory create command
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Subcommand for interacting with Artifactory API.",
+	Long: "'omniactl artifactory create' command allows for the following actions, depending on the chosen subcommand:" +
+		"1, 'group': add a new group to Artifactory." +
+		"2, 'repos': add the local, remote and virtual repositories of a project to Artifactory." +
+		"3, 'permission': add a new permission target to Artifactory.",
+}
+
+var groupCreateCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Add a new group to Artifactory.",
+	Long:  "Creates an Artifactory group with the provided name and description.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createGroup.CreateGroup(svc, groupName, groupDescription)
+	},
+}
+
+var reposCreateCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Add the repositories of a project to Artifactory.",
+	Long: "Creates the repositories of a project for a package type, named <project>-<type>-local, <project>-<type>-remote and <project>-<type>.\n" +
+		"The remote repository proxies the public registry of the package type unless --remote-url is set. " +
+		"The virtual repository aggregates the local and remote repositories and deploys to the local one. " +
+		"Repositories which exist already are left as they are.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createRepo.CreateRepos(svc, repoProject, repoPackageType, repoClasses, repoRemoteURL)
+	},
+}
+
+var permissionCreateCmd = &cobra.Command{
+	Use:   "permission",
+	Short: "Add a new permission target to Artifactory.",
+	Long: "Creates a permission target granting the users and groups the actions on the repositories.\n" +
+		"Without --repos, the local and remote repositories named <name>-*, e.g. msf-maven-local, are used.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createPermission.CreatePermission(svc, permissionName, permissionRepos, permissionUsers, permissionGroups, permissionActions)
+	},
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Subcommand for interacting with Artifactory API.",
+	Long:  "'add' requires a subcommand, e.g. 'user', to be executed.",
+}
+
+var userAddCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Add users to an Artifactory group.",
+	Long:  "Adds the users to the Artifactory group. Users who are members already are skipped.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addUser.AddUsers(svc, addGroup, addUsers)
+	},
+}
+
+var removeCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Subcommand for interacting with Artifactory API.",
+	Long:  "'remove' requires a subcommand, e.g. 'user', to be executed.",
+}
+
+var userRemoveCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Remove users from an Artifactory group.",
+	Long:  "Removes the users from the Artifactory group once confirmed.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return removeUser.RemoveUsers(svc, removeGroup, removeUsers)
+	},
+}
+
+func init() {
+	// artifactory create
+	artifactoryCmd.AddCommand(createCmd)
+	createCmd.AddCommand(groupCreateCmd)
+	createCmd.AddCommand(reposCreateCmd)
+	createCmd.AddCommand(permissionCreateCmd)
+	// artifactory add
+	artifactoryCmd.AddCommand(addCmd)
+	addCmd.AddCommand(userAddCmd)
+	// artifactory remove
+	artifactoryCmd.AddCommand(removeCmd)
+	removeCmd.AddCommand(userRemoveCmd)
+
+	groupCreateCmd.Flags().StringVarP(&groupName, "artifactory-group", "g", "", "Group name (required)")
+	groupCreateCmd.Flags().StringVarP(&groupDescription, "description", "d", "", "Description of the group")
+
+	reposCreateCmd.Flags().StringVarP(&repoProject, "project", "p", "", "Project the repositories are named after, e.g. msf (required)")
+	reposCreateCmd.Flags().StringVarP(&repoPackageType, "package-type", "t", "", "Package type: maven, gradle, npm, pypi, docker, go, helm or generic (required)")
+	reposCreateCmd.Flags().StringSliceVarP(&repoClasses, "classes", "c", createRepo.Classes, "Repository classes to create: local, remote and virtual")
+	reposCreateCmd.Flags().StringVar(&repoRemoteURL, "remote-url", "", "URL proxied by the remote repository (default is the public registry of the package type)")
+
+	permissionCreateCmd.Flags().StringVarP(&permissionName, "name", "n", "", "Permission target name, usually the project, e.g. msf (required)")
+	permissionCreateCmd.Flags().StringSliceVarP(&permissionRepos, "repos", "r", []string{}, "Repositories of the permission target, separated by commas")
+	permissionCreateCmd.Flags().StringSliceVarP(&permissionUsers, "users", "u", []string{}, "Users granted the actions, separated by commas")
+	permissionCreateCmd.Flags().StringSliceVarP(&permissionGroups, "groups", "g", []string{}, "Groups granted the actions, separated by commas")
+	permissionCreateCmd.Flags().StringSliceVarP(&permissionActions, "actions", "a", createPermission.DefaultActions, "Actions granted: read, annotate, write, delete and manage")
+
+	userAddCmd.Flags().StringVarP(&addGroup, "artifactory-group", "g", "", "Group the users are added to (required)")
+	userAddCmd.Flags().StringSliceVarP(&addUsers, "username", "u", []string{}, "Usernames = State Street Lan IDs, separated by commas (required)")
+	userRemoveCmd.Flags().StringVarP(&removeGroup, "artifactory-group", "g", "", "Group the users are removed from (required)")
+	userRemoveCmd.Flags().StringSliceVarP(&removeUsers, "username", "u", []string{}, "Usernames = State Street Lan IDs, separated by commas (required)")
+}
+
+// AddSubCommands adds the sub-commands to the provided command
+func AddSubCommands(cmd *cobra.Command) {
+	cmd.AddCommand(artifactoryCmd)
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"omniactl/auditlog"
+	"omniactl/github/audit/drift"
+	"omniactl/github/service"
+	githubLogin "omniactl/login/github"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	file      string
+	logUser   string
+	logAction string
+	logSince  string
+	logUntil  string
+	verifyLog bool
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Subcommand for auditing access.",
+	Long:  "'audit' requires a subcommand, e.g. 'drift' or 'log', to be executed.",
+}
+
+var driftAuditCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Reports where Github differs from the declared access model.",
+	Long: "'audit drift' compares org membership, team membership and repo permissions with a YAML state file, as used by 'plan', " +
+		"and reports unexpected admins, members who are not on any team and direct collaborators who bypass teams. " +
+		"Every org in the file must declare its members. " +
+		"It exits with code 9 if any drift is found, so it can be run on a schedule.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := githubLogin.CreateClient()
+		if err != nil {
+			return err
+		}
+		return drift.AuditDrift(service.New(client), file)
+	},
+}
+
+var logAuditCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Shows the audit log of administrative actions.",
+	Long: "'audit log' lists the entries of the audit log, which records every request omniactl sent to change anything, " +
+		"with the operator, i.e. the local user who ran omniactl, the account it was authenticated as, the time, target, request and outcome. Entries can be selected by user, action and date range, " +
+		"and the hash chain of the log can be verified to detect tampering.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := auditlog.ParseTime(logSince, false)
+		if err != nil {
+			return err
+		}
+		until, err := auditlog.ParseTime(logUntil, true)
+		if err != nil {
+			return err
+		}
+		return auditlog.ShowLog(auditlog.Query{User: logUser, Action: logAction, Since: since, Until: until}, verifyLog)
+	},
+}
+
+func init() {
+	auditCmd.AddCommand(driftAuditCmd)
+	driftAuditCmd.Flags().StringVarP(&file, "file", "f", "", "YAML state file declaring the access model, e.g. github.yaml (required)")
+	driftAuditCmd.MarkFlagRequired("file")
+
+	auditCmd.AddCommand(logAuditCmd)
+	logAuditCmd.Flags().StringVarP(&logUser, "user", "u", "", "Only list actions by or on this user")
+	logAuditCmd.Flags().StringVarP(&logAction, "action", "a", "", "Only list actions containing this text, e.g. 'DELETE' or 'suspended'")
+	logAuditCmd.Flags().StringVar(&logSince, "since", "", "Only list actions from this date or time on, e.g. 2019-03-01 or 2019-03-01T12:00:00Z")
+	logAuditCmd.Flags().StringVar(&logUntil, "until", "", "Only list actions up to and including this date, or before this time")
+	logAuditCmd.Flags().BoolVar(&verifyLog, "verify", false, "Check the hash chain of the whole log and fail if any entry was changed, inserted or removed")
+}
+
+// AddSubCommands adds the sub-commands to the provided command
+func AddSubCommands(cmd *cobra.Command) {
+	cmd.AddCommand(auditCmd)
+}
@@ -16,19 +16,29 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
+	artifactory "omniactl/cmd/artifactory"
+	audit "omniactl/cmd/audit"
+	concourse "omniactl/cmd/concourse"
 	config "omniactl/cmd/config"
+	confluence "omniactl/cmd/confluence"
 	github "omniactl/cmd/github"
 	jira "omniactl/cmd/jira"
 	login "omniactl/cmd/login"
+	offboard "omniactl/cmd/offboard"
+	project "omniactl/cmd/project"
+	state "omniactl/cmd/state"
+	omniaConfig "omniactl/config"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"omniactl/output"
 	"os"
 
-	homedir "github.com/mitchellh/go-homedir"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
-var cfgFile string
-
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "omniactl",
@@ -46,7 +56,22 @@ supporting and managing existing projects.   For example:
                           --confluence-space-name "Galleon" \
                           --artifactory-group msf-admins \
                           --concourse-required
+
+Exit codes:
+  0    success
+  1    internal or unexpected error
+  2    validation error: missing or invalid input
+  3    not found
+  4    already exists
+  5    permission denied
+  6    rate limited
+  7    unavailable: an API endpoint could not be reached
+  8    configuration error: config file or credentials missing or invalid
+  9    drift: the live state differs from the state file
+  130  aborted by the user
   `,
+	SilenceErrors: true,
+	SilenceUsage:  true,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
@@ -54,20 +79,31 @@ supporting and managing existing projects.   For example:
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Errors are printed and the program exits with the code matching their kind.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		red := color.New(color.FgRed, color.Bold)
+		red.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(errs.ExitCode(err))
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initOutput, initDryRun, initConfig)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		cmd.Println(cmd.UsageString())
+		return errs.Wrap(errs.Validation, err, "invalid flags")
+	})
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.omniactl.yaml)")
+	rootCmd.PersistentFlags().StringVar(&omniaConfig.ConfigFile, "config", "", "config file (default is $OMNIACTL_CONFIG, then the first found of $HOME/.omniactl.yaml and ./.omniactl.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&interactive.Disabled, "non-interactive", false, "never prompt: fail on missing or invalid input and answer confirmations with yes")
+	rootCmd.PersistentFlags().BoolVarP(&interactive.Disabled, "yes", "y", false, "alias for --non-interactive")
+	rootCmd.PersistentFlags().StringVar(&output.Format, "output", output.Text, "output format of list commands: text, json, yaml, csv or table")
+	rootCmd.PersistentFlags().StringVar(&omniaConfig.Profile, "profile", "", "profile of the config file, e.g. dev or prod (default is $OMNIACTL_PROFILE, then the one set by 'config use-profile')")
+	rootCmd.PersistentFlags().BoolVar(&dryrun.Enabled, "dry-run", false, "run all checks, then print the method, path and JSON body of every request that would change anything instead of sending it")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -77,31 +113,63 @@ func init() {
 	login.AddSubCommands(rootCmd)
 	github.AddSubCommands(rootCmd)
 	jira.AddSubCommands(rootCmd)
+	confluence.AddSubCommands(rootCmd)
+	artifactory.AddSubCommands(rootCmd)
+	concourse.AddSubCommands(rootCmd)
+	project.AddSubCommands(rootCmd)
+	offboard.AddSubCommands(rootCmd)
+	state.AddSubCommands(rootCmd)
+	audit.AddSubCommands(rootCmd)
 
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := homedir.Dir()
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-
-		// Search config in home directory with name ".omniactl" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigName(".omniactl")
+// initOutput validates the output format and prints the logo for text output.
+// For any other format, coloured messages are written to stderr so that stdout
+// only holds the rendered results.
+func initOutput() {
+	if err := output.Validate(output.Format); err != nil {
+		red := color.New(color.FgRed, color.Bold)
+		red.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(errs.ExitCode(err))
+	}
+	if !output.IsText() {
+		color.Output = os.Stderr
+		return
+	}
+
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	// The logo is only printed when 'logo.txt' is found in the working directory
+	if dat, err := ioutil.ReadFile("logo.txt"); err == nil {
+		whiteBold.Print(string(dat))
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	white := color.New(color.FgWhite, color.Bold, color.Italic)
+	white.Print("Github project on-boarding and management\n")
+	fmt.Println("")
+	fmt.Println("")
+}
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+// initDryRun warns that nothing is changed when --dry-run is set
+func initDryRun() {
+	if dryrun.Enabled {
+		color.New(color.FgYellow, color.Bold).Println("Dry run: requests which would change anything are printed, not sent.")
+		fmt.Fprintln(color.Output, "")
+	}
+}
+
+// initConfig reads in config file and ENV variables if set.
+func initConfig() {
+	if err := omniaConfig.Init(); err != nil {
+		red := color.New(color.FgRed, color.Bold)
+		red.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(errs.ExitCode(err))
+	}
+	file, legacy := omniaConfig.Used()
+	if _, err := os.Stat(file); err != nil {
+		return
+	}
+	fmt.Fprintln(color.Output, "Using config file:", file)
+	if legacy {
+		color.New(color.FgYellow, color.Bold).Fprintln(color.Output, "This INI config file was written by an earlier version, run 'omniactl config migrate' to convert it to YAML.")
 	}
 }
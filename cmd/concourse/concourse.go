@@ -0,0 +1,146 @@
+package concourse
+
+import (
+	createTeam "omniactl/concourse/create/team"
+	listTeam "omniactl/concourse/list/team"
+	"omniactl/concourse/service"
+	setPipeline "omniactl/concourse/set/pipeline"
+	"omniactl/config"
+	concourseLogin "omniactl/login/concourse"
+
+	"github.com/spf13/cobra"
+)
+
+// svc is the Concourse service shared by all concourse subcommands
+var svc *service.Service
+
+var (
+	teamName         string
+	teamOrg          string
+	teamOwners       []string
+	teamMembers      []string
+	teamOperators    []string
+	teamViewers      []string
+	teamList         string
+	pipeline         setPipeline.Params
+	pipelineTemplate string
+	pipelineFile     string
+)
+
+// concourseCmd represents the concourse command
+var concourseCmd = &cobra.Command{
+	Use:   "concourse",
+	Short: "Subcommand for interacting with Concourse API.",
+	Long: "'omniactl concourse' command allows for interacting with the Concourse API." +
+		"For instance, run the subcommand 'omniactl concourse create team' to create the CI team of a new project.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		svc, err = concourseLogin.CreateClient()
+		return err
+	},
+}
+
+// createCmd represents the concourse create command
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Subcommand for interacting with Concourse API.",
+	Long:  "'create' requires a subcommand, e.g. 'team', to be executed.",
+}
+
+var teamCreateCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Create or update a Concourse team.",
+	Long: "Creates a Concourse team whose roles are mapped to Github teams, or adds the mappings to an existing team.\n" +
+		"Github teams are given by name, in the organisation set by --org, or as <org>/<team>.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		roles := map[string][]string{
+			"owner":             teamOwners,
+			"member":            teamMembers,
+			"pipeline-operator": teamOperators,
+			"viewer":            teamViewers,
+		}
+		return createTeam.CreateTeam(svc, teamName, config.Org(teamOrg), roles)
+	},
+}
+
+// listCmd represents the concourse list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Subcommand for interacting with Concourse API.",
+	Long:  "'list' requires a subcommand, e.g. 'team', to be executed.",
+}
+
+var teamListCmd = &cobra.Command{
+	Use:   "team",
+	Short: "List Concourse teams.",
+	Long:  "Lists the Concourse teams whose name matches --team, e.g. 'gal*', with the users and groups of each role.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listTeam.ListTeam(svc, teamList)
+	},
+}
+
+// setCmd represents the concourse set command
+var setCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Subcommand for interacting with Concourse API.",
+	Long:  "'set' requires a subcommand, e.g. 'pipeline', to be executed.",
+}
+
+var pipelineSetCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Set a Concourse pipeline from a template.",
+	Long: "Renders a pipeline template for the Github repository and sets it as the config of the pipeline, " +
+		"creating and unpausing the pipeline if it does not exist yet.\n" +
+		"Templates are Go templates of the pipeline YAML, rendered with .Team, .Name, .Org, .Repo, .Branch and .GitURI.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pipeline.Org = config.Org(pipeline.Org)
+		if pipeline.Repo == "" {
+			pipeline.Repo = pipeline.Name
+		}
+		if pipeline.GitURI == "" {
+			githubURL, err := config.GetURL("github")
+			if err != nil {
+				return err
+			}
+			if pipeline.GitURI, err = setPipeline.GitURI(githubURL, pipeline.Org, pipeline.Repo); err != nil {
+				return err
+			}
+		}
+		return setPipeline.SetPipeline(svc, pipelineTemplate, pipelineFile, pipeline)
+	},
+}
+
+func init() {
+	// concourse create
+	concourseCmd.AddCommand(createCmd)
+	createCmd.AddCommand(teamCreateCmd)
+	// concourse list
+	concourseCmd.AddCommand(listCmd)
+	listCmd.AddCommand(teamListCmd)
+	// concourse set
+	concourseCmd.AddCommand(setCmd)
+	setCmd.AddCommand(pipelineSetCmd)
+
+	teamCreateCmd.Flags().StringVarP(&teamName, "team", "t", "", "Concourse team name (required)")
+	teamCreateCmd.Flags().StringVarP(&teamOrg, "org", "o", "", "Github organisation of the Github teams (default is the default_org setting)")
+	teamCreateCmd.Flags().StringSliceVar(&teamOwners, "owner", []string{}, "Github teams mapped to the owner role, separated by commas")
+	teamCreateCmd.Flags().StringSliceVar(&teamMembers, "member", []string{}, "Github teams mapped to the member role, separated by commas")
+	teamCreateCmd.Flags().StringSliceVar(&teamOperators, "pipeline-operator", []string{}, "Github teams mapped to the pipeline-operator role, separated by commas")
+	teamCreateCmd.Flags().StringSliceVar(&teamViewers, "viewer", []string{}, "Github teams mapped to the viewer role, separated by commas")
+
+	teamListCmd.Flags().StringVarP(&teamList, "team", "t", "", "Team name or glob, e.g. 'gal*'; all teams are listed if omitted")
+
+	pipelineSetCmd.Flags().StringVarP(&pipeline.Team, "team", "t", "", "Concourse team of the pipeline (required)")
+	pipelineSetCmd.Flags().StringVarP(&pipeline.Name, "pipeline", "p", "", "Pipeline name (required)")
+	pipelineSetCmd.Flags().StringVarP(&pipeline.Org, "org", "o", "", "Github organisation of the repository (default is the default_org setting)")
+	pipelineSetCmd.Flags().StringVarP(&pipeline.Repo, "repo", "r", "", "Github repository built by the pipeline (default is the pipeline name)")
+	pipelineSetCmd.Flags().StringVarP(&pipeline.Branch, "branch", "b", "master", "Branch built by the pipeline")
+	pipelineSetCmd.Flags().StringVar(&pipeline.GitURI, "git-uri", "", "URI the repository is cloned from (default is the SSH URI of the repository on the configured Github)")
+	pipelineSetCmd.Flags().StringVar(&pipelineTemplate, "template", setPipeline.DefaultTemplate, "Pipeline template: starter")
+	pipelineSetCmd.Flags().StringVarP(&pipelineFile, "file", "f", "", "Pipeline template file, used instead of --template")
+}
+
+// AddSubCommands adds the sub-commands to the provided command
+func AddSubCommands(cmd *cobra.Command) {
+	cmd.AddCommand(concourseCmd)
+}
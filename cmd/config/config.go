@@ -13,6 +13,7 @@ var (
 	artifactory string
 	concourse   string
 	vault       string
+	migrateFrom string
 )
 
 // configCmd represents the config command
@@ -26,24 +27,78 @@ var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "'config update' command allows to update config settings",
 	Long: `Config command allows user to list/update config settings for
-	Github, Jira, Confluence, Artifactory, Vault and Concourse.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		updateConfig.UpdateConfigFile(github, jira, confluence, artifactory, concourse, vault)
+	Github, Jira, Confluence, Artifactory, Vault and Concourse.
+	The current profile is updated, or created if it does not exist, e.g. with --profile dev.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateConfig.UpdateConfigFile(github, jira, confluence, artifactory, concourse, vault)
 	},
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "'config list' command displays current config file",
-	Long:  "'config list' displays URL endpoints for Github, Jira, Confluence, Concourse, Artifactory and Vault",
-	Run: func(cmd *cobra.Command, args []string) {
-		listConfig.ListConfigFile()
+	Long:  "'config list' displays URL endpoints for Github, Jira, Confluence, Concourse, Artifactory and Vault of the current profile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listConfig.ListConfigFile()
+	},
+}
+
+var setCmd = &cobra.Command{
+	Use:   "set <setting> <value>",
+	Short: "'config set' sets default_org, email_domain or username_pattern",
+	Long: "'config set' sets a setting at the top of the config file:\n" +
+		"  default_org       Github organisation used by commands when --org is not set\n" +
+		"  email_domain      domain of the email addresses of new users, by default statestreet.com\n" +
+		"  username_pattern  regular expression usernames must match, by default ^e[0-9]{6}$\n" +
+		"Profiles may override them, and so do the OMNIACTL_DEFAULT_ORG, OMNIACTL_EMAIL_DOMAIN and " +
+		"OMNIACTL_USERNAME_PATTERN environment variables.",
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateConfig.SetSetting(args[0], args[1])
+	},
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "'config migrate' converts the INI config file of an earlier version to YAML",
+	Long: "'config migrate' converts the INI .omniactl file in use, or the one set by --from, to YAML, " +
+		"writing it to the file set by --config or OMNIACTL_CONFIG, or else to ~/.omniactl.yaml. " +
+		"The [config] section becomes the default profile. The INI file is left as it is.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateConfig.MigrateConfigFile(migrateFrom)
+	},
+}
+
+var useProfileCmd = &cobra.Command{
+	Use:   "use-profile <profile>",
+	Short: "'config use-profile' selects the profile used by later commands",
+	Long: "'config use-profile' saves the profile in the config file, so that later commands use its URL endpoints. " +
+		"--profile and the OMNIACTL_PROFILE environment variable take precedence over it.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listConfig.UseProfile(args[0])
+	},
+}
+
+var listProfilesCmd = &cobra.Command{
+	Use:   "list-profiles",
+	Short: "'config list-profiles' lists the profiles of the config file",
+	Long:  "'config list-profiles' lists the profiles of the config file, marking the current one with '*'",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listConfig.ListProfiles()
 	},
 }
 
 func init() {
 	configCmd.AddCommand(updateCmd)
 	configCmd.AddCommand(listCmd)
+	configCmd.AddCommand(useProfileCmd)
+	configCmd.AddCommand(listProfilesCmd)
+	configCmd.AddCommand(setCmd)
+	configCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().StringVar(&migrateFrom, "from", "", "INI config file to migrate (default is the one in use)")
 	updateCmd.Flags().StringVarP(&github, "github", "g", "", "Github URL")
 	updateCmd.Flags().StringVarP(&jira, "jira", "j", "", "Jira URL")
 	updateCmd.Flags().StringVarP(&confluence, "confluence", "f", "", "Confluence URL")
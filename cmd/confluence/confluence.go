@@ -0,0 +1,125 @@
+package confluence
+
+import (
+	createSpace "omniactl/confluence/create/space"
+	"omniactl/confluence/grant"
+	listPermission "omniactl/confluence/list/permission"
+	listSpace "omniactl/confluence/list/space"
+	"omniactl/confluence/revoke"
+	"omniactl/confluence/service"
+	confluenceLogin "omniactl/login/confluence"
+
+	"github.com/spf13/cobra"
+)
+
+// svc is the Confluence service shared by all confluence subcommands
+var svc *service.Service
+
+var (
+	spaceKey         string
+	spaceName        string
+	spaceDescription string
+	spaceList        string
+	grantSpace       string
+	grantUsers       []string
+	grantGroups      []string
+	grantLevel       string
+	revokeSpace      string
+	revokeUsers      []string
+	revokeGroups     []string
+)
+
+// confluenceCmd represents the confluence command
+var confluenceCmd = &cobra.Command{
+	Use:   "confluence",
+	Short: "Subcommand for interacting with Confluence API.",
+	Long: "'omniactl confluence' command allows for interacting with the Confluence API." +
+		"For instance, run the subcommand 'omniactl confluence create space' to create the wiki of a new project.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		svc, err = confluenceLogin.CreateClient()
+		return err
+	},
+}
+
+// createCmd represents the confluence create command
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Subcommand for interacting with Confluence API.",
+	Long:  "'create' requires a subcommand, e.g. 'space', to be executed.",
+}
+
+var spaceCreateCmd = &cobra.Command{
+	Use:   "space",
+	Short: "Creates a new Confluence space.",
+	Long:  "Creates a space with the provided key and name. The user omniactl is logged in as becomes the space administrator.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createSpace.CreateSpace(svc, spaceKey, spaceName, spaceDescription)
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Subcommand for interacting with Confluence API.",
+	Long:  "'list' requires a subcommand, e.g. 'space', to be executed.",
+}
+
+var spaceListCmd = &cobra.Command{
+	Use:   "space",
+	Short: "List spaces in Confluence.",
+	Long:  "Lists the spaces in Confluence whose key or name match the provided glob, e.g. 'MSF*', or all spaces if none is provided.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listSpace.ListSpace(svc, spaceList)
+	},
+}
+
+var grantCmd = &cobra.Command{
+	Use:   "grant",
+	Short: "Grant users or groups permissions on a Confluence space.",
+	Long: "'grant' gives the users and groups the permissions of a level on the space:\n" +
+		"view:  view the space\n" +
+		"edit:  view, add and edit pages, blog posts, comments and attachments\n" +
+		"admin: edit, delete any content, export and administer the space",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return grant.Grant(svc, grantSpace, grantUsers, grantGroups, grantLevel)
+	},
+}
+
+var revokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke the permissions of users or groups on a Confluence space.",
+	Long:  "'revoke' removes every permission the users and groups hold on the space once confirmed.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return revoke.Revoke(svc, revokeSpace, revokeUsers, revokeGroups)
+	},
+}
+
+func init() {
+	// confluence create
+	confluenceCmd.AddCommand(createCmd)
+	createCmd.AddCommand(spaceCreateCmd)
+	// confluence list
+	confluenceCmd.AddCommand(listCmd)
+	listCmd.AddCommand(spaceListCmd)
+	// confluence grant, revoke
+	confluenceCmd.AddCommand(grantCmd)
+	confluenceCmd.AddCommand(revokeCmd)
+
+	spaceCreateCmd.Flags().StringVarP(&spaceKey, "key", "k", "", "Space key, letters and digits only, e.g. MSF (required)")
+	spaceCreateCmd.Flags().StringVarP(&spaceName, "confluence-space-name", "n", "", "Space name (required)")
+	spaceCreateCmd.Flags().StringVarP(&spaceDescription, "description", "d", "", "Description of the space")
+	spaceListCmd.Flags().StringVarP(&spaceList, "confluence-space-name", "n", "", "Full or partial space name or key, e.g. 'MSF*'")
+
+	grantCmd.Flags().StringVarP(&grantSpace, "space", "s", "", "Key of the space (required)")
+	grantCmd.Flags().StringSliceVarP(&grantUsers, "user", "u", []string{}, "Users to grant permissions to, separated by commas")
+	grantCmd.Flags().StringSliceVarP(&grantGroups, "group", "g", []string{}, "Groups to grant permissions to, separated by commas")
+	grantCmd.Flags().StringVarP(&grantLevel, "permission", "p", listPermission.View, "Permission level: view, edit or admin")
+	revokeCmd.Flags().StringVarP(&revokeSpace, "space", "s", "", "Key of the space (required)")
+	revokeCmd.Flags().StringSliceVarP(&revokeUsers, "user", "u", []string{}, "Users whose permissions are revoked, separated by commas")
+	revokeCmd.Flags().StringSliceVarP(&revokeGroups, "group", "g", []string{}, "Groups whose permissions are revoked, separated by commas")
+}
+
+// AddSubCommands adds the sub-commands to the provided command
+func AddSubCommands(cmd *cobra.Command) {
+	cmd.AddCommand(confluenceCmd)
+}
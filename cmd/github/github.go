@@ -1,10 +1,13 @@
 package github
 
 import (
+	"omniactl/config"
+	"omniactl/errs"
 	createOrg "omniactl/github/create/org"
 	createRepo "omniactl/github/create/repo"
 	createTeam "omniactl/github/create/team"
 	createUser "omniactl/github/create/user"
+	createUsers "omniactl/github/create/users"
 	deleteUser "omniactl/github/delete/user"
 	listOrg "omniactl/github/list/org"
 	listOrgs "omniactl/github/list/orgs"
@@ -12,42 +15,55 @@ import (
 	listTeams "omniactl/github/list/teams"
 	listUser "omniactl/github/list/user"
 	listUsers "omniactl/github/list/users"
+	"omniactl/github/service"
 	suspendUser "omniactl/github/suspend/user"
+	unsuspendUser "omniactl/github/unsuspend/user"
 	updateUser "omniactl/github/update/user"
+	githubLogin "omniactl/login/github"
 
 	"github.com/spf13/cobra"
 )
 
+// svc is the Github service shared by all github subcommands
+var svc *service.Service
+
 var (
-	username        string
-	usernameSuspend string
-	usernameDelete  string
-	usernameList    string
-	usernamesList   []string
-	reasonSuspend   string
-	usernameUpdate  string
-	email           string
-	org             string
-	role            string
-	teams           []string
-	orgName         string
-	orgProfile      string
-	orgAdmin        string
-	orgTeam         string
-	team            string
-	teamMaintainers []string
-	teamPrivacy     string
-	teamDescription string
-	orgList         string
-	teamList        string
-	orgTeamList     string
-	teamsList       string
-	orgTeamsList    string
-	repoName        string
-	repoOrg         string
-	repoTeam        string
-	repoPrivacy     bool
-	repoDescription string
+	username          string
+	usernameSuspend   string
+	usernameDelete    string
+	usernameList      string
+	usernamesList     []string
+	reasonSuspend     string
+	usernameUpdate    string
+	email             string
+	org               string
+	role              string
+	teams             []string
+	orgName           string
+	orgProfile        string
+	orgAdmin          string
+	orgTeam           string
+	team              string
+	teamMaintainers   []string
+	teamPrivacy       string
+	teamDescription   string
+	orgList           string
+	teamList          string
+	orgTeamList       string
+	teamsList         string
+	orgTeamsList      string
+	repoName          string
+	repoOrg           string
+	repoTeam          string
+	repoPrivacy       bool
+	repoDescription   string
+	listLimit         int
+	usersFile         string
+	usersParallel     int
+	usersReport       string
+	usernameUnsuspend string
+	reasonUnsuspend   string
+	restoreUnsuspend  bool
 )
 
 // githubCmd represents the github command
@@ -56,6 +72,15 @@ var githubCmd = &cobra.Command{
 	Short: "Subcommand for interacting with Github API.",
 	Long: "omniactl github' command allows for interacting with the Github API." +
 		"For instance, run the subcommand 'omniactl github adduser' to add a new user to Github.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		client, err := githubLogin.CreateClient()
+		if err != nil {
+			return err
+		}
+		svc = service.New(client)
+		svc.Limit = listLimit
+		return nil
+	},
 }
 
 // githubCmd represents the github command
@@ -72,8 +97,19 @@ var userCreateCmd = &cobra.Command{
 	Use:   "user",
 	Short: "Add a new user to Github.",
 	Long:  "'user' subcommand requires username and email address, optionally also: organisations and teams to create new Github user.",
-	Run: func(cmd *cobra.Command, args []string) {
-		createUser.AddUser(username, email, org, role, teams)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createUser.AddUser(svc, username, email, config.Org(org), role, teams)
+	},
+}
+
+var usersCreateCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Add multiple new users to Github from a CSV or YAML manifest.",
+	Long: "'users' subcommand validates every row of the manifest, then creates the users concurrently and reports the result of each row.\n" +
+		"CSV manifests have a header row naming the columns username, email, org, role and teams, with teams separated by semicolons.\n" +
+		"YAML manifests hold a list of users with the keys username, email, org, role and teams.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createUsers.AddUsers(svc, usersFile, usersParallel, usersReport)
 	},
 }
 
@@ -87,8 +123,24 @@ var userSuspendCmd = &cobra.Command{
 	Use:   "user",
 	Short: "Suspend a user from Github.",
 	Long:  "'suspend user' subcommand requires the username/Lan ID of the user to be suspended as well as a reason for the suspension.",
-	Run: func(cmd *cobra.Command, args []string) {
-		suspendUser.SuspendUser(usernameSuspend, reasonSuspend)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return suspendUser.SuspendUser(svc, usernameSuspend, reasonSuspend)
+	},
+}
+
+var unsuspendCmd = &cobra.Command{
+	Use:   "unsuspend",
+	Short: "Subcommand for interacting with Github API.",
+	Long:  "'unsuspend' requires a subcommand, e.g. 'user', to be executed.",
+}
+
+var userUnsuspendCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Unsuspend a suspended Github user.",
+	Long: "'unsuspend user' subcommand requires the username/Lan ID of the user to be unsuspended as well as a reason. " +
+		"With --restore, the org and team memberships saved when the user was suspended or offboarded are restored.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return unsuspendUser.UnsuspendUser(svc, usernameUnsuspend, reasonUnsuspend, restoreUnsuspend)
 	},
 }
 
@@ -102,8 +154,8 @@ var userDeleteCmd = &cobra.Command{
 	Use:   "user",
 	Short: "Delete a user from Github.",
 	Long:  "Deleting a user will delete all their repositories, gists, applications, and personal settings. Suspending a user is often a better option.",
-	Run: func(cmd *cobra.Command, args []string) {
-		deleteUser.DeleteUser(usernameDelete)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return deleteUser.DeleteUser(svc, usernameDelete)
 	},
 }
 
@@ -111,14 +163,20 @@ var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Subcommand for interacting with Github API.",
 	Long:  "'list' requires a subcommand, e.g. 'user', to be executed.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if listLimit < 0 {
+			return errs.New(errs.Validation, "--limit must not be negative, got %v", listLimit)
+		}
+		return githubCmd.PersistentPreRunE(cmd, args)
+	},
 }
 
 var userListCmd = &cobra.Command{
 	Use:   "user",
 	Short: "List information for a user.",
 	Long:  "Lists information about the user's username, login, email, organisations, teams, role etc.",
-	Run: func(cmd *cobra.Command, args []string) {
-		listUser.ListUser(usernameList)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listUser.ListUser(svc, usernameList)
 	},
 }
 
@@ -126,8 +184,8 @@ var usersListCmd = &cobra.Command{
 	Use:   "users",
 	Short: "Lists information about multiple users.",
 	Long:  "Lists information about the user's username, login, email, organisations, teams, role etc.",
-	Run: func(cmd *cobra.Command, args []string) {
-		listUsers.ListUsers(usernamesList)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listUsers.ListUsers(svc, usernamesList)
 	},
 }
 
@@ -135,8 +193,8 @@ var orgCreateCmd = &cobra.Command{
 	Use:   "org",
 	Short: "Creates a new Github organization.",
 	Long:  "Creates an organisation, setting a login/username, profile_name and admin.",
-	Run: func(cmd *cobra.Command, args []string) {
-		createOrg.CreateOrg(orgName, orgProfile, orgAdmin)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createOrg.CreateOrg(svc, orgName, orgProfile, orgAdmin)
 	},
 }
 
@@ -150,8 +208,8 @@ var userUpdateCmd = &cobra.Command{
 	Use:   "user",
 	Short: "Updates an existing Github account.",
 	Long:  "Allows adding an existing user to orgs and teams and change their admin status.",
-	Run: func(cmd *cobra.Command, args []string) {
-		updateUser.UpdateUser(usernameUpdate)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateUser.UpdateUser(svc, usernameUpdate)
 	},
 }
 
@@ -159,8 +217,8 @@ var teamCreateCmd = &cobra.Command{
 	Use:   "team",
 	Short: "Creates a new Github team.",
 	Long:  "Creates a new Github team within an existing organisation.",
-	Run: func(cmd *cobra.Command, args []string) {
-		createTeam.CreateTeam(team, orgTeam, teamDescription, teamMaintainers, teamPrivacy)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createTeam.CreateTeam(svc, team, config.Org(orgTeam), teamDescription, teamMaintainers, teamPrivacy)
 	},
 }
 
@@ -168,8 +226,8 @@ var orgListCmd = &cobra.Command{
 	Use:   "org",
 	Short: "Lists information about a Github organization.",
 	Long:  "Provides information on a Github organization's members, repos, admins.",
-	Run: func(cmd *cobra.Command, args []string) {
-		listOrg.ListOrg(orgList)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listOrg.ListOrg(svc, config.Org(orgList))
 	},
 }
 
@@ -177,8 +235,8 @@ var teamListCmd = &cobra.Command{
 	Use:   "team",
 	Short: "Lists information about a Github team.",
 	Long:  "Provides information on a Github team's members, repos, admins etc.",
-	Run: func(cmd *cobra.Command, args []string) {
-		listTeam.ListTeam(teamList, orgTeamList)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listTeam.ListTeam(svc, teamList, config.Org(orgTeamList))
 	},
 }
 
@@ -186,8 +244,8 @@ var orgsListCmd = &cobra.Command{
 	Use:   "orgs",
 	Short: "Lists information about all Github organizations.",
 	Long:  "Provides information on a Github organization's members, id, repos etc.",
-	Run: func(cmd *cobra.Command, args []string) {
-		listOrgs.ListOrgs()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listOrgs.ListOrgs(svc)
 	},
 }
 
@@ -195,8 +253,8 @@ var teamsListCmd = &cobra.Command{
 	Use:   "teams",
 	Short: "Lists information about all Github teams with corresponding orgs.",
 	Long:  "Provides information on a Github team's members, repos, ID etc.",
-	Run: func(cmd *cobra.Command, args []string) {
-		listTeams.ListTeams(orgTeamsList)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listTeams.ListTeams(svc, orgTeamsList)
 	},
 }
 
@@ -204,8 +262,8 @@ var createRepoCmd = &cobra.Command{
 	Use:   "repo",
 	Short: "Creates a new Github repository",
 	Long:  "Creates a new Github repository in a selected org and team with specific permissions, description, privacy etc",
-	Run: func(cmd *cobra.Command, args []string) {
-		createRepo.CreateRepo(repoName, repoOrg, repoTeam, repoDescription, repoPrivacy)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createRepo.CreateRepo(svc, repoName, config.Org(repoOrg), repoTeam, repoDescription, repoPrivacy)
 	},
 }
 
@@ -216,6 +274,8 @@ func init() {
 	createCmd.AddCommand(orgCreateCmd)
 	createCmd.AddCommand(teamCreateCmd)
 	createCmd.AddCommand(createRepoCmd)
+	createCmd.AddCommand(usersCreateCmd)
+	usersCreateCmd.MarkFlagRequired("file")
 
 	// github update
 	githubCmd.AddCommand(updateCmd)
@@ -227,6 +287,11 @@ func init() {
 	userSuspendCmd.MarkFlagRequired("username")
 	userSuspendCmd.MarkFlagRequired("reason")
 
+	// github unsuspend
+	githubCmd.AddCommand(unsuspendCmd)
+	unsuspendCmd.AddCommand(userUnsuspendCmd)
+	userUnsuspendCmd.MarkFlagRequired("username")
+
 	// github delete
 	githubCmd.AddCommand(deleteCmd)
 	deleteCmd.AddCommand(userDeleteCmd)
@@ -242,15 +307,22 @@ func init() {
 	listCmd.AddCommand(teamsListCmd)
 	userListCmd.MarkFlagRequired("username")
 	usersListCmd.MarkFlagRequired("usernames")
+	listCmd.PersistentFlags().IntVar(&listLimit, "limit", 0, "Maximum number of items to list, 0 lists all items")
 
 	// flags for commands
 	userCreateCmd.Flags().StringVarP(&username, "username", "u", "", "Github username = State Street Lan ID (required)")
 	userCreateCmd.Flags().StringVarP(&email, "email", "e", "", "Github email = State Street email (required)")
-	userCreateCmd.Flags().StringVarP(&org, "org", "o", "", "Github organisation the new user will be a member of (required)")
+	userCreateCmd.Flags().StringVarP(&org, "org", "o", "", "Github organisation the new user will be a member of (required unless default_org is set)")
 	userCreateCmd.Flags().StringVarP(&role, "role", "r", "", "Role the new user will have in the selected organisation, e.g. admin, direct_member (defaults to 'direct_member')")
 	userCreateCmd.Flags().StringSliceVarP(&teams, "team", "t", []string{}, "Github teams the new user will be a member of (required)")
+	usersCreateCmd.Flags().StringVarP(&usersFile, "file", "f", "", "CSV or YAML manifest of the users to be created (required)")
+	usersCreateCmd.Flags().IntVarP(&usersParallel, "parallel", "p", createUsers.DefaultParallel, "Maximum number of users created at the same time")
+	usersCreateCmd.Flags().StringVarP(&usersReport, "report", "r", "", "File the per-row report is written to, as JSON, YAML or CSV depending on its extension")
 	userSuspendCmd.Flags().StringVarP(&usernameSuspend, "username", "u", "", "Username = State Street Lan ID of user to be suspended (required)")
 	userSuspendCmd.Flags().StringVarP(&reasonSuspend, "reason", "r", "", "Reason the user is being suspended")
+	userUnsuspendCmd.Flags().StringVarP(&usernameUnsuspend, "username", "u", "", "Username = State Street Lan ID of user to be unsuspended (required)")
+	userUnsuspendCmd.Flags().StringVarP(&reasonUnsuspend, "reason", "r", "", "Reason the user is being unsuspended")
+	userUnsuspendCmd.Flags().BoolVar(&restoreUnsuspend, "restore", false, "Restore the org and team memberships the user had when they were suspended")
 	userDeleteCmd.Flags().StringVarP(&usernameDelete, "username", "u", "", "Username = State Street Lan ID of user to be deleted (required)")
 	userListCmd.Flags().StringVarP(&usernameList, "username", "u", "", "Username = State Street Lan ID of user to list (required)")
 	usersListCmd.Flags().StringSliceVarP(&usernamesList, "usernames", "u", []string{}, "Usernames = State Street Lan ID of user to list (required)")
@@ -259,16 +331,16 @@ func init() {
 	orgCreateCmd.Flags().StringVarP(&orgProfile, "profile", "p", "", "The new organization's display/ profile name")
 	userUpdateCmd.Flags().StringVarP(&usernameUpdate, "username", "u", "", "Username = State Street Lan ID of user to list (required)")
 	teamCreateCmd.Flags().StringVarP(&team, "team", "t", "", "Name of the team to be created (required)")
-	teamCreateCmd.Flags().StringVarP(&orgTeam, "org", "o", "", "Existing Github organisation in which the new team will be created (required)")
+	teamCreateCmd.Flags().StringVarP(&orgTeam, "org", "o", "", "Existing Github organisation in which the new team will be created (required unless default_org is set)")
 	teamCreateCmd.Flags().StringVarP(&teamDescription, "description", "d", "", "Description of team to be created")
 	teamCreateCmd.Flags().StringSliceVarP(&teamMaintainers, "maintainers", "m", []string{}, "Login names of organization members to add as maintainers of the team")
 	teamCreateCmd.Flags().StringVarP(&teamPrivacy, "privacy", "p", "closed", "Level of privacy of the team: secret or closed")
-	orgListCmd.Flags().StringVarP(&orgList, "org", "o", "", "Github organisation about which to list information (required)")
-	teamListCmd.Flags().StringVarP(&orgTeamList, "org", "o", "", "Github org in which the team resides (required)")
+	orgListCmd.Flags().StringVarP(&orgList, "org", "o", "", "Github organisation about which to list information (required unless default_org is set)")
+	teamListCmd.Flags().StringVarP(&orgTeamList, "org", "o", "", "Github org in which the team resides (required unless default_org is set)")
 	teamListCmd.Flags().StringVarP(&teamList, "team", "t", "", "Github team about which information is required (required)")
-	teamsListCmd.Flags().StringVarP(&orgTeamsList, "org", "o", "", "Github organisation which contains teams to be listed")
+	teamsListCmd.Flags().StringVarP(&orgTeamsList, "org", "o", "", "Github organisation which contains teams to be listed, all organisations if not set")
 	createRepoCmd.Flags().StringVarP(&repoName, "name", "n", "", "Name of new Github repository")
-	createRepoCmd.Flags().StringVarP(&repoOrg, "org", "o", "", "Organisation in which new Github repository will be created")
+	createRepoCmd.Flags().StringVarP(&repoOrg, "org", "o", "", "Organisation in which new Github repository will be created (default is the default_org setting)")
 	createRepoCmd.Flags().StringVarP(&repoTeam, "team", "t", "", "Team in which new Github repository will be created")
 	createRepoCmd.Flags().BoolVarP(&repoPrivacy, "private", "p", false, "Select 'true' to create a private repo, 'false' to create a public repo")
 	createRepoCmd.Flags().StringVarP(&repoDescription, "description", "d", "", "Description of new Github repository")
@@ -1,40 +1,51 @@
 package jira
 
 import (
-
-	// createProject "omniactl/jira/create/project"
-	// createRole "omniactl/jira/create/role"
-	// createUser "omniactl/jira/create/user"
-	// listProject "omniactl/jira/list/project"
-	// listRole "omniactl/jira/list/role"
-	// listUser "omniactl/jira/list/user"
-	// suspendUser "omniactl/jira/suspend/user"
-	jiraApi "omniactl/jira"
+	createProject "omniactl/jira/create/project"
+	createRole "omniactl/jira/create/role"
+	createUser "omniactl/jira/create/user"
+	listProject "omniactl/jira/list/project"
+	listRole "omniactl/jira/list/role"
+	listUser "omniactl/jira/list/user"
+	"omniactl/jira/service"
+	suspendUser "omniactl/jira/suspend/user"
+	jiraLogin "omniactl/login/jira"
 
 	"github.com/spf13/cobra"
 )
 
+// svc is the Jira service shared by all jira subcommands
+var svc *service.Service
+
 var (
 	username        string
 	name            string
 	email           string
 	jiraProjectName string
+	userRole        string
+	newProject      createProject.Project
 	usernameSuspend string
 	usernameList    string
 	projectNameList string
 	reasonSuspend   string
 	roleName        string
+	roleDescription string
 )
 
-// githubCmd represents the github command
+// jiraCmd represents the jira command
 var jiraCmd = &cobra.Command{
 	Use:   "jira",
 	Short: "Subcommand for interacting with JIRA API.",
 	Long: "omniactl jira' command allows for interacting with the JIRA API." +
 		"For instance, run the subcommand 'omniactl jira create user' to add a new user to JIRA.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		svc, err = jiraLogin.CreateClient()
+		return err
+	},
 }
 
-// githubCmd represents the github command
+// createCmd represents the jira create command
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Subcommand for interacting with JIRA API.",
@@ -47,27 +58,31 @@ var createCmd = &cobra.Command{
 var userCreateCmd = &cobra.Command{
 	Use:   "user",
 	Short: "Add a new user to JIRA.",
-	Long:  "'user' subcommand requires username, name, email address and project name to create new JIRA user.",
-	Run: func(cmd *cobra.Command, args []string) {
-		jiraApi.AddUser(username, name, email, jiraProjectName)
+	Long: "'user' subcommand requires username, name, email address and project name or key to create new JIRA user.\n" +
+		"The user is added to the project with the role provided by --role, 'Users' by default.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createUser.AddUser(svc, username, name, email, jiraProjectName, userRole)
 	},
 }
 
 var projectCreateCmd = &cobra.Command{
-	Use:   "org",
+	Use:   "project",
 	Short: "Creates a new JIRA project.",
-	Long:  "Creates a project with a provided name.",
-	Run: func(cmd *cobra.Command, args []string) {
-		jiraApi.CreateProject(jiraProjectName)
+	Long: "Creates a project with a provided name and key, optionally from a template and with permission and notification schemes, " +
+		"then adds the users or groups provided by --developers and --administrators to the Developers and Administrators project roles.\n" +
+		"The project lead defaults to the user omniactl is logged in as and the project type to 'software'.\n" +
+		"Templates: scrum, kanban, basic, business, service_desk or a full project template key.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createProject.CreateProject(svc, newProject)
 	},
 }
 
 var roleCreateCmd = &cobra.Command{
 	Use:   "role",
 	Short: "Add role to JIRA.",
-	Long:  "Add a role to JIRA with the provided name",
-	Run: func(cmd *cobra.Command, args []string) {
-		jiraApi.CreateRole(roleName)
+	Long:  "Add a project role to JIRA with the provided name",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createRole.CreateRole(svc, roleName, roleDescription)
 	},
 }
 
@@ -80,42 +95,43 @@ var listCmd = &cobra.Command{
 var userListCmd = &cobra.Command{
 	Use:   "user",
 	Short: "List information for a user or users.",
-	Long:  "Lists information about a user or users, including username, login, email, organisations, teams, role etc.",
-	Run: func(cmd *cobra.Command, args []string) {
-		jiraApi.ListUser(usernameList)
+	Long:  "Lists the users whose username, name or email address start with the provided username, including their name, email and whether they are active.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listUser.ListUser(svc, usernameList)
 	},
 }
 
 var projectListCmd = &cobra.Command{
 	Use:   "project",
 	Short: "List projects in JIRA.",
-	Long:  "Lists the projects in JIRA",
-	Run: func(cmd *cobra.Command, args []string) {
-		jiraApi.ListProject(projectNameList)
+	Long:  "Lists the projects in JIRA whose key or name match the provided glob, e.g. 'MSF*', or all projects if none is provided.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listProject.ListProject(svc, projectNameList)
 	},
 }
 
 var roleListCmd = &cobra.Command{
 	Use:   "role",
 	Short: "List roles in JIRA.",
-	Long:  "List the roles in JIRA.",
-	Run: func(cmd *cobra.Command, args []string) {
-		jiraApi.ListRole(roleName)
+	Long:  "List the project roles in JIRA whose name matches the provided glob or contains the provided name.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listRole.ListRole(svc, roleName)
 	},
 }
 
 var suspendCmd = &cobra.Command{
 	Use:   "suspend",
-	Short: "Subcommand for interacting with Github API.",
+	Short: "Subcommand for interacting with JIRA API.",
 	Long:  "'suspend' requires a subcommand, e.g. 'user', to be executed.",
 }
 
 var userSuspendCmd = &cobra.Command{
 	Use:   "user",
-	Short: "Suspend a user from Github.",
-	Long:  "'suspend user' subcommand requires the username/Lan ID of the user to be suspended as well as a reason for the suspension.",
-	Run: func(cmd *cobra.Command, args []string) {
-		jiraApi.SuspendUser(usernameSuspend, reasonSuspend)
+	Short: "Suspend a user from JIRA.",
+	Long: "'suspend user' subcommand requires the username/Lan ID of the user to be suspended as well as a reason for the suspension.\n" +
+		"The user is deactivated, so that they can no longer log in. JIRA does not store the reason.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return suspendUser.SuspendUser(svc, usernameSuspend, reasonSuspend)
 	},
 }
 
@@ -133,23 +149,28 @@ func init() {
 	// jira suspend
 	jiraCmd.AddCommand(suspendCmd)
 	suspendCmd.AddCommand(userSuspendCmd)
-	// jira suspend user --username --reason
-	//userSuspendCmd.MarkFlagRequired("username")
-	//userSuspendCmd.MarkFlagRequired("reason")
-	// jira list user
-	//userListCmd.MarkFlagRequired("username")
 
 	userCreateCmd.Flags().StringVarP(&username, "username", "u", "", "JIRA username is State Street Lan ID (required)")
 	userCreateCmd.Flags().StringVarP(&name, "name", "n", "", "Full name (required)")
 	userCreateCmd.Flags().StringVarP(&email, "email", "e", "", "Email is State Street email (required)")
-	userCreateCmd.Flags().StringVarP(&jiraProjectName, "jira-project-name", "j", "", "JIRA project name (required)")
-	projectCreateCmd.Flags().StringVarP(&jiraProjectName, "jira-project-name", "j", "", "JIRA project name (required)")
+	userCreateCmd.Flags().StringVarP(&jiraProjectName, "jira-project-name", "j", "", "JIRA project name or key (required)")
+	userCreateCmd.Flags().StringVarP(&userRole, "role", "r", createUser.DefaultRole, "Project role the user is added with")
+	projectCreateCmd.Flags().StringVarP(&newProject.Name, "jira-project-name", "j", "", "JIRA project name (required)")
+	projectCreateCmd.Flags().StringVarP(&newProject.Key, "key", "k", "", "JIRA project key, e.g. MSF (required)")
+	projectCreateCmd.Flags().StringVarP(&newProject.Lead, "lead", "l", "", "Username of the project lead (default is the logged in user)")
+	projectCreateCmd.Flags().StringVarP(&newProject.Type, "type", "t", "", "Project type: software, business or service_desk (default is software, or the type of the template)")
+	projectCreateCmd.Flags().StringVar(&newProject.Template, "template", "", "Project template: scrum, kanban, basic, business, service_desk or a full template key")
+	projectCreateCmd.Flags().StringVar(&newProject.PermissionScheme, "permission-scheme", "", "Name or ID of the permission scheme (default is the Jira default)")
+	projectCreateCmd.Flags().StringVar(&newProject.NotificationScheme, "notification-scheme", "", "Name or ID of the notification scheme (default is the Jira default)")
+	projectCreateCmd.Flags().StringSliceVar(&newProject.Developers, "developers", []string{}, "Users or groups assigned the Developers role, separated by commas")
+	projectCreateCmd.Flags().StringSliceVar(&newProject.Administrators, "administrators", []string{}, "Users or groups assigned the Administrators role, separated by commas")
 	roleCreateCmd.Flags().StringVarP(&roleName, "role", "r", "", "The role name (required)")
+	roleCreateCmd.Flags().StringVarP(&roleDescription, "description", "d", "", "Description of the role")
 
 	userSuspendCmd.Flags().StringVarP(&usernameSuspend, "username", "u", "", "Username is State Street Lan ID of user to be suspended (required)")
 	userSuspendCmd.Flags().StringVarP(&reasonSuspend, "reason", "r", "", "Reason the user is being suspended")
-	userListCmd.Flags().StringVarP(&usernameList, "username", "u", "", "Username is State Street Lan ID of user to list (required)")
-	projectListCmd.Flags().StringVarP(&jiraProjectName, "jira-project-name", "j", "", "JIRA project name (required)")
+	userListCmd.Flags().StringVarP(&usernameList, "username", "u", "", "Username, name or email address the users start with (required)")
+	projectListCmd.Flags().StringVarP(&projectNameList, "jira-project-name", "j", "", "Full or partial JIRA project name or key, e.g. 'MSF*'")
 	roleListCmd.Flags().StringVarP(&roleName, "role", "r", "", "Full or partial role name")
 }
 
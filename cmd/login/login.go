@@ -15,7 +15,11 @@
 package login
 
 import (
-	"fmt"
+	loginApi "omniactl/login"
+	"omniactl/login/session"
+	"omniactl/login/status"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -23,18 +27,59 @@ import (
 // loginCmd represents the login command
 var loginCmd = &cobra.Command{
 	Use:   "login",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("login called")
+	Short: "Log into Github, Jira, Confluence, Artifactory, Concourse or Vault.",
+	Long: "'omniactl login <system>' logs into the system with the credentials from Vault and saves the session " +
+		"token it issues, never the password, in an encrypted session cache: a Jira or Confluence session cookie, " +
+		"an Artifactory or Concourse access token, or the Vault token. Later commands use the session " +
+		"instead of reading the credentials from Vault or logging in again, until it expires after --ttl " +
+		"or when the token of the system does. Github issues no session, so its token is only checked. " +
+		"Without a system, the system is prompted for.\n" +
+		"The cache is ~/.omniactl.d/sessions, or the file set by OMNIACTL_SESSION_FILE, encrypted with the " +
+		"key in omniactl/session.key in the user's config directory, or the file set by OMNIACTL_SESSION_KEY_FILE, " +
+		"or a key derived with scrypt from OMNIACTL_SESSION_KEY.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return loginApi.Login("")
 	},
 }
 
+// systemCmd returns the login subcommand of the system
+func systemCmd(system string) *cobra.Command {
+	return &cobra.Command{
+		Use:   system,
+		Short: "Log into " + strings.Title(system) + " and save the session.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return loginApi.Login(system)
+		},
+	}
+}
+
+// statusTimeout is how long each system is given to answer 'login status'
+var statusTimeout time.Duration
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check the login status of every configured system.",
+	Long: "Probes every system with a URL in the current profile concurrently and reports whether it can be reached, " +
+		"who omniactl is authenticated as, the scopes or expiry of the token, and the version of the system. " +
+		"Systems which do not answer within --timeout are reported as unreachable. The exit code is that of " +
+		"the first system which failed.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return status.ShowStatus(statusTimeout)
+	},
+}
+
+func init() {
+	loginCmd.AddCommand(statusCmd)
+	statusCmd.Flags().DurationVar(&statusTimeout, "timeout", status.DefaultTimeout, "How long each system is given to answer")
+	for _, system := range loginApi.Systems {
+		loginCmd.AddCommand(systemCmd(system))
+	}
+	loginCmd.PersistentFlags().DurationVar(&session.TTL, "ttl", session.DefaultTTL, "How long the session is used for, unless the system's token expires sooner")
+}
+
 // AddSubCommands adds the sub-commands to the provided command
 func AddSubCommands(cmd *cobra.Command) {
 	cmd.AddCommand(loginCmd)
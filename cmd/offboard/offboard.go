@@ -0,0 +1,59 @@
+package offboard
+
+import (
+	offboardUser "omniactl/github/offboard/user"
+	"omniactl/github/service"
+	githubLogin "omniactl/login/github"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	username   string
+	reason     string
+	transferTo string
+	reportFile string
+)
+
+// offboardCmd represents the offboard command
+var offboardCmd = &cobra.Command{
+	Use:   "offboard",
+	Short: "Subcommand for offboarding users.",
+	Long:  "'offboard' requires a subcommand, e.g. 'user', to be executed.",
+}
+
+var userOffboardCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Removes all access of a user and suspends them.",
+	Long: "'offboard user' lists the orgs, teams, repos and SSH keys of a user, then transfers or archives the repos they own, " +
+		"including private ones, which are listed once confirmed with an impersonation token deleted straight after, " +
+		"removes them from their teams and orgs, deletes their SSH keys and suspends their account, " +
+		"which stops their personal access tokens from authenticating. " +
+		"A report of every step, signed with the signing_key of the 'audit' credentials, is written for audits.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := offboardUser.SigningKey()
+		if err != nil {
+			return err
+		}
+		client, err := githubLogin.CreateClient()
+		if err != nil {
+			return err
+		}
+		return offboardUser.OffboardUser(service.New(client), username, reason, transferTo, reportFile, key)
+	},
+}
+
+func init() {
+	offboardCmd.AddCommand(userOffboardCmd)
+	userOffboardCmd.MarkFlagRequired("username")
+
+	userOffboardCmd.Flags().StringVarP(&username, "username", "u", "", "Username = State Street Lan ID of user to be offboarded (required)")
+	userOffboardCmd.Flags().StringVarP(&reason, "reason", "r", "", "Reason the user is being offboarded, recorded in the suspension and the report")
+	userOffboardCmd.Flags().StringVarP(&transferTo, "transfer-to", "t", "", "User or organisation the repos owned by the user are transferred to, instead of archiving them")
+	userOffboardCmd.Flags().StringVar(&reportFile, "report", "", "File the signed offboarding report is written to (default 'offboard-<username>-<time>.json')")
+}
+
+// AddSubCommands adds the sub-commands to the provided command
+func AddSubCommands(cmd *cobra.Command) {
+	cmd.AddCommand(offboardCmd)
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"omniactl/config"
+	"omniactl/github/service"
+	artifactoryLogin "omniactl/login/artifactory"
+	concourseLogin "omniactl/login/concourse"
+	confluenceLogin "omniactl/login/confluence"
+	githubLogin "omniactl/login/github"
+	jiraLogin "omniactl/login/jira"
+	projectApi "omniactl/project"
+
+	"github.com/spf13/cobra"
+)
+
+var project projectApi.Project
+
+// createCmd represents the create command
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Subcommand for creating resources across all systems.",
+	Long:  "'create' requires a subcommand, e.g. 'project', to be executed.",
+}
+
+var projectCreateCmd = &cobra.Command{
+	Use:   "project",
+	Short: "On-boards a new project across Github, Jira, Confluence, Artifactory and Concourse.",
+	Long: "'create project' creates the Github user, team and core repository for a new project, " +
+		"creates the Jira project, Confluence space and Artifactory repositories and gives the user access to them, creates the Concourse team of the Github team with a starter pipeline for the core repository, and reports the outcome for each system in a summary.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project.Org = config.Org(project.Org)
+		client, err := githubLogin.CreateClient()
+		if err != nil {
+			return err
+		}
+		services := projectApi.Services{Github: service.New(client)}
+		if project.JiraProjectName != "" {
+			if services.Jira, err = jiraLogin.CreateClient(); err != nil {
+				return err
+			}
+		}
+		if project.ConfluenceSpaceName != "" {
+			if services.Confluence, err = confluenceLogin.CreateClient(); err != nil {
+				return err
+			}
+		}
+		if project.ArtifactoryGroup != "" || project.ArtifactoryPackageType != "" {
+			if services.Artifactory, err = artifactoryLogin.CreateClient(); err != nil {
+				return err
+			}
+		}
+		if project.ConcourseRequired {
+			if services.Concourse, err = concourseLogin.CreateClient(); err != nil {
+				return err
+			}
+		}
+		_, err = projectApi.CreateProject(services, project)
+		return err
+	},
+}
+
+func init() {
+	createCmd.AddCommand(projectCreateCmd)
+
+	projectCreateCmd.Flags().StringVarP(&project.Username, "user", "u", "", "Username = State Street Lan ID of the project member (required)")
+	projectCreateCmd.Flags().StringVarP(&project.Name, "name", "n", "", "Full name of the project member")
+	projectCreateCmd.Flags().StringVarP(&project.Email, "email", "e", "", "Email = State Street email of the project member (required)")
+	projectCreateCmd.Flags().StringVarP(&project.Org, "org", "o", "", "Github organisation of the project (required unless default_org is set)")
+	projectCreateCmd.Flags().StringVarP(&project.Team, "team", "t", "", "Github team to be created for the project")
+	projectCreateCmd.Flags().StringVar(&project.CoreProjectName, "core-project-name", "", "Name of the core Github repository")
+	projectCreateCmd.Flags().StringVar(&project.JiraProjectName, "jira-project-name", "", "Name of the Jira project")
+	projectCreateCmd.Flags().StringVar(&project.JiraProjectKey, "jira-project-key", "", "Key of the Jira project, e.g. MSF")
+	projectCreateCmd.Flags().StringVar(&project.JiraTemplate, "jira-template", "", "Template of the Jira project, e.g. scrum or kanban")
+	projectCreateCmd.Flags().StringVar(&project.ConfluenceSpaceName, "confluence-space-name", "", "Name of the Confluence space")
+	projectCreateCmd.Flags().StringVar(&project.ConfluenceSpaceKey, "confluence-space-key", "", "Key of the Confluence space, e.g. MSF")
+	projectCreateCmd.Flags().StringVar(&project.ArtifactoryGroup, "artifactory-group", "", "Artifactory group the project member will be added to")
+	projectCreateCmd.Flags().StringVar(&project.ArtifactoryPackageType, "artifactory-package-type", "", "Package type of the Artifactory repositories named after the team, e.g. maven")
+	projectCreateCmd.Flags().BoolVar(&project.ConcourseRequired, "concourse-required", false, "Set to create a Concourse team owned by the Github team, with a starter pipeline for the core repository")
+}
+
+// AddSubCommands adds the sub-commands to the provided command
+func AddSubCommands(cmd *cobra.Command) {
+	cmd.AddCommand(createCmd)
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"omniactl/github/service"
+	"omniactl/github/state"
+	githubLogin "omniactl/login/github"
+
+	"github.com/spf13/cobra"
+)
+
+var file string
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Shows the changes needed for Github to match a state file.",
+	Long: "'plan' compares the orgs, teams, memberships and team repo permissions in a YAML state file with Github " +
+		"and lists the changes 'apply' would make. Nothing is changed.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := githubLogin.CreateClient()
+		if err != nil {
+			return err
+		}
+		return state.PlanFile(service.New(client), file)
+	},
+}
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Changes Github to match a state file.",
+	Long: "'apply' lists the changes needed for Github to match a YAML state file, as 'plan' does, " +
+		"and once confirmed creates orgs, repos and teams and adds, updates or removes memberships and team repo permissions.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := githubLogin.CreateClient()
+		if err != nil {
+			return err
+		}
+		return state.ApplyFile(service.New(client), file)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{planCmd, applyCmd} {
+		cmd.Flags().StringVarP(&file, "file", "f", "", "YAML state file, e.g. github.yaml (required)")
+		cmd.MarkFlagRequired("file")
+	}
+}
+
+// AddSubCommands adds the sub-commands to the provided command
+func AddSubCommands(cmd *cobra.Command) {
+	cmd.AddCommand(planCmd)
+	cmd.AddCommand(applyCmd)
+}
@@ -0,0 +1,153 @@
+package team
+
+import (
+	"errors"
+	"fmt"
+	listTeam "omniactl/concourse/list/team"
+	"omniactl/concourse/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// DefaultRole is the role the Github team of a new project is mapped to
+const DefaultRole = "owner"
+
+// nameFormat is the format of Concourse team names
+var nameFormat = regexp.MustCompile(`^[A-Za-z0-9._\-]+$`)
+
+// Team is the body of a request to create or update a Concourse team
+type Team struct {
+	Auth listTeam.Auth `json:"auth"`
+}
+
+// CreateTeam creates a Concourse team, or updates an existing one, mapping each role
+// to the Github teams listed for it. Github teams are given by name, in the Github
+// organisation org, or as <org>/<team>. The mappings of an existing team are kept.
+func CreateTeam(svc *service.Service, name string, org string, roles map[string][]string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	magentaBold.Println("Action selected: Create or update a Concourse team")
+
+	name, err := CheckName(name)
+	if err != nil {
+		return err
+	}
+	auth, err := GithubAuth(org, roles)
+	if err != nil {
+		return err
+	}
+	team, err := listTeam.GetTeam(svc, name)
+	if err != nil && !errs.Is(err, errs.NotFound) {
+		return err
+	}
+	action := "created"
+	if team != nil {
+		auth = Merge(team.Auth, auth)
+		action = "updated"
+	}
+	if err := svc.Do("PUT", "teams/"+name, Team{Auth: auth}, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error creating Concourse team '%v'", name)
+	}
+	fmt.Println("")
+	whiteBold.Printf("Concourse team '%v' %v: %v\n", name, action, auth)
+	return nil
+}
+
+// CheckName checks flag input, prompting if none or an invalid name was set
+func CheckName(name string) (string, error) {
+	validate := func(input string) error {
+		if !nameFormat.MatchString(input) {
+			return errors.New("Team name can only contain the following characters: A-Z, a-z, 0-9, ., -, _")
+		}
+		return nil
+	}
+	if validate(name) == nil {
+		return name, nil
+	}
+	prompt := promptui.Prompt{
+		Label:    "Concourse team name",
+		Validate: validate,
+	}
+	return interactive.Prompt(prompt)
+}
+
+// GithubAuth returns the auth mapping each role to the groups of the Github teams,
+// e.g. 'galleon' in organisation 'aps' becomes the group 'github:aps:galleon'
+func GithubAuth(org string, roles map[string][]string) (listTeam.Auth, error) {
+	auth := listTeam.Auth{}
+	for role, teams := range roles {
+		if !isRole(role) {
+			return nil, errs.New(errs.Validation, "'%v' is not a Concourse role, choose among: %v", role, strings.Join(listTeam.Roles, ", "))
+		}
+		for _, t := range teams {
+			group, err := GithubGroup(org, t)
+			if err != nil {
+				return nil, err
+			}
+			if auth[role] == nil {
+				auth[role] = map[string][]string{"users": {}, "groups": {}}
+			}
+			auth[role]["groups"] = append(auth[role]["groups"], group)
+		}
+	}
+	if len(auth) == 0 {
+		return nil, errs.New(errs.Validation, "no Github team mapped to any Concourse role")
+	}
+	return auth, nil
+}
+
+// GithubGroup returns the Concourse group of a Github team given by name, in the
+// organisation org, or as <org>/<team>
+func GithubGroup(org string, team string) (string, error) {
+	if parts := strings.SplitN(team, "/", 2); len(parts) == 2 {
+		org, team = parts[0], parts[1]
+	}
+	if org == "" || team == "" {
+		return "", errs.New(errs.Validation, "Github team '%v' needs an organisation: set --org or give it as <org>/<team>", team)
+	}
+	return "github:" + org + ":" + team, nil
+}
+
+// Merge returns the users and groups of each role of both auths, without duplicates
+func Merge(a listTeam.Auth, b listTeam.Auth) listTeam.Auth {
+	merged := listTeam.Auth{}
+	for _, auth := range []listTeam.Auth{a, b} {
+		for role, holders := range auth {
+			if merged[role] == nil {
+				merged[role] = map[string][]string{"users": {}, "groups": {}}
+			}
+			for kind, names := range holders {
+				merged[role][kind] = union(merged[role][kind], names)
+			}
+		}
+	}
+	return merged
+}
+
+func union(a []string, b []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, s := range append(append([]string{}, a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func isRole(role string) bool {
+	for _, r := range listTeam.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
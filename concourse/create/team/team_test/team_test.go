@@ -0,0 +1,90 @@
+package team_test
+
+import (
+	createTeam "omniactl/concourse/create/team"
+	"omniactl/concourse/fake"
+	listTeam "omniactl/concourse/list/team"
+	"omniactl/concourse/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var (
+	// server is the fake Concourse instance used by all tests in this package
+	server *fake.Server
+	// svc is the Concourse service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddTeam("msf", "github:aps:msf")
+	svc = service.New(server.URL, server.Client())
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestCreateTeam(t *testing.T) {
+	roles := map[string][]string{"owner": {"galleon"}, "viewer": {"ops/support"}}
+	if err := createTeam.CreateTeam(svc, "galleon", "aps", roles); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	team := server.Team("galleon")
+	if team == nil {
+		t.Fatal("Team 'galleon' was not created")
+	}
+	if got := team.Auth["owner"]["groups"]; !reflect.DeepEqual(got, []string{"github:aps:galleon"}) {
+		t.Errorf("Expected owner groups [github:aps:galleon] Got %v", got)
+	}
+	if got := team.Auth["viewer"]["groups"]; !reflect.DeepEqual(got, []string{"github:ops:support"}) {
+		t.Errorf("Expected viewer groups [github:ops:support] Got %v", got)
+	}
+}
+
+func TestUpdateTeamKeepsMappings(t *testing.T) {
+	if err := createTeam.CreateTeam(svc, "msf", "aps", map[string][]string{"owner": {"msf-admins"}, "member": {"msf"}}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	team, err := listTeam.GetTeam(svc, "msf")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	want := listTeam.Auth{
+		"owner":  {"users": {}, "groups": {"github:aps:msf", "github:aps:msf-admins"}},
+		"member": {"users": {}, "groups": {"github:aps:msf"}},
+	}
+	if !reflect.DeepEqual(team.Auth, want) {
+		t.Errorf("Expected auth %v Got %v", want, team.Auth)
+	}
+}
+
+func TestCreateTeamErrors(t *testing.T) {
+	type test struct {
+		name  string
+		team  string
+		org   string
+		roles map[string][]string
+		want  errs.Kind
+	}
+	tests := []test{
+		{"invalid name", "gal leon", "aps", map[string][]string{"owner": {"galleon"}}, errs.Validation},
+		{"unknown role", "qa", "aps", map[string][]string{"admin": {"galleon"}}, errs.Validation},
+		{"no organisation", "qa", "", map[string][]string{"owner": {"galleon"}}, errs.Validation},
+		{"no mappings", "qa", "aps", map[string][]string{"owner": {}}, errs.Validation},
+	}
+	for _, tc := range tests {
+		err := createTeam.CreateTeam(svc, tc.team, tc.org, tc.roles)
+		if !errs.Is(err, tc.want) {
+			t.Errorf("Creating team with %v: expected '%v' error Got '%v'", tc.name, tc.want, err)
+		}
+	}
+	if server.Team("qa") != nil {
+		t.Error("Team 'qa' was created despite invalid input")
+	}
+}
@@ -0,0 +1,320 @@
+// Package fake provides an in-process fake of the Concourse REST API for tests.
+// It keeps teams and pipelines in memory, so tests can run against it without
+// network access.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"omniactl/concourse/service"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Credentials of the local administrator the fake client authenticates as
+const (
+	AdminLogin    = "admin"
+	AdminPassword = "secret"
+)
+
+// Token is the access token issued to the administrator
+const Token = "fake-concourse-token"
+
+// Version is the Concourse version reported by the fake server
+const Version = "5.1.0"
+
+// Team is a Concourse team held by the fake server, with the users and groups
+// of each role, e.g. {"owner": {"groups": ["github:aps:galleon"]}}
+type Team struct {
+	ID   int
+	Name string
+	Auth map[string]map[string][]string
+}
+
+// Pipeline is a Concourse pipeline held by the fake server
+type Pipeline struct {
+	Name    string
+	Team    string
+	Config  string
+	Version int
+	Paused  bool
+}
+
+// Server is an in-process fake Concourse instance
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	teams     map[string]*Team
+	pipelines map[string]*Pipeline
+}
+
+// NewServer starts a fake Concourse server containing the main team, owned by the
+// administrator. The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		teams:     make(map[string]*Team),
+		pipelines: make(map[string]*Pipeline),
+	}
+	s.teams["main"] = &Team{ID: 1, Name: "main", Auth: map[string]map[string][]string{
+		"owner": {"users": {"local:" + AdminLogin}, "groups": {}},
+	}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an HTTP client authenticating with the token of the administrator
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: &bearer{Token}}
+}
+
+// bearer sets the access token of every request it sends
+type bearer struct {
+	token string
+}
+
+func (b *bearer) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(clone)
+}
+
+// AddTeam adds a team whose owners are the provided groups to the fake server
+func (s *Server) AddTeam(name string, groups ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams[name] = &Team{ID: len(s.teams) + 1, Name: name, Auth: map[string]map[string][]string{
+		"owner": {"users": {}, "groups": groups},
+	}}
+}
+
+// Team returns a copy of the team with the provided name, or nil if it does not exist
+func (s *Server) Team(name string) *Team {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.teams[name]
+	if !ok {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// Pipeline returns a copy of the pipeline of the team, or nil if it does not exist
+func (s *Server) Pipeline(team string, name string) *Pipeline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pipelines[team+"/"+name]
+	if !ok {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeText writes a plain text response, as Concourse does for most errors
+func writeText(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	fmt.Fprint(w, text)
+}
+
+func teamJSON(t *Team) map[string]interface{} {
+	return map[string]interface{}{"id": t.ID, "name": t.Name, "auth": t.Auth}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/"+service.TokenPath {
+		s.serveToken(w, r)
+		return
+	}
+	// The version is public, as 'fly' checks it before logging in
+	if r.URL.Path == "/api/v1/info" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"version": Version, "worker_version": "2.1"})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeText(w, http.StatusUnauthorized, "not authorized")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1"), "/")
+	segments := strings.Split(path, "/")
+
+	switch {
+	case r.Method == "GET" && path == "user":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"user_name": AdminLogin, "is_admin": true, "teams": map[string][]string{"main": {"owner"}},
+		})
+
+	case r.Method == "GET" && path == "teams":
+		names := []string{}
+		for name := range s.teams {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		items := []interface{}{}
+		for _, name := range names {
+			items = append(items, teamJSON(s.teams[name]))
+		}
+		writeJSON(w, http.StatusOK, items)
+
+	case r.Method == "PUT" && len(segments) == 2 && segments[0] == "teams":
+		body := struct {
+			Auth map[string]map[string][]string `json:"auth"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Auth) == 0 {
+			writeText(w, http.StatusBadRequest, "auth config for the team must not be empty")
+			return
+		}
+		for role := range body.Auth {
+			if role != "owner" && role != "member" && role != "pipeline-operator" && role != "viewer" {
+				writeText(w, http.StatusBadRequest, fmt.Sprintf("invalid role '%v'", role))
+				return
+			}
+		}
+		if t, ok := s.teams[segments[1]]; ok {
+			t.Auth = body.Auth
+			writeJSON(w, http.StatusOK, teamJSON(t))
+			return
+		}
+		t := &Team{ID: len(s.teams) + 1, Name: segments[1], Auth: body.Auth}
+		s.teams[t.Name] = t
+		writeJSON(w, http.StatusCreated, teamJSON(t))
+
+	case len(segments) >= 3 && segments[0] == "teams" && segments[2] == "pipelines":
+		if _, ok := s.teams[segments[1]]; !ok {
+			writeText(w, http.StatusNotFound, "")
+			return
+		}
+		s.servePipelines(w, r, segments[1], segments[3:])
+
+	default:
+		writeText(w, http.StatusNotFound, "")
+	}
+}
+
+// serveToken issues the access token for the local administrator, as Concourse
+// does when logging in with a username and password
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if id, secret, ok := r.BasicAuth(); r.Method != "POST" || !ok || id != service.ClientID || secret != service.ClientSecret {
+		writeText(w, http.StatusUnauthorized, "invalid client")
+		return
+	}
+	r.ParseForm()
+	if r.PostForm.Get("grant_type") != "password" || r.PostForm.Get("username") != AdminLogin || r.PostForm.Get("password") != AdminPassword {
+		writeText(w, http.StatusUnauthorized, "invalid username and password")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": Token, "token_type": "bearer", "expires_in": 86400})
+}
+
+func (s *Server) servePipelines(w http.ResponseWriter, r *http.Request, team string, segments []string) {
+	switch {
+	case r.Method == "GET" && len(segments) == 0:
+		names := []string{}
+		for _, p := range s.pipelines {
+			if p.Team == team {
+				names = append(names, p.Name)
+			}
+		}
+		sort.Strings(names)
+		items := []interface{}{}
+		for _, name := range names {
+			p := s.pipelines[team+"/"+name]
+			items = append(items, map[string]interface{}{"name": p.Name, "team_name": p.Team, "paused": p.Paused})
+		}
+		writeJSON(w, http.StatusOK, items)
+
+	case r.Method == "GET" && len(segments) == 2 && segments[1] == "config":
+		p, ok := s.pipelines[team+"/"+segments[0]]
+		if !ok {
+			writeText(w, http.StatusNotFound, "")
+			return
+		}
+		config := map[string]interface{}{}
+		yaml.Unmarshal([]byte(p.Config), &config)
+		w.Header().Set("X-Concourse-Config-Version", strconv.Itoa(p.Version))
+		writeJSON(w, http.StatusOK, map[string]interface{}{"config": jsonable(config)})
+
+	case r.Method == "PUT" && len(segments) == 2 && segments[1] == "config":
+		data, _ := ioutil.ReadAll(r.Body)
+		config := struct {
+			Jobs []struct {
+				Name string `yaml:"name"`
+			} `yaml:"jobs"`
+		}{}
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": []string{"malformed config: " + err.Error()}})
+			return
+		}
+		if len(config.Jobs) == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": []string{"invalid jobs: pipeline must contain at least one job"}})
+			return
+		}
+		p, ok := s.pipelines[team+"/"+segments[0]]
+		version := r.Header.Get("X-Concourse-Config-Version")
+		switch {
+		case !ok && version != "":
+			writeText(w, http.StatusNotFound, "")
+		case ok && version != strconv.Itoa(p.Version):
+			writeText(w, http.StatusConflict, "pipeline config has changed since it was fetched")
+		case ok:
+			p.Config = string(data)
+			p.Version++
+			writeJSON(w, http.StatusOK, map[string]interface{}{"errors": []string{}})
+		default:
+			s.pipelines[team+"/"+segments[0]] = &Pipeline{Name: segments[0], Team: team, Config: string(data), Version: 1, Paused: true}
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"errors": []string{}})
+		}
+
+	case r.Method == "PUT" && len(segments) == 2 && (segments[1] == "pause" || segments[1] == "unpause"):
+		p, ok := s.pipelines[team+"/"+segments[0]]
+		if !ok {
+			writeText(w, http.StatusNotFound, "")
+			return
+		}
+		p.Paused = segments[1] == "pause"
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		writeText(w, http.StatusNotFound, "")
+	}
+}
+
+// jsonable converts the maps decoded from YAML, which have interface{} keys,
+// into maps which can be encoded as JSON
+func jsonable(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, vv := range value {
+			m[fmt.Sprint(k)] = jsonable(vv)
+		}
+		return m
+	case map[string]interface{}:
+		for k, vv := range value {
+			value[k] = jsonable(vv)
+		}
+	case []interface{}:
+		for i, vv := range value {
+			value[i] = jsonable(vv)
+		}
+	}
+	return v
+}
@@ -0,0 +1,109 @@
+package team
+
+import (
+	"fmt"
+	"omniactl/concourse/service"
+	"omniactl/errs"
+	"omniactl/glob"
+	"omniactl/output"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Roles of Concourse teams, from most to least privileged
+var Roles = []string{"owner", "member", "pipeline-operator", "viewer"}
+
+// Auth holds the users and groups of each role of a team,
+// e.g. {"owner": {"groups": ["github:aps:galleon"]}}
+type Auth map[string]map[string][]string
+
+// Team is a Concourse team
+type Team struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Auth Auth   `json:"auth"`
+}
+
+// ListTeam lists the teams whose name matches the pattern, e.g. 'gal*'.
+// All teams are listed if the pattern is empty.
+func ListTeam(svc *service.Service, pattern string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: List Concourse teams")
+	teams, err := GetTeams(svc, pattern)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(teams)
+	}
+	if len(teams) == 0 {
+		color.New(color.FgRed).Printf("No Concourse teams found matching '%v'.\n", pattern)
+		return nil
+	}
+	PrintTeams(teams)
+	return nil
+}
+
+// GetTeams returns the teams whose name matches the pattern
+func GetTeams(svc *service.Service, pattern string) ([]Team, error) {
+	all := []Team{}
+	if err := svc.Do("GET", "teams", nil, &all); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting Concourse teams")
+	}
+	teams := []Team{}
+	for _, t := range all {
+		if glob.Match(pattern, t.Name) {
+			teams = append(teams, t)
+		}
+	}
+	return teams, nil
+}
+
+// GetTeam returns the team with the provided name, or a NotFound error
+func GetTeam(svc *service.Service, name string) (*Team, error) {
+	teams, err := GetTeams(svc, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range teams {
+		if t.Name == name {
+			return &t, nil
+		}
+	}
+	return nil, errs.New(errs.NotFound, "Concourse team '%v' does not exist", name)
+}
+
+// CheckIfTeamExists checks if a team with the provided name exists
+func CheckIfTeamExists(svc *service.Service, name string) (bool, error) {
+	_, err := GetTeam(svc, name)
+	if errs.Is(err, errs.NotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// String returns the users and groups of each role of the auth, e.g.
+// "owner: github:aps:galleon; viewer: local:ci"
+func (a Auth) String() string {
+	parts := []string{}
+	for _, role := range Roles {
+		holders := append(append([]string{}, a[role]["users"]...), a[role]["groups"]...)
+		if len(holders) == 0 {
+			continue
+		}
+		sort.Strings(holders)
+		parts = append(parts, role+": "+strings.Join(holders, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// PrintTeams prints teams as colourised text
+func PrintTeams(teams []Team) {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	for i, t := range teams {
+		greenBold.Printf("%-5v", i+1)
+		fmt.Printf("Name: %-25v | %v\n", t.Name, t.Auth)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"omniactl/errs"
+	"strings"
+	"time"
+)
+
+// API is the path of the Concourse REST API below the Concourse URL
+const API = "api/v1/"
+
+// TokenPath is the path below the Concourse URL which issues access tokens
+const TokenPath = "sky/token"
+
+// Credentials of the client the fly CLI, and so omniactl, requests tokens as
+const (
+	ClientID     = "fly"
+	ClientSecret = "Zmx5"
+)
+
+// Service holds the Concourse client shared by the concourse sub-packages.
+// It is created once by the cobra layer and passed to every function
+// which interacts with the Concourse API.
+type Service struct {
+	// Client sends the requests, authenticating them
+	Client *http.Client
+	// URL is the Concourse URL from the config file, e.g. https://concourse.example.com
+	URL string
+	// Username is the login requests are authenticated as
+	Username string
+}
+
+// New returns a Service sending requests to the Concourse instance at concourseURL with the provided client
+func New(concourseURL string, client *http.Client) *Service {
+	return &Service{Client: client, URL: strings.TrimSuffix(concourseURL, "/")}
+}
+
+// Token logs into the Concourse instance at concourseURL with a local username and
+// password, as 'fly login' does, and returns the access token and when it expires,
+// zero if Concourse did not say. The request is sent with the provided client, which
+// should not add any authentication of its own.
+func Token(client *http.Client, concourseURL string, username string, password string) (string, time.Time, error) {
+	form := url.Values{}
+	form.Set("grant_type", "password")
+	form.Set("username", username)
+	form.Set("password", password)
+	form.Set("scope", "openid profile email federated:id groups")
+	req, err := http.NewRequest("POST", strings.TrimSuffix(concourseURL, "/")+"/"+TokenPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	req.SetBasicAuth(ClientID, ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, errs.Wrap(errs.Unavailable, err, "error sending request to Concourse")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", time.Time{}, errs.New(errs.FromStatus(resp.StatusCode, ""), "Concourse login as '%v' failed: %v", username, resp.Status)
+	}
+	token := struct {
+		AccessToken string    `json:"access_token"`
+		Expiry      time.Time `json:"expiry"`
+		ExpiresIn   int64     `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", time.Time{}, errs.New(errs.Internal, "Concourse login as '%v' returned no access token", username)
+	}
+	if token.Expiry.IsZero() && token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token.AccessToken, token.Expiry, nil
+}
+
+// Do sends a request to the Concourse REST API, encoding body as JSON if it is not nil,
+// and decodes the JSON response into result if it is not nil. path is relative to
+// the REST API, e.g. "teams/msf".
+func (s *Service) Do(method string, path string, body interface{}, result interface{}) error {
+	var data []byte
+	header := map[string]string{"Accept": "application/json"}
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return errs.Wrap(errs.Internal, err, "error encoding request")
+		}
+		header["Content-Type"] = "application/json"
+	}
+	resp, _, err := s.Send(method, path, header, data)
+	if err != nil {
+		return err
+	}
+	if result == nil || len(bytes.TrimSpace(resp)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp, result); err != nil {
+		return errs.Wrap(errs.Internal, err, "error decoding response from Concourse")
+	}
+	return nil
+}
+
+// Send sends a request with the headers and raw body to the Concourse REST API and
+// returns the body and headers of the response. It is used for requests which are
+// not JSON, such as setting the YAML config of a pipeline.
+func (s *Service) Send(method string, path string, header map[string]string, body []byte) ([]byte, http.Header, error) {
+	req, err := http.NewRequest(method, s.URL+"/"+API+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, nil, errs.Wrap(errs.Unavailable, err, "error sending request to Concourse")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, errs.Wrap(errs.Unavailable, err, "error reading response from Concourse")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := errorMessage(data)
+		if message == "" {
+			message = resp.Status
+		}
+		return nil, nil, errs.New(errs.FromStatus(resp.StatusCode, message), "%v", message)
+	}
+	return data, resp.Header, nil
+}
+
+// errorMessage returns the messages of a Concourse error response, e.g.
+// {"errors": ["..."]} for an invalid pipeline config, or the body if it is plain text
+func errorMessage(data []byte) string {
+	concourseErr := struct {
+		Errors []string `json:"errors"`
+	}{}
+	if err := json.Unmarshal(data, &concourseErr); err != nil {
+		return strings.TrimSpace(string(data))
+	}
+	return strings.Join(concourseErr.Errors, "; ")
+}
+
+// Query returns path with the values appended as an escaped query string
+func Query(path string, values map[string]string) string {
+	v := url.Values{}
+	for key, value := range values {
+		v.Set(key, value)
+	}
+	return path + "?" + v.Encode()
+}
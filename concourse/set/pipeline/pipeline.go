@@ -0,0 +1,196 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	listTeam "omniactl/concourse/list/team"
+	"omniactl/concourse/service"
+	"omniactl/errs"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultTemplate is the template pipelines are set from unless another is chosen
+const DefaultTemplate = "starter"
+
+// Templates are the pipeline templates, rendered with the Params of the pipeline.
+// Values in double parentheses, e.g. ((github-private-key)), are resolved by the
+// credential manager of Concourse when the pipeline runs.
+var Templates = map[string]string{
+	"starter": `resources:
+- name: source
+  type: git
+  source:
+    uri: {{ .GitURI }}
+    branch: {{ .Branch }}
+    private_key: ((github-private-key))
+
+jobs:
+- name: build
+  plan:
+  - get: source
+    trigger: true
+  - task: build
+    config:
+      platform: linux
+      image_resource:
+        type: registry-image
+        source: {repository: busybox}
+      inputs:
+      - name: source
+      run:
+        path: sh
+        args:
+        - -exc
+        - |
+          cd source
+          if [ -x ci/build.sh ]; then ci/build.sh; else ls -la; fi
+`,
+}
+
+// nameFormat is the format of Concourse pipeline names
+var nameFormat = regexp.MustCompile(`^[A-Za-z0-9._\-]+$`)
+
+// Params are the values a pipeline template is rendered with
+type Params struct {
+	Team   string
+	Name   string
+	Org    string
+	Repo   string
+	Branch string
+	// GitURI is the URI the source is cloned from, e.g. git@github.example.com:aps/galleon-core.git
+	GitURI string
+}
+
+// SetPipeline renders the template, or the template file if file is set, and sets it
+// as the config of the pipeline of the team, creating and unpausing the pipeline if
+// it does not exist yet
+func SetPipeline(svc *service.Service, templateName string, file string, p Params) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	magentaBold.Println("Action selected: Set a Concourse pipeline")
+
+	if !nameFormat.MatchString(p.Name) {
+		return errs.New(errs.Validation, "pipeline name '%v' can only contain the following characters: A-Z, a-z, 0-9, ., -, _", p.Name)
+	}
+	text, err := GetTemplate(templateName, file)
+	if err != nil {
+		return err
+	}
+	config, err := Render(text, p)
+	if err != nil {
+		return err
+	}
+	if _, err := listTeam.GetTeam(svc, p.Team); err != nil {
+		return err
+	}
+	version, err := GetConfigVersion(svc, p.Team, p.Name)
+	if err != nil {
+		return err
+	}
+	if err := SetConfig(svc, p.Team, p.Name, version, config); err != nil {
+		return err
+	}
+	fmt.Println("")
+	if version != "" {
+		whiteBold.Printf("Config of pipeline '%v' of Concourse team '%v' updated.\n", p.Name, p.Team)
+		return nil
+	}
+	// New pipelines are paused until they are unpaused
+	if err := svc.Do("PUT", "teams/"+p.Team+"/pipelines/"+p.Name+"/unpause", nil, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error unpausing pipeline '%v'", p.Name)
+	}
+	whiteBold.Printf("Pipeline '%v' created in Concourse team '%v'.\n", p.Name, p.Team)
+	return nil
+}
+
+// GetTemplate returns the contents of the template file if file is set, otherwise the named template
+func GetTemplate(name string, file string) (string, error) {
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", errs.Wrap(errs.Validation, err, "error reading pipeline template '%v'", file)
+		}
+		return string(data), nil
+	}
+	if name == "" {
+		name = DefaultTemplate
+	}
+	text, ok := Templates[name]
+	if !ok {
+		names := []string{}
+		for n := range Templates {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", errs.New(errs.Validation, "'%v' is not a pipeline template, choose among: %v", name, strings.Join(names, ", "))
+	}
+	return text, nil
+}
+
+// Render renders the pipeline template with the params and checks the result is valid YAML
+func Render(text string, p Params) ([]byte, error) {
+	if p.Branch == "" {
+		p.Branch = "master"
+	}
+	tmpl, err := template.New(p.Name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error parsing pipeline template")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, p); err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error rendering pipeline template")
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &config); err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "rendered pipeline config is not valid YAML")
+	}
+	return buf.Bytes(), nil
+}
+
+// GetConfigVersion returns the version of the config of the pipeline, which must be
+// sent with any update of it, or "" if the pipeline does not exist
+func GetConfigVersion(svc *service.Service, team string, name string) (string, error) {
+	_, header, err := svc.Send("GET", "teams/"+team+"/pipelines/"+name+"/config", map[string]string{"Accept": "application/json"}, nil)
+	if errs.Is(err, errs.NotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errs.Wrap(errs.KindOf(err), err, "error getting config of pipeline '%v'", name)
+	}
+	return header.Get("X-Concourse-Config-Version"), nil
+}
+
+// SetConfig sets the YAML config of the pipeline. version must be the current version
+// of the config, or "" to create the pipeline.
+func SetConfig(svc *service.Service, team string, name string, version string, config []byte) error {
+	header := map[string]string{"Content-Type": "application/x-yaml"}
+	if version != "" {
+		header["X-Concourse-Config-Version"] = version
+	}
+	if _, _, err := svc.Send("PUT", "teams/"+team+"/pipelines/"+name+"/config", header, config); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error setting config of pipeline '%v'", name)
+	}
+	return nil
+}
+
+// GitURI returns the SSH URI of the Github repository, given the Github API URL from
+// the config file, e.g. https://github.example.com/api/v3 or https://api.github.com
+func GitURI(githubURL string, org string, repo string) (string, error) {
+	if org == "" || repo == "" {
+		return "", errs.New(errs.Validation, "the Github organisation and repository of the pipeline are required")
+	}
+	u, err := url.Parse(githubURL)
+	if err != nil || u.Host == "" {
+		return "", errs.New(errs.Config, "'%v' is not a valid Github URL", githubURL)
+	}
+	host := strings.TrimPrefix(u.Hostname(), "api.")
+	return "git@" + host + ":" + org + "/" + repo + ".git", nil
+}
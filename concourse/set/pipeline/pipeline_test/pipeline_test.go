@@ -0,0 +1,132 @@
+package pipeline_test
+
+import (
+	"io/ioutil"
+	"omniactl/concourse/fake"
+	"omniactl/concourse/service"
+	setPipeline "omniactl/concourse/set/pipeline"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	// server is the fake Concourse instance used by all tests in this package
+	server *fake.Server
+	// svc is the Concourse service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddTeam("galleon", "github:aps:galleon")
+	svc = service.New(server.URL, server.Client())
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestSetPipeline(t *testing.T) {
+	params := setPipeline.Params{Team: "galleon", Name: "galleon-core", Org: "aps", Repo: "galleon-core", GitURI: "git@github.example.com:aps/galleon-core.git"}
+	if err := setPipeline.SetPipeline(svc, "", "", params); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	p := server.Pipeline("galleon", "galleon-core")
+	if p == nil {
+		t.Fatal("Pipeline 'galleon-core' was not created")
+	}
+	if p.Paused {
+		t.Error("New pipeline was left paused")
+	}
+	if !strings.Contains(p.Config, "uri: git@github.example.com:aps/galleon-core.git") || !strings.Contains(p.Config, "branch: master") {
+		t.Errorf("Starter pipeline was not rendered with the repository:\n%v", p.Config)
+	}
+
+	// Setting the pipeline again updates the config with the version fetched first
+	params.Branch = "develop"
+	if err := setPipeline.SetPipeline(svc, setPipeline.DefaultTemplate, "", params); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if p = server.Pipeline("galleon", "galleon-core"); p.Version != 2 || !strings.Contains(p.Config, "branch: develop") {
+		t.Errorf("Expected version 2 building develop Got version %v:\n%v", p.Version, p.Config)
+	}
+}
+
+func TestSetPipelineFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "pipeline.yml")
+	ioutil.WriteFile(file, []byte("jobs:\n- name: {{ .Repo }}-test\n  plan: []\n"), 0600)
+
+	params := setPipeline.Params{Team: "galleon", Name: "tests", Repo: "galleon-core"}
+	if err := setPipeline.SetPipeline(svc, "", file, params); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if p := server.Pipeline("galleon", "tests"); p == nil || !strings.Contains(p.Config, "name: galleon-core-test") {
+		t.Errorf("Pipeline was not set from the template file: %+v", p)
+	}
+}
+
+func TestSetPipelineErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	invalid := filepath.Join(dir, "invalid.yml")
+	ioutil.WriteFile(invalid, []byte("jobs: [\n"), 0600)
+	empty := filepath.Join(dir, "empty.yml")
+	ioutil.WriteFile(empty, []byte("resources: []\n"), 0600)
+
+	type test struct {
+		name     string
+		team     string
+		pipeline string
+		template string
+		file     string
+		want     errs.Kind
+	}
+	tests := []test{
+		{"unknown team", "nope", "core", "", "", errs.NotFound},
+		{"invalid pipeline name", "galleon", "core pipeline", "", "", errs.Validation},
+		{"unknown template", "galleon", "core", "nightly", "", errs.Validation},
+		{"missing template file", "galleon", "core", "", filepath.Join(dir, "missing.yml"), errs.Validation},
+		{"invalid YAML", "galleon", "core", "", invalid, errs.Validation},
+		{"config rejected by Concourse", "galleon", "core", "", empty, errs.Validation},
+	}
+	for _, tc := range tests {
+		params := setPipeline.Params{Team: tc.team, Name: tc.pipeline, GitURI: "git@github.example.com:aps/core.git"}
+		err := setPipeline.SetPipeline(svc, tc.template, tc.file, params)
+		if !errs.Is(err, tc.want) {
+			t.Errorf("Setting pipeline with %v: expected '%v' error Got '%v'", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestGitURI(t *testing.T) {
+	type test struct {
+		githubURL string
+		want      string
+	}
+	tests := []test{
+		{"https://github.example.com/api/v3", "git@github.example.com:aps/core.git"},
+		{"https://api.github.com/", "git@github.com:aps/core.git"},
+	}
+	for _, tc := range tests {
+		got, err := setPipeline.GitURI(tc.githubURL, "aps", "core")
+		if err != nil || got != tc.want {
+			t.Errorf("Git URI of %v: expected '%v' Got '%v', error '%v'", tc.githubURL, tc.want, got, err)
+		}
+	}
+	if _, err := setPipeline.GitURI("https://github.example.com/api/v3", "", "core"); !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error without organisation Got '%v'", errs.Validation, err)
+	}
+}
@@ -1 +1,466 @@
+// Package config reads the settings of omniactl, through viper, from the first
+// config file found of: the file set by --config, the file set by OMNIACTL_CONFIG,
+// ~/.omniactl.yaml and ./.omniactl.yaml. Settings are overridden by environment
+// variables prefixed with OMNIACTL_, e.g. OMNIACTL_DEFAULT_ORG. INI .omniactl files
+// written by earlier versions are read if no YAML file is found, or if one is set
+// by --config or OMNIACTL_CONFIG, and converted by Migrate.
 package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"omniactl/errs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FileName is the config file written by 'omniactl config update'
+const FileName = ".omniactl.yaml"
+
+// LegacyFileName is the INI config file written by earlier versions
+const LegacyFileName = ".omniactl"
+
+// Environment variables selecting the config file and profile, and prefixing the
+// variables which override settings
+const (
+	EnvConfig  = "OMNIACTL_CONFIG"
+	EnvProfile = "OMNIACTL_PROFILE"
+	EnvPrefix  = "OMNIACTL"
+)
+
+// DefaultProfile is the profile used unless another profile is selected
+const DefaultProfile = "default"
+
+// Keys of the settings, which may be set at the top of the config file or in a profile
+const (
+	DefaultOrgKey      = "default_org"
+	EmailDomainKey     = "email_domain"
+	UsernamePatternKey = "username_pattern"
+)
+
+// Built-in values of the settings
+const (
+	DefaultEmailDomain     = "statestreet.com"
+	DefaultUsernamePattern = `^e[0-9]{6}$`
+)
+
+// Systems are the systems whose URL is set in each profile
+var Systems = []string{"github", "jira", "confluence", "artifactory", "concourse", "vault"}
+
+// ConfigFile is the config file set by the --config flag
+var ConfigFile string
+
+// Profile is the profile selected by the --profile flag
+var Profile string
+
+// File is the layout of the config file. Profiles map the name of each profile to
+// the URLs of the systems and to the settings which differ from the top level ones.
+type File struct {
+	CurrentProfile  string                       `yaml:"current_profile,omitempty"`
+	DefaultOrg      string                       `yaml:"default_org,omitempty"`
+	EmailDomain     string                       `yaml:"email_domain,omitempty"`
+	UsernamePattern string                       `yaml:"username_pattern,omitempty"`
+	Profiles        map[string]map[string]string `yaml:"profiles,omitempty"`
+}
+
+// profileName matches the names of profiles, which are keys of the config file
+var profileName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+var (
+	// mu serialises loading and reading the config, as clients may be created concurrently
+	mu     sync.Mutex
+	loaded bool
+	used   string
+	legacy bool
+)
+
+// Init reads the config file, replacing any config read before. It is called once
+// the flags are parsed; other functions read the config on first use otherwise.
+func Init() error {
+	mu.Lock()
+	defer mu.Unlock()
+	return load()
+}
+
+// Used returns the config file which was read, or where the config is written if
+// none was found, and whether it is an INI file of an earlier version
+func Used() (string, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	ensureLoaded()
+	return used, legacy
+}
+
+// Find returns the first config file found of: --config, OMNIACTL_CONFIG,
+// ~/.omniactl.yaml, ./.omniactl.yaml, then the INI files ./.omniactl and ~/.omniactl
+// of earlier versions, and whether it is an INI file. If none exists, it returns
+// ~/.omniactl.yaml, which is where the config is written.
+func Find() (string, bool, error) {
+	if file := explicit(); file != "" {
+		return file, isINI(file), nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", false, errs.Wrap(errs.Config, err, "error finding home directory")
+	}
+	candidates := []struct {
+		file   string
+		legacy bool
+	}{
+		{filepath.Join(home, FileName), false},
+		{FileName, false},
+		{LegacyFileName, true},
+		{filepath.Join(home, LegacyFileName), true},
+	}
+	for _, c := range candidates {
+		if info, err := os.Stat(c.file); err == nil && !info.IsDir() {
+			return c.file, c.legacy, nil
+		}
+	}
+	return filepath.Join(home, FileName), false, nil
+}
+
+// explicit returns the config file set by --config or OMNIACTL_CONFIG, if any
+func explicit() string {
+	if ConfigFile != "" {
+		return ConfigFile
+	}
+	return os.Getenv(EnvConfig)
+}
+
+// isINI reports whether the file is an INI file of an earlier version: either its
+// extension is .ini, or it has no YAML extension and its first line which is not
+// blank or a comment is a [section] or a key = value
+func isINI(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return false
+	case ".ini":
+		return true
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return true
+		}
+		equals, colon := strings.Index(line, "="), strings.Index(line, ":")
+		return equals > 0 && (colon < 0 || equals < colon)
+	}
+	return false
+}
+
+// target returns where the config is written when no YAML file is found: the file
+// set by --config or OMNIACTL_CONFIG, or ~/.omniactl.yaml. If the file set is an
+// INI file, the YAML file next to it is, e.g. .omniactl.yaml for .omniactl.
+func target() (string, error) {
+	if file := explicit(); file != "" {
+		if !isINI(file) {
+			return file, nil
+		}
+		if strings.ToLower(filepath.Ext(file)) == ".ini" {
+			file = strings.TrimSuffix(file, filepath.Ext(file))
+		}
+		return file + ".yaml", nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", errs.Wrap(errs.Config, err, "error finding home directory")
+	}
+	return filepath.Join(home, FileName), nil
+}
+
+// load reads the config file found by Find into viper
+func load() error {
+	loaded = true
+	viper.Reset()
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+	viper.SetDefault(EmailDomainKey, DefaultEmailDomain)
+	viper.SetDefault(UsernamePatternKey, DefaultUsernamePattern)
+	viper.SetConfigType("yaml")
+
+	file, isLegacy, err := Find()
+	if err != nil {
+		return err
+	}
+	used, legacy = file, isLegacy
+
+	var f *File
+	if isLegacy {
+		f, err = ReadLegacy(file)
+	} else {
+		f, err = read(file)
+	}
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error encoding config")
+	}
+	if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+		return errs.Wrap(errs.Config, err, "error reading config file '%v'", file)
+	}
+	if _, err := regexp.Compile(setting(UsernamePatternKey)); err != nil {
+		return errs.Wrap(errs.Config, err, "invalid %v in config file '%v'", UsernamePatternKey, file)
+	}
+	return nil
+}
+
+// ensureLoaded reads the config file if it was not read yet. Errors are reported
+// by Init; if it fails, the config is empty.
+func ensureLoaded() {
+	if !loaded {
+		load()
+	}
+}
+
+// read decodes the YAML config file, which is empty if it does not exist
+func read(file string) (*File, error) {
+	f := &File{}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, errs.Wrap(errs.Config, err, "error reading config file '%v'", file)
+	}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, errs.Wrap(errs.Config, err, "error decoding config file '%v'", file)
+	}
+	return f, nil
+}
+
+// write encodes the config file, only readable by its owner
+func write(file string, f *File) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error encoding config")
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return errs.Wrap(errs.Config, err, "error creating config directory")
+	}
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		return errs.Wrap(errs.Config, err, "error writing config file '%v'", file)
+	}
+	return nil
+}
+
+// CurrentProfile returns the profile selected by --profile, then OMNIACTL_PROFILE,
+// then 'omniactl config use-profile', or the default profile
+func CurrentProfile() string {
+	mu.Lock()
+	defer mu.Unlock()
+	ensureLoaded()
+	return currentProfile()
+}
+
+func currentProfile() string {
+	for _, profile := range []string{Profile, os.Getenv(EnvProfile), viper.GetString("current_profile")} {
+		if profile != "" {
+			return strings.ToLower(profile)
+		}
+	}
+	return DefaultProfile
+}
+
+// Profiles returns the names of the profiles in the config file, sorted
+func Profiles() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	ensureLoaded()
+	names := []string{}
+	for name := range viper.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the value of the key, e.g. "github", in the profile, without falling
+// back on the top level settings
+func Get(profile string, key string) string {
+	mu.Lock()
+	defer mu.Unlock()
+	ensureLoaded()
+	return viper.GetString("profiles." + profile + "." + key)
+}
+
+// GetURL returns the URL endpoint configured for the given API, e.g. "github",
+// in the current profile. OMNIACTL_PROFILES_<PROFILE>_<API> overrides it.
+func GetURL(name string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	ensureLoaded()
+	profile := currentProfile()
+	if url := viper.GetString("profiles." + profile + "." + name); url != "" {
+		return url, nil
+	}
+	if !viper.IsSet("profiles." + profile) {
+		return "", errs.New(errs.Config, "no '%v' profile in config file '%v', run 'omniactl config update --profile %v' to create it",
+			profile, used, profile)
+	}
+	return "", errs.New(errs.Config, "no %v URL set in the '%v' profile of config file '%v'", name, profile, used)
+}
+
+// Setting returns the value of the setting, e.g. DefaultOrgKey, in the current
+// profile, or else at the top of the config file. OMNIACTL_<KEY> overrides the top
+// level setting.
+func Setting(key string) string {
+	mu.Lock()
+	defer mu.Unlock()
+	ensureLoaded()
+	return setting(key)
+}
+
+func setting(key string) string {
+	if value := viper.GetString("profiles." + currentProfile() + "." + key); value != "" {
+		return value
+	}
+	return viper.GetString(key)
+}
+
+// Org returns the organisation, or the default organisation if it is empty
+func Org(org string) string {
+	if org != "" {
+		return org
+	}
+	return Setting(DefaultOrgKey)
+}
+
+// CheckUsername checks that the username matches the username pattern
+func CheckUsername(username string) error {
+	pattern := Setting(UsernamePatternKey)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return errs.Wrap(errs.Config, err, "invalid %v", UsernamePatternKey)
+	}
+	if !re.MatchString(username) {
+		if pattern == DefaultUsernamePattern {
+			return errs.New(errs.Validation, "username '%v' is not a State Street Lan ID, e.g. 'e123456'", username)
+		}
+		return errs.New(errs.Validation, "username '%v' does not match the username pattern '%v'", username, pattern)
+	}
+	return nil
+}
+
+// CheckEmail checks that the email is an address of the email domain
+func CheckEmail(email string) error {
+	domain := Setting(EmailDomainKey)
+	if !regexp.MustCompile(`^[a-z0-9._%+\-]+@` + regexp.QuoteMeta(domain) + `$`).MatchString(email) {
+		return errs.New(errs.Validation, "email '%v' is not an address of the %v domain, e.g. 'example@%v'", email, domain, domain)
+	}
+	return nil
+}
+
+// Update applies the change to the config file which was read, or to the file
+// returned by Find if none exists, then reads it again. An INI file of an earlier
+// version is migrated to ~/.omniactl.yaml and left as it is, unless it was set by
+// --config or OMNIACTL_CONFIG, which must then be migrated first.
+func Update(change func(f *File) error) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	ensureLoaded()
+	file := used
+
+	var f *File
+	var err error
+	if legacy && explicit() != "" {
+		return "", errs.New(errs.Config, "config file '%v' is an INI file of an earlier version, run 'omniactl config migrate' "+
+			"and set --config or %v to the YAML file", file, EnvConfig)
+	}
+	if legacy {
+		f, err = ReadLegacy(file)
+		if err == nil {
+			file, err = target()
+		}
+	} else {
+		f, err = read(file)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := change(f); err != nil {
+		return "", err
+	}
+	if err := write(file, f); err != nil {
+		return "", err
+	}
+	return file, load()
+}
+
+// SetURLs sets the URLs of the systems in the profile, creating it if needed
+func SetURLs(profile string, urls map[string]string) (string, error) {
+	if !profileName.MatchString(profile) {
+		return "", errs.New(errs.Validation, "invalid profile name '%v', use lower case letters, digits, '-' and '_'", profile)
+	}
+	return Update(func(f *File) error {
+		if f.Profiles == nil {
+			f.Profiles = map[string]map[string]string{}
+		}
+		if f.Profiles[profile] == nil {
+			f.Profiles[profile] = map[string]string{}
+		}
+		for _, name := range Systems {
+			f.Profiles[profile][name] = urls[name]
+		}
+		return nil
+	})
+}
+
+// UseProfile saves the profile as the one used when neither --profile nor
+// OMNIACTL_PROFILE is set. The profile must exist.
+func UseProfile(profile string) (string, error) {
+	profile = strings.ToLower(profile)
+	return Update(func(f *File) error {
+		if _, ok := f.Profiles[profile]; !ok {
+			names := []string{}
+			for name := range f.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return errs.New(errs.NotFound, "no '%v' profile in the config file, profiles are: %v", profile, strings.Join(names, ", "))
+		}
+		f.CurrentProfile = profile
+		return nil
+	})
+}
+
+// Set sets the value of a setting at the top of the config file
+func Set(key string, value string) (string, error) {
+	if key == UsernamePatternKey {
+		if _, err := regexp.Compile(value); err != nil {
+			return "", errs.Wrap(errs.Validation, err, "invalid %v", key)
+		}
+	}
+	return Update(func(f *File) error {
+		switch key {
+		case DefaultOrgKey:
+			f.DefaultOrg = value
+		case EmailDomainKey:
+			f.EmailDomain = value
+		case UsernamePatternKey:
+			f.UsernamePattern = value
+		default:
+			return errs.New(errs.Validation, "unknown setting '%v', settings are %v, %v and %v",
+				key, DefaultOrgKey, EmailDomainKey, UsernamePatternKey)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,321 @@
+package config
+
+import (
+	"io/ioutil"
+	"omniactl/errs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+const testConfig = `default_org: aps
+profiles:
+  default:
+    github: https://github.example.com/api/v3
+  dev:
+    github: https://github.dev.example.com/api/v3
+  prod:
+    github: https://github.prod.example.com/api/v3
+    jira: https://jira.prod.example.com
+    default_org: msf
+`
+
+const testLegacyConfig = `current_profile = prod
+
+[config]
+github = https://github.example.com/api/v3
+jira   =
+
+[profile prod]
+github = https://github.prod.example.com/api/v3
+`
+
+// inTempDir runs the test in a temporary working directory, with a temporary home
+// directory, and returns a function restoring them
+func inTempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := filepath.Join(dir, "home")
+	work := filepath.Join(dir, "work")
+	os.Mkdir(home, 0700)
+	os.Mkdir(work, 0700)
+	wd, _ := os.Getwd()
+	os.Chdir(work)
+	homedir.DisableCache = true
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	return home, func() {
+		os.Chdir(wd)
+		os.Setenv("HOME", oldHome)
+		os.RemoveAll(dir)
+		for _, env := range []string{EnvConfig, EnvProfile, "OMNIACTL_DEFAULT_ORG", "OMNIACTL_PROFILES_PROD_JIRA"} {
+			os.Unsetenv(env)
+		}
+		Profile = ""
+		ConfigFile = ""
+		Init()
+	}
+}
+
+func TestFind(t *testing.T) {
+	home, restore := inTempDir(t)
+	defer restore()
+
+	type test struct {
+		create string
+		flag   string
+		env    string
+		file   string
+		legacy bool
+	}
+	// Each test adds a file or sets a flag, which takes precedence over the previous ones
+	tests := []test{
+		{"", "", "", filepath.Join(home, FileName), false},
+		{filepath.Join(home, LegacyFileName), "", "", filepath.Join(home, LegacyFileName), true},
+		{LegacyFileName, "", "", LegacyFileName, true},
+		{FileName, "", "", FileName, false},
+		{filepath.Join(home, FileName), "", "", filepath.Join(home, FileName), false},
+		{"", "", "env.yaml", "env.yaml", false},
+		{"", "flag.yaml", "env.yaml", "flag.yaml", false},
+	}
+	for _, test := range tests {
+		if test.create != "" {
+			ioutil.WriteFile(test.create, []byte{}, 0600)
+		}
+		ConfigFile = test.flag
+		os.Setenv(EnvConfig, test.env)
+		file, legacy, err := Find()
+		if file != test.file || legacy != test.legacy || err != nil {
+			t.Errorf("Expected '%v' legacy %v Got '%v' legacy %v, error '%v'", test.file, test.legacy, file, legacy, err)
+		}
+	}
+}
+
+func TestGetURL(t *testing.T) {
+	_, restore := inTempDir(t)
+	defer restore()
+	ioutil.WriteFile(FileName, []byte(testConfig), 0600)
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	type test struct {
+		flag string
+		env  string
+		name string
+		url  string
+		err  bool
+	}
+	tests := []test{
+		{"", "", "github", "https://github.example.com/api/v3", false},
+		{"", "dev", "github", "https://github.dev.example.com/api/v3", false},
+		{"prod", "dev", "github", "https://github.prod.example.com/api/v3", false},
+		{"prod", "", "jira", "https://jira.prod.example.com", false},
+		{"dev", "", "jira", "", true},
+		{"uat", "", "github", "", true},
+	}
+	for _, test := range tests {
+		Profile = test.flag
+		os.Setenv(EnvProfile, test.env)
+		url, err := GetURL(test.name)
+		if url != test.url || (err != nil) != test.err || (test.err && !errs.Is(err, errs.Config)) {
+			t.Errorf("Expected '%v' error %v for %v with --profile '%v' and %v '%v' Got '%v' error '%v'",
+				test.url, test.err, test.name, test.flag, EnvProfile, test.env, url, err)
+		}
+	}
+
+	Profile = "prod"
+	os.Setenv("OMNIACTL_PROFILES_PROD_JIRA", "https://jira.example.com")
+	if url, _ := GetURL("jira"); url != "https://jira.example.com" {
+		t.Errorf("Expected the environment to override the Jira URL Got '%v'", url)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	_, restore := inTempDir(t)
+	defer restore()
+	ioutil.WriteFile(FileName, []byte(testConfig), 0600)
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	if org := Org(""); org != "aps" {
+		t.Errorf("Expected the default org 'aps' Got '%v'", org)
+	}
+	if org := Org("other"); org != "other" {
+		t.Errorf("Expected the org of the flag 'other' Got '%v'", org)
+	}
+	os.Setenv("OMNIACTL_DEFAULT_ORG", "env")
+	if org := Org(""); org != "env" {
+		t.Errorf("Expected the default org of the environment 'env' Got '%v'", org)
+	}
+	Profile = "prod"
+	if org := Org(""); org != "msf" {
+		t.Errorf("Expected the default org of the prod profile 'msf' Got '%v'", org)
+	}
+
+	if err := CheckUsername("e123456"); err != nil {
+		t.Errorf("Expected 'e123456' to match the default pattern Got '%v'", err)
+	}
+	if err := CheckEmail("first.last@statestreet.com"); err != nil {
+		t.Errorf("Expected an address of the default domain to be valid Got '%v'", err)
+	}
+	if _, err := Set(UsernamePatternKey, `^[a-z]+$`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Set(EmailDomainKey, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckUsername("e123456"); !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Validation, err)
+	}
+	if err := CheckUsername("first"); err != nil {
+		t.Errorf("Expected 'first' to match the new pattern Got '%v'", err)
+	}
+	if err := CheckEmail("first.last@statestreet.com"); !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Validation, err)
+	}
+	if err := CheckEmail("first.last@example.com"); err != nil {
+		t.Errorf("Expected an address of the new domain to be valid Got '%v'", err)
+	}
+
+	if _, err := Set(UsernamePatternKey, `^[a-z`); !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error for an invalid pattern Got '%v'", errs.Validation, err)
+	}
+	if _, err := Set("colour", "blue"); !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error for an unknown setting Got '%v'", errs.Validation, err)
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	_, restore := inTempDir(t)
+	defer restore()
+	ioutil.WriteFile(FileName, []byte(testConfig), 0600)
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	if profiles := Profiles(); !reflect.DeepEqual(profiles, []string{"default", "dev", "prod"}) {
+		t.Errorf("Expected profiles default, dev and prod Got %v", profiles)
+	}
+	if _, err := UseProfile("prod"); err != nil {
+		t.Fatalf("Use profile failed: %v", err)
+	}
+	if profile := CurrentProfile(); profile != "prod" {
+		t.Errorf("Expected profile 'prod' Got '%v'", profile)
+	}
+	if url, _ := GetURL("jira"); url != "https://jira.prod.example.com" {
+		t.Errorf("Expected the Jira URL of prod Got '%v'", url)
+	}
+	os.Setenv(EnvProfile, "dev")
+	if profile := CurrentProfile(); profile != "dev" {
+		t.Errorf("Expected %v to take precedence Got '%v'", EnvProfile, profile)
+	}
+	os.Unsetenv(EnvProfile)
+
+	// The other profiles are kept
+	if url := Get("dev", "github"); url != "https://github.dev.example.com/api/v3" {
+		t.Errorf("Expected the dev profile to be kept Got '%v'", url)
+	}
+
+	_, err := UseProfile("uat")
+	if !errs.Is(err, errs.NotFound) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.NotFound, err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	home, restore := inTempDir(t)
+	defer restore()
+	ioutil.WriteFile(LegacyFileName, []byte(testLegacyConfig), 0600)
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The INI file is read until it is migrated
+	if url, err := GetURL("github"); url != "https://github.prod.example.com/api/v3" {
+		t.Errorf("Expected the Github URL of prod Got '%v', error '%v'", url, err)
+	}
+	file, err := Migrate("")
+	if file != filepath.Join(home, FileName) || err != nil {
+		t.Fatalf("Expected migration to '%v' Got '%v', error '%v'", filepath.Join(home, FileName), file, err)
+	}
+	if used, legacy := Used(); used != file || legacy {
+		t.Errorf("Expected '%v' to be used Got '%v', legacy %v", file, used, legacy)
+	}
+	f, err := read(file)
+	expected := &File{
+		CurrentProfile: "prod",
+		Profiles: map[string]map[string]string{
+			"default": {"github": "https://github.example.com/api/v3"},
+			"prod":    {"github": "https://github.prod.example.com/api/v3"},
+		},
+	}
+	if err != nil || !reflect.DeepEqual(f, expected) {
+		t.Errorf("Expected %+v Got %+v, error '%v'", expected, f, err)
+	}
+	if _, err := os.Stat(LegacyFileName); err != nil {
+		t.Errorf("Expected the INI file to be kept Got '%v'", err)
+	}
+
+	_, err = Migrate(LegacyFileName)
+	if !errs.Is(err, errs.AlreadyExists) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.AlreadyExists, err)
+	}
+}
+
+func TestExplicitLegacyFile(t *testing.T) {
+	_, restore := inTempDir(t)
+	defer restore()
+
+	type test struct {
+		file   string
+		legacy bool
+		yaml   string
+	}
+	tests := []test{
+		{"old", true, "old.yaml"},
+		{"omniactl.ini", true, "omniactl.yaml"},
+		{"new.yaml", false, "new.yaml"},
+	}
+	for _, test := range tests {
+		content := testLegacyConfig
+		if !test.legacy {
+			content = testConfig
+		}
+		ioutil.WriteFile(test.file, []byte(content), 0600)
+		os.Setenv(EnvConfig, test.file)
+		if err := Init(); err != nil {
+			t.Fatalf("Expected '%v' to be read Got '%v'", test.file, err)
+		}
+		if used, legacy := Used(); used != test.file || legacy != test.legacy {
+			t.Errorf("Expected '%v' legacy %v Got '%v' legacy %v", test.file, test.legacy, used, legacy)
+		}
+		if to, err := target(); to != test.yaml || err != nil {
+			t.Errorf("Expected the YAML file '%v' Got '%v', error '%v'", test.yaml, to, err)
+		}
+	}
+
+	// An INI file set by OMNIACTL_CONFIG is read, but must be migrated before it is updated
+	os.Setenv(EnvConfig, "old")
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+	if url, err := GetURL("github"); url != "https://github.prod.example.com/api/v3" {
+		t.Errorf("Expected the Github URL of prod Got '%v', error '%v'", url, err)
+	}
+	if _, err := Set(DefaultOrgKey, "aps"); !errs.Is(err, errs.Config) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Config, err)
+	}
+	if file, err := Migrate(""); file != "old.yaml" || err != nil {
+		t.Errorf("Expected migration to 'old.yaml' Got '%v', error '%v'", file, err)
+	}
+	if f, err := read("old.yaml"); err != nil || f.CurrentProfile != "prod" {
+		t.Errorf("Expected the migrated config Got %+v, error '%v'", f, err)
+	}
+}
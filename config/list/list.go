@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	// "github.com/manifoldco/promptui"
-	ini "gopkg.in/ini.v1"
 	// "io"
-	"log"
-	// "os"
+	"omniactl/config"
+	"omniactl/errs"
+	"os"
 	// "regexp"
 	// "strings"
 )
@@ -22,39 +22,94 @@ var (
 	vault       string
 )
 
-func ListConfigFile() {
+// ListConfigFile prints the URL endpoints of the current profile and the settings
+func ListConfigFile() error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: Display config file")
 	// whiteBold := color.New(color.FgHiWhite, color.Bold)
 	greenBold := color.New(color.FgGreen, color.Bold)
 	URLs := make(map[string]string)
 
-	cfg, err := ini.Load(".omniactl")
-	if err != nil {
-		log.Fatalln("Error loading .omniactl config file:", err)
+	file, legacy := config.Used()
+	profile := config.CurrentProfile()
+	found := false
+	for _, p := range config.Profiles() {
+		found = found || p == profile
+	}
+	if !found {
+		return errs.New(errs.Config, "no '%v' profile in config file '%v'", profile, file)
+	}
+	greenBold.Printf("%-17v ", "Config file")
+	if legacy {
+		fmt.Println(file, "(INI, run 'omniactl config migrate' to convert it)")
+	} else {
+		fmt.Println(file)
 	}
+	greenBold.Printf("%-17v ", "Profile")
+	fmt.Println(profile)
 
-	github = fmt.Sprint(cfg.Section("config").Key("github").String())
+	github = config.Get(profile, "github")
 	URLs["Github"] = github
 
-	jira = fmt.Sprint(cfg.Section("config").Key("jira").String())
+	jira = config.Get(profile, "jira")
 	URLs["Jira"] = jira
 
-	confluence = fmt.Sprint(cfg.Section("config").Key("confluence").String())
+	confluence = config.Get(profile, "confluence")
 	URLs["Confluence"] = confluence
 
-	artifactory = fmt.Sprint(cfg.Section("config").Key("artifactory").String())
+	artifactory = config.Get(profile, "artifactory")
 	URLs["Artifactory"] = artifactory
 
-	concourse = fmt.Sprint(cfg.Section("config").Key("concourse").String())
+	concourse = config.Get(profile, "concourse")
 	URLs["Concourse"] = concourse
 
-	vault = fmt.Sprint(cfg.Section("config").Key("vault").String())
+	vault = config.Get(profile, "vault")
 	URLs["Vault"] = vault
 
 	for name, url := range URLs {
-		greenBold.Printf("%-15v ", name)
+		greenBold.Printf("%-17v ", name)
 		fmt.Println(url)
 	}
+	for _, key := range []string{config.DefaultOrgKey, config.EmailDomainKey, config.UsernamePatternKey} {
+		greenBold.Printf("%-17v ", key)
+		fmt.Println(config.Setting(key))
+	}
+	fmt.Println("")
+	return nil
+}
+
+// ListProfiles prints the profiles of the config file, marking the current one
+func ListProfiles() error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: List config profiles")
+	greenBold := color.New(color.FgGreen, color.Bold)
+
+	current := config.CurrentProfile()
+	for _, profile := range config.Profiles() {
+		if profile == current {
+			greenBold.Printf("* %v\n", profile)
+		} else {
+			fmt.Printf("  %v\n", profile)
+		}
+	}
+	fmt.Println("")
+	return nil
+}
+
+// UseProfile selects the profile used when neither --profile nor OMNIACTL_PROFILE is set
+func UseProfile(profile string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Use config profile")
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+
+	file, err := config.UseProfile(profile)
+	if err != nil {
+		return err
+	}
+	whiteBold.Printf("Using profile '%v' of config file %v\n", profile, file)
+	if env := os.Getenv(config.EnvProfile); env != "" && env != profile {
+		color.New(color.FgYellow, color.Bold).Printf("%v is set to '%v', which takes precedence\n", config.EnvProfile, env)
+	}
 	fmt.Println("")
+	return nil
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"omniactl/errs"
+	"os"
+	"strings"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// ReadLegacy converts an INI config file of an earlier version: the [config] section
+// is the default profile, [profile <name>] sections are the other profiles and the
+// current_profile key at the top is the current profile
+func ReadLegacy(file string) (*File, error) {
+	cfg, err := ini.Load(file)
+	if err != nil {
+		return nil, errs.Wrap(errs.Config, err, "error loading INI config file '%v'", file)
+	}
+	f := &File{
+		CurrentProfile: cfg.Section(ini.DEFAULT_SECTION).Key("current_profile").String(),
+		Profiles:       map[string]map[string]string{},
+	}
+	for _, section := range cfg.Sections() {
+		profile := ""
+		switch name := section.Name(); {
+		case name == "config":
+			profile = DefaultProfile
+		case strings.HasPrefix(name, "profile "):
+			profile = strings.ToLower(strings.TrimPrefix(name, "profile "))
+		default:
+			continue
+		}
+		values := map[string]string{}
+		for _, key := range section.Keys() {
+			if key.String() != "" {
+				values[key.Name()] = key.String()
+			}
+		}
+		f.Profiles[profile] = values
+	}
+	return f, nil
+}
+
+// Migrate converts the INI config file of an earlier version, or the one found by
+// Find if from is empty, to the file set by --config or OMNIACTL_CONFIG, or else to
+// ~/.omniactl.yaml. If the file set is the INI file, the YAML file is written next
+// to it. The INI file is left as it is. It returns the new file.
+func Migrate(from string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	ensureLoaded()
+	if from == "" {
+		if !legacy {
+			return "", errs.New(errs.NotFound, "no INI config file found to migrate, '%v' is used", used)
+		}
+		from = used
+	}
+	f, err := ReadLegacy(from)
+	if err != nil {
+		return "", err
+	}
+	to, err := target()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(to); err == nil {
+		return "", errs.New(errs.AlreadyExists, "config file '%v' already exists", to)
+	}
+	if err := write(to, f); err != nil {
+		return "", err
+	}
+	return to, load()
+}
@@ -5,9 +5,8 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
-	ini "gopkg.in/ini.v1"
-	"io"
-	"log"
+	"omniactl/config"
+	"omniactl/interactive"
 	"os"
 	"regexp"
 	"strings"
@@ -32,7 +31,7 @@ var (
 )
 
 // UpdateConfigFile collects input from string or prompt and updates current config file
-func UpdateConfigFile(github string, jira string, confluence string, artifactory string, concourse string, vault string) {
+func UpdateConfigFile(github string, jira string, confluence string, artifactory string, concourse string, vault string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: Update config file")
 
@@ -57,61 +56,51 @@ func UpdateConfigFile(github string, jira string, confluence string, artifactory
 	}
 
 	CheckConfigFile()
-	UrlConfirmed = CheckAllFlags(UrlFromFlag)
-	WriteToConfigFile(UrlConfirmed)
+	UrlConfirmed, err := CheckAllFlags(UrlFromFlag)
+	if err != nil {
+		return err
+	}
+	return WriteToConfigFile(UrlConfirmed)
 }
 
-// WriteToConfigFile saves updated URL endpoints to config file
-func WriteToConfigFile(Urls map[string]string) {
-	nf, err := os.Create(".omniactl")
+// WriteToConfigFile saves updated URL endpoints to the current profile of the config
+// file, keeping the other profiles
+func WriteToConfigFile(Urls map[string]string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
 
+	// Write the values confirmed/added by user in the profile
+	profile := config.CurrentProfile()
+	file, err := config.SetURLs(profile, Urls)
 	if err != nil {
-		log.Fatal("Error updating config file:", err)
-	}
-	defer nf.Close()
-
-	// Write new config map with updated values
-	// Create new config file
-	ncf := ("[config]\n" +
-		"github=" + Urls["github"] + "\n" +
-		"jira=" + Urls["jira"] + "\n" +
-		"confluence=" + Urls["confluence"] + "\n" +
-		"artifactory=" + Urls["artifactory"] + "\n" +
-		"concourse=" + Urls["concourse"] + "\n" +
-		"vault=" + Urls["vault"] + "\n")
-
-	// Save values confirmed/added by user in new config file
-	_, err = io.Copy(nf, strings.NewReader(ncf))
-	if err != nil {
-		log.Fatal("Error creating new config file", err)
+		return err
 	}
 
-	whiteBold.Println("Config file updated:")
+	whiteBold.Printf("Config file %v updated, profile '%v':\n", file, profile)
 	for name, url := range Urls {
 		fmt.Printf("%-15v %v", name, url)
 		fmt.Println("")
 	}
+	return nil
 }
 
 // CheckAllFlags loops over all flags and checks if they were put or not
-func CheckAllFlags(UrlFromFlag map[string]string) map[string]string {
+func CheckAllFlags(UrlFromFlag map[string]string) (map[string]string, error) {
 	fmt.Println("")
 	for name, url := range UrlFromFlag {
-		url = CheckFlag(url, name)
+		url, err := CheckFlag(url, name)
+		if err != nil {
+			return nil, err
+		}
 		UrlFromFlag[name] = url
 		fmt.Println("")
 	}
-	return UrlFromFlag
+	return UrlFromFlag, nil
 }
 
 // CheckFlag checks an individual flag for correct format and replaces with prompt input when necessary
-func CheckFlag(url string, name string) string {
+func CheckFlag(url string, name string) (string, error) {
 	greenBold := color.New(color.FgGreen, color.Bold)
-	cfg, err := ini.Load(".omniactl")
-	if err != nil {
-		log.Fatalln("Error opening '.omniactl' config file:", err)
-	}
+	current := config.Get(config.CurrentProfile(), strings.ToLower(name))
 
 	if url != "" {
 		check := CheckURLFormat(url)
@@ -119,24 +108,24 @@ func CheckFlag(url string, name string) string {
 		case true:
 			greenBold.Printf("New %v URL ", name)
 			fmt.Println(url)
-			return url
+			return url, nil
 		default:
-			url = PromptURL(name)
-			return url
+			return PromptURL(name)
 		}
 	} else {
 		greenBold.Printf("Current %v URL:", name)
 		fmt.Println("")
-		fmt.Println(cfg.Section("config").Key(strings.ToLower(name)).String())
+		fmt.Println(current)
 
-		result := AcceptCurrent()
+		result, err := AcceptCurrent()
+		if err != nil {
+			return "", err
+		}
 		switch result {
 		case "yes":
-			url = fmt.Sprint(cfg.Section("config").Key(strings.ToLower(name)).String())
-			return url
+			return current, nil
 		default:
-			url = PromptURL(name)
-			return url
+			return PromptURL(name)
 		}
 	}
 }
@@ -151,21 +140,17 @@ func CheckURLFormat(URL string) bool {
 }
 
 // AcceptCurrent asks if user wants to accept the current value
-func AcceptCurrent() string {
+func AcceptCurrent() (string, error) {
 	prompt := promptui.Select{
 		Label: "Accept current value?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "yes")
 }
 
 // PromptURL asks user to provide a new URL and checks its format
-func PromptURL(name string) string {
+func PromptURL(name string) (string, error) {
 
 	validate := func(input string) error {
 		isCorrectFormat := regexp.MustCompile("^(http:\\/\\/www\\.|https:\\/\\/www\\.|http:\\/\\/|https:\\/\\/)?[a-z0-9]+([\\-\\.]{1}[a-z0-9]+)*\\.[a-z]{2,5}(:[0-9]{1,5})?(\\/.*)?$").MatchString
@@ -187,50 +172,59 @@ func PromptURL(name string) string {
 		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
+	return interactive.Prompt(prompt)
+}
+
+// CheckConfigFile loads values of the current profile from the config file.
+// Values which are not set are replaced with default values.
+func CheckConfigFile() {
+	profile := config.CurrentProfile()
+	for name, url := range map[string]*string{
+		"github":      &github,
+		"jira":        &jira,
+		"confluence":  &confluence,
+		"artifactory": &artifactory,
+		"concourse":   &concourse,
+		"vault":       &vault,
+	} {
+		*url = config.Get(profile, name)
+		if *url == "" {
+			*url = Defaults[name]
+		}
+	}
+}
+
+// SetSetting sets a setting, e.g. default_org, at the top of the config file
+func SetSetting(key string, value string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Update config setting")
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+
+	file, err := config.Set(key, value)
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return err
+	}
+	whiteBold.Printf("Config file %v updated:\n", file)
+	fmt.Printf("%-15v %v\n", key, value)
+	if env := os.Getenv(config.EnvPrefix + "_" + strings.ToUpper(key)); env != "" && env != value {
+		color.New(color.FgYellow, color.Bold).Printf("%v_%v is set to '%v', which takes precedence\n", config.EnvPrefix, strings.ToUpper(key), env)
 	}
-	return result
+	return nil
 }
 
-// CheckConfigFile tries to load values from .omniactl config file,
-// If file cannot be opened, it sets values with default values
-func CheckConfigFile() {
-	cfg, err := ini.Load(".omniactl")
-	// If file cannot be opened or doesn't exist, use default values
+// MigrateConfigFile converts the INI config file of an earlier version to YAML
+func MigrateConfigFile(from string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Migrate config file")
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+
+	to, err := config.Migrate(from)
 	if err != nil {
-		github = Defaults["github"]
-		jira = Defaults["jira"]
-		confluence = Defaults["confluence"]
-		artifactory = Defaults["artifactory"]
-		concourse = Defaults["concourse"]
-		vault = Defaults["vault"]
-	} else {
-		// Load values from file. If field is empty, replace with default value
-		github = fmt.Sprint(cfg.Section("config").Key("github").String())
-		if github == "" {
-			github = Defaults["github"]
-		}
-		jira = fmt.Sprint(cfg.Section("config").Key("jira").String())
-		if jira == "" {
-			jira = Defaults["jira"]
-		}
-		confluence = fmt.Sprint(cfg.Section("config").Key("confluence").String())
-		if confluence == "" {
-			confluence = Defaults["confluence"]
-		}
-		artifactory = fmt.Sprint(cfg.Section("config").Key("artifactory").String())
-		if artifactory == "" {
-			artifactory = Defaults["artifactory"]
-		}
-		concourse = fmt.Sprint(cfg.Section("config").Key("concourse").String())
-		if concourse == "" {
-			concourse = Defaults["concourse"]
-		}
-		vault = fmt.Sprint(cfg.Section("config").Key("vault").String())
-		if vault == "" {
-			vault = Defaults["vault"]
-		}
+		return err
+	}
+	whiteBold.Printf("Config file migrated to %v, the INI file can be removed\n", to)
+	if _, legacy := config.Used(); legacy {
+		whiteBold.Printf("Set --config or %v to %v to use it\n", config.EnvConfig, to)
 	}
+	return nil
 }
@@ -0,0 +1,101 @@
+package space
+
+import (
+	"errors"
+	"fmt"
+	listSpace "omniactl/confluence/list/space"
+	"omniactl/confluence/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"regexp"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// keyFormat is the format of Confluence space keys: letters and digits only
+var keyFormat = regexp.MustCompile(`^[A-Za-z0-9]{1,255}$`)
+
+// Space is the body of a request to create a Confluence space
+type Space struct {
+	Key         string      `json:"key"`
+	Name        string      `json:"name"`
+	Description Description `json:"description"`
+}
+
+// Description is the plain text description of a space
+type Description struct {
+	Plain struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"plain"`
+}
+
+// CreateSpace creates a Confluence space, prompting for the key and name if none were
+// provided. The user omniactl is logged in as becomes the space administrator.
+func CreateSpace(svc *service.Service, key string, name string, description string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Create new Confluence space")
+
+	key, err := CheckKey(svc, key)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		prompt := promptui.Prompt{
+			Label: "Confluence space name",
+			Validate: func(input string) error {
+				if input == "" {
+					return errors.New("Enter a name for the new space")
+				}
+				return nil
+			},
+		}
+		if name, err = interactive.Prompt(prompt); err != nil {
+			return err
+		}
+	}
+	return CreateConfluenceSpace(svc, key, name, description)
+}
+
+// CheckKey checks flag input, prompting if none or an invalid key was set,
+// and that no space has the key yet
+func CheckKey(svc *service.Service, key string) (string, error) {
+	validate := func(input string) error {
+		if !keyFormat.MatchString(input) {
+			return errors.New("Enter letters and digits only, e.g. 'MSF'")
+		}
+		return nil
+	}
+	if validate(key) != nil {
+		prompt := promptui.Prompt{
+			Label:    "Confluence space key",
+			Validate: validate,
+		}
+		var err error
+		if key, err = interactive.Prompt(prompt); err != nil {
+			return "", err
+		}
+	}
+	exists, err := listSpace.CheckIfSpaceExists(svc, key)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", errs.New(errs.AlreadyExists, "Confluence space '%v' already exists", key)
+	}
+	return key, nil
+}
+
+// CreateConfluenceSpace sends an HTTP Post request to create the space
+func CreateConfluenceSpace(svc *service.Service, key string, name string, description string) error {
+	body := Space{Key: key, Name: name}
+	body.Description.Plain.Value = description
+	body.Description.Plain.Representation = "plain"
+	if err := svc.Do("POST", "space", body, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error creating Confluence space '%v'", key)
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Confluence space '%v' created with key '%v'.\n", name, key)
+	return nil
+}
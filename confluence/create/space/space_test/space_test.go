@@ -0,0 +1,93 @@
+package space_test
+
+import (
+	"fmt"
+	createSpace "omniactl/confluence/create/space"
+	"omniactl/confluence/fake"
+	listSpace "omniactl/confluence/list/space"
+	"omniactl/confluence/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"os"
+	"testing"
+)
+
+var (
+	// server is the fake Confluence instance used by all tests in this package
+	server *fake.Server
+	// svc is the Confluence service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	// More spaces than fit on a page, so that listing is paginated
+	for i := 0; i < listSpace.PageSize+5; i++ {
+		server.AddSpace(fmt.Sprintf("TEST%v", i), fmt.Sprintf("Test space %v", i))
+	}
+	server.AddSpace("MSF", "Market Surveillance")
+	svc = service.New(server.URL, server.Client())
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestCreateSpace(t *testing.T) {
+	if err := createSpace.CreateSpace(svc, "GAL", "Galleon", "Galleon engineering wiki"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	space := server.Space("GAL")
+	if space == nil || space.Name != "Galleon" || space.Description != "Galleon engineering wiki" {
+		t.Errorf("Space 'GAL' was not created as expected: %+v", space)
+	}
+	if len(space.Users[fake.AdminLogin]) == 0 {
+		t.Error("Creator of space 'GAL' holds no permissions")
+	}
+}
+
+func TestCreateSpaceErrors(t *testing.T) {
+	type test struct {
+		key  string
+		name string
+		want errs.Kind
+	}
+	tests := []test{
+		{"MSF", "Market Surveillance", errs.AlreadyExists},
+		{"MS-F", "Invalid key", errs.Validation},
+		{"NEW", "", errs.Validation},
+	}
+	for _, tc := range tests {
+		err := createSpace.CreateSpace(svc, tc.key, tc.name, "")
+		if !errs.Is(err, tc.want) {
+			t.Errorf("Creating space '%v': expected '%v' error Got '%v'", tc.key, tc.want, err)
+		}
+	}
+}
+
+func TestGetSpaces(t *testing.T) {
+	type test struct {
+		glob string
+		want int
+	}
+	tests := []test{
+		{"TEST*", listSpace.PageSize + 5},
+		{"market*", 1},
+		{"XYZ", 0},
+	}
+	for _, tc := range tests {
+		spaces, err := listSpace.GetSpaces(svc, tc.glob)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if len(spaces) != tc.want {
+			t.Errorf("Listing '%v': expected %v spaces Got %v", tc.glob, tc.want, len(spaces))
+		}
+	}
+
+	_, err := listSpace.GetSpace(svc, "NOPE")
+	if !errs.Is(err, errs.NotFound) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.NotFound, err)
+	}
+}
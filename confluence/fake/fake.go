@@ -0,0 +1,391 @@
+// Package fake provides an in-process fake of the Confluence Server REST and JSON-RPC
+// APIs for tests. It keeps users, groups, spaces and space permissions in memory, so
+// tests can run against it without network access.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Credentials of the administrator the fake client authenticates as
+const (
+	AdminLogin    = "admin"
+	AdminPassword = "secret"
+)
+
+// SessionID is the value of the JSESSIONID cookie set for requests authenticated
+// as the administrator
+const SessionID = "fake-confluence-session"
+
+// Space is a Confluence space held by the fake server, with the permissions
+// of each user and group
+type Space struct {
+	ID          int64
+	Key         string
+	Name        string
+	Description string
+	Users       map[string][]string
+	Groups      map[string][]string
+}
+
+// Server is an in-process fake Confluence Server instance
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	nextID int64
+	users  map[string]string
+	groups map[string]bool
+	spaces map[string]*Space
+}
+
+// NewServer starts a fake Confluence server containing the administrator and the
+// confluence-users group. The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		nextID: 10000,
+		users:  make(map[string]string),
+		groups: make(map[string]bool),
+		spaces: make(map[string]*Space),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.AddUser(AdminLogin, "Administrator")
+	s.AddGroup("confluence-users")
+	return s
+}
+
+// Client returns an HTTP client authenticating as the administrator
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: &basicAuth{AdminLogin, AdminPassword}}
+}
+
+// basicAuth sets the credentials of every request it sends
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (b *basicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	clone.SetBasicAuth(b.username, b.password)
+	return http.DefaultTransport.RoundTrip(clone)
+}
+
+// AddUser adds a user to the fake server
+func (s *Server) AddUser(name string, displayName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[name] = displayName
+}
+
+// AddGroup adds a group to the fake server
+func (s *Server) AddGroup(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[name] = true
+}
+
+// AddSpace adds a space to the fake server, giving the administrator every permission
+func (s *Server) AddSpace(key string, name string) *Space {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addSpace(key, name, "")
+}
+
+// Space returns a copy of the space with the provided key, or nil if it does not exist
+func (s *Server) Space(key string) *Space {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sp, ok := s.spaces[key]
+	if !ok {
+		return nil
+	}
+	c := *sp
+	c.Users = copyPermissions(sp.Users)
+	c.Groups = copyPermissions(sp.Groups)
+	return &c
+}
+
+// Grant gives the user or group the permissions on the space, as if granted in the Confluence UI
+func (s *Server) Grant(key string, name string, group bool, permissions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grant(s.spaces[key], name, group, permissions)
+}
+
+func copyPermissions(m map[string][]string) map[string][]string {
+	c := make(map[string][]string)
+	for k, v := range m {
+		c[k] = append([]string{}, v...)
+	}
+	return c
+}
+
+// adminPermissions are given to the creator of a space
+var adminPermissions = []string{
+	"VIEWSPACE", "EDITSPACE", "EDITBLOG", "COMMENT", "CREATEATTACHMENT", "REMOVEOWNCONTENT",
+	"REMOVEPAGE", "REMOVEBLOG", "REMOVECOMMENT", "REMOVEATTACHMENT", "REMOVEMAIL",
+	"EXPORTSPACE", "SETPAGEPERMISSIONS", "SETSPACEPERMISSIONS",
+}
+
+func (s *Server) addSpace(key string, name string, description string) *Space {
+	s.nextID++
+	sp := &Space{ID: s.nextID, Key: key, Name: name, Description: description, Users: make(map[string][]string), Groups: make(map[string][]string)}
+	s.spaces[key] = sp
+	s.grant(sp, AdminLogin, false, adminPermissions)
+	return sp
+}
+
+func (s *Server) grant(sp *Space, name string, group bool, permissions []string) {
+	holders := sp.Users
+	if group {
+		holders = sp.Groups
+	}
+	for _, p := range permissions {
+		if !contains(holders[name], p) {
+			holders[name] = append(holders[name], p)
+		}
+	}
+	sort.Strings(holders[name])
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie("JSESSIONID"); err == nil {
+		if c.Value != SessionID {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"statusCode": 401, "message": "Not authenticated"})
+			return
+		}
+	} else if username, password, ok := r.BasicAuth(); !ok || username != AdminLogin || password != AdminPassword {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"statusCode": 401, "message": "Not authenticated"})
+		return
+	} else {
+		// Confluence starts a session for requests authenticated with a password
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: SessionID, Path: "/", HttpOnly: true})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case strings.HasPrefix(path, "rpc/json-rpc/confluenceservice-v2/") && r.Method == "POST":
+		s.serveRPC(w, r, strings.TrimPrefix(path, "rpc/json-rpc/confluenceservice-v2/"))
+	case strings.HasPrefix(path, "rest/api/"):
+		s.serveREST(w, r, strings.Split(strings.TrimPrefix(path, "rest/api/"), "/"))
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format of the Confluence REST API
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"statusCode": status, "message": message})
+}
+
+func spaceJSON(sp *Space) map[string]interface{} {
+	return map[string]interface{}{"id": sp.ID, "key": sp.Key, "name": sp.Name, "type": "global"}
+}
+
+func (s *Server) serveREST(w http.ResponseWriter, r *http.Request, segments []string) {
+	query := r.URL.Query()
+	switch {
+	case r.Method == "GET" && len(segments) == 2 && segments[0] == "user" && segments[1] == "current":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"type": "known", "username": AdminLogin, "displayName": s.users[AdminLogin]})
+
+	case r.Method == "GET" && len(segments) == 1 && segments[0] == "user":
+		name, ok := s.users[query.Get("username")]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No user found with username : %v", query.Get("username")))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"type": "known", "username": query.Get("username"), "displayName": name})
+
+	case r.Method == "GET" && len(segments) == 2 && segments[0] == "group":
+		if !s.groups[segments[1]] {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No group found with name : %v", segments[1]))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"type": "group", "name": segments[1]})
+
+	case r.Method == "GET" && len(segments) == 1 && segments[0] == "space":
+		start, _ := strconv.Atoi(query.Get("start"))
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		if limit < 1 {
+			limit = 25
+		}
+		keys := []string{}
+		for key := range s.spaces {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		results := []interface{}{}
+		for i := start; i < len(keys) && i < start+limit; i++ {
+			results = append(results, spaceJSON(s.spaces[keys[i]]))
+		}
+		links := map[string]interface{}{}
+		if start+limit < len(keys) {
+			links["next"] = fmt.Sprintf("/rest/api/space?start=%v&limit=%v", start+limit, limit)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": results, "start": start, "limit": limit, "size": len(results), "_links": links})
+
+	case r.Method == "GET" && len(segments) == 2 && segments[0] == "space":
+		sp, ok := s.spaces[segments[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No space with key : %v", segments[1]))
+			return
+		}
+		writeJSON(w, http.StatusOK, spaceJSON(sp))
+
+	case r.Method == "POST" && len(segments) == 1 && segments[0] == "space":
+		body := struct {
+			Key         string `json:"key"`
+			Name        string `json:"name"`
+			Description struct {
+				Plain struct {
+					Value string `json:"value"`
+				} `json:"plain"`
+			} `json:"description"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := s.spaces[body.Key]; ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("A space already exists with key %v", body.Key))
+			return
+		}
+		if body.Key == "" || body.Name == "" {
+			writeError(w, http.StatusBadRequest, "A space needs a key and a name")
+			return
+		}
+		writeJSON(w, http.StatusOK, spaceJSON(s.addSpace(body.Key, body.Name, body.Description.Plain.Value)))
+
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// serveRPC answers a JSON-RPC call. Like Confluence, errors are returned with status 200.
+func (s *Server) serveRPC(w http.ResponseWriter, r *http.Request, method string) {
+	params := []json.RawMessage{}
+	json.NewDecoder(r.Body).Decode(&params)
+	rpcError := func(message string) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"jsonrpc": "2.0", "error": map[string]interface{}{"code": 500, "message": message}})
+	}
+	space := func(i int) *Space {
+		var key string
+		if len(params) > i {
+			json.Unmarshal(params[i], &key)
+		}
+		sp, ok := s.spaces[key]
+		if !ok {
+			rpcError(fmt.Sprintf("com.atlassian.confluence.rpc.RemoteException: No space found for space key: %v", key))
+			return nil
+		}
+		return sp
+	}
+	// entity returns whether the name is a group and whether it is a user or group at all
+	entity := func(name string) (bool, bool) {
+		if _, ok := s.users[name]; ok {
+			return false, true
+		}
+		return true, s.groups[name]
+	}
+
+	switch method {
+	case "getSpacePermissionSets":
+		sp := space(0)
+		if sp == nil {
+			return
+		}
+		byType := map[string][]interface{}{}
+		for name, permissions := range sp.Users {
+			for _, p := range permissions {
+				byType[p] = append(byType[p], map[string]interface{}{"type": p, "userName": name, "groupName": nil})
+			}
+		}
+		for name, permissions := range sp.Groups {
+			for _, p := range permissions {
+				byType[p] = append(byType[p], map[string]interface{}{"type": p, "userName": nil, "groupName": name})
+			}
+		}
+		sets := []interface{}{}
+		for _, p := range adminPermissions {
+			sets = append(sets, map[string]interface{}{"type": p, "spacePermissions": byType[p]})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"jsonrpc": "2.0", "result": sets})
+
+	case "addPermissionsToSpace":
+		sp := space(2)
+		if sp == nil {
+			return
+		}
+		var permissions []string
+		var name string
+		json.Unmarshal(params[0], &permissions)
+		json.Unmarshal(params[1], &name)
+		group, ok := entity(name)
+		if !ok {
+			rpcError(fmt.Sprintf("com.atlassian.confluence.rpc.RemoteException: User or group '%v' does not exist", name))
+			return
+		}
+		for _, p := range permissions {
+			if !contains(adminPermissions, p) {
+				rpcError(fmt.Sprintf("com.atlassian.confluence.rpc.RemoteException: Unknown permission type '%v'", p))
+				return
+			}
+		}
+		s.grant(sp, name, group, permissions)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"jsonrpc": "2.0", "result": true})
+
+	case "removePermissionFromSpace":
+		sp := space(2)
+		if sp == nil {
+			return
+		}
+		var permission, name string
+		json.Unmarshal(params[0], &permission)
+		json.Unmarshal(params[1], &name)
+		group, ok := entity(name)
+		if !ok {
+			rpcError(fmt.Sprintf("com.atlassian.confluence.rpc.RemoteException: User or group '%v' does not exist", name))
+			return
+		}
+		holders := sp.Users
+		if group {
+			holders = sp.Groups
+		}
+		remaining := []string{}
+		for _, p := range holders[name] {
+			if p != permission {
+				remaining = append(remaining, p)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(holders, name)
+		} else {
+			holders[name] = remaining
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"jsonrpc": "2.0", "result": true})
+
+	default:
+		rpcError(fmt.Sprintf("No method %v", method))
+	}
+}
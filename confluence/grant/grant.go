@@ -0,0 +1,73 @@
+package grant
+
+import (
+	listPermission "omniactl/confluence/list/permission"
+	listSpace "omniactl/confluence/list/space"
+	"omniactl/confluence/service"
+	"omniactl/errs"
+
+	"github.com/fatih/color"
+)
+
+// Grant gives the users and groups the permissions of the level, i.e. view, edit or
+// admin, on the space. Every user and group is checked before any permission is granted.
+func Grant(svc *service.Service, spaceKey string, users []string, groups []string, level string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Grant Confluence space permissions")
+
+	if err := listPermission.CheckLevel(level); err != nil {
+		return err
+	}
+	if err := CheckHolders(svc, spaceKey, users, groups); err != nil {
+		return err
+	}
+	for _, name := range append(append([]string{}, users...), groups...) {
+		if err := GrantPermissions(svc, spaceKey, name, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckHolders checks that the space exists, at least one user or group was provided,
+// and every user and group exists
+func CheckHolders(svc *service.Service, spaceKey string, users []string, groups []string) error {
+	if spaceKey == "" {
+		return errs.New(errs.Validation, "no Confluence space provided")
+	}
+	if _, err := listSpace.GetSpace(svc, spaceKey); err != nil {
+		return err
+	}
+	if len(users) == 0 && len(groups) == 0 {
+		return errs.New(errs.Validation, "no Confluence users or groups provided")
+	}
+	for _, name := range users {
+		exists, err := listPermission.CheckIfUserExists(svc, name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errs.New(errs.NotFound, "Confluence user '%v' does not exist", name)
+		}
+	}
+	for _, name := range groups {
+		exists, err := listPermission.CheckIfGroupExists(svc, name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errs.New(errs.NotFound, "Confluence group '%v' does not exist", name)
+		}
+	}
+	return nil
+}
+
+// GrantPermissions adds the permissions of the level for the user or group to the space
+func GrantPermissions(svc *service.Service, spaceKey string, name string, level string) error {
+	params := []interface{}{listPermission.Levels[level], name, spaceKey}
+	if err := svc.Call("addPermissionsToSpace", params, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error granting '%v' permission on Confluence space '%v' to '%v'", level, spaceKey, name)
+	}
+	color.New(color.FgHiWhite, color.Bold).Printf("'%v' granted '%v' permission on Confluence space '%v'.\n", name, level, spaceKey)
+	return nil
+}
@@ -0,0 +1,153 @@
+package grant_test
+
+import (
+	"net/http"
+	"omniactl/auditlog"
+	"omniactl/confluence/fake"
+	"omniactl/confluence/grant"
+	listPermission "omniactl/confluence/list/permission"
+	"omniactl/confluence/revoke"
+	"omniactl/confluence/service"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+var (
+	// server is the fake Confluence instance used by all tests in this package
+	server *fake.Server
+	// svc is the Confluence service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddSpace("MSF", "Market Surveillance")
+	server.AddUser("e930001", "Developer")
+	server.AddUser("e930002", "Contractor")
+	server.AddGroup("msf-devs")
+	svc = service.New(server.URL, server.Client())
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func sorted(list []string) []string {
+	c := append([]string{}, list...)
+	sort.Strings(c)
+	return c
+}
+
+func TestGrant(t *testing.T) {
+	if err := grant.Grant(svc, "MSF", []string{"e930001"}, []string{"msf-devs"}, listPermission.Edit); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	space := server.Space("MSF")
+	want := sorted(listPermission.Levels[listPermission.Edit])
+	if got := space.Users["e930001"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected user permissions %v Got %v", want, got)
+	}
+	if got := space.Groups["msf-devs"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected group permissions %v Got %v", want, got)
+	}
+
+	holders, err := listPermission.GetPermissions(svc, "MSF")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(holders) != 3 {
+		t.Errorf("Expected permissions of admin, e930001 and msf-devs Got %+v", holders)
+	}
+}
+
+func TestGrantErrors(t *testing.T) {
+	type test struct {
+		name   string
+		space  string
+		users  []string
+		groups []string
+		level  string
+		want   errs.Kind
+	}
+	tests := []test{
+		{"unknown space", "NOPE", []string{"e930001"}, nil, "view", errs.NotFound},
+		{"unknown user", "MSF", []string{"e999999"}, nil, "view", errs.NotFound},
+		{"unknown group", "MSF", nil, []string{"nobody"}, "view", errs.NotFound},
+		{"no users or groups", "MSF", nil, nil, "view", errs.Validation},
+		{"unknown level", "MSF", []string{"e930001"}, nil, "owner", errs.Validation},
+	}
+	for _, tc := range tests {
+		err := grant.Grant(svc, tc.space, tc.users, tc.groups, tc.level)
+		if !errs.Is(err, tc.want) {
+			t.Errorf("Granting with %v: expected '%v' error Got '%v'", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	if err := grant.Grant(svc, "MSF", []string{"e930002"}, nil, listPermission.Admin); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := revoke.Revoke(svc, "MSF", []string{"e930002"}, nil); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	space := server.Space("MSF")
+	if got := space.Users["e930002"]; len(got) != 0 {
+		t.Errorf("Expected no permissions for e930002 Got %v", got)
+	}
+	if len(space.Users[fake.AdminLogin]) == 0 {
+		t.Error("Permissions of other users were revoked")
+	}
+}
+
+func TestPermissionsAreReadInDryRun(t *testing.T) {
+	if err := grant.Grant(svc, "MSF", []string{"e930001"}, nil, listPermission.View); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	want, err := listPermission.GetPermissions(svc, "MSF")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	log := filepath.Join(os.TempDir(), "grant-dryrun-audit.log")
+	os.Remove(log)
+	defer os.Remove(log)
+	os.Setenv(auditlog.EnvFile, log)
+	defer os.Unsetenv(auditlog.EnvFile)
+	client := &http.Client{Transport: &auditlog.Transport{
+		Base:   &dryrun.Transport{Base: server.Client().Transport},
+		System: "confluence",
+	}}
+	dryRunSvc := service.New(server.URL, client)
+
+	// Reading permissions is neither printed as a change in a dry run nor logged
+	for _, enabled := range []bool{true, false} {
+		dryrun.Enabled = enabled
+		got, err := listPermission.GetPermissions(dryRunSvc, "MSF")
+		dryrun.Enabled = false
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v with dry run %v Got %v, error '%v'", want, enabled, got, err)
+		}
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Errorf("Expected no audit log for reads Got '%v'", err)
+	}
+
+	// Revoking in a dry run finds the holder and changes nothing
+	dryrun.Enabled = true
+	err = revoke.Revoke(dryRunSvc, "MSF", []string{"e930001"}, nil)
+	dryrun.Enabled = false
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got := server.Space("MSF").Users["e930001"]; len(got) == 0 {
+		t.Error("Permissions were revoked in a dry run")
+	}
+}
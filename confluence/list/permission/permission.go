@@ -0,0 +1,126 @@
+package permission
+
+import (
+	"fmt"
+	"omniactl/confluence/service"
+	"omniactl/errs"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Permission levels which can be granted on a space
+const (
+	View  = "view"
+	Edit  = "edit"
+	Admin = "admin"
+)
+
+// Levels maps each permission level to the Confluence space permissions it grants.
+// Every level includes the permissions of the levels below it.
+var Levels = map[string][]string{
+	View: {"VIEWSPACE"},
+	Edit: {"VIEWSPACE", "EDITSPACE", "EDITBLOG", "COMMENT", "CREATEATTACHMENT", "REMOVEOWNCONTENT"},
+	Admin: {"VIEWSPACE", "EDITSPACE", "EDITBLOG", "COMMENT", "CREATEATTACHMENT", "REMOVEOWNCONTENT",
+		"REMOVEPAGE", "REMOVEBLOG", "REMOVECOMMENT", "REMOVEATTACHMENT", "REMOVEMAIL",
+		"EXPORTSPACE", "SETPAGEPERMISSIONS", "SETSPACEPERMISSIONS"},
+}
+
+// Holder is a user or group and the permissions they hold on a space
+type Holder struct {
+	Name        string
+	Group       bool
+	Permissions []string
+}
+
+// CheckLevel returns a validation error if the permission level is unknown
+func CheckLevel(level string) error {
+	if _, ok := Levels[level]; !ok {
+		return errs.New(errs.Validation, "unknown permission level '%v', use one of %v, %v or %v", level, View, Edit, Admin)
+	}
+	return nil
+}
+
+// GetPermissions returns the users and groups holding permissions on the space, sorted by name
+func GetPermissions(svc *service.Service, spaceKey string) ([]Holder, error) {
+	sets := []struct {
+		Type             string `json:"type"`
+		SpacePermissions []struct {
+			UserName  string `json:"userName"`
+			GroupName string `json:"groupName"`
+		} `json:"spacePermissions"`
+	}{}
+	if err := svc.Call("getSpacePermissionSets", []interface{}{spaceKey}, &sets); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting permissions of Confluence space '%v'", spaceKey)
+	}
+	// users and groups may share a name, so they are kept apart
+	byName := map[bool]map[string]*Holder{false: {}, true: {}}
+	for _, set := range sets {
+		for _, p := range set.SpacePermissions {
+			name, group := p.UserName, false
+			if name == "" {
+				name, group = p.GroupName, true
+			}
+			if name == "" {
+				// anonymous access
+				continue
+			}
+			if byName[group][name] == nil {
+				byName[group][name] = &Holder{Name: name, Group: group}
+			}
+			byName[group][name].Permissions = append(byName[group][name].Permissions, set.Type)
+		}
+	}
+	holders := []Holder{}
+	for _, names := range byName {
+		for _, h := range names {
+			sort.Strings(h.Permissions)
+			holders = append(holders, *h)
+		}
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if holders[i].Name == holders[j].Name {
+			return !holders[i].Group
+		}
+		return holders[i].Name < holders[j].Name
+	})
+	return holders, nil
+}
+
+// CheckIfUserExists checks if a Confluence user with the provided username exists
+func CheckIfUserExists(svc *service.Service, username string) (bool, error) {
+	err := svc.Do("GET", service.Query("user", map[string]string{"username": username}), nil, nil)
+	if errs.Is(err, errs.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errs.Wrap(errs.KindOf(err), err, "error getting Confluence user '%v'", username)
+	}
+	return true, nil
+}
+
+// CheckIfGroupExists checks if a Confluence group with the provided name exists
+func CheckIfGroupExists(svc *service.Service, name string) (bool, error) {
+	err := svc.Do("GET", "group/"+name, nil, nil)
+	if errs.Is(err, errs.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errs.Wrap(errs.KindOf(err), err, "error getting Confluence group '%v'", name)
+	}
+	return true, nil
+}
+
+// PrintHolders prints the permissions of the users and groups as colourised text
+func PrintHolders(holders []Holder) {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	for i, h := range holders {
+		kind := "user"
+		if h.Group {
+			kind = "group"
+		}
+		greenBold.Printf("%-5v", i+1)
+		fmt.Printf("%-6v %-20v | %v\n", kind, h.Name, strings.Join(h.Permissions, ", "))
+	}
+}
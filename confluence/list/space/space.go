@@ -0,0 +1,88 @@
+package space
+
+import (
+	"fmt"
+	"omniactl/confluence/result"
+	"omniactl/confluence/service"
+	"omniactl/errs"
+	"omniactl/glob"
+	"omniactl/output"
+	"strconv"
+
+	"github.com/fatih/color"
+)
+
+// PageSize is the number of spaces requested per page
+const PageSize = 50
+
+// ListSpace lists the spaces whose key or name match the glob, e.g. 'MSF*'.
+// All spaces are listed if the glob is empty.
+func ListSpace(svc *service.Service, glob string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: List Confluence spaces")
+	spaces, err := GetSpaces(svc, glob)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(spaces)
+	}
+	if len(spaces) == 0 {
+		color.New(color.FgRed).Printf("No Confluence spaces found matching '%v'.\n", glob)
+		return nil
+	}
+	PrintSpaces(spaces)
+	return nil
+}
+
+// GetSpaces returns the spaces whose key or name match the pattern, following every page
+func GetSpaces(svc *service.Service, pattern string) ([]result.Space, error) {
+	spaces := []result.Space{}
+	for start := 0; ; start += PageSize {
+		page := struct {
+			Results []result.Space `json:"results"`
+			Links   struct {
+				Next string `json:"next"`
+			} `json:"_links"`
+		}{}
+		path := service.Query("space", map[string]string{"start": strconv.Itoa(start), "limit": strconv.Itoa(PageSize)})
+		if err := svc.Do("GET", path, nil, &page); err != nil {
+			return nil, errs.Wrap(errs.KindOf(err), err, "error getting Confluence spaces")
+		}
+		for _, s := range page.Results {
+			if glob.Match(pattern, s.Key, s.Name) {
+				spaces = append(spaces, s)
+			}
+		}
+		if page.Links.Next == "" {
+			return spaces, nil
+		}
+	}
+}
+
+// GetSpace returns the space with the provided key, or a NotFound error
+func GetSpace(svc *service.Service, key string) (*result.Space, error) {
+	space := &result.Space{}
+	if err := svc.Do("GET", "space/"+key, nil, space); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting Confluence space '%v'", key)
+	}
+	return space, nil
+}
+
+// CheckIfSpaceExists checks if a space with the provided key exists
+func CheckIfSpaceExists(svc *service.Service, key string) (bool, error) {
+	_, err := GetSpace(svc, key)
+	if errs.Is(err, errs.NotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PrintSpaces prints spaces as colourised text
+func PrintSpaces(spaces []result.Space) {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	for i, s := range spaces {
+		greenBold.Printf("%-5v", i+1)
+		fmt.Printf("Key: %-12v | Name: %-40v | Type: %v\n", s.Key, s.Name, s.Type)
+	}
+}
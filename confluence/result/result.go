@@ -0,0 +1,12 @@
+// Package result holds the structs returned by the confluence list commands.
+// They are rendered by the output package in the format selected through
+// the global --output flag.
+package result
+
+// Space describes a Confluence space
+type Space struct {
+	Key  string `json:"key" yaml:"key"`
+	Name string `json:"name" yaml:"name"`
+	ID   int64  `json:"id" yaml:"id"`
+	Type string `json:"type" yaml:"type"`
+}
@@ -0,0 +1,82 @@
+package revoke
+
+import (
+	"fmt"
+	"omniactl/confluence/grant"
+	listPermission "omniactl/confluence/list/permission"
+	"omniactl/confluence/service"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// Revoke removes every permission the users and groups hold on the space once confirmed
+func Revoke(svc *service.Service, spaceKey string, users []string, groups []string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Revoke Confluence space permissions")
+
+	if err := grant.CheckHolders(svc, spaceKey, users, groups); err != nil {
+		return err
+	}
+	holders, err := listPermission.GetPermissions(svc, spaceKey)
+	if err != nil {
+		return err
+	}
+	revoke := []listPermission.Holder{}
+	for _, h := range holders {
+		if (!h.Group && contains(users, h.Name)) || (h.Group && contains(groups, h.Name)) {
+			revoke = append(revoke, h)
+		}
+	}
+	if len(revoke) == 0 {
+		color.New(color.FgRed).Printf("None of '%v' hold permissions on Confluence space '%v'.\n", strings.Join(append(append([]string{}, users...), groups...), "', '"), spaceKey)
+		return nil
+	}
+	listPermission.PrintHolders(revoke)
+
+	check, err := PromptRevoke(spaceKey)
+	if err != nil {
+		return err
+	}
+	if check != "yes" {
+		return nil
+	}
+	for _, h := range revoke {
+		if err := RevokePermissions(svc, spaceKey, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PromptRevoke asks for confirmation before the permissions are removed
+func PromptRevoke(spaceKey string) (string, error) {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Revoke these permissions on Confluence space '%v'?", spaceKey),
+		Items: []string{"yes", "no"},
+	}
+	return interactive.Select(prompt, "yes")
+}
+
+// RevokePermissions removes each permission the user or group holds on the space
+func RevokePermissions(svc *service.Service, spaceKey string, h listPermission.Holder) error {
+	for _, p := range h.Permissions {
+		if err := svc.Call("removePermissionFromSpace", []interface{}{p, h.Name, spaceKey}, nil); err != nil {
+			return errs.Wrap(errs.KindOf(err), err, "error revoking '%v' on Confluence space '%v' from '%v'", p, spaceKey, h.Name)
+		}
+	}
+	color.New(color.FgHiWhite, color.Bold).Printf("Permissions of '%v' on Confluence space '%v' revoked.\n", h.Name, spaceKey)
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
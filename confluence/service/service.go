@@ -0,0 +1,190 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"strings"
+)
+
+// API is the path of the Confluence REST API below the Confluence URL
+const API = "rest/api/"
+
+// RPC is the path of the Confluence JSON-RPC API below the Confluence URL.
+// Confluence Server only manages space permissions through this API.
+const RPC = "rpc/json-rpc/confluenceservice-v2/"
+
+// Service holds the Confluence client shared by the confluence sub-packages.
+// It is created once by the cobra layer and passed to every function
+// which interacts with the Confluence API.
+type Service struct {
+	// Client sends the requests, authenticating them
+	Client *http.Client
+	// URL is the Confluence URL from the config file, e.g. https://confluence.example.com
+	URL string
+	// Username is the login requests are authenticated as
+	Username string
+}
+
+// New returns a Service sending requests to the Confluence instance at confluenceURL with the provided client
+func New(confluenceURL string, client *http.Client) *Service {
+	return &Service{Client: client, URL: strings.TrimSuffix(confluenceURL, "/")}
+}
+
+// Login authenticates a request for the current user with the username and password
+// and returns the session cookie Confluence sets, e.g. "JSESSIONID=...", which
+// authenticates later requests instead of the password
+func Login(client *http.Client, confluenceURL string, username string, password string) (string, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(confluenceURL, "/")+"/"+API+"user/current", nil)
+	if err != nil {
+		return "", errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	req.SetBasicAuth(username, password)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errs.Wrap(errs.Unavailable, err, "error sending request to Confluence")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", errs.New(errs.FromStatus(resp.StatusCode, ""), "Confluence login as '%v' failed: %v", username, resp.Status)
+	}
+	user := struct {
+		Type string `json:"type"`
+	}{}
+	// Confluence answers requests with wrong credentials as the anonymous user
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil || user.Type == "anonymous" {
+		return "", errs.New(errs.PermissionDenied, "Confluence login as '%v' failed: not authenticated", username)
+	}
+	for _, c := range resp.Cookies() {
+		if c.Name == "JSESSIONID" && c.Value != "" {
+			return c.Name + "=" + c.Value, nil
+		}
+	}
+	return "", errs.New(errs.Internal, "Confluence login as '%v' returned no session cookie", username)
+}
+
+// Do sends a request to the Confluence REST API, encoding body as JSON if it is not nil,
+// and decodes the JSON response into result if it is not nil. path is relative to
+// the REST API, e.g. "space/MSF".
+func (s *Service) Do(method string, path string, body interface{}, result interface{}) error {
+	data, status, err := s.send(method, API+path, body, false)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		message := struct {
+			Message string `json:"message"`
+		}{}
+		json.Unmarshal(data, &message)
+		if message.Message == "" {
+			message.Message = http.StatusText(status)
+		}
+		return errs.New(errs.FromStatus(status, message.Message), "%v", message.Message)
+	}
+	return decode(data, result)
+}
+
+// readOnlyMethods are the JSON-RPC methods which only read. They are sent with
+// POST like all others, so they are marked for --dry-run and the audit log.
+var readOnlyMethods = map[string]bool{
+	"getSpacePermissionSets": true,
+}
+
+// Call calls a method of the Confluence JSON-RPC API with the parameters and decodes
+// its result into result if it is not nil
+func (s *Service) Call(method string, params []interface{}, result interface{}) error {
+	data, status, err := s.send("POST", RPC+method, params, readOnlyMethods[method])
+	if err != nil {
+		return err
+	}
+	response := struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	if err := json.Unmarshal(data, &response); err != nil && (status < 200 || status > 299) {
+		return errs.New(errs.FromStatus(status, ""), "%v", http.StatusText(status))
+	}
+	if response.Error != nil {
+		return errs.New(classifyRPC(status, response.Error.Message), "%v", response.Error.Message)
+	}
+	if status < 200 || status > 299 {
+		return errs.New(errs.FromStatus(status, ""), "%v", http.StatusText(status))
+	}
+	return decode(response.Result, result)
+}
+
+// classifyRPC classifies a JSON-RPC error, which Confluence returns with status 200
+// and a message naming the remote exception
+func classifyRPC(status int, message string) errs.Kind {
+	if status < 200 || status > 299 {
+		return errs.FromStatus(status, message)
+	}
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "not permitted"), strings.Contains(lower, "notpermitted"), strings.Contains(lower, "authentication"):
+		return errs.PermissionDenied
+	case strings.Contains(lower, "no space"), strings.Contains(lower, "does not exist"), strings.Contains(lower, "not found"):
+		return errs.NotFound
+	}
+	return errs.Validation
+}
+
+// send sends the request, marked with dryrun.ReadOnly if readOnly is set
+func (s *Service) send(method string, path string, body interface{}, readOnly bool) ([]byte, int, error) {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return nil, 0, errs.Wrap(errs.Internal, err, "error encoding request")
+		}
+	}
+	req, err := http.NewRequest(method, s.URL+"/"+path, &buf)
+	if err != nil {
+		return nil, 0, errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if readOnly {
+		req = dryrun.ReadOnly(req)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, 0, errs.Wrap(errs.Unavailable, err, "error sending request to Confluence")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, errs.Wrap(errs.Unavailable, err, "error reading response from Confluence")
+	}
+	return data, resp.StatusCode, nil
+}
+
+func decode(data []byte, result interface{}) error {
+	if result == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return errs.Wrap(errs.Internal, err, "error decoding response from Confluence")
+	}
+	return nil
+}
+
+// Query returns path with the values appended as an escaped query string
+func Query(path string, values map[string]string) string {
+	v := url.Values{}
+	for key, value := range values {
+		v.Set(key, value)
+	}
+	return path + "?" + v.Encode()
+}
@@ -0,0 +1,104 @@
+// Package dryrun prints the requests which would change anything instead of sending them.
+// Requests which only read, i.e. GET and HEAD or requests marked with ReadOnly, are still
+// sent, so that all validation and existence checks run as usual.
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/fatih/color"
+)
+
+// Enabled is set through the global --dry-run flag
+var Enabled bool
+
+// readOnlyKey is the context key marking requests which only read
+type readOnlyKey struct{}
+
+// ReadOnly marks a request which only reads although its method is not GET or HEAD,
+// e.g. a JSON-RPC call reading permissions, so that it is sent in a dry run and
+// not written to the audit log
+func ReadOnly(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), readOnlyKey{}, true))
+}
+
+// IsReadOnly reports whether the request only reads: its method is GET or HEAD, or
+// it is marked with ReadOnly
+func IsReadOnly(req *http.Request) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return true
+	}
+	readOnly, _ := req.Context().Value(readOnlyKey{}).(bool)
+	return readOnly
+}
+
+// Transport is an http.RoundTripper which, when dry-run is enabled, prints the method,
+// path and JSON body of every request that changes anything and answers it itself.
+// The response echoes the request's body with status 200 OK, so that callers decoding
+// the created resource get back what they sent. PUT and DELETE requests without a body
+// are answered with 204 No Content, as Github answers them.
+type Transport struct {
+	// Base is the transport requests are sent with, http.DefaultTransport if nil
+	Base http.RoundTripper
+}
+
+// RoundTrip sends read-only requests and prints all others when dry-run is enabled
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Enabled || IsReadOnly(req) {
+		return t.base().RoundTrip(req)
+	}
+
+	body := []byte{}
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	Print(req.Method, req.URL.RequestURI(), body)
+
+	status := http.StatusOK
+	if len(body) == 0 && (req.Method == http.MethodPut || req.Method == http.MethodDelete) {
+		status = http.StatusNoContent
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %v", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// Print prints a request which is not sent, with its JSON body indented
+func Print(method string, path string, body []byte) {
+	color.New(color.FgYellow, color.Bold).Printf("DRY RUN: %v %v\n", method, path)
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return
+	}
+	indented := bytes.Buffer{}
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		fmt.Fprintln(color.Output, string(body))
+		return
+	}
+	fmt.Fprintln(color.Output, indented.String())
+}
@@ -0,0 +1,164 @@
+package dryrun_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	createTeam "omniactl/github/create/team"
+	createUser "omniactl/github/create/user"
+	deleteUser "omniactl/github/delete/user"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	"omniactl/github/snapshot"
+	suspendUser "omniactl/github/suspend/user"
+	updateUser "omniactl/github/update/user"
+	"omniactl/interactive"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/github"
+)
+
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package, sending requests through the dry-run transport
+	svc *service.Service
+	// dir is the snapshot directory
+	dir string
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddOrg("testing")
+	server.AddUser("e300001", "e300001@statestreet.com")
+	server.AddOrgMember("testing", "e300001", "member")
+
+	client, err := github.NewEnterpriseClient(server.URL+"/api/v3/", server.URL+"/api/uploads/", &http.Client{Transport: &dryrun.Transport{}})
+	if err != nil {
+		panic(err)
+	}
+	svc = service.New(client)
+	interactive.Disabled = true
+	dryrun.Enabled = true
+
+	dir, err = ioutil.TempDir("", "dryrun_test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(snapshot.EnvDir, dir)
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// capture returns everything printed to color.Output while f runs
+func capture(f func()) string {
+	out := &bytes.Buffer{}
+	defer func(w io.Writer) { color.Output = w }(color.Output)
+	color.Output = out
+	f()
+	return out.String()
+}
+
+func TestSuspendUserDryRun(t *testing.T) {
+	var err error
+	printed := capture(func() { err = suspendUser.SuspendUser(svc, "e300001", "left the company") })
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if server.User("e300001").Suspended {
+		t.Error("User 'e300001' was suspended in a dry run")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "e300001.json")); !os.IsNotExist(err) {
+		t.Error("Snapshot of 'e300001' was written in a dry run")
+	}
+	for _, want := range []string{"DRY RUN: PUT /api/v3/users/e300001/suspended", `"reason": "left the company"`} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Dry run printed:\n%v\nwant it to contain '%v'", printed, want)
+		}
+	}
+}
+
+func TestCreateUserDryRun(t *testing.T) {
+	var username string
+	var err error
+	printed := capture(func() { username, _, err = createUser.CreateUser(svc, "e300002", "e300002@statestreet.com") })
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	// The response echoes the request, so later steps use the login that was sent
+	if username != "e300002" {
+		t.Errorf("CreateUser() got username '%v', want 'e300002'", username)
+	}
+	if server.User("e300002") != nil {
+		t.Error("User 'e300002' was created in a dry run")
+	}
+	if !strings.Contains(printed, "DRY RUN: POST /api/v3/admin/users") {
+		t.Errorf("Dry run printed:\n%v\nwant it to contain the POST to admin/users", printed)
+	}
+}
+
+func TestChecksStillRun(t *testing.T) {
+	// Existence checks are sent, so a missing org fails the dry run as it would fail the real run
+	err := createTeam.CreateTeam(svc, "devops", "missing", "DevOps", nil, "closed")
+	if !errs.Is(err, errs.NotFound) && !errs.Is(err, errs.Validation) {
+		t.Errorf("CreateTeam() in missing org got error %v, want a not found or validation error", err)
+	}
+	if server.Team("missing", "devops") != nil {
+		t.Error("Team 'devops' was created in a dry run")
+	}
+}
+
+func TestMakeSiteAdminDryRun(t *testing.T) {
+	var err error
+	printed := capture(func() { err = updateUser.MakeSiteAdmin(svc, "e300001") })
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if server.User("e300001").SiteAdmin {
+		t.Error("User 'e300001' was promoted to site admin in a dry run")
+	}
+	if !strings.Contains(printed, "DRY RUN: PUT /api/v3/users/e300001/site_admin") {
+		t.Errorf("Dry run printed:\n%v\nwant it to contain the PUT to site_admin", printed)
+	}
+}
+
+func TestRemoveOrgMemberDryRun(t *testing.T) {
+	var err error
+	printed := capture(func() { err = updateUser.RemoveOrgMember(svc, "e300001") })
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, ok := server.Org("testing").Members["e300001"]; !ok {
+		t.Error("User 'e300001' was removed from organisation 'testing' in a dry run")
+	}
+	if !strings.Contains(printed, "DRY RUN: DELETE /api/v3/orgs/testing/members/e300001") {
+		t.Errorf("Dry run printed:\n%v\nwant it to contain the DELETE of the membership", printed)
+	}
+}
+
+func TestDeleteFromGithubDryRun(t *testing.T) {
+	var err error
+	printed := capture(func() { err = deleteUser.DeleteFromGithub(svc, "e300001") })
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if server.User("e300001") == nil {
+		t.Error("User 'e300001' was deleted in a dry run")
+	}
+	for _, want := range []string{"DRY RUN: DELETE /api/v3/admin/users/e300001", "User 'e300001' would be deleted."} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Dry run printed:\n%v\nwant it to contain '%v'", printed, want)
+		}
+	}
+}
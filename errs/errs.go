@@ -0,0 +1,208 @@
+// Package errs defines the typed errors returned by omniactl's library packages
+// and the exit codes the cobra layer maps them to.
+//
+// Exit codes:
+//
+//	0    success
+//	1    internal or unexpected error
+//	2    validation error: missing or invalid input
+//	3    not found
+//	4    already exists
+//	5    permission denied: authentication failed or insufficient privileges
+//	6    rate limited
+//	7    unavailable: an API endpoint could not be reached
+//	8    configuration error: config file or credentials missing or invalid
+//	9    drift: Github does not match the declared access model
+//	130  aborted by the user
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// Kind classifies an error
+type Kind int
+
+// Kinds of errors, see the package documentation for the corresponding exit codes
+const (
+	Internal Kind = iota
+	Validation
+	NotFound
+	AlreadyExists
+	PermissionDenied
+	RateLimited
+	Unavailable
+	Config
+	Aborted
+	Drift
+)
+
+// exitCodes maps each kind of error to the exit code of the program
+var exitCodes = map[Kind]int{
+	Internal:         1,
+	Validation:       2,
+	NotFound:         3,
+	AlreadyExists:    4,
+	PermissionDenied: 5,
+	RateLimited:      6,
+	Unavailable:      7,
+	Config:           8,
+	Aborted:          130,
+	Drift:            9,
+}
+
+// String returns a readable name for the kind of error
+func (k Kind) String() string {
+	switch k {
+	case Validation:
+		return "validation error"
+	case NotFound:
+		return "not found"
+	case AlreadyExists:
+		return "already exists"
+	case PermissionDenied:
+		return "permission denied"
+	case RateLimited:
+		return "rate limited"
+	case Unavailable:
+		return "unavailable"
+	case Config:
+		return "configuration error"
+	case Aborted:
+		return "aborted"
+	case Drift:
+		return "drift detected"
+	default:
+		return "internal error"
+	}
+}
+
+// Error is an error of a specific kind, optionally wrapping the underlying cause
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	if e.Message == "" {
+		return e.Err.Error()
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying cause of the error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error of the given kind with a formatted message
+func New(kind Kind, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap returns an error of the given kind wrapping err with a formatted message.
+// It returns nil if err is nil.
+func Wrap(kind Kind, err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// KindOf returns the kind of err, or Internal if err is not a typed error
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Internal
+}
+
+// Is reports whether err is of the given kind
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
+
+// ExitCode returns the exit code for err, or 0 if err is nil
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return exitCodes[KindOf(err)]
+}
+
+// FromGithub wraps an error returned by the Github client with a formatted
+// message, classifying it by the response received from Github.
+// It returns nil if err is nil.
+func FromGithub(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return Wrap(classifyGithub(err), err, format, args...)
+}
+
+func classifyGithub(err error) Kind {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var respErr *github.ErrorResponse
+	var urlErr *url.Error
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &rateErr), errors.As(err, &abuseErr):
+		return RateLimited
+	case errors.As(err, &respErr):
+		// Github answers 422 "Validation Failed" with the reason in the errors, e.g.
+		// {"code": "already_exists"} or {"code": "custom", "message": "name already exists on this account"}
+		for _, e := range respErr.Errors {
+			if e.Code == "already_exists" {
+				return AlreadyExists
+			}
+			if kind := FromStatus(respErr.Response.StatusCode, e.Message); kind == AlreadyExists {
+				return kind
+			}
+		}
+		return FromStatus(respErr.Response.StatusCode, respErr.Message)
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		return Unavailable
+	}
+	return Internal
+}
+
+// FromStatus classifies an HTTP status code and message returned by an API
+func FromStatus(status int, message string) Kind {
+	switch {
+	case status == 401 || status == 403:
+		if strings.Contains(strings.ToLower(message), "rate limit") {
+			return RateLimited
+		}
+		return PermissionDenied
+	case status == 404:
+		return NotFound
+	case status == 409:
+		return AlreadyExists
+	case status == 422:
+		lower := strings.ToLower(message)
+		if strings.Contains(lower, "already") || strings.Contains(lower, "taken") {
+			return AlreadyExists
+		}
+		return Validation
+	case status == 429:
+		return RateLimited
+	case status == 400:
+		return Validation
+	case status >= 500:
+		return Unavailable
+	}
+	return Internal
+}
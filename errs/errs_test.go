@@ -0,0 +1,101 @@
+package errs
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errors.New("plain error"), 1},
+		{New(Validation, "missing input"), 2},
+		{New(NotFound, "no such user"), 3},
+		{Wrap(AlreadyExists, errors.New("taken"), "creating user"), 4},
+		{New(PermissionDenied, "forbidden"), 5},
+		{New(RateLimited, "slow down"), 6},
+		{New(Unavailable, "connection refused"), 7},
+		{New(Config, "no config file"), 8},
+		{New(Aborted, "interrupted"), 130},
+		{New(Drift, "unexpected admins"), 9},
+	}
+
+	for _, test := range tests {
+		if code := ExitCode(test.err); code != test.code {
+			t.Errorf("Expected exit code %v for '%v' Got %v", test.code, test.err, code)
+		}
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	tests := []struct {
+		status  int
+		message string
+		kind    Kind
+	}{
+		{400, "Problems parsing JSON", Validation},
+		{401, "Bad credentials", PermissionDenied},
+		{403, "Must have admin rights", PermissionDenied},
+		{403, "API rate limit exceeded", RateLimited},
+		{404, "Not Found", NotFound},
+		{422, "Login has already been taken", AlreadyExists},
+		{422, "Validation Failed", Validation},
+		{502, "Bad Gateway", Unavailable},
+	}
+
+	for _, test := range tests {
+		if kind := FromStatus(test.status, test.message); kind != test.kind {
+			t.Errorf("Expected '%v' for %v %v Got '%v'", test.kind, test.status, test.message, kind)
+		}
+	}
+}
+
+func TestFromGithub(t *testing.T) {
+	validationFailed := func(e github.Error) error {
+		return &github.ErrorResponse{
+			Response: &http.Response{StatusCode: 422, Request: &http.Request{Method: "POST", URL: &url.URL{}}},
+			Message:  "Validation Failed",
+			Errors:   []github.Error{e},
+		}
+	}
+	tests := []struct {
+		err  error
+		kind Kind
+	}{
+		{validationFailed(github.Error{Resource: "Team", Field: "name", Code: "already_exists"}), AlreadyExists},
+		{validationFailed(github.Error{Resource: "Repository", Field: "name", Code: "custom", Message: "name already exists on this account"}), AlreadyExists},
+		{validationFailed(github.Error{Resource: "User", Field: "login", Code: "custom", Message: "login is already taken"}), AlreadyExists},
+		{validationFailed(github.Error{Resource: "Team", Field: "name", Code: "missing_field"}), Validation},
+	}
+
+	for _, test := range tests {
+		if kind := KindOf(FromGithub(test.err, "request failed")); kind != test.kind {
+			t.Errorf("Expected '%v' for '%v' Got '%v'", test.kind, test.err, kind)
+		}
+	}
+}
+
+func TestWrapKeepsCause(t *testing.T) {
+	cause := errors.New("cause")
+	err := Wrap(NotFound, Wrap(Internal, cause, "inner"), "outer")
+
+	if !errors.Is(err, cause) {
+		t.Error("Wrapped error does not unwrap to its cause")
+	}
+	if KindOf(err) != NotFound {
+		t.Errorf("Expected '%v' Got '%v'", NotFound, KindOf(err))
+	}
+	if err.Error() != "outer: inner: cause" {
+		t.Errorf("Unexpected error message '%v'", err.Error())
+	}
+	if Wrap(NotFound, nil, "nothing") != nil {
+		t.Error("Wrapping a nil error did not return nil")
+	}
+}
@@ -0,0 +1,225 @@
+// Package drift compares the access granted on Github with the access model declared
+// in a state file, as used by 'omniactl plan', and reports the differences.
+package drift
+
+import (
+	"fmt"
+	"omniactl/errs"
+	createUser "omniactl/github/create/user"
+	listTeam "omniactl/github/list/team"
+	"omniactl/github/service"
+	"omniactl/github/state"
+	"omniactl/output"
+	"sort"
+
+	"github.com/fatih/color"
+)
+
+// Kinds of findings
+const (
+	UnexpectedAdmin    = "unexpected admin"
+	OrgMembership      = "org membership"
+	TeamMembership     = "team membership"
+	TeamPermission     = "team permission"
+	DirectCollaborator = "direct collaborator"
+	NoTeam             = "no team"
+	Missing            = "missing"
+)
+
+// Finding is a difference between Github and the declared access model.
+// Expected and Actual hold roles or permissions, empty if there are none.
+type Finding struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	Org      string `json:"org" yaml:"org"`
+	Team     string `json:"team" yaml:"team"`
+	Repo     string `json:"repo" yaml:"repo"`
+	Name     string `json:"name" yaml:"name"`
+	Expected string `json:"expected" yaml:"expected"`
+	Actual   string `json:"actual" yaml:"actual"`
+}
+
+// String describes the finding in a single line, e.g. "unexpected admin MSF e123456: admin, expected member"
+func (f Finding) String() string {
+	target := f.Org
+	if f.Team != "" {
+		target += "/" + f.Team
+	}
+	if f.Repo != "" {
+		target += "/" + f.Repo
+	}
+	if f.Name != "" {
+		target += " " + f.Name
+	}
+	actual, expected := f.Actual, f.Expected
+	if actual == "" {
+		actual = "none"
+	}
+	if expected == "" {
+		expected = "none"
+	}
+	return fmt.Sprintf("%v %v: %v, expected %v", f.Kind, target, actual, expected)
+}
+
+// AuditDrift compares Github with the access model in the state file and reports
+// every finding in the selected output format. It returns a Drift error if any
+// finding was reported, so that scheduled runs fail on drift.
+func AuditDrift(svc *service.Service, file string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Audit drift from the declared access model")
+	s, err := state.Load(file)
+	if err != nil {
+		return err
+	}
+	findings, err := Detect(svc, s)
+	if err != nil {
+		return err
+	}
+	if err := PrintFindings(findings); err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		return errs.New(errs.Drift, "%v findings of drift from '%v'", len(findings), file)
+	}
+	return nil
+}
+
+// Detect returns the differences between Github and the state: every change
+// 'omniactl apply' would make, members of the managed orgs who are not on any
+// team and direct collaborators on repos whose collaborators are not declared.
+// Every org must declare its members, as unexpected admins are found by comparing
+// the org's members with them.
+func Detect(svc *service.Service, s *state.State) ([]Finding, error) {
+	for _, org := range s.Orgs {
+		if org.Members == nil {
+			return nil, errs.New(errs.Validation, "organisation '%v' declares no members, so unexpected admins cannot be detected; "+
+				"declare its members with their roles, e.g. 'e123456: admin'", org.Name)
+		}
+	}
+	changes, err := state.Plan(svc, s)
+	if err != nil {
+		return nil, err
+	}
+	findings := []Finding{}
+	missingOrgs := make(map[string]bool)
+	for _, c := range changes {
+		if c.Resource == state.OrgResource {
+			missingOrgs[c.Org] = true
+		}
+		findings = append(findings, fromChange(c))
+	}
+
+	for _, org := range s.Orgs {
+		if missingOrgs[org.Name] {
+			continue
+		}
+		orgFindings, err := detectOrg(svc, org)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, orgFindings...)
+	}
+	return findings, nil
+}
+
+// fromChange turns a change of the plan into a finding
+func fromChange(c state.Change) Finding {
+	f := Finding{Org: c.Org, Team: c.Team, Repo: c.Repo, Name: c.Name, Expected: c.To, Actual: c.From}
+	switch {
+	case c.Action == state.Create && (c.Resource == state.OrgResource || c.Resource == state.TeamResource || c.Resource == state.RepoResource):
+		f.Kind = Missing
+		f.Expected = c.Resource
+	case c.Resource == state.OrgMemberResource && c.From == "admin":
+		f.Kind = UnexpectedAdmin
+	case c.Resource == state.OrgMemberResource:
+		f.Kind = OrgMembership
+	case c.Resource == state.TeamMemberResource:
+		f.Kind = TeamMembership
+	case c.Resource == state.TeamRepoResource:
+		f.Kind = TeamPermission
+	case c.Resource == state.CollaboratorResource:
+		f.Kind = DirectCollaborator
+	}
+	return f
+}
+
+// detectOrg finds the members of the org who are not on any of its teams and the
+// direct collaborators on its repos which do not declare their collaborators
+func detectOrg(svc *service.Service, org state.Org) ([]Finding, error) {
+	findings := []Finding{}
+	members, err := state.GetOrgMembers(svc, org.Name)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := createUser.GetTeamsForOrg(svc, org.Name)
+	if err != nil {
+		return nil, err
+	}
+	onTeam := make(map[string]bool)
+	for _, team := range teams {
+		teamMembers, err := state.GetTeamMembers(svc, team.ID)
+		if err != nil {
+			return nil, err
+		}
+		for login := range teamMembers {
+			onTeam[login] = true
+		}
+	}
+	for _, login := range sortedKeys(members) {
+		if !onTeam[login] {
+			findings = append(findings, Finding{Kind: NoTeam, Org: org.Name, Name: login, Actual: members[login]})
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, repo := range org.Repos {
+		declared[repo.Name] = repo.Collaborators != nil
+	}
+	repos, err := listTeam.ListRepos(svc, fmt.Sprintf("orgs/%v/repos", org.Name))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Name < repos[j].Name })
+	for _, repo := range repos {
+		// Declared collaborators are compared by the plan
+		if declared[repo.Name] {
+			continue
+		}
+		collaborators, err := state.GetCollaborators(svc, org.Name+"/"+repo.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, login := range sortedKeys(collaborators) {
+			findings = append(findings, Finding{Kind: DirectCollaborator, Org: org.Name, Repo: repo.Name, Name: login, Actual: collaborators[login]})
+		}
+	}
+	return findings, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PrintFindings prints one line per finding for text output and renders the
+// findings in any other output format
+func PrintFindings(findings []Finding) error {
+	if !output.IsText() {
+		return output.Print(findings)
+	}
+	fmt.Println("")
+	if len(findings) == 0 {
+		color.New(color.FgGreen, color.Bold).Println("No drift. Github matches the declared access model.")
+		return nil
+	}
+	red := color.New(color.FgRed)
+	for _, f := range findings {
+		red.Println(f.String())
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Drift found: %v findings.\n", len(findings))
+	return nil
+}
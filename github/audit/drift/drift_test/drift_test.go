@@ -0,0 +1,165 @@
+package drift_test
+
+import (
+	"io/ioutil"
+	"omniactl/errs"
+	"omniactl/github/audit/drift"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	"omniactl/github/state"
+	"omniactl/interactive"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package
+	svc *service.Service
+	// dir holds the state files written by the tests
+	dir string
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	for _, login := range []string{"e200001", "e200002", "e200003", "e200004"} {
+		server.AddUser(login, login+"@statestreet.com")
+	}
+	server.AddOrg("MSF")
+	server.AddOrgMember("MSF", "e200001", "admin")
+	server.AddOrgMember("MSF", "e200002", "admin")
+	server.AddOrgMember("MSF", "e200003", "member")
+	devops := server.AddTeam("MSF", "devops")
+	server.AddTeamMember(devops.ID, "e200001")
+	server.AddTeamMember(devops.ID, "e200002")
+	server.AddRepo("MSF", "pipeline")
+	server.AddRepo("MSF", "website")
+	server.AddTeamRepo(devops.ID, "MSF/pipeline", "push")
+	server.AddCollaborator("MSF/pipeline", "e200004", "push")
+	server.AddCollaborator("MSF/website", "e200004", "pull")
+
+	server.AddOrg("CTO")
+	server.AddOrgMember("CTO", "e200001", "admin")
+	leads := server.AddTeam("CTO", "leads")
+	server.AddTeamMember(leads.ID, "e200001")
+	server.AddTeamMember(leads.ID, "e200003")
+	server.AddRepo("CTO", "roadmap")
+	server.AddTeamRepo(leads.ID, "CTO/roadmap", "push")
+	server.AddCollaborator("CTO/roadmap", "e200004", "pull")
+
+	svc = service.New(server.Client())
+	interactive.Disabled = true
+
+	var err error
+	dir, err = ioutil.TempDir("", "drift_test")
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// writeFile writes a state file to the test directory, replacing the previous one
+func writeFile(t *testing.T, content string) string {
+	file := filepath.Join(dir, "github.yaml")
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	return file
+}
+
+func TestAuditDrift(t *testing.T) {
+	file := writeFile(t, `
+orgs:
+  - name: MSF
+    members:
+      e200001: admin
+      e200002: member
+      e200003: member
+    repos:
+      - name: website
+        collaborators:
+          e200004: pull
+    teams:
+      - name: devops
+        members:
+          e200001: maintainer
+          e200002: member
+        repos:
+          pipeline: push
+`)
+	s, err := state.Load(file)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	findings, err := drift.Detect(svc, s)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	want := []string{
+		"unexpected admin MSF e200002: admin, expected member",
+		"team membership MSF/devops e200001: member, expected maintainer",
+		"no team MSF e200003: member, expected none",
+		"direct collaborator MSF/pipeline e200004: push, expected none",
+	}
+	got := []string{}
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() got:\n%v\nwant:\n%v", got, want)
+	}
+
+	if err := drift.AuditDrift(svc, file); !errs.Is(err, errs.Drift) {
+		t.Errorf("AuditDrift() got error %v, want a drift error", err)
+	}
+}
+
+func TestAuditNoDrift(t *testing.T) {
+	file := writeFile(t, `
+orgs:
+  - name: CTO
+    members:
+      e200001: admin
+      e200003: member
+    repos:
+      - name: roadmap
+        collaborators:
+          e200004: pull
+    teams:
+      - name: leads
+        members:
+          e200001: member
+          e200003: member
+        repos:
+          roadmap: push
+`)
+
+	if err := drift.AuditDrift(svc, file); err != nil {
+		t.Errorf("AuditDrift() got error %v, want none", err)
+	}
+}
+
+func TestAuditDriftWithoutMembers(t *testing.T) {
+	// Without declared members, the stray admin e200002 could not be reported
+	file := writeFile(t, `
+orgs:
+  - name: MSF
+    teams:
+      - name: devops
+        members:
+          e200001: member
+          e200002: member
+`)
+
+	if err := drift.AuditDrift(svc, file); !errs.Is(err, errs.Validation) {
+		t.Errorf("AuditDrift() got error %v, want a validation error", err)
+	}
+}
@@ -2,72 +2,75 @@ package org
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/fatih/color"
-	"github.com/google/go-github/github"
-	"log"
-	githubLogin "omniactl/login/github"
-	createUser "omniactl/github/create/user"
 	"github.com/manifoldco/promptui"
-	"errors"
+	"omniactl/config"
+	"omniactl/errs"
+	createUser "omniactl/github/create/user"
+	"omniactl/github/service"
+	"omniactl/interactive"
 	"regexp"
 )
 
-// Client which interacts with Github API
-var Client *github.Client
-
 type Org struct {
 	Login       string `json:"login"`
 	Admin       string `json:"admin"`
 	ProfileName string `json:"profile_name"`
 }
 
-func CreateOrg(orgLogin string, orgProfile string, orgAdmin string) {
+func CreateOrg(svc *service.Service, orgLogin string, orgProfile string, orgAdmin string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: Create new Github organisation")
 
-	switch orgLogin {
-	case "":
-		orgLogin = PromptNewOrgLogin()
-		orgAdmin = PromptNewOrgAdmin()
-		orgProfile = PromptNewOrgProfile()
-		CreateGithubOrg(orgLogin, orgProfile, orgAdmin)
-	default:
-		check := CheckIfOrgExists(orgLogin)
+	var err error
+	if orgLogin != "" {
+		check, err := CheckIfOrgExists(svc, orgLogin)
+		if err != nil {
+			return err
+		}
 		if check == true {
-			fmt.Println("Organisation '%v' already exists.", orgLogin)
-			orgLogin = PromptNewOrgLogin()
-			orgAdmin = PromptNewOrgAdmin()
-			orgProfile = PromptNewOrgProfile()
-			CreateGithubOrg(orgLogin, orgProfile, orgAdmin)
-		} else if check == false {
-			switch orgAdmin {
-			case "":
-				orgAdmin = PromptNewOrgAdmin()
-				orgProfile = PromptNewOrgProfile()
-				CreateGithubOrg(orgLogin, orgProfile, orgAdmin)
-			default:
-				check := CheckIfUserExists(orgAdmin)
-				if check == true {
-					switch orgProfile {
-					case "":
-						orgProfile = PromptNewOrgProfile()
-						CreateGithubOrg(orgLogin, orgProfile, orgAdmin)
-					default:
-						CreateGithubOrg(orgLogin, orgProfile, orgAdmin)
-					}
-				} else if check == false {
-					fmt.Println("User 'v%' does not exist.", orgAdmin)
-					orgAdmin = PromptNewOrgAdmin()
-					orgProfile = PromptNewOrgProfile()
-					CreateGithubOrg(orgLogin, orgProfile, orgAdmin)
-				}
-			}
+			fmt.Printf("Organisation '%v' already exists.\n", orgLogin)
+			orgLogin, orgAdmin, orgProfile = "", "", ""
+		}
+	}
+	if orgLogin == "" {
+		if orgLogin, err = PromptNewOrgLogin(); err != nil {
+			return err
+		}
+		orgAdmin, orgProfile = "", ""
+	}
+
+	if orgAdmin != "" {
+		check, err := CheckIfUserExists(svc, orgAdmin)
+		if err != nil {
+			return err
+		}
+		if check == false {
+			fmt.Printf("User '%v' does not exist.\n", orgAdmin)
+			orgAdmin, orgProfile = "", ""
+		}
+	}
+	if orgAdmin == "" {
+		if orgAdmin, err = PromptNewOrgAdmin(svc); err != nil {
+			return err
+		}
+	}
+
+	if orgProfile == "" {
+		if orgProfile, err = PromptNewOrgProfile(); err != nil {
+			return err
 		}
 	}
+	return CreateGithubOrg(svc, orgLogin, orgProfile, orgAdmin)
 }
 
-func PromptNewOrgProfile() string {
+func PromptNewOrgProfile() (string, error) {
+	// The display name is optional, so it is left empty in non-interactive mode
+	if interactive.Disabled {
+		return "", nil
+	}
 	templates := &promptui.PromptTemplates{
 		Success: "{{ . | green | bold }} ",
 	}
@@ -75,20 +78,18 @@ func PromptNewOrgProfile() string {
 		Label:     "Org display name",
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
-func PromptNewOrgAdmin() string {
+func PromptNewOrgAdmin(svc *service.Service) (string, error) {
 	validate := func(input string) error {
-		isCorrectFormat := regexp.MustCompile("^e[0-9]{6}$").MatchString
-		if isCorrectFormat(input) != true {
-			return errors.New("Username must be in the format of a State Street Lan ID, e.g. 'e123456'")
+		if err := config.CheckUsername(input); err != nil {
+			return err
+		}
+		check, err := CheckIfUserExists(svc, input)
+		if err != nil {
+			return err
 		}
-		check := CheckIfUserExists(input)
 		switch check {
 		case true:
 			return nil
@@ -102,18 +103,13 @@ func PromptNewOrgAdmin() string {
 	}
 	prompt := promptui.Prompt{
 		Label:     "Org admin",
-		Validate: validate,
+		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
-
-func PromptNewOrgLogin() string {
+func PromptNewOrgLogin() (string, error) {
 	validate := func(input string) error {
 		isCorrectFormat := regexp.MustCompile("^[a-z0-9._%+\\-]+$").MatchString
 		if isCorrectFormat(input) != true {
@@ -127,56 +123,59 @@ func PromptNewOrgLogin() string {
 	}
 	prompt := promptui.Prompt{
 		Label:     "Org login name",
-		Validate: validate,
+		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
-func CheckIfUserExists(orgAdmin string) bool {
-	Client := githubLogin.CreateClient()
-	_, _, err := Client.Users.Get(context.Background(), orgAdmin)
+// CheckIfUserExists reports whether the user exists. Errors other than the user
+// not being found are returned.
+func CheckIfUserExists(svc *service.Service, orgAdmin string) (bool, error) {
+	_, _, err := svc.Client.Users.Get(context.Background(), orgAdmin)
 	if err != nil {
-		return false
+		err = errs.FromGithub(err, "error checking if user '%v' exists", orgAdmin)
+		if errs.Is(err, errs.NotFound) {
+			return false, nil
+		}
+		return false, err
 	}
-	return true
+	return true, nil
 }
 
-func CheckIfOrgExists(orgLogin string) bool {
-	allOrgs := createUser.GetAllOrgs()
+func CheckIfOrgExists(svc *service.Service, orgLogin string) (bool, error) {
+	allOrgs, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		return false, err
+	}
 	for _, v := range allOrgs {
 		if v.Name == orgLogin {
-			return true
-		} 
+			return true, nil
+		}
 	}
-	return false
+	return false, nil
 }
 
-func CreateGithubOrg(orgLogin string, orgProfile string, orgAdmin string) {
+func CreateGithubOrg(svc *service.Service, orgLogin string, orgProfile string, orgAdmin string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
 
-	Client = githubLogin.CreateClient()
-
 	body := Org{orgLogin, orgAdmin, orgProfile}
 
-	req, err := Client.NewRequest("POST", "https://github.dev.us-east-1.aws.galleon.c.statestr.com/api/v3/admin/organizations", body)
+	req, err := svc.Client.NewRequest("POST", "admin/organizations", body)
 	if err != nil {
-		log.Fatalln("Error creating new request:\n", err)
+		return errs.Wrap(errs.Internal, err, "error creating new request")
 	}
 
 	newOrg := Org{}
-	_, err = Client.Do(context.Background(), req, &newOrg)
+	_, err = svc.Client.Do(context.Background(), req, &newOrg)
 
 	if err != nil {
-		log.Fatalf("Error creating new organisation:\n%v", err)
+		return errs.FromGithub(err, "error creating new organisation '%v'", orgLogin)
 	}
 
 	orgLogin = newOrg.Login
 	fmt.Println("")
 	whiteBold.Println("New Github organisation created: ")
 	whiteBold.Println("Name:", orgLogin, " Admin:", orgAdmin, " Display name:", orgProfile)
+	return nil
 }
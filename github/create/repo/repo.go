@@ -7,60 +7,74 @@ import (
 	"github.com/fatih/color"
 	"github.com/google/go-github/github"
 	"github.com/manifoldco/promptui"
-	"log"
+	"omniactl/config"
+	"omniactl/errs"
 	createOrg "omniactl/github/create/org"
 	createTeam "omniactl/github/create/team"
 	createUser "omniactl/github/create/user"
 	listTeam "omniactl/github/list/team"
-	githubLogin "omniactl/login/github"
-	"os"
+	"omniactl/github/service"
+	"omniactl/interactive"
 	"regexp"
-	"strings"
 )
 
-func CreateRepo(name string, org string, team string, description string, privacy bool) {
+func CreateRepo(svc *service.Service, name string, org string, team string, description string, privacy bool) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: Create a new Github repository")
 	var collaborators []string
 
-	org = CheckOrgFlag(org)
-	teamMap := CheckTeamFlag(team, org)
-	name = CheckNameFlag(name)
-	url, repoName := CreateGithubRepo(name, org, teamMap, description, privacy)
-	result := PromptCollaborators()
+	org, err := CheckOrgFlag(svc, org)
+	if err != nil {
+		return err
+	}
+	teamMap, err := CheckTeamFlag(svc, team, org)
+	if err != nil {
+		return err
+	}
+	name, err = CheckNameFlag(name)
+	if err != nil {
+		return err
+	}
+	url, repoName, err := CreateGithubRepo(svc, name, org, teamMap, description, privacy)
+	if err != nil {
+		return err
+	}
+	result, err := PromptCollaborators()
+	if err != nil {
+		return err
+	}
 	switch result {
 	case "Add all org members":
-		collaborators = AddAllOrgMembers(org)
-		AddCollaborators(collaborators, url, repoName)
+		collaborators, err = AddAllOrgMembers(svc, org)
 	case "Add all team members":
-		collaborators = AddAllTeamMembers(teamMap)
-		AddCollaborators(collaborators, url, repoName)
+		collaborators, err = AddAllTeamMembers(svc, teamMap)
 	case "Add a specific user":
-		collaborators = AddSpecificUsers()
-		AddCollaborators(collaborators, url, repoName)
+		collaborators, err = AddSpecificUsers(svc)
 	default:
-		os.Exit(0)
+		return nil
 	}
+	if err != nil {
+		return err
+	}
+	return AddCollaborators(svc, collaborators, url, repoName)
 }
 
-func AddAllOrgMembers(org string) []string {
-	Client := githubLogin.CreateClient()
+func AddAllOrgMembers(svc *service.Service, org string) ([]string, error) {
 	var Collaborators []string
 
-	members, _, err := Client.Organizations.ListMembers(context.Background(), org, nil)
-	if err != nil {
-		log.Fatalln("Error getting info about organisation:", err)
+	var members []*github.User
+	if err := svc.List(fmt.Sprintf("orgs/%v/members", org), 0, &members); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting info about organisation '%v'", org)
 	}
 
 	for _, v := range members {
 		memberLogin := v.GetLogin()
 		Collaborators = append(Collaborators, memberLogin)
 	}
-	return Collaborators
+	return Collaborators, nil
 }
 
-func AddAllTeamMembers(teamMap map[string]createUser.Team) []string {
-	Client := githubLogin.CreateClient()
+func AddAllTeamMembers(svc *service.Service, teamMap map[string]createUser.Team) ([]string, error) {
 	var Collaborators []string
 	var TeamID int64
 
@@ -68,56 +82,58 @@ func AddAllTeamMembers(teamMap map[string]createUser.Team) []string {
 		TeamID = v.ID
 	}
 
-	members, _, err := Client.Teams.ListTeamMembers(context.Background(), TeamID, nil)
-	if err != nil {
-		log.Fatalln("Error getting info about team members:", err)
+	var members []*github.User
+	if err := svc.List(fmt.Sprintf("teams/%v/members", TeamID), 0, &members); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting info about team members")
 	}
 
 	for _, v := range members {
 		memberLogin := v.GetLogin()
 		Collaborators = append(Collaborators, memberLogin)
 	}
-	return Collaborators
+	return Collaborators, nil
 }
 
-func AddCollaborators(collaborators []string, urlRepo string, repoName string) {
-	Client := githubLogin.CreateClient()
+func AddCollaborators(svc *service.Service, collaborators []string, urlRepo string, repoName string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
 
 	fmt.Println("")
 	for _, userLogin := range collaborators {
-		urlUser := fmt.Sprintf("/collaborators/%v", userLogin)
-		var urlSlice []string
-		urlSlice = append(urlSlice, urlRepo)
-		urlSlice = append(urlSlice, urlUser)
-		urlComplete := strings.Join(urlSlice, "")
+		url := fmt.Sprintf("%v/collaborators/%v", urlRepo, userLogin)
 
-		req, err := Client.NewRequest("PUT", urlComplete, nil)
+		req, err := svc.Client.NewRequest("PUT", url, nil)
 		if err != nil {
-			log.Fatalln("Error creating new request:\n", err)
+			return errs.Wrap(errs.Internal, err, "error creating new request")
 		}
 
-		_, err = Client.Do(context.Background(), req, nil)
+		_, err = svc.Client.Do(context.Background(), req, nil)
 		if err != nil {
-			log.Fatalln("Error adding collaborators to repository: ", err)
+			return errs.FromGithub(err, "error adding collaborator '%v' to repository '%v'", userLogin, repoName)
 		}
 
 		whiteBold.Printf("User '%v' added as collaborator to repository '%v'.", userLogin, repoName)
 		fmt.Println("")
 	}
+	return nil
 }
 
-func AddSpecificUsers() []string {
+func AddSpecificUsers(svc *service.Service) ([]string, error) {
 	var AddCollaborator bool
 	var Collaborators []string
 
 	AddCollaborator = true
 
 	for AddCollaborator == true {
-		userLogin := PromptUsername()
+		userLogin, err := PromptUsername(svc)
+		if err != nil {
+			return nil, err
+		}
 		Collaborators = append(Collaborators, userLogin)
 
-		result := PromptAnotherCollaborator()
+		result, err := PromptAnotherCollaborator()
+		if err != nil {
+			return nil, err
+		}
 		switch result {
 		case "yes":
 			continue
@@ -126,16 +142,18 @@ func AddSpecificUsers() []string {
 			break
 		}
 	}
-	return Collaborators
+	return Collaborators, nil
 }
 
-func PromptUsername() string {
+func PromptUsername(svc *service.Service) (string, error) {
 	validate := func(input string) error {
-		isCorrectFormat := regexp.MustCompile("^e[0-9]{6}$").MatchString
-		if isCorrectFormat(input) != true {
-			return errors.New("Username must be in the format of a State Street Lan ID, e.g. 'e123456'")
+		if err := config.CheckUsername(input); err != nil {
+			return err
+		}
+		check, err := createUser.CheckIfUserExists(svc, input)
+		if err != nil {
+			return err
 		}
-		check := createUser.CheckIfUserExists(input)
 		switch check {
 		case true:
 			return nil
@@ -154,43 +172,30 @@ func PromptUsername() string {
 		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
-func PromptAnotherCollaborator() string {
+func PromptAnotherCollaborator() (string, error) {
 	prompt := promptui.Select{
 		Label: "Invite another collaborator?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "no")
 }
 
-func PromptCollaborators() string {
+func PromptCollaborators() (string, error) {
 	prompt := promptui.Select{
 		Label: "Invite collaborators?",
 		Items: []string{"Add all org members", "Add all team members", "Add a specific user", "Do not add any collaborators"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "Do not add any collaborators")
 }
 
-func CreateGithubRepo(name string, org string, teamMap map[string]createUser.Team, description string, privacy bool) (string, string) {
+func CreateGithubRepo(svc *service.Service, name string, org string, teamMap map[string]createUser.Team, description string, privacy bool) (string, string, error) {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
 	var TeamID int64
-	Client := githubLogin.CreateClient()
 
 	for _, v := range teamMap {
 		TeamID = v.ID
@@ -200,7 +205,7 @@ func CreateGithubRepo(name string, org string, teamMap map[string]createUser.Tea
 	repo := &github.Repository{
 		Name:             github.String(name),
 		Description:      github.String(description),
-		Private:          github.Bool(false),
+		Private:          github.Bool(privacy),
 		TeamID:           github.Int64(TeamID),
 		AllowRebaseMerge: github.Bool(true),
 		AllowSquashMerge: github.Bool(true),
@@ -208,9 +213,9 @@ func CreateGithubRepo(name string, org string, teamMap map[string]createUser.Tea
 	}
 
 	// Create repo inside specific organization
-	repo, _, err := Client.Repositories.Create(context.Background(), org, repo)
+	repo, _, err := svc.Client.Repositories.Create(context.Background(), org, repo)
 	if err != nil {
-		log.Fatalln("Error creating repo:", err)
+		return "", "", errs.FromGithub(err, "error creating repo '%v'", name)
 	}
 
 	repoName := repo.GetName()
@@ -218,38 +223,37 @@ func CreateGithubRepo(name string, org string, teamMap map[string]createUser.Tea
 	fmt.Println("")
 	whiteBold.Printf("Repository '%v' has been created.\n", repoName)
 	// fmt.Println("ID: ", repo.GetID())
-	// permissions, _, _ := Client.Repositories.GetPermissionLevel(context.Background(), org, repo.GetName(), "e111111")
+	// permissions, _, _ := svc.Client.Repositories.GetPermissionLevel(context.Background(), org, repo.GetName(), "e111111")
 	// fmt.Println("Permissions: ", permissions.GetPermission())
 	fmt.Print("(Go to repo: ")
-	fmt.Printf("https://github.dev.us-east-1.aws.galleon.c.statestr.com/%v/%v)", org, repoName)
+	fmt.Printf("%v)", repo.GetHTMLURL())
 	fmt.Println("")
 	fmt.Println("")
 
-	url := repo.GetURL()
-	return url, repoName
+	// Path of the repository relative to the client's base URL
+	url := fmt.Sprintf("repos/%v/%v", repo.GetOwner().GetLogin(), repoName)
+	return url, repoName, nil
 }
 
-func CheckNameFlag(name string) string {
+func CheckNameFlag(name string) (string, error) {
 	greenBold := color.New(color.FgGreen, color.Bold)
 	red := color.New(color.FgRed)
 	if name != "" {
 		isCorrectFormat := regexp.MustCompile("^[a-z0-9._%+\\-]+$").MatchString
 		if isCorrectFormat(name) != true {
 			red.Println("Repo name can only contain the following characters: A-Z, a-z, 0-9, -, _")
-			name = PromptName()
-			return name
+			return PromptName()
 		} else {
 			greenBold.Print("Repo name ")
 			fmt.Println(name)
-			return name
+			return name, nil
 		}
 	} else {
-		name = PromptName()
-		return name
+		return PromptName()
 	}
 }
 
-func PromptName() string {
+func PromptName() (string, error) {
 	validate := func(input string) error {
 		isCorrectFormat := regexp.MustCompile("^[a-zA-Z0-9._%+\\-]+$").MatchString
 		if isCorrectFormat(input) != true {
@@ -267,104 +271,103 @@ func PromptName() string {
 		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
-func CheckOrgFlag(org string) string {
+func CheckOrgFlag(svc *service.Service, org string) (string, error) {
 	red := color.New(color.FgRed)
 	greenBold := color.New(color.FgGreen, color.Bold)
 
 	if org != "" {
-		check := createOrg.CheckIfOrgExists(org)
+		check, err := createOrg.CheckIfOrgExists(svc, org)
+		if err != nil {
+			return "", err
+		}
 		switch check {
 		case true:
 			greenBold.Print("Organisation ")
 			fmt.Println(org)
-			return org
+			return org, nil
 		default:
+			if interactive.Disabled {
+				return "", errs.New(errs.NotFound, "Github organisation '%v' does not exist", org)
+			}
 			red.Printf("Organisation '%v' does not exist.", org)
 			fmt.Println("")
-			org = createTeam.PromptOrg()
-			return org
+			return createTeam.PromptOrg(svc)
 		}
 	} else {
-		check := CheckIfUserRepo()
+		check, err := CheckIfUserRepo()
+		if err != nil {
+			return "", err
+		}
 		switch check {
 		case "User":
-			org = ""
-			return org
+			return "", nil
 		default:
-			org = createTeam.PromptOrg()
-			return org
+			return createTeam.PromptOrg(svc)
 		}
 	}
 }
 
-func CheckTeamFlag(team string, org string) map[string]createUser.Team {
+func CheckTeamFlag(svc *service.Service, team string, org string) (map[string]createUser.Team, error) {
 	teamMap := make(map[string]createUser.Team)
 	red := color.New(color.FgRed)
 	greenBold := color.New(color.FgGreen, color.Bold)
 
 	if org == "" {
-		return teamMap
+		return teamMap, nil
 	}
 
 	if team == "" {
-		check := CheckIfTeamRepo()
+		check, err := CheckIfTeamRepo()
+		if err != nil {
+			return nil, err
+		}
 		switch check {
 		case "Team":
-			teamMap = listTeam.PromptTeam(org)
-			return teamMap
+			return listTeam.PromptTeam(svc, org)
 		default:
-			return teamMap
+			return teamMap, nil
 		}
 	} else {
-		teamsForOrg := createUser.GetTeamsForOrg(org)
+		teamsForOrg, err := createUser.GetTeamsForOrg(svc, org)
+		if err != nil {
+			return nil, err
+		}
 		check := createTeam.CheckIfTeamExists(team, teamsForOrg)
 		switch check {
 		case true:
 			greenBold.Print("Team ")
 			fmt.Println(team)
-			teamMap = listTeam.CreateTeamMap(team, org)
-			return teamMap
+			return listTeam.CreateTeamMap(svc, team, org)
 		default:
+			if interactive.Disabled {
+				return nil, errs.New(errs.NotFound, "team '%v' does not exist in Github organisation '%v'", team, org)
+			}
 			red.Printf("Team '%v' does not exist.", team)
 			fmt.Println("")
-			teamMap = listTeam.PromptTeam(org)
-			return teamMap
+			return listTeam.PromptTeam(svc, org)
 		}
 	}
 }
 
-func CheckIfTeamRepo() string {
+func CheckIfTeamRepo() (string, error) {
 	prompt := promptui.Select{
 		Label: "Create repo within the organisation itself or within a team?",
 		Items: []string{"Organisation", "Team"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "Organisation")
 }
 
-func CheckIfUserRepo() string {
+func CheckIfUserRepo() (string, error) {
 	prompt := promptui.Select{
 		Label: "Create repo for authenticated user or within an organisation/team?",
 		Items: []string{"Organisation/team", "User"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "")
 }
 
 type Repo struct {
@@ -6,57 +6,85 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
-	"log"
+	"omniactl/errs"
 	createOrg "omniactl/github/create/org"
 	createUser "omniactl/github/create/user"
-	githubLogin "omniactl/login/github"
+	"omniactl/github/service"
+	"omniactl/interactive"
 	"regexp"
 	"sort"
 )
 
 // CreateTeam creates a new Github team based on flag or prompt input
-func CreateTeam(team string, org string, teamDescription string, teamMaintainers []string, teamPrivacy string) {
+func CreateTeam(svc *service.Service, team string, org string, teamDescription string, teamMaintainers []string, teamPrivacy string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	greenBold := color.New(color.FgGreen, color.Bold)
 	red := color.New(color.FgRed)
 	magentaBold.Println("Action selected: Create a new Github team")
 
-	if org == "" {
-		org = PromptOrg()
-		team = PromptTeam(org)
-		CreateGithubTeam(team, org, teamDescription, teamMaintainers, teamPrivacy)
-	} else {
-		check := createOrg.CheckIfOrgExists(org)
+	if org != "" {
+		check, err := createOrg.CheckIfOrgExists(svc, org)
+		if err != nil {
+			return err
+		}
 		switch check {
 		case true:
 			greenBold.Print("Organisation ")
 			fmt.Println(org)
-			if team == "" {
-				team = PromptTeam(org)
-				CreateGithubTeam(team, org, teamDescription, teamMaintainers, teamPrivacy)
-			} else {
-				allTeams := createUser.GetTeamsForOrg(org)
+			if team != "" {
+				allTeams, err := createUser.GetTeamsForOrg(svc, org)
+				if err != nil {
+					return err
+				}
 				check = CheckIfTeamExists(team, allTeams)
 				switch check {
 				case true:
+					if interactive.Disabled {
+						return errs.New(errs.AlreadyExists, "team '%v' already exists in Github organisation '%v'", team, org)
+					}
 					red.Printf("Team '%v' already exists.", team)
 					fmt.Println("")
-					team = PromptTeam(org)
-					CreateGithubTeam(team, org, teamDescription, teamMaintainers, teamPrivacy)
 				default:
 					greenBold.Print("Team name ")
 					fmt.Println(org)
-					CreateGithubTeam(team, org, teamDescription, teamMaintainers, teamPrivacy)
+					teamDescription, err = CheckDescription(teamDescription)
+					if err != nil {
+						return err
+					}
+					return CreateGithubTeam(svc, team, org, teamDescription, teamMaintainers, teamPrivacy)
 				}
 			}
+			team, err := PromptTeam(svc, org)
+			if err != nil {
+				return err
+			}
+			teamDescription, err = CheckDescription(teamDescription)
+			if err != nil {
+				return err
+			}
+			return CreateGithubTeam(svc, team, org, teamDescription, teamMaintainers, teamPrivacy)
 		default:
+			if interactive.Disabled {
+				return errs.New(errs.NotFound, "Github organisation '%v' does not exist", org)
+			}
 			red.Printf("Organisation '%v' does not exist.", org)
 			fmt.Println("")
-			org = PromptOrg()
-			team = PromptTeam(org)
-			CreateGithubTeam(team, org, teamDescription, teamMaintainers, teamPrivacy)
 		}
 	}
+
+	org, err := PromptOrg(svc)
+	if err != nil {
+		return err
+	}
+	team, err = PromptTeam(svc, org)
+	if err != nil {
+		return err
+	}
+	teamDescription, err = CheckDescription(teamDescription)
+	if err != nil {
+		return err
+	}
+	return CreateGithubTeam(svc, team, org, teamDescription, teamMaintainers, teamPrivacy)
 }
 
 // Team holds information to be passed in Http request
@@ -68,26 +96,21 @@ type Team struct {
 }
 
 // CreateGithubTeam sends an HTTP Post request to create the team with the user input
-func CreateGithubTeam(team string, org string, teamDescription string, teamMaintainers []string, teamPrivacy string) {
+func CreateGithubTeam(svc *service.Service, team string, org string, teamDescription string, teamMaintainers []string, teamPrivacy string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	Client := githubLogin.CreateClient()
 	body := Team{Name: team, Description: teamDescription, Privacy: teamPrivacy}
 
-	if teamDescription == "" {
-		teamDescription = PromptDescription()
-	}
+	url := fmt.Sprintf("orgs/%v/teams", org)
 
-	url := fmt.Sprintf("https://github.dev.us-east-1.aws.galleon.c.statestr.com/api/v3/orgs/%v/teams", org)
-
-	req, err := Client.NewRequest("POST", url, body)
+	req, err := svc.Client.NewRequest("POST", url, body)
 	if err != nil {
-		log.Fatalln("Error creating new request:\n", err)
+		return errs.Wrap(errs.Internal, err, "error creating new request")
 	}
 
 	newTeam := Team{}
-	_, err = Client.Do(context.Background(), req, &newTeam)
+	_, err = svc.Client.Do(context.Background(), req, &newTeam)
 	if err != nil {
-		log.Fatalf("Error creating new team:\n%v", err)
+		return errs.FromGithub(err, "error creating new team '%v'", team)
 	}
 
 	team = newTeam.Name
@@ -95,18 +118,27 @@ func CreateGithubTeam(team string, org string, teamDescription string, teamMaint
 	fmt.Println("")
 	whiteBold.Printf("Team '%v' created in Github organisation '%v'.", team, org)
 	fmt.Println("")
+	return nil
+}
+
+// CheckDescription prompts for a description if none was provided by flag
+func CheckDescription(teamDescription string) (string, error) {
+	if teamDescription != "" {
+		return teamDescription, nil
+	}
+	return PromptDescription()
 }
 
 // PromptDescription asks if a description for the new team should be added
-func PromptDescription() string {
+func PromptDescription() (string, error) {
 	prompt := promptui.Select{
 		Label: "Add a description for the team?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
+	result, err := interactive.Select(prompt, "no")
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return "", err
 	}
 
 	switch result {
@@ -118,13 +150,9 @@ func PromptDescription() string {
 			Label:     "Description",
 			Templates: templates,
 		}
-		result, err := prompt.Run()
-		if err != nil {
-			log.Fatalf("Prompt failed %v\n", err)
-		}
-		return result
+		return interactive.Prompt(prompt)
 	default:
-		return ""
+		return "", nil
 	}
 }
 
@@ -139,8 +167,11 @@ func CheckIfTeamExists(input string, allTeams map[string]createUser.Team) bool {
 }
 
 // PromptTeam prompts user to enter a name for new team
-func PromptTeam(org string) string {
-	allTeams := createUser.GetTeamsForOrg(org)
+func PromptTeam(svc *service.Service, org string) (string, error) {
+	allTeams, err := createUser.GetTeamsForOrg(svc, org)
+	if err != nil {
+		return "", err
+	}
 	validate := func(input string) error {
 		isCorrectFormat := regexp.MustCompile("^[a-z0-9._%+\\-]+$").MatchString
 		if isCorrectFormat(input) != true {
@@ -164,18 +195,16 @@ func PromptTeam(org string) string {
 		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
 // PromptOrg asks which org the new team should be created in
-func PromptOrg() string {
+func PromptOrg(svc *service.Service) (string, error) {
 	greenBold := color.New(color.FgGreen, color.Bold)
-	allOrgs := createUser.GetAllOrgs()
+	allOrgs, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		return "", err
+	}
 	var orgsSlice []string
 
 	for k := range allOrgs {
@@ -188,11 +217,11 @@ func PromptOrg() string {
 		Items: orgsSlice,
 	}
 
-	_, org, err := prompt.Run()
+	org, err := interactive.Select(prompt, "")
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return "", err
 	}
 	greenBold.Print("Organisation ")
 	fmt.Println(org)
-	return org
+	return org, nil
 }
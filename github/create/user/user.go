@@ -4,10 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"omniactl/config"
+	"omniactl/errs"
+	"omniactl/github/service"
+	"omniactl/interactive"
 	githubLogin "omniactl/login/github"
-	"os"
-	"regexp"
 	"sort"
 
 	"github.com/fatih/color"
@@ -20,7 +21,6 @@ var (
 	MagentaBold *color.Color
 	WhiteBold   *color.Color
 	Red         *color.Color
-	Client      *github.Client
 )
 
 func init() {
@@ -28,118 +28,140 @@ func init() {
 	MagentaBold = color.New(color.FgMagenta, color.Bold, color.Underline)
 	WhiteBold = color.New(color.FgHiWhite, color.Bold)
 	Red = color.New(color.FgRed)
-	Client = githubLogin.CreateClient()
 }
 
 // AddUser gets values required for adding a new Github User, either through CLI flags or User prompt
-func AddUser(username string, email string, org string, role string, teams []string) {
+func AddUser(svc *service.Service, username string, email string, org string, role string, teams []string) error {
 	MagentaBold.Println("Action selected: Add new user to Github")
-	CheckLogin()
-	username = GetUsername(username)
-	email = GetEmail(email)
-	switch org {
-	case "":
-		check := PromptAddUserToOrg()
-		if check == "yes" {
-			orgs := GetOrgs(org, role, teams)
-			username, _ = CreateUser(username, email)
-			AddUserToOrgs(username, orgs)
-		} else {
-			_, _ = CreateUser(username, email)
+	if err := CheckLogin(svc); err != nil {
+		return err
+	}
+	username, err := GetUsername(svc, username)
+	if err != nil {
+		return err
+	}
+	email, err = GetEmail(email)
+	if err != nil {
+		return err
+	}
+	if org == "" {
+		check, err := PromptAddUserToOrg()
+		if err != nil {
+			return err
+		}
+		if check != "yes" {
+			_, _, err = CreateUser(svc, username, email)
+			return err
 		}
-	default:
-		orgs := GetOrgs(org, role, teams)
-		username, _ = CreateUser(username, email)
-		AddUserToOrgs(username, orgs)
 	}
+	orgs, err := GetOrgs(svc, org, role, teams)
+	if err != nil {
+		return err
+	}
+	username, _, err = CreateUser(svc, username, email)
+	if err != nil {
+		return err
+	}
+	return AddUserToOrgs(svc, username, orgs)
 }
 
 // CheckLogin checks Github token by logging into Github
-func CheckLogin() {
+func CheckLogin(svc *service.Service) error {
 	// githubLogin.GithubLogin("check")
-	err := githubLogin.CheckGithubLogin()
+	err := githubLogin.CheckGithubLogin(svc.Client)
 	if err != nil {
-		log.Fatalln("Connection to Github failed:", err)
+		return errs.Wrap(errs.KindOf(err), err, "connection to Github failed")
 	}
+	return nil
 }
 
 // GetUsername receives username through flag or prompt input
-func GetUsername(username string) string {
+func GetUsername(svc *service.Service, username string) (string, error) {
 	if username != "" {
-		check := CheckUsernameFormat(username)
+		check, err := CheckUsernameFormat(svc, username)
+		if err != nil {
+			return "", err
+		}
 		if check == true {
 			GreenBold.Print("Username ")
 			fmt.Println(username)
-			return username
+			return username, nil
 		} else {
-			username = PromptUsername()
-			return username
+			return PromptUsername(svc)
 		}
 	} else {
-		username = PromptUsername()
-		return username
+		return PromptUsername(svc)
 	}
 }
 
-// CheckUsernameFormat checks flag input has correct format
-func CheckUsernameFormat(input string) bool {
+// CheckUsernameFormat checks flag input matches the configured username pattern.
+// If the user already exists and adding another user is declined, an error is returned.
+func CheckUsernameFormat(svc *service.Service, input string) (bool, error) {
 	red := color.New(color.FgRed)
 
-	isCorrectFormat := regexp.MustCompile("^e[0-9]{6}$").MatchString
-	if isCorrectFormat(input) {
-		check := CheckIfUserExists(input)
+	err := config.CheckUsername(input)
+	if errs.Is(err, errs.Config) {
+		return false, err
+	}
+	if err == nil {
+		check, err := CheckIfUserExists(svc, input)
+		if err != nil {
+			return false, err
+		}
 		switch check {
 		case true:
 			red.Println("Username already exists.")
-			result := PromptAbort()
+			result, err := PromptAbort()
+			if err != nil {
+				return false, err
+			}
 			if result == "add another user" {
-				return false
-			} else {
-				os.Exit(1)
+				return false, nil
 			}
-
+			return false, errs.New(errs.AlreadyExists, "user '%v' already exists", input)
 		case false:
-			return true
+			return true, nil
 		}
 	} else {
-		red.Println("Format incorrect for State Street Lan ID. E.g. 'e123456'")
-		return false
+		red.Println(err)
+		return false, nil
 	}
-	return false
+	return false, nil
 }
 
-func PromptAbort() string {
+func PromptAbort() (string, error) {
 	prompt := promptui.Select{
 		Label: "Exit program or add another user?",
 		Items: []string{"exit", "add another user"},
 	}
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-
-	return result
+	return interactive.Select(prompt, "exit")
 }
 
-// CheckIfUserExists checks if user already exists in Github
-func CheckIfUserExists(username string) bool {
-	Client := githubLogin.CreateClient()
-	_, _, err := Client.Users.Get(context.Background(), username)
+// CheckIfUserExists checks if user already exists in Github.
+// Errors other than the user not being found are returned.
+func CheckIfUserExists(svc *service.Service, username string) (bool, error) {
+	_, _, err := svc.Client.Users.Get(context.Background(), username)
 	if err != nil {
-		return false
+		err = errs.FromGithub(err, "error checking if user '%v' exists", username)
+		if errs.Is(err, errs.NotFound) {
+			return false, nil
+		}
+		return false, err
 	}
-	return true
+	return true, nil
 }
 
 // PromptUsername prompts user for username and checks input
-func PromptUsername() string {
+func PromptUsername(svc *service.Service) (string, error) {
 	validate := func(input string) error {
 		red := color.New(color.FgRed)
-		isCorrectFormat := regexp.MustCompile("^e[0-9]{6}$").MatchString
-		if isCorrectFormat(input) != true {
-			return errors.New("Username must be in the format of a State Street Lan ID, e.g. 'e123456'")
+		if err := config.CheckUsername(input); err != nil {
+			return err
+		}
+		check, err := CheckIfUserExists(svc, input)
+		if err != nil {
+			return err
 		}
-		check := CheckIfUserExists(input)
 		switch check {
 		case true:
 			red.Print("Username already exists.")
@@ -158,50 +180,38 @@ func PromptUsername() string {
 		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
 // GetEmail gets email either from flag or prompt input
-func GetEmail(email string) string {
+func GetEmail(email string) (string, error) {
 	if email != "" {
 		check := CheckEmailFormat(email)
 		if check == true {
 			GreenBold.Print("Email ")
 			fmt.Println(email)
-			return email
+			return email, nil
 		} else {
-			email = PromptEmail()
-			return email
+			return PromptEmail()
 		}
 	} else {
-		email = PromptEmail()
-		return email
+		return PromptEmail()
 	}
 }
 
-// CheckEmailFormat checks flag input for correct format
+// CheckEmailFormat checks flag input is an address of the configured email domain
 func CheckEmailFormat(input string) bool {
-	isCorrectFormat := regexp.MustCompile("^[a-z0-9._%+\\-]+@statestreet.com$").MatchString
-	if isCorrectFormat(input) {
-		return true
-	} else {
-		fmt.Println("Email must be the new user's State Street email address, e.g. 'example@statestreet.com'")
+	if err := config.CheckEmail(input); err != nil {
+		fmt.Println(err)
 		return false
 	}
+	return true
 }
 
 // PromptEmail prompts user for input and checks it
-func PromptEmail() string {
+func PromptEmail() (string, error) {
 	validate := func(input string) error {
-		isCorrectFormat := regexp.MustCompile("^[a-z0-9._%+\\-]+@statestreet.com$").MatchString
-		if isCorrectFormat(input) != true {
-			return errors.New("Email must be the new user's State Street email address, e.g. 'example@statestreet.com'")
-		}
-		return nil
+		return config.CheckEmail(input)
 	}
 	templates := &promptui.PromptTemplates{
 		Success: "{{ . | green | bold }} ",
@@ -211,12 +221,7 @@ func PromptEmail() string {
 		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
 // Org structs holds info on Github org, with id, role and teams
@@ -237,80 +242,92 @@ type Team struct {
 var OrgCount int
 
 // GetOrgs checks flag and prompt input and returns a map of all selected orgs
-func GetOrgs(flagOrg string, flagRole string, flagTeams []string) map[string]Org {
-	allOrgs := GetAllOrgs()
+func GetOrgs(svc *service.Service, flagOrg string, flagRole string, flagTeams []string) (map[string]Org, error) {
+	allOrgs, err := GetAllOrgs(svc)
+	if err != nil {
+		return nil, err
+	}
 	flagOrgs := make(map[string]Org)
 	promptOrgs := make(map[string]Org)
 	sliceTeams := []Team{}
 
 	// Checks if flag has been set, if not: prompt for Orgs
 	if flagOrg == "" {
-		promptOrgs = SelectOrgs(flagOrgs)
+		promptOrgs, err = SelectOrgs(svc, flagOrgs)
+		if err != nil {
+			return nil, err
+		}
 	} else if flagOrg != "" {
 		// Check if org entered at flag exists
-		check := CheckOrgExists(flagOrg)
+		value, check := allOrgs[flagOrg]
 		if check == true {
 			OrgCount++
 			GreenBold.Print("Organisation ", OrgCount, " ")
 			fmt.Println(flagOrg)
 
 			// Add new org to flagOrg map, getting name and ID from allOrgs
-			value, ok := allOrgs[flagOrg]
-			if ok {
-				flagOrgs[flagOrg] = value
-			} else {
-				log.Fatalln("Error saving organisations selected as flags.")
-			}
+			flagOrgs[flagOrg] = value
 
 			// Add role for selected org, either from flag or prompt
-			if flagRole != "" {
-				check = CheckRoleExists(flagRole)
-				if check == true {
-					GreenBold.Print("Role ")
-					fmt.Println(flagRole)
-					value.Role = flagRole
-					flagOrgs[flagOrg] = value
-				} else {
+			if flagRole != "" && CheckRoleExists(flagRole) {
+				GreenBold.Print("Role ")
+				fmt.Println(flagRole)
+			} else {
+				if flagRole != "" {
 					Red.Printf("Role '%v' does not exist.\n", flagRole)
-					flagRole = PromptRole()
-					value.Role = flagRole
-					flagOrgs[flagOrg] = value
 				}
-			} else {
-				flagRole = PromptRole()
-				value.Role = flagRole
-				flagOrgs[flagOrg] = value
+				flagRole, err = PromptRole()
+				if err != nil {
+					return nil, err
+				}
 			}
+			value.Role = flagRole
+			flagOrgs[flagOrg] = value
 
 			// Add team for selected org
+			allTeams, err := GetTeamsForOrg(svc, flagOrg)
+			if err != nil {
+				return nil, err
+			}
 			if len(flagTeams) != 0 {
 				for _, v := range flagTeams {
-					check = CheckTeamExists(flagOrg, v)
-					if check == true {
-						GreenBold.Print("Team ")
-						fmt.Println(v)
-						allTeams := GetTeamsForOrg(flagOrg)
-						id, ok := allTeams[v]
-						if ok {
-							sliceTeams = append(sliceTeams, id)
-						}
+					id, ok := allTeams[v]
+					if !ok {
+						return nil, errs.New(errs.NotFound, "Github team '%v' does not exist in organisation '%v'", v, flagOrg)
 					}
+					GreenBold.Print("Team ")
+					fmt.Println(v)
+					sliceTeams = append(sliceTeams, id)
 				}
 				value.Teams = sliceTeams
 				flagOrgs[flagOrg] = value
 			} else {
-				teams := GetTeamsForOrg(flagOrg)
-				sliceTeams = SelectTeams(teams)
+				sliceTeams, err = SelectTeams(allTeams)
+				if err != nil {
+					return nil, err
+				}
 				value.Teams = sliceTeams
 				flagOrgs[flagOrg] = value
-				result := PromptAnotherOrg()
+				result, err := PromptAnotherOrg()
+				if err != nil {
+					return nil, err
+				}
 				if result == "yes" {
-					promptOrgs = SelectOrgs(flagOrgs)
+					promptOrgs, err = SelectOrgs(svc, flagOrgs)
+					if err != nil {
+						return nil, err
+					}
 				}
 			}
 		} else {
+			if interactive.Disabled {
+				return nil, errs.New(errs.NotFound, "Github organisation '%v' does not exist", flagOrg)
+			}
 			Red.Printf("Organisation '%v' does not exist.\n", flagOrg)
-			promptOrgs = SelectOrgs(flagOrgs)
+			promptOrgs, err = SelectOrgs(svc, flagOrgs)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 	// Add orgs from prompt to flagOrgs
@@ -318,19 +335,18 @@ func GetOrgs(flagOrg string, flagRole string, flagTeams []string) map[string]Org
 		flagOrgs[k] = v
 	}
 	selectedOrgs := flagOrgs
-	return selectedOrgs
+	return selectedOrgs, nil
 }
 
 // CheckOrgExists checks if org provided by flag
 // exists in list of Github orgs
-func CheckOrgExists(orgName string) bool {
-	allOrgs := GetAllOrgs()
-	_, ok := allOrgs[orgName]
-	if ok {
-		return true
-	} else {
-		return false
+func CheckOrgExists(svc *service.Service, orgName string) (bool, error) {
+	allOrgs, err := GetAllOrgs(svc)
+	if err != nil {
+		return false, err
 	}
+	_, ok := allOrgs[orgName]
+	return ok, nil
 }
 
 // CheckRoleExists checks if role provided by flag exists
@@ -349,28 +365,33 @@ func CheckRoleExists(role string) bool {
 
 // CheckTeamExists checks if team name provided by flag exists
 // in selected Github org
-func CheckTeamExists(orgName string, teamName string) bool {
-	allTeams := GetTeamsForOrg(orgName)
-	_, ok := allTeams[teamName]
-	if ok {
-		return true
-	} else {
-		return false
+func CheckTeamExists(svc *service.Service, orgName string, teamName string) (bool, error) {
+	allTeams, err := GetTeamsForOrg(svc, orgName)
+	if err != nil {
+		return false, err
 	}
+	_, ok := allTeams[teamName]
+	return ok, nil
 }
 
 // SelectOrgs prompts user to select orgs, roles in orgs and teams for the new user
-func SelectOrgs(flagOrgs map[string]Org) map[string]Org {
+func SelectOrgs(svc *service.Service, flagOrgs map[string]Org) (map[string]Org, error) {
 	red := color.New(color.FgRed)
 	userOrgs := map[string]Org{}
 	addOrgs := true
-	allOrgs := DeleteFlagOrgs(flagOrgs)
+	allOrgs, err := DeleteFlagOrgs(svc, flagOrgs)
+	if err != nil {
+		return nil, err
+	}
 	s := CreateOrgList(allOrgs)
 
 	// While true: keep prompting for adding a new organisation
 	for addOrgs == true {
 		// User selects one organisation by name with prompt
-		orgName := PromptOrgNames(s)
+		orgName, err := PromptOrgNames(s)
+		if err != nil {
+			return nil, err
+		}
 
 		// Deletes selected org from list to be prompted so user can't select it twice
 		for i, v := range s {
@@ -383,85 +404,62 @@ func SelectOrgs(flagOrgs map[string]Org) map[string]Org {
 
 		// Add new org to userOrgs list, adding information about org from allOrgs map
 		value, ok := allOrgs[orgName]
-		if ok {
-			userOrgs[orgName] = value
-		} else {
-			log.Fatalln("Error saving selected organisation.")
+		if !ok {
+			return nil, errs.New(errs.Internal, "error saving selected organisation '%v'", orgName)
 		}
+		userOrgs[orgName] = value
 
 		// User chooses role for selected Org
-		role := PromptRole()
+		role, err := PromptRole()
+		if err != nil {
+			return nil, err
+		}
 		value.Role = role
 		userOrgs[orgName] = value
 
 		// Get list of all teams in chosen org
-		teamsForOrg := GetTeamsForOrg(orgName)
-
-		if len(s) != 0 && len(teamsForOrg) != 0 {
-			teams := SelectTeams(teamsForOrg)
-
-			value.Teams = teams
-
-			if ok {
-				userOrgs[orgName] = value
-			} else {
-				log.Fatalln("Error saving selected organisation.")
-			}
-
-			result := PromptAnotherOrg()
+		teamsForOrg, err := GetTeamsForOrg(svc, orgName)
+		if err != nil {
+			return nil, err
+		}
 
-			switch result {
-			case "yes":
-				continue
-			case "no":
-				addOrgs = false
-				break
-			default:
-				log.Fatalln("Prompt for organisations failed.")
+		if len(teamsForOrg) != 0 {
+			teams, err := SelectTeams(teamsForOrg)
+			if err != nil {
+				return nil, err
 			}
-		} else if len(s) == 0 && len(teamsForOrg) != 0 {
-			teams := SelectTeams(teamsForOrg)
-
 			value.Teams = teams
-
-			if ok {
-				userOrgs[orgName] = value
-			} else {
-				log.Fatalln("Error saving selected organisation.")
-			}
-
-			addOrgs = false
-
-			red.Println("All available organisations have been selected.")
-			break
-		} else if len(s) == 0 && len(teamsForOrg) == 0 {
+			userOrgs[orgName] = value
+		} else {
 			red.Println("No teams available for this organisation.")
+		}
+
+		if len(s) == 0 {
 			red.Println("All available organisations have been selected.")
 			addOrgs = false
 			break
-		} else if len(s) != 0 && len(teamsForOrg) == 0 {
-			red.Println("No teams available for this organisation.")
-			result := PromptAnotherOrg()
+		}
 
-			switch result {
-			case "yes":
-				continue
-			case "no":
-				addOrgs = false
-				break
-			default:
-				log.Fatalln("Prompt for organisations failed.")
-			}
-		} else {
-			log.Fatalln("Error selecting teams for organisations.")
+		result, err := PromptAnotherOrg()
+		if err != nil {
+			return nil, err
+		}
+		switch result {
+		case "yes":
+			continue
+		default:
+			addOrgs = false
 		}
 	}
-	return userOrgs
+	return userOrgs, nil
 }
 
 // DeleteFlagOrgs deletes organisation from prompt if it has already been set from flag
-func DeleteFlagOrgs(flagOrgs map[string]Org) map[string]Org {
-	allOrgs := GetAllOrgs()
+func DeleteFlagOrgs(svc *service.Service, flagOrgs map[string]Org) (map[string]Org, error) {
+	allOrgs, err := GetAllOrgs(svc)
+	if err != nil {
+		return nil, err
+	}
 
 	for k := range flagOrgs {
 		_, ok := allOrgs[k]
@@ -469,7 +467,7 @@ func DeleteFlagOrgs(flagOrgs map[string]Org) map[string]Org {
 			delete(allOrgs, k)
 		}
 	}
-	return allOrgs
+	return allOrgs, nil
 }
 
 // CreateOrgList puts org names into list to be prompted
@@ -483,13 +481,12 @@ func CreateOrgList(allOrgs map[string]Org) []string {
 }
 
 // GetAllOrgs calls Github API to receive currently available orgs with their IDs
-func GetAllOrgs() map[string]Org {
+func GetAllOrgs(svc *service.Service) (map[string]Org, error) {
 	allOrgs := make(map[string]Org)
 
-	orgs, _, err := Client.Organizations.ListAll(context.Background(), nil)
-
-	if err != nil {
-		log.Fatalln("Error getting list of organisations from Github:", err)
+	var orgs []*github.Organization
+	if err := svc.List("organizations", 0, &orgs); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting list of organisations from Github")
 	}
 
 	for _, v := range orgs {
@@ -500,18 +497,18 @@ func GetAllOrgs() map[string]Org {
 			ID:   v.GetID(),
 		}
 	}
-	return allOrgs
+	return allOrgs, nil
 }
 
 // PromptOrgNames prompts user to choose available orgs from a list
-func PromptOrgNames(s []string) string {
+func PromptOrgNames(s []string) (string, error) {
 	prompt := promptui.Select{
 		Label: "Select Organisation(s)",
 		Items: s,
 	}
-	_, org, err := prompt.Run()
+	org, err := interactive.Select(prompt, "")
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return "", err
 	}
 
 	OrgCount++
@@ -519,46 +516,42 @@ func PromptOrgNames(s []string) string {
 	GreenBold.Print("Organisation ", OrgCount, " ")
 	fmt.Println(org)
 
-	return org
+	return org, nil
 }
 
 // PromptAnotherOrg asks if user wants to add another org
-func PromptAnotherOrg() string {
+func PromptAnotherOrg() (string, error) {
 	prompt := promptui.Select{
 		Label: "Add another organisation?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "no")
 }
 
 // PromptRole asks user to set the role of new user in chosen org
-func PromptRole() string {
+func PromptRole() (string, error) {
 	prompt := promptui.Select{
 		Label: "Role?",
 		Items: []string{"member", "admin"},
 	}
-	_, role, err := prompt.Run()
+	role, err := interactive.Select(prompt, "member")
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return "", err
 	}
 
 	GreenBold.Print("Role ")
 	fmt.Println(role)
-	return role
+	return role, nil
 }
 
 // GetTeamsForOrg returns a map of the teams in given org
-func GetTeamsForOrg(org string) map[string]Team {
+func GetTeamsForOrg(svc *service.Service, org string) (map[string]Team, error) {
 	teamsForOrg := make(map[string]Team)
 
-	teams, _, err := Client.Teams.ListTeams(context.Background(), org, nil)
-	if err != nil {
-		log.Fatalln("Error getting list of teams from Github:", err)
+	var teams []*github.Team
+	if err := svc.List(fmt.Sprintf("orgs/%v/teams", org), 0, &teams); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting list of teams for organisation '%v' from Github", org)
 	}
 
 	for _, v := range teams {
@@ -566,7 +559,7 @@ func GetTeamsForOrg(org string) map[string]Team {
 		id := v.GetID()
 		teamsForOrg[name] = Team{name, id}
 	}
-	return teamsForOrg
+	return teamsForOrg, nil
 }
 
 // CreateTeamList creates list with available Team names for prompt
@@ -580,18 +573,22 @@ func CreateTeamList(teamsForOrg map[string]Team) []string {
 }
 
 // SelectTeams prompts the user to select teams for the current org
-func SelectTeams(teamsForOrg map[string]Team) []Team {
+func SelectTeams(teamsForOrg map[string]Team) ([]Team, error) {
 	userTeams := []Team{}
 	addTeams := true
 	s := CreateTeamList(teamsForOrg)
 
-	if s == nil {
+	// Teams are optional, so none are selected in non-interactive mode
+	if len(s) == 0 || interactive.Disabled {
 		addTeams = false
 	}
 
 	// Keep prompting for teams while true
 	for addTeams == true {
-		teamName := PromptTeams(s)
+		teamName, err := PromptTeams(s)
+		if err != nil {
+			return nil, err
+		}
 
 		value, ok := teamsForOrg[teamName]
 		if ok {
@@ -613,52 +610,47 @@ func SelectTeams(teamsForOrg map[string]Team) []Team {
 			break
 		}
 
-		result := PromptAnotherTeam()
+		result, err := PromptAnotherTeam()
+		if err != nil {
+			return nil, err
+		}
 
 		switch result {
 		case "yes":
 			continue
-		case "no":
-			addTeams = false
-			break
 		default:
-			log.Fatalln("Prompt for teams failed.")
+			addTeams = false
 		}
 	}
-	return userTeams
+	return userTeams, nil
 }
 
 // PromptAnotherTeam asks user if they want to add another team to current org
-func PromptAnotherTeam() string {
+func PromptAnotherTeam() (string, error) {
 	prompt := promptui.Select{
 		Label: "Add another team?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "no")
 }
 
 // PromptTeams offers user available teams to select
-func PromptTeams(s []string) string {
+func PromptTeams(s []string) (string, error) {
 	prompt := promptui.Select{
 		Label: "Select Team(s)",
 		Items: s,
 	}
 
-	_, team, err := prompt.Run()
-
+	team, err := interactive.Select(prompt, "")
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return "", err
 	}
 
 	GreenBold.Print("Team ")
 	fmt.Println(team)
 
-	return team
+	return team, nil
 }
 
 type User struct {
@@ -667,33 +659,29 @@ type User struct {
 	ID    int64  `json:"id"`
 }
 
-func PromptAddUserToOrg() string {
+func PromptAddUserToOrg() (string, error) {
 	prompt := promptui.Select{
 		Label: "Add user to Github org/ teams?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "no")
 }
 
 // CreateUser a new github user with the username and email provided
-func CreateUser(username string, email string) (string, int64) {
+func CreateUser(svc *service.Service, username string, email string) (string, int64, error) {
 	body := User{Login: username, Email: email}
 
-	req, err := Client.NewRequest("POST", "https://github.dev.us-east-1.aws.galleon.c.statestr.com/api/v3/admin/users", body)
+	req, err := svc.Client.NewRequest("POST", "admin/users", body)
 	if err != nil {
-		log.Fatalln("Error creating new request:\n", err)
+		return "", 0, errs.Wrap(errs.Internal, err, "error creating new request")
 	}
 
 	newUser := User{}
-	_, err = Client.Do(context.Background(), req, &newUser)
+	_, err = svc.Client.Do(context.Background(), req, &newUser)
 
 	if err != nil {
-		log.Fatalf("Error creating new user:\n%v", err)
+		return "", 0, errs.FromGithub(err, "error creating new user '%v'", username)
 	}
 
 	username = newUser.Login
@@ -703,11 +691,11 @@ func CreateUser(username string, email string) (string, int64) {
 	WhiteBold.Printf("User '%v' created.", username)
 	fmt.Println("")
 
-	return username, userID
+	return username, userID, nil
 }
 
 // AddUserToOrgs invites new user to selected github orgs and teams
-func AddUserToOrgs(username string, orgs map[string]Org) {
+func AddUserToOrgs(svc *service.Service, username string, orgs map[string]Org) error {
 	for _, v := range orgs {
 		orgName := v.Name
 		role := v.Role
@@ -716,10 +704,9 @@ func AddUserToOrgs(username string, orgs map[string]Org) {
 			Role: github.String(role),
 		}
 
-		_, resp, err := Client.Organizations.EditOrgMembership(context.Background(), username, orgName, membershipOptions)
+		_, _, err := svc.Client.Organizations.EditOrgMembership(context.Background(), username, orgName, membershipOptions)
 		if err != nil {
-			fmt.Println(resp.Status)
-			log.Fatalf("Error creating adding '%v' to Github organisation: %v", username, err)
+			return errs.FromGithub(err, "error adding '%v' to Github organisation '%v'", username, orgName)
 		}
 		WhiteBold.Printf("User '%v' added to Github organisation '%v' as '%v'.", username, orgName, role)
 		fmt.Println("")
@@ -728,19 +715,22 @@ func AddUserToOrgs(username string, orgs map[string]Org) {
 		for _, vv := range teams {
 			teamName := vv.Name
 			teamID := vv.ID
-			AddUserToTeams(username, teamID, teamName)
+			if err := AddUserToTeams(svc, username, teamID, teamName); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
 // AddUserToTeams adds user to selected teams within chosen org
-func AddUserToTeams(username string, teamID int64, teamName string) {
-	_, resp, err := Client.Teams.AddTeamMembership(context.Background(), teamID, username, nil)
+func AddUserToTeams(svc *service.Service, username string, teamID int64, teamName string) error {
+	_, _, err := svc.Client.Teams.AddTeamMembership(context.Background(), teamID, username, nil)
 	if err != nil {
-		fmt.Println(resp.Status)
-		log.Fatalf("Error adding '%v' to Github team: %v", username, err)
+		return errs.FromGithub(err, "error adding '%v' to Github team '%v'", username, teamName)
 	}
 
 	WhiteBold.Printf("User '%v' added to Github team '%v'.", username, teamName)
 	fmt.Println("")
+	return nil
 }
@@ -2,33 +2,76 @@ package user_test
 
 import (
 	"context"
+	"omniactl/errs"
 	createUser "omniactl/github/create/user"
-	deleteUser "omniactl/github/delete/user"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	"omniactl/interactive"
 	githubLogin "omniactl/login/github"
+	"os"
 	"testing"
 )
 
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package
+	svc *service.Service
+	// orgIDs and teamIDs hold the IDs of the organisations and teams created on the fake server
+	orgIDs  = make(map[string]int64)
+	teamIDs = make(map[string]int64)
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddUser("e661018", "existing@statestreet.com")
+	for _, org := range []string{"MSF", "testing", "causeway", "devtools", "Security"} {
+		orgIDs[org] = server.AddOrg(org).ID
+	}
+	for _, team := range []string{"test_team", "team2", "public_team3"} {
+		teamIDs[team] = server.AddTeam("testing", team).ID
+	}
+	svc = service.New(server.Client())
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
 func TestCheckLogin(t *testing.T) {
-	err := githubLogin.CheckGithubLogin()
+	err := githubLogin.CheckGithubLogin(svc.Client)
 	if err != nil {
 		t.Error("Connection to Github failed.")
 	}
 }
 
 func TestGetUsername(t *testing.T) {
+	interactive.Disabled = true
+	defer func() { interactive.Disabled = false }()
+
 	type test struct {
 		data   string
 		answer string
+		valid  bool
+		kind   errs.Kind
 	}
 
+	// Invalid usernames would be prompted for, which fails without a terminal
 	tests := []test{
-		test{"e223344", "e223344"},
-		test{"0000000", ""},
-		test{"newUser", ""},
+		test{"e223344", "e223344", true, 0},
+		test{"0000000", "", false, errs.Validation},
+		test{"newUser", "", false, errs.Validation},
+		test{"e661018", "", false, errs.AlreadyExists},
 	}
 
 	for _, v := range tests {
-		x := createUser.GetUsername(v.data)
+		x, err := createUser.GetUsername(svc, v.data)
+		if v.valid && err != nil {
+			t.Error("Unexpected error:", err)
+		}
+		if !v.valid && !errs.Is(err, v.kind) {
+			t.Errorf("Expected '%v' error Got '%v'", v.kind, err)
+		}
 		if x != v.answer {
 			t.Error("Expected", v.answer, "Got", x)
 		}
@@ -48,7 +91,10 @@ func TestCheckUsernameFormat(t *testing.T) {
 	}
 
 	for _, v := range tests {
-		x := createUser.CheckUsernameFormat(v.data)
+		x, err := createUser.CheckUsernameFormat(svc, v.data)
+		if err != nil {
+			t.Error("Unexpected error:", err)
+		}
 		if x != v.answer {
 			t.Error("Expected", v.answer, "Got", x)
 		}
@@ -68,7 +114,10 @@ func TestCheckIfUserExists(t *testing.T) {
 	}
 
 	for _, v := range tests {
-		x := createUser.CheckIfUserExists(v.data)
+		x, err := createUser.CheckIfUserExists(svc, v.data)
+		if err != nil {
+			t.Error("Unexpected error:", err)
+		}
 		if x != v.answer {
 			t.Error("Expected", v.answer, "Got", x)
 		}
@@ -76,19 +125,30 @@ func TestCheckIfUserExists(t *testing.T) {
 }
 
 func TestGetEmail(t *testing.T) {
+	interactive.Disabled = true
+	defer func() { interactive.Disabled = false }()
+
 	type test struct {
 		data   string
 		answer string
+		valid  bool
 	}
 
+	// Invalid addresses would be prompted for, which fails without a terminal
 	tests := []test{
-		test{"example@statestreet.com", "example@statestreet.com"},
-		test{"notanaddress", ""},
-		test{"1234", ""},
+		test{"example@statestreet.com", "example@statestreet.com", true},
+		test{"notanaddress", "", false},
+		test{"1234", "", false},
 	}
 
 	for _, v := range tests {
-		x := createUser.GetEmail(v.data)
+		x, err := createUser.GetEmail(v.data)
+		if v.valid && err != nil {
+			t.Error("Unexpected error:", err)
+		}
+		if !v.valid && !errs.Is(err, errs.Validation) {
+			t.Errorf("Expected '%v' error Got '%v'", errs.Validation, err)
+		}
 		if x != v.answer {
 			t.Error("Expected", v.answer, "Got", x)
 		}
@@ -116,10 +176,14 @@ func TestCheckEmailFormat(t *testing.T) {
 }
 
 func TestCheckGetOrgs(t *testing.T) {
+	interactive.Disabled = true
+	defer func() { interactive.Disabled = false }()
+
 	type test struct {
 		org   string
 		role  string
 		teams []string
+		valid bool
 	}
 
 	tests := []test{
@@ -127,11 +191,32 @@ func TestCheckGetOrgs(t *testing.T) {
 			org:   "testing",
 			role:  "member",
 			teams: []string{"test_team"},
+			valid: true,
+		},
+		// Unknown organisations and teams are not found rather than skipped
+		test{
+			org:   "falseOrg",
+			role:  "member",
+			teams: []string{},
+		},
+		test{
+			org:   "testing",
+			role:  "member",
+			teams: []string{"test_team", "false_team"},
 		},
 	}
 
 	for _, v := range tests {
-		x := createUser.GetOrgs(v.org, v.role, v.teams)
+		x, err := createUser.GetOrgs(svc, v.org, v.role, v.teams)
+		if !v.valid {
+			if !errs.Is(err, errs.NotFound) {
+				t.Errorf("Expected '%v' error Got '%v'", errs.NotFound, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error("Unexpected error:", err)
+		}
 		for k, vv := range x {
 			if k != v.org {
 				t.Error("Expected", v.org, "Got", k)
@@ -162,7 +247,10 @@ func TestCheckIfOrgExists(t *testing.T) {
 	}
 
 	for _, v := range tests {
-		x := createUser.CheckOrgExists(v.data)
+		x, err := createUser.CheckOrgExists(svc, v.data)
+		if err != nil {
+			t.Error("Unexpected error:", err)
+		}
 		if x != v.answer {
 			t.Error("Expected", v.answer, "Got", x)
 		}
@@ -202,7 +290,10 @@ func TestCheckTeamExists(t *testing.T) {
 	}
 
 	for _, v := range tests {
-		x := createUser.CheckTeamExists(v.org, v.team)
+		x, err := createUser.CheckTeamExists(svc, v.org, v.team)
+		if err != nil {
+			t.Error("Unexpected error:", err)
+		}
 		if x != v.answer {
 			t.Error("Expected", v.answer, "Got", x)
 		}
@@ -213,18 +304,21 @@ func TestDeleteFlagOrgs(t *testing.T) {
 	testOrg := map[string]createUser.Org{
 		"testing": createUser.Org{
 			Name: "testing",
-			ID:   569,
+			ID:   orgIDs["testing"],
 			Role: "member",
 			Teams: []createUser.Team{
 				createUser.Team{
 					Name: "test_team",
-					ID:   90,
+					ID:   teamIDs["test_team"],
 				},
 			},
 		},
 	}
 
-	x := createUser.DeleteFlagOrgs(testOrg)
+	x, err := createUser.DeleteFlagOrgs(svc, testOrg)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
 	for k := range testOrg {
 		_, ok := x[k]
 		if ok {
@@ -237,12 +331,12 @@ func TestCreateOrgList(t *testing.T) {
 	testOrg := map[string]createUser.Org{
 		"testing": createUser.Org{
 			Name: "testing",
-			ID:   569,
+			ID:   orgIDs["testing"],
 			Role: "member",
 			Teams: []createUser.Team{
 				createUser.Team{
 					Name: "test_team",
-					ID:   90,
+					ID:   teamIDs["test_team"],
 				},
 			},
 		},
@@ -257,15 +351,12 @@ func TestCreateOrgList(t *testing.T) {
 }
 
 func TestGetAllOrgs(t *testing.T) {
-	testOrgs := map[string]int64{
-		"MSF":      29,
-		"testing":  569,
-		"causeway": 32,
-		"devtools": 684,
-		"Security": 376,
-	}
+	testOrgs := orgIDs
 
-	x := createUser.GetAllOrgs()
+	x, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
 	for k, v := range testOrgs {
 		value, ok := x[k]
 		if ok {
@@ -280,13 +371,12 @@ func TestGetAllOrgs(t *testing.T) {
 
 func TestGetTeamsForOrg(t *testing.T) {
 	org := "testing"
-	teams := map[string]int64{
-		"test_team":    90,
-		"public_team3": 122,
-		"team2":        108,
-	}
+	teams := teamIDs
 
-	x := createUser.GetTeamsForOrg(org)
+	x, err := createUser.GetTeamsForOrg(svc, org)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
 	for k, v := range teams {
 		value, ok := x[k]
 		if ok {
@@ -303,7 +393,7 @@ func TestCreateTeamList(t *testing.T) {
 	Teams := map[string]createUser.Team{
 		"test_team": createUser.Team{
 			Name: "test_team",
-			ID:   90,
+			ID:   teamIDs["test_team"],
 		},
 	}
 
@@ -329,53 +419,94 @@ func TestCreateUser(t *testing.T) {
 	}
 
 	for _, v := range tests {
-		x, _ := createUser.CreateUser(v.username, v.email)
+		x, _, err := createUser.CreateUser(svc, v.username, v.email)
+		if err != nil {
+			t.Error("Unexpected error:", err)
+		}
 		if x != v.answer {
 			t.Errorf("Expected '%v' Got '%v'", v.answer, x)
 		}
+		user := server.User(v.username)
+		if user == nil || user.Email != v.email {
+			t.Errorf("User '%v' was not created with email '%v'", v.username, v.email)
+		}
 	}
 }
 
 func TestAddUserToOrgs(t *testing.T) {
-	username := "e444444"
+	username := "e555555"
+	server.AddUser(username, "test5@statestreet.com")
 	testOrg := map[string]createUser.Org{
 		"testing": createUser.Org{
 			Name: "testing",
-			ID:   569,
+			ID:   orgIDs["testing"],
 			Role: "member",
 			Teams: []createUser.Team{
 				createUser.Team{
 					Name: "test_team",
-					ID:   90,
+					ID:   teamIDs["test_team"],
 				},
 			},
 		},
 	}
 
-	createUser.AddUserToOrgs(username, testOrg)
+	if err := createUser.AddUserToOrgs(svc, username, testOrg); err != nil {
+		t.Error("Unexpected error:", err)
+	}
 
-	Client := githubLogin.CreateClient()
 	for k, v := range testOrg {
-		isMember, _, err := Client.Organizations.IsMember(context.Background(), k, username)
+		isMember, _, err := svc.Client.Organizations.IsMember(context.Background(), k, username)
 		if err != nil {
-			deleteUser.DeleteFromGithub(username)
 			t.Errorf("Checking member status of '%v' in organisation '%v' failed", username, k)
 		}
 		if isMember == false {
-			deleteUser.DeleteFromGithub(username)
 			t.Errorf("New user '%v' could not be added to organisation '%v'", username, k)
 		}
+		if role := server.Org(k).Members[username]; role != v.Role {
+			t.Errorf("Expected role '%v' in organisation '%v' Got '%v'", v.Role, k, role)
+		}
 		for _, vv := range v.Teams {
-			isMember, _, err := Client.Teams.IsTeamMember(context.Background(), vv.ID, username)
+			isMember, _, err := svc.Client.Teams.IsTeamMember(context.Background(), vv.ID, username)
 			if err != nil {
-				deleteUser.DeleteFromGithub(username)
 				t.Errorf("Checking member status of '%v' in team '%v' failed", username, vv.Name)
 			}
 			if isMember == false {
-				deleteUser.DeleteFromGithub(username)
 				t.Errorf("New user '%v' could not be added to team '%v'", username, vv.Name)
 			}
-			deleteUser.DeleteFromGithub(username)
 		}
 	}
 }
+
+func TestCreateUserErrors(t *testing.T) {
+	type test struct {
+		username string
+		email    string
+		kind     errs.Kind
+	}
+
+	tests := []test{
+		test{"e661018", "existing@statestreet.com", errs.AlreadyExists},
+	}
+
+	for _, v := range tests {
+		_, _, err := createUser.CreateUser(svc, v.username, v.email)
+		if !errs.Is(err, v.kind) {
+			t.Errorf("Expected '%v' error Got '%v'", v.kind, err)
+		}
+	}
+}
+
+func TestCheckUsernameFormatNonInteractive(t *testing.T) {
+	interactive.Disabled = true
+	defer func() { interactive.Disabled = false }()
+
+	_, err := createUser.CheckUsernameFormat(svc, "e661018")
+	if !errs.Is(err, errs.AlreadyExists) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.AlreadyExists, err)
+	}
+
+	_, err = createUser.GetEmail("notanaddress")
+	if !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Validation, err)
+	}
+}
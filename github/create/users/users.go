@@ -0,0 +1,366 @@
+package users
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"omniactl/config"
+	"omniactl/errs"
+	createUser "omniactl/github/create/user"
+	"omniactl/github/service"
+	"omniactl/interactive"
+	"omniactl/output"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultParallel is the default number of users created at the same time
+const DefaultParallel = 4
+
+// Statuses of a row in the report
+const (
+	StatusCreated = "created"
+	StatusFailed  = "failed"
+	StatusInvalid = "invalid"
+	StatusSkipped = "skipped"
+)
+
+// Row is a user to be created, read from a CSV or YAML manifest.
+// In CSV manifests, teams are separated by semicolons.
+type Row struct {
+	Line     int      `yaml:"-"`
+	Username string   `yaml:"username"`
+	Email    string   `yaml:"email"`
+	Org      string   `yaml:"org"`
+	Role     string   `yaml:"role"`
+	Teams    []string `yaml:"teams"`
+}
+
+// Report is the result of creating the user of one row of the manifest
+type Report struct {
+	Line     int      `json:"line" yaml:"line"`
+	Username string   `json:"username" yaml:"username"`
+	Email    string   `json:"email" yaml:"email"`
+	Org      string   `json:"org" yaml:"org"`
+	Role     string   `json:"role" yaml:"role"`
+	Teams    []string `json:"teams" yaml:"teams"`
+	Status   string   `json:"status" yaml:"status"`
+	Error    string   `json:"error" yaml:"error"`
+}
+
+// csvColumns are the columns of a CSV manifest, in any order after the header row
+var csvColumns = []string{"username", "email", "org", "role", "teams"}
+
+// AddUsers creates the users listed in the manifest file, validating every row before
+// any user is created. Users are created concurrently, at most parallel at a time.
+// A report with the result of every row is rendered in the selected output format
+// and, if reportFile is set, written to reportFile in the format matching its extension.
+// Rows are never prompted for, invalid input fails the row instead.
+func AddUsers(svc *service.Service, file string, parallel int, reportFile string) error {
+	createUser.MagentaBold.Println("Action selected: Add new users to Github from a manifest")
+	defer func(disabled bool) { interactive.Disabled = disabled }(interactive.Disabled)
+	interactive.Disabled = true
+
+	if parallel < 1 {
+		return errs.New(errs.Validation, "--parallel must be at least 1, got %v", parallel)
+	}
+	if err := createUser.CheckLogin(svc); err != nil {
+		return err
+	}
+	rows, err := ReadManifest(file)
+	if err != nil {
+		return err
+	}
+
+	orgs, rowErrs := ValidateRows(svc, rows)
+	invalid := 0
+	for _, err := range rowErrs {
+		if err != nil {
+			invalid++
+		}
+	}
+
+	reports := make([]Report, len(rows))
+	for i, row := range rows {
+		reports[i] = newReport(row)
+	}
+	if invalid > 0 {
+		for i := range reports {
+			if rowErrs[i] != nil {
+				reports[i].Status = StatusInvalid
+				reports[i].Error = rowErrs[i].Error()
+			} else {
+				reports[i].Status = StatusSkipped
+			}
+		}
+		if err := WriteReport(reports, reportFile); err != nil {
+			return err
+		}
+		return errs.New(errs.Validation, "%v of %v rows in '%v' are invalid, no users were created", invalid, len(rows), file)
+	}
+
+	createErrs := CreateUsers(svc, rows, orgs, parallel)
+	var firstErr error
+	failed := 0
+	for i, err := range createErrs {
+		if err != nil {
+			reports[i].Status = StatusFailed
+			reports[i].Error = err.Error()
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		} else {
+			reports[i].Status = StatusCreated
+		}
+	}
+	if err := WriteReport(reports, reportFile); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return errs.Wrap(errs.KindOf(firstErr), firstErr, "%v of %v users could not be created", failed, len(rows))
+	}
+	return nil
+}
+
+func newReport(row Row) Report {
+	return Report{
+		Line:     row.Line,
+		Username: row.Username,
+		Email:    row.Email,
+		Org:      row.Org,
+		Role:     row.Role,
+		Teams:    row.Teams,
+	}
+}
+
+// ReadManifest reads the users to be created from a CSV file with a header row,
+// or from a YAML file holding a list of users, depending on the file's extension
+func ReadManifest(file string) ([]Row, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error opening manifest")
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return ParseCSV(f)
+	case ".yaml", ".yml":
+		return ParseYAML(f)
+	default:
+		return nil, errs.New(errs.Validation, "manifest '%v' must be a .csv, .yaml or .yml file", file)
+	}
+}
+
+// ParseCSV parses a CSV manifest. The header row names the columns:
+// username, email, org, role and teams. Teams are separated by semicolons.
+func ParseCSV(r io.Reader) ([]Row, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error reading CSV manifest")
+	}
+	if len(records) == 0 {
+		return nil, errs.New(errs.Validation, "CSV manifest is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns[:2] {
+		if _, ok := columns[name]; !ok {
+			return nil, errs.New(errs.Validation, "CSV manifest has no '%v' column, the header row must name the columns: %v", name, strings.Join(csvColumns, ", "))
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := []Row{}
+	for i, record := range records[1:] {
+		row := Row{
+			Line:     i + 2,
+			Username: field(record, "username"),
+			Email:    field(record, "email"),
+			Org:      field(record, "org"),
+			Role:     field(record, "role"),
+		}
+		for _, team := range strings.Split(field(record, "teams"), ";") {
+			if team = strings.TrimSpace(team); team != "" {
+				row.Teams = append(row.Teams, team)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ParseYAML parses a YAML manifest holding a list of users with the keys
+// username, email, org, role and teams
+func ParseYAML(r io.Reader) ([]Row, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error reading YAML manifest")
+	}
+	rows := []Row{}
+	if err := yaml.UnmarshalStrict(data, &rows); err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error parsing YAML manifest")
+	}
+	for i := range rows {
+		rows[i].Line = i + 1
+	}
+	return rows, nil
+}
+
+// ValidateRows checks every row of the manifest without prompting and returns
+// the organisations, roles and teams of each valid row together with an error
+// for each invalid row. Rows without an org only create the user.
+func ValidateRows(svc *service.Service, rows []Row) ([]map[string]createUser.Org, []error) {
+	orgs := make([]map[string]createUser.Org, len(rows))
+	rowErrs := make([]error, len(rows))
+
+	allOrgs, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		for i := range rowErrs {
+			rowErrs[i] = err
+		}
+		return orgs, rowErrs
+	}
+	teamsForOrgs := make(map[string]map[string]createUser.Team)
+	seen := make(map[string]int)
+
+	for i, row := range rows {
+		if line, ok := seen[row.Username]; ok {
+			rowErrs[i] = errs.New(errs.Validation, "user '%v' is listed twice, first on line %v", row.Username, line)
+			continue
+		}
+		seen[row.Username] = row.Line
+		orgs[i], rowErrs[i] = validateRow(svc, row, allOrgs, teamsForOrgs)
+	}
+	return orgs, rowErrs
+}
+
+// validateRow checks a single row, caching the teams of each org in teamsForOrgs
+func validateRow(svc *service.Service, row Row, allOrgs map[string]createUser.Org, teamsForOrgs map[string]map[string]createUser.Team) (map[string]createUser.Org, error) {
+	check, err := createUser.CheckUsernameFormat(svc, row.Username)
+	if err != nil {
+		return nil, err
+	}
+	if !check {
+		if err := config.CheckUsername(row.Username); err != nil {
+			return nil, err
+		}
+		return nil, errs.New(errs.AlreadyExists, "user '%v' already exists", row.Username)
+	}
+	if err := config.CheckEmail(row.Email); err != nil {
+		return nil, err
+	}
+
+	if row.Org == "" {
+		if row.Role != "" || len(row.Teams) != 0 {
+			return nil, errs.New(errs.Validation, "role and teams require an org")
+		}
+		return nil, nil
+	}
+	org, ok := allOrgs[row.Org]
+	if !ok {
+		return nil, errs.New(errs.NotFound, "Github organisation '%v' does not exist", row.Org)
+	}
+	org.Role = row.Role
+	if org.Role == "" {
+		org.Role = "member"
+	}
+	if !createUser.CheckRoleExists(org.Role) {
+		return nil, errs.New(errs.Validation, "role '%v' does not exist, use member or admin", org.Role)
+	}
+
+	teams, ok := teamsForOrgs[row.Org]
+	if !ok {
+		teams, err = createUser.GetTeamsForOrg(svc, row.Org)
+		if err != nil {
+			return nil, err
+		}
+		teamsForOrgs[row.Org] = teams
+	}
+	for _, name := range row.Teams {
+		team, ok := teams[name]
+		if !ok {
+			return nil, errs.New(errs.NotFound, "Github team '%v' does not exist in organisation '%v'", name, row.Org)
+		}
+		org.Teams = append(org.Teams, team)
+	}
+	return map[string]createUser.Org{row.Org: org}, nil
+}
+
+// CreateUsers creates the users of the validated rows with at most parallel
+// requests in flight and returns the error, if any, of each row
+func CreateUsers(svc *service.Service, rows []Row, orgs []map[string]createUser.Org, parallel int) []error {
+	rowErrs := make([]error, len(rows))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i := range rows {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			username, _, err := createUser.CreateUser(svc, rows[i].Username, rows[i].Email)
+			if err == nil {
+				err = createUser.AddUserToOrgs(svc, username, orgs[i])
+			}
+			rowErrs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	return rowErrs
+}
+
+// WriteReport renders the report in the selected output format, using a table for
+// text output, and writes it to file, if set, in the format matching its extension
+func WriteReport(reports []Report, file string) error {
+	format := output.Format
+	if output.IsText() {
+		fmt.Println("")
+		color.New(color.FgHiWhite, color.Bold).Println("Report:")
+		format = output.Table
+	}
+	if err := output.Render(output.Writer, format, reports); err != nil {
+		return err
+	}
+	if file == "" {
+		return nil
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error creating report file")
+	}
+	defer f.Close()
+	return output.Render(f, reportFormat(file), reports)
+}
+
+// reportFormat returns the output format matching the extension of a report file
+func reportFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return output.JSON
+	case ".yaml", ".yml":
+		return output.YAML
+	case ".csv":
+		return output.CSV
+	default:
+		return output.Table
+	}
+}
@@ -0,0 +1,195 @@
+package users_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"omniactl/errs"
+	createUsers "omniactl/github/create/users"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	"omniactl/output"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package
+	svc *service.Service
+	// dir holds the manifests and reports written by the tests
+	dir string
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddUser("e661018", "existing@statestreet.com")
+	server.AddOrg("testing")
+	server.AddTeam("testing", "test_team")
+	server.AddTeam("testing", "team2")
+	svc = service.New(server.Client())
+	output.Writer = ioutil.Discard
+
+	var err error
+	dir, err = ioutil.TempDir("", "users_test")
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func writeManifest(t *testing.T, name string, content string) string {
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal("Writing manifest failed:", err)
+	}
+	return file
+}
+
+func TestParseCSV(t *testing.T) {
+	manifest := "username,email,org,role,teams\n" +
+		"e111111,one@statestreet.com,testing,admin,test_team;team2\n" +
+		"e222222,two@statestreet.com,,,\n"
+
+	rows, err := createUsers.ParseCSV(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows Got %v", len(rows))
+	}
+	if rows[0].Line != 2 || rows[0].Username != "e111111" || rows[0].Role != "admin" || len(rows[0].Teams) != 2 || rows[0].Teams[1] != "team2" {
+		t.Errorf("First row was not parsed correctly: %+v", rows[0])
+	}
+	if rows[1].Org != "" || len(rows[1].Teams) != 0 {
+		t.Errorf("Second row was not parsed correctly: %+v", rows[1])
+	}
+
+	_, err = createUsers.ParseCSV(strings.NewReader("login,mail\ne111111,one@statestreet.com\n"))
+	if !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error for missing columns Got '%v'", errs.Validation, err)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	manifest := `
+- username: e111111
+  email: one@statestreet.com
+  org: testing
+  teams: [test_team]
+- username: e222222
+  email: two@statestreet.com
+`
+	rows, err := createUsers.ParseYAML(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(rows) != 2 || rows[1].Line != 2 || rows[0].Teams[0] != "test_team" {
+		t.Errorf("YAML manifest was not parsed correctly: %+v", rows)
+	}
+
+	_, err = createUsers.ParseYAML(strings.NewReader("- user: e111111\n"))
+	if !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error for unknown keys Got '%v'", errs.Validation, err)
+	}
+}
+
+func TestAddUsers(t *testing.T) {
+	manifest := writeManifest(t, "new_joiners.csv", "username,email,org,role,teams\n"+
+		"e300001,first@statestreet.com,testing,member,test_team\n"+
+		"e300002,second@statestreet.com,testing,admin,test_team;team2\n"+
+		"e300003,third@statestreet.com,,,\n")
+	report := filepath.Join(dir, "report.json")
+
+	if err := createUsers.AddUsers(svc, manifest, 2, report); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	for _, username := range []string{"e300001", "e300002", "e300003"} {
+		if server.User(username) == nil {
+			t.Errorf("User '%v' was not created", username)
+		}
+	}
+	if role := server.Org("testing").Members["e300002"]; role != "admin" {
+		t.Errorf("Expected role 'admin' Got '%v'", role)
+	}
+	if _, ok := server.Team("testing", "team2").Members["e300002"]; !ok {
+		t.Error("User 'e300002' was not added to team 'team2'")
+	}
+
+	data, err := ioutil.ReadFile(report)
+	if err != nil {
+		t.Fatal("Reading report failed:", err)
+	}
+	reports := []createUsers.Report{}
+	if err := json.Unmarshal(data, &reports); err != nil {
+		t.Fatal("Parsing report failed:", err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("Expected 3 rows in report Got %v", len(reports))
+	}
+	for _, v := range reports {
+		if v.Status != createUsers.StatusCreated {
+			t.Errorf("Expected status '%v' for '%v' Got '%v' (%v)", createUsers.StatusCreated, v.Username, v.Status, v.Error)
+		}
+	}
+}
+
+func TestAddUsersInvalid(t *testing.T) {
+	manifest := writeManifest(t, "invalid.yaml", `
+- username: e400001
+  email: valid@statestreet.com
+  org: testing
+- username: e661018
+  email: existing@statestreet.com
+- username: newUser
+  email: new@statestreet.com
+- username: e400004
+  email: notanaddress
+- username: e400005
+  email: five@statestreet.com
+  org: falseOrg
+- username: e400006
+  email: six@statestreet.com
+  org: testing
+  teams: [false_team]
+- username: e400001
+  email: twice@statestreet.com
+`)
+	out := &bytes.Buffer{}
+	output.Writer = out
+	output.Format = output.CSV
+	defer func() {
+		output.Writer = ioutil.Discard
+		output.Format = output.Text
+	}()
+
+	err := createUsers.AddUsers(svc, manifest, createUsers.DefaultParallel, "")
+	if !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Validation, err)
+	}
+	if server.User("e400001") != nil {
+		t.Error("Users were created although the manifest is invalid")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("Expected header and 7 rows in report Got %v", lines)
+	}
+	statuses := []string{createUsers.StatusSkipped}
+	for i := 0; i < 6; i++ {
+		statuses = append(statuses, createUsers.StatusInvalid)
+	}
+	for i, status := range statuses {
+		if !strings.Contains(lines[i+1], ","+status+",") {
+			t.Errorf("Expected status '%v' Got '%v'", status, lines[i+1])
+		}
+	}
+}
@@ -4,61 +4,69 @@ import (
 	"context"
 	"fmt"
 	"github.com/fatih/color"
-	"github.com/google/go-github/github"
 	"github.com/manifoldco/promptui"
-	"log"
+	"omniactl/dryrun"
+	"omniactl/errs"
 	listUser "omniactl/github/list/user"
-	githubLogin "omniactl/login/github"
+	"omniactl/github/service"
+	"omniactl/interactive"
 )
 
-// Client which interacts with Github API
-var Client *github.Client
-
 // DeleteUser checks flags, gets user info and, once confirmed, deletes user
-func DeleteUser(username string) {
+func DeleteUser(svc *service.Service, username string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: Delete user from Github")
-	username = listUser.CheckUsername(username)
-	githubUser := listUser.GetGithubUser(username)
-	listUser.PrintUserInfo(githubUser)
-	check := PromptDelete(username)
+	username, err := listUser.CheckUsername(svc, username)
+	if err != nil {
+		return err
+	}
+	githubUser, err := listUser.GetGithubUser(svc, username)
+	if err != nil {
+		return err
+	}
+	if err := listUser.PrintUserInfo(svc, githubUser); err != nil {
+		return err
+	}
+	check, err := PromptDelete(username)
+	if err != nil {
+		return err
+	}
 	switch check {
 	case "yes":
-		DeleteFromGithub(username)
-	case "no":
-		return
+		return DeleteFromGithub(svc, username)
 	}
+	return nil
 }
 
 // PromptDelete prompts for confirmation of deletion
-func PromptDelete(username string) string {
+func PromptDelete(username string) (string, error) {
 	prompt := promptui.Select{
 		Label: "Delete the user? (This will remove all their repositories, gists, applications, and personal settings)",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "yes")
 }
 
 // DeleteFromGithub deletes a user from Github, including all their repos
-func DeleteFromGithub(username string) {
+func DeleteFromGithub(svc *service.Service, username string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	Client := githubLogin.CreateClient()
-	url := fmt.Sprintf("https://github.dev.us-east-1.aws.galleon.c.statestr.com/api/v3/admin/users/%v", username)
+	url := fmt.Sprintf("admin/users/%v", username)
 
-	req, err := Client.NewRequest("DELETE", url, nil)
+	req, err := svc.Client.NewRequest("DELETE", url, nil)
 	if err != nil {
-		log.Fatalln("Error creating new request:\n", err)
+		return errs.Wrap(errs.Internal, err, "error creating new request")
 	}
-	_, err = Client.Do(context.Background(), req, nil)
+	_, err = svc.Client.Do(context.Background(), req, nil)
 	if err != nil {
-		log.Fatalln("Error deleting user", err)
+		return errs.FromGithub(err, "error deleting user '%v'", username)
 	}
 	fmt.Println("")
-	whiteBold.Printf("User '%v' deleted.", username)
+	if dryrun.Enabled {
+		whiteBold.Printf("User '%v' would be deleted.", username)
+	} else {
+		whiteBold.Printf("User '%v' deleted.", username)
+	}
 	fmt.Println("")
+	return nil
 }
@@ -0,0 +1,1155 @@
+// Package fake provides an in-process fake of the Github Enterprise REST API
+// for tests. It keeps users, organisations, teams and repositories in memory,
+// so tests can run against it without network access.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// AdminLogin is the login of the site administrator the fake client authenticates as
+const AdminLogin = "admin"
+
+// Scopes and Version are reported in the headers of every response, as Github
+// Enterprise does for requests authenticated with a token
+const (
+	Scopes  = "admin:org, repo, site_admin, user"
+	Version = "2.16.5"
+)
+
+// User is a Github user held by the fake server
+type User struct {
+	Login     string
+	ID        int64
+	Name      string
+	Email     string
+	SiteAdmin bool
+	Suspended bool
+	// SuspendedReason holds the reason given for the last suspension
+	SuspendedReason string
+	// UnsuspendedReason holds the reason given for the last unsuspension
+	UnsuspendedReason string
+	// Keys maps the IDs of the user's public SSH keys to the keys
+	Keys map[int64]string
+	// Tokens is the number of the user's personal access tokens
+	Tokens int
+	// Impersonation is the token a site admin impersonates the user with, if one was created
+	Impersonation string
+	// Impersonations counts the impersonation tokens created for the user
+	Impersonations int
+}
+
+// Org is a Github organisation held by the fake server, with members mapped to their role
+type Org struct {
+	Login       string
+	ID          int64
+	ProfileName string
+	Members     map[string]string
+}
+
+// Team is a Github team held by the fake server, with members mapped to their role
+// and repositories (by full name) mapped to the team's permission
+type Team struct {
+	ID          int64
+	Name        string
+	Org         string
+	Description string
+	Privacy     string
+	Permission  string
+	Members     map[string]string
+	Repos       map[string]string
+}
+
+// Repo is a Github repository held by the fake server, with collaborators mapped to their permission
+type Repo struct {
+	ID            int64
+	Name          string
+	Owner         string
+	Description   string
+	Private       bool
+	Archived      bool
+	UpdatedAt     time.Time
+	Collaborators map[string]string
+}
+
+// FullName returns the owner/name of the repository
+func (r *Repo) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// Server is an in-process fake Github Enterprise instance
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	nextID int64
+	users  map[string]*User
+	orgs   map[string]*Org
+	teams  map[int64]*Team
+	repos  map[string]*Repo
+	routes []route
+}
+
+// NewServer starts a fake Github Enterprise server containing only the site administrator.
+// The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		users: make(map[string]*User),
+		orgs:  make(map[string]*Org),
+		teams: make(map[int64]*Team),
+		repos: make(map[string]*Repo),
+	}
+	s.registerRoutes()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.AddUser(AdminLogin, "admin@statestreet.com").SiteAdmin = true
+	return s
+}
+
+// Client returns a Github client pointing at the fake server
+func (s *Server) Client() *github.Client {
+	client, err := github.NewEnterpriseClient(s.URL+"/api/v3/", s.URL+"/api/uploads/", nil)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// AddUser adds a user to the fake server
+func (s *Server) AddUser(login string, email string) *User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(login, email)
+}
+
+// AddKey adds a public SSH key to an existing user and returns its ID
+func (s *Server) AddKey(login string, key string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.id()
+	s.users[login].Keys[id] = key
+	return id
+}
+
+// AddToken adds a personal access token to an existing user
+func (s *Server) AddToken(login string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[login].Tokens++
+}
+
+// AddOrg adds an organisation to the fake server
+func (s *Server) AddOrg(login string) *Org {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addOrg(login, "")
+}
+
+// AddOrgMember adds an existing user to an organisation with the given role, i.e. member or admin
+func (s *Server) AddOrgMember(org string, login string, role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs[org].Members[login] = role
+}
+
+// AddTeam adds a team to an existing organisation
+func (s *Server) AddTeam(org string, name string) *Team {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTeam(org, name, "", "closed")
+}
+
+// AddTeamMember adds an existing user to a team as a member, also making them a member of the team's org
+func (s *Server) AddTeamMember(teamID int64, login string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := s.teams[teamID]
+	team.Members[login] = "member"
+	if _, ok := s.orgs[team.Org].Members[login]; !ok {
+		s.orgs[team.Org].Members[login] = "member"
+	}
+}
+
+// AddRepo adds a repository owned by an existing user or organisation
+func (s *Server) AddRepo(owner string, name string) *Repo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addRepo(owner, name, "", false)
+}
+
+// AddTeamRepo gives a team the provided permission, i.e. pull, push or admin, on an existing repository
+func (s *Server) AddTeamRepo(teamID int64, fullName string, permission string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams[teamID].Repos[fullName] = permission
+}
+
+// AddCollaborator adds a user as a direct collaborator with the provided permission to an existing repository
+func (s *Server) AddCollaborator(fullName string, login string, permission string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos[fullName].Collaborators[login] = permission
+}
+
+// User returns a copy of the user with the provided login, or nil if it does not exist
+func (s *Server) User(login string) *User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[login]
+	if !ok {
+		return nil
+	}
+	c := *u
+	c.Keys = make(map[int64]string)
+	for id, key := range u.Keys {
+		c.Keys[id] = key
+	}
+	return &c
+}
+
+// Org returns a copy of the organisation with the provided login, or nil if it does not exist
+func (s *Server) Org(login string) *Org {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orgs[login]
+	if !ok {
+		return nil
+	}
+	c := *o
+	c.Members = copyMap(o.Members)
+	return &c
+}
+
+// Team returns a copy of the team with the provided name in the org, or nil if it does not exist
+func (s *Server) Team(org string, name string) *Team {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.findTeam(org, name)
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.Members = copyMap(t.Members)
+	c.Repos = copyMap(t.Repos)
+	return &c
+}
+
+// Repo returns a copy of the repository with the provided full name, or nil if it does not exist
+func (s *Server) Repo(fullName string) *Repo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.repos[fullName]
+	if !ok {
+		return nil
+	}
+	c := *r
+	c.Collaborators = copyMap(r.Collaborators)
+	return &c
+}
+
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) addUser(login string, email string) *User {
+	u := &User{Login: login, ID: s.id(), Email: email, Keys: make(map[int64]string)}
+	s.users[login] = u
+	return u
+}
+
+func (s *Server) addOrg(login string, profile string) *Org {
+	o := &Org{Login: login, ID: s.id(), ProfileName: profile, Members: make(map[string]string)}
+	s.orgs[login] = o
+	return o
+}
+
+func (s *Server) addTeam(org string, name string, description string, privacy string) *Team {
+	t := &Team{
+		ID:          s.id(),
+		Name:        name,
+		Org:         org,
+		Description: description,
+		Privacy:     privacy,
+		Permission:  "pull",
+		Members:     make(map[string]string),
+		Repos:       make(map[string]string),
+	}
+	s.teams[t.ID] = t
+	return t
+}
+
+func (s *Server) addRepo(owner string, name string, description string, private bool) *Repo {
+	r := &Repo{
+		ID:            s.id(),
+		Name:          name,
+		Owner:         owner,
+		Description:   description,
+		Private:       private,
+		UpdatedAt:     time.Now().UTC(),
+		Collaborators: make(map[string]string),
+	}
+	s.repos[r.FullName()] = r
+	return r
+}
+
+func (s *Server) findTeam(org string, name string) *Team {
+	for _, t := range s.teams {
+		if t.Org == org && t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func copyMap(m map[string]string) map[string]string {
+	c := make(map[string]string)
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// route maps a method and path pattern, e.g. "orgs/{org}/teams", to a handler
+type route struct {
+	method  string
+	pattern []string
+	handler func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+func (s *Server) handle(method string, pattern string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string)) {
+	s.routes = append(s.routes, route{method, strings.Split(pattern, "/"), handler})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v3"), "/")
+	segments := strings.Split(path, "/")
+
+	w.Header().Set("X-OAuth-Scopes", Scopes)
+	w.Header().Set("X-GitHub-Enterprise-Version", Version)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rt := range s.routes {
+		if rt.method != r.Method || len(rt.pattern) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		match := true
+		for i, p := range rt.pattern {
+			if strings.HasPrefix(p, "{") {
+				params[strings.Trim(p, "{}")] = segments[i]
+			} else if p != segments[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			rt.handler(w, r, params)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+// writeValidationError writes a 422 "Validation Failed" with a single error, which is
+// how Github reports the reason, e.g. code "already_exists", or "custom" with a message
+func writeValidationError(w http.ResponseWriter, resource string, field string, code string, message string) {
+	e := map[string]string{"resource": resource, "field": field, "code": code}
+	if message != "" {
+		e["message"] = message
+	}
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{"message": "Validation Failed", "errors": []interface{}{e}})
+}
+
+// writePage writes the page of items selected by the page and per_page query
+// parameters, setting a Link header for the next and last pages like Github does
+func writePage(w http.ResponseWriter, r *http.Request, items []interface{}) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = 30
+	}
+	last := (len(items) + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+
+	if page < last {
+		link := func(p int, rel string) string {
+			u := *r.URL
+			q := u.Query()
+			q.Set("page", strconv.Itoa(p))
+			q.Set("per_page", strconv.Itoa(perPage))
+			u.RawQuery = q.Encode()
+			return fmt.Sprintf(`<http://%v%v>; rel="%v"`, r.Host, u.String(), rel)
+		}
+		w.Header().Set("Link", link(page+1, "next")+", "+link(last, "last"))
+	}
+
+	start := (page - 1) * perPage
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+func decode(r *http.Request, v interface{}) {
+	json.NewDecoder(r.Body).Decode(v)
+}
+
+func (s *Server) userJSON(u *User) map[string]interface{} {
+	v := map[string]interface{}{
+		"login":      u.Login,
+		"id":         u.ID,
+		"name":       u.Name,
+		"email":      u.Email,
+		"site_admin": u.SiteAdmin,
+		"type":       "User",
+	}
+	if u.Suspended {
+		v["suspended_at"] = time.Now().UTC()
+	}
+	return v
+}
+
+func (s *Server) orgJSON(o *Org) map[string]interface{} {
+	var public, private int
+	for _, r := range s.repos {
+		if r.Owner == o.Login {
+			if r.Private {
+				private++
+			} else {
+				public++
+			}
+		}
+	}
+	return map[string]interface{}{
+		"login":               o.Login,
+		"id":                  o.ID,
+		"name":                o.ProfileName,
+		"public_repos":        public,
+		"total_private_repos": private,
+		"type":                "Organization",
+	}
+}
+
+func (s *Server) teamJSON(t *Team) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            t.ID,
+		"name":          t.Name,
+		"slug":          t.Name,
+		"description":   t.Description,
+		"privacy":       t.Privacy,
+		"permission":    t.Permission,
+		"members_count": len(t.Members),
+		"repos_count":   len(t.Repos),
+		"organization":  map[string]interface{}{"login": t.Org, "id": s.orgs[t.Org].ID},
+	}
+}
+
+func (s *Server) repoJSON(r *Repo, permission string) map[string]interface{} {
+	v := map[string]interface{}{
+		"id":          r.ID,
+		"name":        r.Name,
+		"full_name":   r.FullName(),
+		"owner":       map[string]interface{}{"login": r.Owner},
+		"description": r.Description,
+		"private":     r.Private,
+		"archived":    r.Archived,
+		"updated_at":  r.UpdatedAt,
+		"url":         fmt.Sprintf("%v/api/v3/repos/%v", s.URL, r.FullName()),
+		"html_url":    fmt.Sprintf("%v/%v", s.URL, r.FullName()),
+	}
+	if permission != "" {
+		v["permissions"] = map[string]bool{
+			"pull":  true,
+			"push":  permission == "push" || permission == "admin",
+			"admin": permission == "admin",
+		}
+	}
+	return v
+}
+
+func (s *Server) membershipJSON(o *Org, login string) map[string]interface{} {
+	return map[string]interface{}{
+		"state":        "active",
+		"role":         o.Members[login],
+		"organization": map[string]interface{}{"login": o.Login, "id": o.ID},
+		"user":         map[string]interface{}{"login": login},
+	}
+}
+
+func (s *Server) sortedUsers(logins map[string]string) []interface{} {
+	names := []string{}
+	for login := range logins {
+		names = append(names, login)
+	}
+	sort.Strings(names)
+	items := []interface{}{}
+	for _, login := range names {
+		if u, ok := s.users[login]; ok {
+			items = append(items, s.userJSON(u))
+		}
+	}
+	return items
+}
+
+func (s *Server) registerRoutes() {
+	// Users
+	s.handle("GET", "user", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		writeJSON(w, http.StatusOK, s.userJSON(s.users[AdminLogin]))
+	})
+	s.handle("GET", "users/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		u, ok := s.users[p["user"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(w, http.StatusOK, s.userJSON(u))
+	})
+	s.handle("POST", "admin/users", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		body := struct {
+			Login string `json:"login"`
+			Email string `json:"email"`
+		}{}
+		decode(r, &body)
+		if body.Login == "" {
+			writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+			return
+		}
+		if _, ok := s.users[body.Login]; ok {
+			writeValidationError(w, "User", "login", "custom", "login is already taken")
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.userJSON(s.addUser(body.Login, body.Email)))
+	})
+	s.handle("DELETE", "admin/users/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		login := p["user"]
+		if _, ok := s.users[login]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		delete(s.users, login)
+		for _, o := range s.orgs {
+			delete(o.Members, login)
+		}
+		for _, t := range s.teams {
+			delete(t.Members, login)
+		}
+		for name, repo := range s.repos {
+			delete(repo.Collaborators, login)
+			if repo.Owner == login {
+				delete(s.repos, name)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.handle("PUT", "users/{user}/suspended", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		u, ok := s.users[p["user"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		body := struct {
+			Reason string `json:"reason"`
+		}{}
+		decode(r, &body)
+		u.Suspended = true
+		u.SuspendedReason = body.Reason
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.handle("DELETE", "users/{user}/suspended", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		u, ok := s.users[p["user"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		body := struct {
+			Reason string `json:"reason"`
+		}{}
+		decode(r, &body)
+		u.Suspended = false
+		u.UnsuspendedReason = body.Reason
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.handle("GET", "users/{user}/keys", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		u, ok := s.users[p["user"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		ids := []int{}
+		for id := range u.Keys {
+			ids = append(ids, int(id))
+		}
+		sort.Ints(ids)
+		items := []interface{}{}
+		for _, id := range ids {
+			items = append(items, map[string]interface{}{"id": id, "key": u.Keys[int64(id)]})
+		}
+		writePage(w, r, items)
+	})
+	s.handle("DELETE", "admin/keys/{key}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		id, _ := strconv.ParseInt(p["key"], 10, 64)
+		for _, u := range s.users {
+			if _, ok := u.Keys[id]; ok {
+				delete(u.Keys, id)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Not Found")
+	})
+	s.handle("POST", "admin/users/{user}/authorizations", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		u, ok := s.users[p["user"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		body := struct {
+			Scopes []string `json:"scopes"`
+		}{}
+		decode(r, &body)
+		status := http.StatusOK
+		if u.Impersonation == "" {
+			u.Impersonation = "impersonation-" + u.Login
+			u.Impersonations++
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, map[string]interface{}{"id": u.ID, "token": u.Impersonation, "scopes": body.Scopes})
+	})
+	// Only the impersonation token is deleted, not the user's personal access tokens
+	s.handle("DELETE", "admin/users/{user}/authorizations", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		u, ok := s.users[p["user"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		u.Impersonation = ""
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.handle("PUT", "users/{user}/site_admin", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		u, ok := s.users[p["user"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		u.SiteAdmin = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.handle("DELETE", "users/{user}/site_admin", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		u, ok := s.users[p["user"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		u.SiteAdmin = false
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Organisations
+	s.handle("GET", "organizations", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		names := []string{}
+		for login := range s.orgs {
+			names = append(names, login)
+		}
+		sort.Strings(names)
+		items := []interface{}{}
+		for _, login := range names {
+			items = append(items, s.orgJSON(s.orgs[login]))
+		}
+		writePage(w, r, items)
+	})
+	s.handle("GET", "orgs/{org}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		o, ok := s.orgs[p["org"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(w, http.StatusOK, s.orgJSON(o))
+	})
+	s.handle("POST", "admin/organizations", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		body := struct {
+			Login       string `json:"login"`
+			Admin       string `json:"admin"`
+			ProfileName string `json:"profile_name"`
+		}{}
+		decode(r, &body)
+		if body.Login == "" {
+			writeValidationError(w, "Organization", "login", "missing_field", "")
+			return
+		}
+		if _, ok := s.orgs[body.Login]; ok {
+			writeValidationError(w, "Organization", "login", "already_exists", "")
+			return
+		}
+		if _, ok := s.users[body.Admin]; !ok {
+			writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+			return
+		}
+		o := s.addOrg(body.Login, body.ProfileName)
+		o.Members[body.Admin] = "admin"
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"login": o.Login, "id": o.ID})
+	})
+	s.handle("GET", "orgs/{org}/members", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		o, ok := s.orgs[p["org"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		members := o.Members
+		if role := r.URL.Query().Get("role"); role == "admin" || role == "member" {
+			members = make(map[string]string)
+			for login, v := range o.Members {
+				if v == role {
+					members[login] = v
+				}
+			}
+		}
+		writePage(w, r, s.sortedUsers(members))
+	})
+	s.handle("GET", "orgs/{org}/members/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		o, ok := s.orgs[p["org"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		if _, ok := o.Members[p["user"]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	removeMember := func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		o, ok := s.orgs[p["org"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		delete(o.Members, p["user"])
+		for _, t := range s.teams {
+			if t.Org == o.Login {
+				delete(t.Members, p["user"])
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+	s.handle("DELETE", "orgs/{org}/members/{user}", removeMember)
+	s.handle("DELETE", "orgs/{org}/memberships/{user}", removeMember)
+	s.handle("GET", "orgs/{org}/memberships/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		o, ok := s.orgs[p["org"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		if _, ok := o.Members[p["user"]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(w, http.StatusOK, s.membershipJSON(o, p["user"]))
+	})
+	s.handle("PUT", "orgs/{org}/memberships/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		o, ok := s.orgs[p["org"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		if _, ok := s.users[p["user"]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		body := struct {
+			Role string `json:"role"`
+		}{}
+		decode(r, &body)
+		if body.Role == "" {
+			body.Role = "member"
+		}
+		if body.Role != "member" && body.Role != "admin" {
+			writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+			return
+		}
+		o.Members[p["user"]] = body.Role
+		writeJSON(w, http.StatusOK, s.membershipJSON(o, p["user"]))
+	})
+
+	// Teams
+	s.handle("GET", "orgs/{org}/teams", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if _, ok := s.orgs[p["org"]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		ids := []int{}
+		for id, t := range s.teams {
+			if t.Org == p["org"] {
+				ids = append(ids, int(id))
+			}
+		}
+		sort.Ints(ids)
+		items := []interface{}{}
+		for _, id := range ids {
+			items = append(items, s.teamJSON(s.teams[int64(id)]))
+		}
+		writePage(w, r, items)
+	})
+	s.handle("POST", "orgs/{org}/teams", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if _, ok := s.orgs[p["org"]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		body := struct {
+			Name        string   `json:"name"`
+			Description string   `json:"description"`
+			Privacy     string   `json:"privacy"`
+			Maintainers []string `json:"maintainers"`
+		}{}
+		decode(r, &body)
+		if body.Name == "" {
+			writeValidationError(w, "Team", "name", "missing_field", "")
+			return
+		}
+		if s.findTeam(p["org"], body.Name) != nil {
+			writeValidationError(w, "Team", "name", "already_exists", "")
+			return
+		}
+		if body.Privacy == "" {
+			body.Privacy = "secret"
+		}
+		t := s.addTeam(p["org"], body.Name, body.Description, body.Privacy)
+		for _, login := range body.Maintainers {
+			t.Members[login] = "maintainer"
+		}
+		writeJSON(w, http.StatusCreated, s.teamJSON(t))
+	})
+	team := func(w http.ResponseWriter, p map[string]string) *Team {
+		id, _ := strconv.ParseInt(p["team"], 10, 64)
+		t, ok := s.teams[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return nil
+		}
+		return t
+	}
+	s.handle("GET", "teams/{team}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			writeJSON(w, http.StatusOK, s.teamJSON(t))
+		}
+	})
+	s.handle("DELETE", "teams/{team}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			delete(s.teams, t.ID)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	s.handle("GET", "teams/{team}/members", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			members := t.Members
+			if role := r.URL.Query().Get("role"); role == "maintainer" || role == "member" {
+				members = make(map[string]string)
+				for login, v := range t.Members {
+					if v == role {
+						members[login] = v
+					}
+				}
+			}
+			writePage(w, r, s.sortedUsers(members))
+		}
+	})
+	s.handle("GET", "teams/{team}/members/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			if _, ok := t.Members[p["user"]]; !ok {
+				writeError(w, http.StatusNotFound, "Not Found")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	s.handle("GET", "teams/{team}/memberships/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			role, ok := t.Members[p["user"]]
+			if !ok {
+				writeError(w, http.StatusNotFound, "Not Found")
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"state": "active", "role": role})
+		}
+	})
+	s.handle("PUT", "teams/{team}/memberships/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			if _, ok := s.users[p["user"]]; !ok {
+				writeError(w, http.StatusNotFound, "Not Found")
+				return
+			}
+			body := struct {
+				Role string `json:"role"`
+			}{}
+			decode(r, &body)
+			if body.Role == "" {
+				body.Role = "member"
+			}
+			t.Members[p["user"]] = body.Role
+			if _, ok := s.orgs[t.Org].Members[p["user"]]; !ok {
+				s.orgs[t.Org].Members[p["user"]] = "member"
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"state": "active", "role": body.Role})
+		}
+	})
+	s.handle("DELETE", "teams/{team}/memberships/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			delete(t.Members, p["user"])
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	s.handle("GET", "teams/{team}/repos", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			names := []string{}
+			for name := range t.Repos {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			items := []interface{}{}
+			for _, name := range names {
+				if repo, ok := s.repos[name]; ok {
+					items = append(items, s.repoJSON(repo, t.Repos[name]))
+				}
+			}
+			writePage(w, r, items)
+		}
+	})
+	s.handle("PUT", "teams/{team}/repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			name := p["owner"] + "/" + p["repo"]
+			if _, ok := s.repos[name]; !ok {
+				writeError(w, http.StatusNotFound, "Not Found")
+				return
+			}
+			body := struct {
+				Permission string `json:"permission"`
+			}{}
+			decode(r, &body)
+			if body.Permission == "" {
+				body.Permission = t.Permission
+			}
+			t.Repos[name] = body.Permission
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	s.handle("DELETE", "teams/{team}/repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if t := team(w, p); t != nil {
+			delete(t.Repos, p["owner"]+"/"+p["repo"])
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	// Repositories
+	// listRepos lists the repos of the owner, only the public ones unless private is set
+	listRepos := func(w http.ResponseWriter, r *http.Request, owner string, private bool) {
+		names := []string{}
+		for name, repo := range s.repos {
+			if repo.Owner == owner && (private || !repo.Private) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		items := []interface{}{}
+		for _, name := range names {
+			items = append(items, s.repoJSON(s.repos[name], ""))
+		}
+		writePage(w, r, items)
+	}
+	createRepo := func(w http.ResponseWriter, r *http.Request, owner string) {
+		body := struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Private     bool   `json:"private"`
+			TeamID      int64  `json:"team_id"`
+		}{}
+		decode(r, &body)
+		if body.Name == "" {
+			writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+			return
+		}
+		if _, ok := s.repos[owner+"/"+body.Name]; ok {
+			writeValidationError(w, "Repository", "name", "custom", "name already exists on this account")
+			return
+		}
+		repo := s.addRepo(owner, body.Name, body.Description, body.Private)
+		if t, ok := s.teams[body.TeamID]; ok {
+			t.Repos[repo.FullName()] = "pull"
+		}
+		writeJSON(w, http.StatusCreated, s.repoJSON(repo, "admin"))
+	}
+	s.handle("GET", "orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if _, ok := s.orgs[p["org"]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		listRepos(w, r, p["org"], true)
+	})
+	s.handle("POST", "orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if _, ok := s.orgs[p["org"]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		createRepo(w, r, p["org"])
+	})
+	// Like Github, only the public repos of other users are listed, even to site admins
+	s.handle("GET", "users/{user}/repos", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if _, ok := s.users[p["user"]]; !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		listRepos(w, r, p["user"], false)
+	})
+	// Requests with an impersonation token list the repos of the impersonated user
+	s.handle("GET", "user/repos", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		login := AdminLogin
+		if fields := strings.Fields(r.Header.Get("Authorization")); len(fields) == 2 {
+			for _, u := range s.users {
+				if u.Impersonation != "" && u.Impersonation == fields[1] {
+					login = u.Login
+				}
+			}
+		}
+		listRepos(w, r, login, true)
+	})
+	s.handle("POST", "user/repos", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		createRepo(w, r, AdminLogin)
+	})
+	repo := func(w http.ResponseWriter, p map[string]string) *Repo {
+		repo, ok := s.repos[p["owner"]+"/"+p["repo"]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return nil
+		}
+		return repo
+	}
+	s.handle("GET", "repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if repo := repo(w, p); repo != nil {
+			writeJSON(w, http.StatusOK, s.repoJSON(repo, ""))
+		}
+	})
+	s.handle("PATCH", "repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if repo := repo(w, p); repo != nil {
+			body := struct {
+				Description *string `json:"description"`
+				Private     *bool   `json:"private"`
+				Archived    *bool   `json:"archived"`
+			}{}
+			decode(r, &body)
+			if body.Description != nil {
+				repo.Description = *body.Description
+			}
+			if body.Private != nil {
+				repo.Private = *body.Private
+			}
+			if body.Archived != nil {
+				repo.Archived = *body.Archived
+			}
+			repo.UpdatedAt = time.Now().UTC()
+			writeJSON(w, http.StatusOK, s.repoJSON(repo, ""))
+		}
+	})
+	s.handle("POST", "repos/{owner}/{repo}/transfer", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if repo := repo(w, p); repo != nil {
+			body := struct {
+				NewOwner string `json:"new_owner"`
+			}{}
+			decode(r, &body)
+			_, isUser := s.users[body.NewOwner]
+			_, isOrg := s.orgs[body.NewOwner]
+			if !isUser && !isOrg {
+				writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+				return
+			}
+			if _, ok := s.repos[body.NewOwner+"/"+repo.Name]; ok {
+				writeValidationError(w, "Repository", "name", "custom", "name already exists on this account")
+				return
+			}
+			oldName := repo.FullName()
+			delete(s.repos, oldName)
+			repo.Owner = body.NewOwner
+			s.repos[repo.FullName()] = repo
+			for _, t := range s.teams {
+				if permission, ok := t.Repos[oldName]; ok {
+					delete(t.Repos, oldName)
+					t.Repos[repo.FullName()] = permission
+				}
+			}
+			writeJSON(w, http.StatusAccepted, s.repoJSON(repo, ""))
+		}
+	})
+	s.handle("GET", "repos/{owner}/{repo}/collaborators", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if repo := repo(w, p); repo != nil {
+			names := []string{}
+			for login := range repo.Collaborators {
+				names = append(names, login)
+			}
+			sort.Strings(names)
+			items := []interface{}{}
+			for _, login := range names {
+				if u, ok := s.users[login]; ok {
+					v := s.userJSON(u)
+					permission := repo.Collaborators[login]
+					v["permissions"] = map[string]bool{
+						"pull":  true,
+						"push":  permission == "push" || permission == "admin",
+						"admin": permission == "admin",
+					}
+					items = append(items, v)
+				}
+			}
+			writePage(w, r, items)
+		}
+	})
+	s.handle("PUT", "repos/{owner}/{repo}/collaborators/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if repo := repo(w, p); repo != nil {
+			if _, ok := s.users[p["user"]]; !ok {
+				writeError(w, http.StatusNotFound, "Not Found")
+				return
+			}
+			body := struct {
+				Permission string `json:"permission"`
+			}{}
+			decode(r, &body)
+			if body.Permission == "" {
+				body.Permission = "push"
+			}
+			repo.Collaborators[p["user"]] = body.Permission
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	s.handle("DELETE", "repos/{owner}/{repo}/collaborators/{user}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if repo := repo(w, p); repo != nil {
+			delete(repo.Collaborators, p["user"])
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
@@ -4,169 +4,215 @@ import (
 	"context"
 	"fmt"
 	"github.com/fatih/color"
+	"github.com/google/go-github/github"
 	"github.com/manifoldco/promptui"
-	"log"
+	"omniactl/errs"
 	createOrg "omniactl/github/create/org"
 	createTeam "omniactl/github/create/team"
 	createUser "omniactl/github/create/user"
 	listTeam "omniactl/github/list/team"
-	githubLogin "omniactl/login/github"
+	"omniactl/github/result"
+	"omniactl/github/service"
+	"omniactl/interactive"
+	"omniactl/output"
 )
 
-func ListOrg(org string) {
+func ListOrg(svc *service.Service, org string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: List information about a Github organisation")
 
-	org = CheckFlag(org)
-	result := PromptAction()
+	org, err := CheckFlag(svc, org)
+	if err != nil {
+		return err
+	}
+	result, err := PromptAction()
+	if err != nil {
+		return err
+	}
 	switch result {
 	case "Organisation stats":
-		ListOrgStats(org)
+		return ListOrgStats(svc, org)
 	case "Organisation members":
-		ListOrgMembers(org)
+		return ListOrgMembers(svc, org)
 	case "Organisation repositories":
-		ListOrgRepos(org)
+		return ListOrgRepos(svc, org)
 	case "Organisation teams":
-		ListOrgTeams(org)
+		return ListOrgTeams(svc, org)
 	}
+	return nil
 }
 
 // CheckFlag checks input from flag and prompts user if necessary
-func CheckFlag(org string) string {
+func CheckFlag(svc *service.Service, org string) (string, error) {
 	red := color.New(color.FgRed)
 	if org != "" {
-		check := createOrg.CheckIfOrgExists(org)
+		check, err := createOrg.CheckIfOrgExists(svc, org)
+		if err != nil {
+			return "", err
+		}
 		switch check {
 		case true:
-			return org
+			return org, nil
 		default:
 			red.Printf("Organisation '%v' does not exist.", org)
 			fmt.Println("")
-			org = createTeam.PromptOrg()
-			return org
+			return createTeam.PromptOrg(svc)
 		}
 	} else {
-		org = createTeam.PromptOrg()
-		return org
+		return createTeam.PromptOrg(svc)
 	}
 }
 
 // PromptAction asks user to select which info they want about selected team
-func PromptAction() string {
+func PromptAction() (string, error) {
 	prompt := promptui.Select{
 		Label: "Select action",
 		Items: []string{"Organisation stats", "Organisation members", "Organisation repositories", "Organisation teams"},
 	}
 
-	_, result, err := prompt.Run()
+	return interactive.Select(prompt, "")
+}
+
+// GetOrgStats gets the stats of an organisation
+func GetOrgStats(svc *service.Service, org string) (result.Org, error) {
+	gitOrg, _, err := svc.Client.Organizations.Get(context.Background(), org)
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return result.Org{}, errs.FromGithub(err, "error retrieving org information from Github")
 	}
-
-	return result
+	return result.Org{
+		Name:         gitOrg.GetLogin(),
+		ID:           gitOrg.GetID(),
+		PrivateRepos: gitOrg.GetTotalPrivateRepos(),
+		PublicRepos:  gitOrg.GetPublicRepos(),
+		CreatedAt:    gitOrg.GetCreatedAt(),
+		UpdatedAt:    gitOrg.GetUpdatedAt(),
+	}, nil
 }
 
-func ListOrgStats(org string) {
-	Client := githubLogin.CreateClient()
+func ListOrgStats(svc *service.Service, org string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
 	greenBold := color.New(color.FgGreen, color.Bold)
 
-	gitOrg, _, err := Client.Organizations.Get(context.Background(), org)
+	stats, err := GetOrgStats(svc, org)
 	if err != nil {
-		log.Fatalln("Error retrieving org information from Github:\n", err)
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(stats)
 	}
 
 	fmt.Println("")
 	whiteBold.Println("Organisation stats:")
 	greenBold.Print("Name ")
-	fmt.Println(gitOrg.GetLogin())
+	fmt.Println(stats.Name)
 	greenBold.Print("ID ")
-	fmt.Println(gitOrg.GetID())
+	fmt.Println(stats.ID)
 	greenBold.Print("Private repos ")
-	fmt.Println(gitOrg.GetTotalPrivateRepos())
+	fmt.Println(stats.PrivateRepos)
 	greenBold.Print("Public repos ")
-	fmt.Println(gitOrg.GetPublicRepos())
+	fmt.Println(stats.PublicRepos)
 	greenBold.Print("Time created ")
-	fmt.Println(gitOrg.GetCreatedAt())
+	fmt.Println(stats.CreatedAt)
 	greenBold.Print("Time last updated ")
-	fmt.Println(gitOrg.GetUpdatedAt())
+	fmt.Println(stats.UpdatedAt)
 	fmt.Println("")
+	return nil
 }
 
-func ListOrgRepos(org string) {
-	Client := githubLogin.CreateClient()
+func ListOrgRepos(svc *service.Service, org string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	PageCount := 1
-	NextPage := true
-	RepoCount := 1
+
+	repos, err := listTeam.ListRepos(svc, fmt.Sprintf("orgs/%v/repos", org))
+	if err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error retrieving repos for org '%v'", org)
+	}
+	if !output.IsText() {
+		return output.Print(repos)
+	}
 
 	fmt.Println("")
 	whiteBold.Printf("'%v' repositories: ", org)
 	fmt.Println("")
-	for NextPage == true {
-		url := fmt.Sprintf("https://github.dev.us-east-1.aws.galleon.c.statestr.com/api/v3/orgs/%v/repos?page=%v&per_page=100", org, PageCount)
+	listTeam.PrintRepos(repos)
+	return nil
+}
 
-		req, err := Client.NewRequest("GET", url, nil)
-		if err != nil {
-			log.Fatalln("Error creating HTTP request:\n", err)
-		}
+// GetOrgMembers gets the members of an organisation
+func GetOrgMembers(svc *service.Service, org string) ([]result.Member, error) {
+	var members []*github.User
+	if err := svc.List(fmt.Sprintf("orgs/%v/members", org), svc.Limit, &members); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting info about organisation")
+	}
 
-		// Create repo struct to save data of HTTP request
-		orgRepos := listTeam.Repos{}
-		_, err = Client.Do(context.Background(), req, &orgRepos)
+	orgMembers := []result.Member{}
+	for _, v := range members {
+		user, _, err := svc.Client.Users.Get(context.Background(), v.GetLogin())
 		if err != nil {
-			log.Fatalln("Error retrieving repos for team", err)
-		}
-
-		PageCount++
-
-		switch len(orgRepos) {
-		case 0:
-			NextPage = false
-		default:
-			for _, v := range orgRepos {
-				fmt.Printf("%-5v Name: %-40v | ID: %-15v | Updated at: %-40v | Private: %-20v", RepoCount, v.Name, v.ID, v.UpdatedAt, v.Private)
-				fmt.Println("")
-				RepoCount++
-			}
+			return nil, errs.FromGithub(err, "error getting info about organisation members")
 		}
+		orgMembers = append(orgMembers, result.Member{
+			Login:     v.GetLogin(),
+			ID:        v.GetID(),
+			Name:      user.GetName(),
+			SiteAdmin: v.GetSiteAdmin(),
+		})
 	}
+	return orgMembers, nil
 }
 
-func ListOrgMembers(org string) {
-	Client := githubLogin.CreateClient()
+func ListOrgMembers(svc *service.Service, org string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
 
-	members, _, err := Client.Organizations.ListMembers(context.Background(), org, nil)
+	members, err := GetOrgMembers(svc, org)
 	if err != nil {
-		log.Fatalln("Error getting info about organisation:", err)
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(members)
 	}
 
 	whiteBold.Println("Organisation members:")
-	for _, v := range members {
-		user, _, err := Client.Users.Get(context.Background(), v.GetLogin())
+	listTeam.PrintMembers(members)
+	return nil
+}
+
+// GetOrgTeams gets the teams of an organisation, sorted by name and cut to the limit of the service
+func GetOrgTeams(svc *service.Service, org string) ([]result.Team, error) {
+	teams, err := createUser.GetTeamsForOrg(svc, org)
+	if err != nil {
+		return nil, err
+	}
+
+	names := createUser.CreateTeamList(teams)
+	names = names[:service.Truncate(len(names), svc.Limit)]
+
+	orgTeams := []result.Team{}
+	for _, k := range names {
+		team, _, err := svc.Client.Teams.GetTeam(context.Background(), teams[k].ID)
 		if err != nil {
-			log.Fatalln("Error getting info about organisation members:", err)
+			return nil, errs.FromGithub(err, "error getting information about Github team '%v'", k)
 		}
-
-		fmt.Printf("Name: %-25v | Login: %-20v | ID: %-15v | Site admin: %-10v\n", user.GetName(), v.GetLogin(), v.GetID(), v.GetSiteAdmin())
+		orgTeams = append(orgTeams, listTeam.GetTeamInfo(team))
 	}
+	return orgTeams, nil
 }
 
-func ListOrgTeams(org string) {
+func ListOrgTeams(svc *service.Service, org string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	teams := createUser.GetTeamsForOrg(org)
-	Client := githubLogin.CreateClient()
+
+	teams, err := GetOrgTeams(svc, org)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(teams)
+	}
 
 	fmt.Println("")
 	whiteBold.Println("Teams:")
-	for k, v := range teams {
-		teamID := v.ID
-		team, _, err := Client.Teams.GetTeam(context.Background(), teamID)
-		if err != nil {
-			log.Fatalf("Error getting information about Github team '%v': %v", k, err)
-		}
-		fmt.Printf("Name: %-25v | ID: %-10v | Permission: %-10v | No of repos: %-10v | Description: %-35v \n", team.GetName(), team.GetID(), team.GetPermission(), team.GetReposCount(), team.GetDescription())
+	for _, team := range teams {
+		fmt.Printf("Name: %-25v | ID: %-10v | Permission: %-10v | No of repos: %-10v | Description: %-35v \n", team.Name, team.ID, team.Permission, team.Repos, team.Description)
 	}
 	fmt.Println("")
+	return nil
 }
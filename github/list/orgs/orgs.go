@@ -1,76 +1,115 @@
 package orgs
 
 import (
-createTeam "omniactl/github/create/team"
-createUser "omniactl/github/create/user"
-// createOrg "omniactl/github/create/org"
-githubLogin "omniactl/login/github"
-listOrg "omniactl/github/list/org"
-"github.com/manifoldco/promptui"
-"log"
-"os"
-"fmt"
-"github.com/fatih/color"
-// "errors"
-// "regexp"
-"context"
+	createTeam "omniactl/github/create/team"
+	createUser "omniactl/github/create/user"
+	// createOrg "omniactl/github/create/org"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+	listOrg "omniactl/github/list/org"
+	"omniactl/github/result"
+	"omniactl/github/service"
+	"omniactl/interactive"
+	"omniactl/output"
+	"sort"
+	// "errors"
+	// "regexp"
 )
 
 // ListOrgs lists all available Github orgs
-func ListOrgs() {
+func ListOrgs(svc *service.Service) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: List all Github organisations")
 
-	ListAllOrgsInfo()
+	if err := ListAllOrgsInfo(svc); err != nil {
+		return err
+	}
+	// Rendered results are not followed by prompts for more information
+	if !output.IsText() {
+		return nil
+	}
 	fmt.Println("")
-	result := PromptMoreInfo()
+	result, err := PromptMoreInfo()
+	if err != nil {
+		return err
+	}
 	switch result {
 	case "yes":
-		org := createTeam.PromptOrg()
-		result := listOrg.PromptAction()
+		org, err := createTeam.PromptOrg(svc)
+		if err != nil {
+			return err
+		}
+		result, err := listOrg.PromptAction()
+		if err != nil {
+			return err
+		}
 		switch result {
 		case "Organisation stats":
-			listOrg.ListOrgStats(org)
+			return listOrg.ListOrgStats(svc, org)
 		case "Organisation members":
-			listOrg.ListOrgMembers(org)
+			return listOrg.ListOrgMembers(svc, org)
 		case "Organisation repositories":
-			listOrg.ListOrgRepos(org)
+			return listOrg.ListOrgRepos(svc, org)
 		case "Organisation teams":
-			listOrg.ListOrgTeams(org)
+			return listOrg.ListOrgTeams(svc, org)
 		}
-	default:
-		os.Exit(0)
 	}
+	return nil
 }
 
 // ListAllOrgsInfo provides an overview over all available Github orgs
-func ListAllOrgsInfo() {
+func ListAllOrgsInfo(svc *service.Service) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	Client := githubLogin.CreateClient()
-	allOrgs := createUser.GetAllOrgs()
+	orgs, err := GetAllOrgsInfo(svc)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(orgs)
+	}
 
 	fmt.Println("")
 	whiteBold.Println("Github organisations:")
-	for k, v := range allOrgs {
-		org, _, err := Client.Organizations.Get(context.Background(), k)
+	for _, v := range orgs {
+		fmt.Printf("Name: %-25v | ID: %-15v | Private repos: %-12v | Public repos: %-15v", v.Name, v.ID, v.PrivateRepos, v.PublicRepos)
+		fmt.Println("")
+	}
+	return nil
+}
+
+// GetAllOrgsInfo gets the stats of all available Github orgs, sorted by name
+// and cut to the limit of the service
+func GetAllOrgsInfo(svc *service.Service) ([]result.Org, error) {
+	allOrgs, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for k := range allOrgs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	names = names[:service.Truncate(len(names), svc.Limit)]
+
+	orgs := []result.Org{}
+	for _, k := range names {
+		org, err := listOrg.GetOrgStats(svc, k)
 		if err != nil {
-			log.Fatalln("Error getting organisation information from Github:", err)
+			return nil, err
 		}
-		fmt.Printf("Name: %-25v | ID: %-15v | Private repos: %-12v | Public repos: %-15v", v.Name, v.ID, org.GetTotalPrivateRepos(), org.GetPublicRepos())
-		fmt.Println("")
+		orgs = append(orgs, org)
 	}
+	return orgs, nil
 }
 
 // PromptMoreInfo checks if user wants more detail about a given org
-func PromptMoreInfo() string {
+func PromptMoreInfo() (string, error) {
 	prompt := promptui.Select{
 		Label: "Get more information on one of the organisations?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
-}
\ No newline at end of file
+	return interactive.Select(prompt, "no")
+}
@@ -3,12 +3,16 @@ package team
 import (
 	"fmt"
 	"github.com/fatih/color"
+	"github.com/google/go-github/github"
 	"github.com/manifoldco/promptui"
-	"log"
+	"omniactl/errs"
 	createOrg "omniactl/github/create/org"
 	createTeam "omniactl/github/create/team"
 	createUser "omniactl/github/create/user"
-	githubLogin "omniactl/login/github"
+	"omniactl/github/result"
+	"omniactl/github/service"
+	"omniactl/interactive"
+	"omniactl/output"
 	"time"
 	// "github.com/fatih/color"
 	// "errors"
@@ -18,130 +22,177 @@ import (
 )
 
 // ListTeam receives flag input and shows structure of package
-func ListTeam(team string, org string) {
+func ListTeam(svc *service.Service, team string, org string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: List information about a Github team")
 
-	githubTeam := CheckFlag(team, org)
-	ListGithubTeam(githubTeam)
+	githubTeam, err := CheckFlag(svc, team, org)
+	if err != nil {
+		return err
+	}
+	return ListGithubTeam(svc, githubTeam)
 }
 
 // CheckFlag checks if input was put via flags, checks input or prompts user
-func CheckFlag(team string, org string) map[string]createUser.Team {
+func CheckFlag(svc *service.Service, team string, org string) (map[string]createUser.Team, error) {
 	greenBold := color.New(color.FgGreen, color.Bold)
 	red := color.New(color.FgRed)
-	githubTeam := make(map[string]createUser.Team)
 	if org != "" {
-		check := createOrg.CheckIfOrgExists(org)
+		check, err := createOrg.CheckIfOrgExists(svc, org)
+		if err != nil {
+			return nil, err
+		}
 		switch check {
 		case true:
 			greenBold.Print("Organisation ")
 			fmt.Println(org)
 			if team == "" {
-				githubTeam = PromptTeam(org)
-				return githubTeam
+				return PromptTeam(svc, org)
 			} else {
-				teamsForOrg := createUser.GetTeamsForOrg(org)
+				teamsForOrg, err := createUser.GetTeamsForOrg(svc, org)
+				if err != nil {
+					return nil, err
+				}
 				check := createTeam.CheckIfTeamExists(team, teamsForOrg)
 				switch check {
 				case true:
 					greenBold.Print("Team ")
 					fmt.Println(team)
-					githubTeam = CreateTeamMap(team, org)
-					return githubTeam
+					return CreateTeamMap(svc, team, org)
 				default:
 					red.Printf("Team '%v' does not exist.", team)
 					fmt.Println("")
-					githubTeam = PromptTeam(org)
-					return githubTeam
+					return PromptTeam(svc, org)
 				}
 			}
 		default:
 			red.Printf("Organisation '%v' does not exist.", org)
 			fmt.Println("")
-			org = createTeam.PromptOrg()
-			githubTeam = PromptTeam(org)
-			return githubTeam
 		}
-	} else {
-		org = createTeam.PromptOrg()
-		githubTeam = PromptTeam(org)
-		return githubTeam
 	}
+	org, err := createTeam.PromptOrg(svc)
+	if err != nil {
+		return nil, err
+	}
+	return PromptTeam(svc, org)
 }
 
 // ListGithubTeam prints out information on chosen github team
-func ListGithubTeam(githubTeam map[string]createUser.Team) {
+func ListGithubTeam(svc *service.Service, githubTeam map[string]createUser.Team) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
 	greenBold := color.New(color.FgGreen, color.Bold)
-	Client := githubLogin.CreateClient()
 
 	for k, v := range githubTeam {
 		teamID := v.ID
-		team, _, err := Client.Teams.GetTeam(context.Background(), teamID)
+		team, _, err := svc.Client.Teams.GetTeam(context.Background(), teamID)
 		if err != nil {
-			log.Fatalf("Error getting information about Github team '%v': %v", k, err)
+			return errs.FromGithub(err, "error getting information about Github team '%v'", k)
 		}
 
-		result := PromptAction()
+		action, err := PromptAction()
+		if err != nil {
+			return err
+		}
 
-		switch result {
+		switch action {
 		case "Team stats":
+			info := GetTeamInfo(team)
+			if !output.IsText() {
+				return output.Print(info)
+			}
 			fmt.Println("")
 			whiteBold.Println("Team stats:")
 			greenBold.Print("Name ")
-			fmt.Println(team.GetName())
+			fmt.Println(info.Name)
 			greenBold.Print("ID ")
-			fmt.Println(team.GetID())
+			fmt.Println(info.ID)
 			greenBold.Print("Description ")
-			fmt.Println(team.GetDescription())
+			fmt.Println(info.Description)
 			greenBold.Print("Organisation ")
-			fmt.Println(team.GetOrganization().GetLogin())
+			fmt.Println(info.Org)
 			greenBold.Print("Permission ")
-			fmt.Println(team.GetPermission())
+			fmt.Println(info.Permission)
 			greenBold.Print("Privacy ")
-			fmt.Println(team.GetPrivacy())
+			fmt.Println(info.Privacy)
 			greenBold.Print("No of repos ")
-			fmt.Println(team.GetReposCount())
+			fmt.Println(info.Repos)
 		case "Team repositories":
-			fmt.Println("")
-			whiteBold.Println("Team repositories:")
-			GetRepos(teamID)
+			if output.IsText() {
+				fmt.Println("")
+				whiteBold.Println("Team repositories:")
+			}
+			if err := GetRepos(svc, teamID); err != nil {
+				return err
+			}
 		case "Team members":
-			fmt.Println("")
-			whiteBold.Println("Team members:")
-			members, _, err := Client.Teams.ListTeamMembers(context.Background(), teamID, nil)
+			members, err := GetTeamMembers(svc, teamID)
 			if err != nil {
-				log.Fatalln("Error getting info about team members:", err)
+				return err
 			}
-
-			for _, v := range members {
-				user, _, err := Client.Users.Get(context.Background(), v.GetLogin())
-				if err != nil {
-					log.Fatalln("Error getting info about organisation members:", err)
-				}
-
-				fmt.Printf("Name: %-25v | Login: %-20v | ID: %-15v | Site admin: %-10v\n", user.GetName(), v.GetLogin(), v.GetID(), v.GetSiteAdmin())
+			if !output.IsText() {
+				return output.Print(members)
 			}
+			fmt.Println("")
+			whiteBold.Println("Team members:")
+			PrintMembers(members)
 		default:
-			log.Fatalln("Error selecting action.")
+			return errs.New(errs.Validation, "error selecting action: '%v'", action)
+		}
+	}
+	return nil
+}
+
+// GetTeamInfo returns the result struct describing a Github team
+func GetTeamInfo(team *github.Team) result.Team {
+	return result.Team{
+		Org:         team.GetOrganization().GetLogin(),
+		Name:        team.GetName(),
+		ID:          team.GetID(),
+		Description: team.GetDescription(),
+		Permission:  team.GetPermission(),
+		Privacy:     team.GetPrivacy(),
+		Repos:       team.GetReposCount(),
+	}
+}
+
+// GetTeamMembers gets the members of a team
+func GetTeamMembers(svc *service.Service, teamID int64) ([]result.Member, error) {
+	var members []*github.User
+	if err := svc.List(fmt.Sprintf("teams/%v/members", teamID), svc.Limit, &members); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting info about team members")
+	}
+
+	teamMembers := []result.Member{}
+	for _, v := range members {
+		user, _, err := svc.Client.Users.Get(context.Background(), v.GetLogin())
+		if err != nil {
+			return nil, errs.FromGithub(err, "error getting info about team members")
 		}
+		teamMembers = append(teamMembers, result.Member{
+			Login:     v.GetLogin(),
+			ID:        v.GetID(),
+			Name:      user.GetName(),
+			SiteAdmin: v.GetSiteAdmin(),
+		})
+	}
+	return teamMembers, nil
+}
+
+// PrintMembers prints organisation or team members as colourised text
+func PrintMembers(members []result.Member) {
+	for _, v := range members {
+		fmt.Printf("Name: %-25v | Login: %-20v | ID: %-15v | Site admin: %-10v\n", v.Name, v.Login, v.ID, v.SiteAdmin)
 	}
 }
 
 // PromptAction asks user to select which info they want about selected team
-func PromptAction() string {
+func PromptAction() (string, error) {
 	prompt := promptui.Select{
 		Label: "Select action",
 		Items: []string{"Team stats", "Team members", "Team repositories"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-
-	return result
+	return interactive.Select(prompt, "")
 }
 
 // Repos struct created to contain info on team repos received from HTTP request
@@ -252,45 +303,47 @@ type Repos []struct {
 }
 
 // GetRepos sends an HTTP request to get names and stats of team repos
-func GetRepos(teamID int64) {
-	Client := githubLogin.CreateClient()
-	PageCount := 1
-	NextPage := true
-	RepoCount := 1
-
-	for NextPage == true {
-		url := fmt.Sprintf("https://github.dev.us-east-1.aws.galleon.c.statestr.com/api/v3/teams/%v/repos?page=%v&per_page=100", teamID, PageCount)
-
-		req, err := Client.NewRequest("GET", url, nil)
-		if err != nil {
-			log.Fatalln("Error creating HTTP request:\n", err)
-		}
+func GetRepos(svc *service.Service, teamID int64) error {
+	repos, err := ListRepos(svc, fmt.Sprintf("teams/%v/repos", teamID))
+	if err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error retrieving repos for team")
+	}
+	if !output.IsText() {
+		return output.Print(repos)
+	}
+	PrintRepos(repos)
+	return nil
+}
 
-		// Create repo struct to save data of HTTP request
-		teamRepos := Repos{}
-		_, err = Client.Do(context.Background(), req, &teamRepos)
-		if err != nil {
-			log.Fatalln("Error retrieving repos for team", err)
-		}
+// ListRepos gets all pages, up to the service limit, of the repositories listed at the given path, e.g. "teams/1/repos"
+func ListRepos(svc *service.Service, path string) ([]result.Repo, error) {
+	// Create repo struct to save data of HTTP requests
+	allRepos := Repos{}
+	if err := svc.List(path, svc.Limit, &allRepos); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error retrieving repos")
+	}
 
-		PageCount++
+	repos := []result.Repo{}
+	for _, v := range allRepos {
+		repos = append(repos, result.Repo{Name: v.Name, ID: int64(v.ID), Private: v.Private, UpdatedAt: v.UpdatedAt})
+	}
+	return repos, nil
+}
 
-		switch len(teamRepos) {
-		case 0:
-			NextPage = false
-		default:
-			for _, v := range teamRepos {
-				fmt.Printf("%-5v Name: %-40v | ID: %-15v | Updated at: %-40v | Private: %-20v", RepoCount, v.Name, v.ID, v.UpdatedAt, v.Private)
-				fmt.Println("")
-				RepoCount++
-			}
-		}
+// PrintRepos prints repositories as colourised text
+func PrintRepos(repos []result.Repo) {
+	for i, v := range repos {
+		fmt.Printf("%-5v Name: %-40v | ID: %-15v | Updated at: %-40v | Private: %-20v", i+1, v.Name, v.ID, v.UpdatedAt, v.Private)
+		fmt.Println("")
 	}
 }
 
 // CreateTeamMap takes team name string and returns a map containing the team
-func CreateTeamMap(team string, org string) map[string]createUser.Team {
-	teamsForOrg := createUser.GetTeamsForOrg(org)
+func CreateTeamMap(svc *service.Service, team string, org string) (map[string]createUser.Team, error) {
+	teamsForOrg, err := createUser.GetTeamsForOrg(svc, org)
+	if err != nil {
+		return nil, err
+	}
 	githubTeam := make(map[string]createUser.Team)
 
 	// Checks if team name is a key in available teams,
@@ -299,14 +352,16 @@ func CreateTeamMap(team string, org string) map[string]createUser.Team {
 	if ok {
 		githubTeam[team] = value
 	}
-	return githubTeam
+	return githubTeam, nil
 }
 
 // PromptTeam prompts user to select team for which to provide info
-func PromptTeam(org string) map[string]createUser.Team {
+func PromptTeam(svc *service.Service, org string) (map[string]createUser.Team, error) {
 	// Creates list with available Team names for prompt
-	githubTeam := make(map[string]createUser.Team)
-	teamsForOrg := createUser.GetTeamsForOrg(org)
+	teamsForOrg, err := createUser.GetTeamsForOrg(svc, org)
+	if err != nil {
+		return nil, err
+	}
 
 	s := []string{}
 	for k := range teamsForOrg {
@@ -319,16 +374,19 @@ func PromptTeam(org string) map[string]createUser.Team {
 		Items: s,
 	}
 
-	_, team, err := prompt.Run()
+	team, err := interactive.Select(prompt, "")
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return nil, err
 	}
 
-	githubTeam = CreateTeamMap(team, org)
+	githubTeam, err := CreateTeamMap(svc, team, org)
+	if err != nil {
+		return nil, err
+	}
 
 	greenBold := color.New(color.FgGreen, color.Bold)
 	greenBold.Print("Team ")
 	fmt.Println(team)
 
-	return githubTeam
+	return githubTeam, nil
 }
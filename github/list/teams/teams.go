@@ -1,10 +1,13 @@
 package teams
 
 import (
-	createUser "omniactl/github/create/user"
 	createOrg "omniactl/github/create/org"
+	createUser "omniactl/github/create/user"
 	// githubLogin "omniactl/login/github"
 	createTeam "omniactl/github/create/team"
+	"omniactl/github/result"
+	"omniactl/github/service"
+	"omniactl/output"
 	// "github.com/manifoldco/promptui"
 	// "log"
 	"fmt"
@@ -12,69 +15,125 @@ import (
 	// "errors"
 	// "regexp"
 	// "context"
-	// "sort"
+	"sort"
 )
 
 // ListTeams lists all teams for all orgs or all teams for a specific org when flag is provided
-func ListTeams(org string) {
+func ListTeams(svc *service.Service, org string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: List all Github teams for all or selected organisations")
 
-	CheckFlag(org)
+	return CheckFlag(svc, org)
 }
 
-func CheckFlag(org string) {
+func CheckFlag(svc *service.Service, org string) error {
 	if org == "" {
-		ListAllTeams()
-	} else {
-		check := createOrg.CheckIfOrgExists(org)
-		switch check {
-		case true:
-			ListOrgTeams(org)
-		default:
-			org := createTeam.PromptOrg()
-			ListOrgTeams(org)
+		return ListAllTeams(svc)
+	}
+	check, err := createOrg.CheckIfOrgExists(svc, org)
+	if err != nil {
+		return err
+	}
+	switch check {
+	case true:
+		return ListOrgTeams(svc, org)
+	default:
+		org, err := createTeam.PromptOrg(svc)
+		if err != nil {
+			return err
 		}
+		return ListOrgTeams(svc, org)
 	}
 }
 
-func ListAllTeams() {
+func ListAllTeams(svc *service.Service) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	allOrgs := createUser.GetAllOrgs()
+	allOrgs, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		return err
+	}
 
-	for k, _ := range allOrgs {
-		teams := createUser.GetTeamsForOrg(k)
+	orgNames := []string{}
+	for k := range allOrgs {
+		orgNames = append(orgNames, k)
+	}
+	sort.Strings(orgNames)
+
+	allTeams := []result.Team{}
+	count := 0
+	for _, k := range orgNames {
+		if svc.Limit > 0 && count >= svc.Limit {
+			break
+		}
+		teams, err := GetTeams(svc, k)
+		if err != nil {
+			return err
+		}
+		if svc.Limit > 0 {
+			teams = teams[:service.Truncate(len(teams), svc.Limit-count)]
+		}
+		count += len(teams)
+		if !output.IsText() {
+			allTeams = append(allTeams, teams...)
+			continue
+		}
 		fmt.Println("")
 		whiteBold.Print("Organisation:")
 		fmt.Println("\t" + k)
 		whiteBold.Print("Teams:")
 		if len(teams) != 0 {
-			for kk := range teams {
-				fmt.Printf("\t\t%-30v", kk)
+			for _, team := range teams {
+				fmt.Printf("\t\t%-30v", team.Name)
 				fmt.Println("")
 			}
 		} else {
 			fmt.Print("\n")
 		}
 	}
+	if !output.IsText() {
+		return output.Print(allTeams)
+	}
+	return nil
 }
 
-
-func ListOrgTeams(org string) {
+func ListOrgTeams(svc *service.Service, org string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	teams := createUser.GetTeamsForOrg(org)
+	teams, err := GetTeams(svc, org)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(teams)
+	}
 
 	fmt.Println("")
 	whiteBold.Print("Organisation:")
 	fmt.Println("\t" + org)
 	whiteBold.Print("Teams:")
 	if len(teams) != 0 {
-		for kk := range teams {
-			fmt.Printf("\t\t%-30v", kk)
+		for _, team := range teams {
+			fmt.Printf("\t\t%-30v", team.Name)
 			fmt.Println("")
 		}
 	} else {
 		fmt.Print("\n")
 	}
 	fmt.Println("")
-}
\ No newline at end of file
+	return nil
+}
+
+// GetTeams gets the names and IDs of the teams in an org, sorted by name
+// and cut to the limit of the service
+func GetTeams(svc *service.Service, org string) ([]result.Team, error) {
+	teamsForOrg, err := createUser.GetTeamsForOrg(svc, org)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := []result.Team{}
+	for _, v := range teamsForOrg {
+		teams = append(teams, result.Team{Org: org, Name: v.Name, ID: v.ID})
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams[:service.Truncate(len(teams), svc.Limit)], nil
+}
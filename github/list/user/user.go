@@ -7,57 +7,78 @@ import (
 	"github.com/fatih/color"
 	"github.com/google/go-github/github"
 	"github.com/manifoldco/promptui"
-	"log"
+	"omniactl/errs"
 	createUser "omniactl/github/create/user"
-	githubLogin "omniactl/login/github"
+	"omniactl/github/result"
+	"omniactl/github/service"
+	"omniactl/interactive"
+	"omniactl/output"
+	"sort"
+	"strings"
 )
 
 var (
 	GreenBold *color.Color
 	WhiteBold *color.Color
 	Red       *color.Color
-	Client    *github.Client
 )
 
 func init() {
 	GreenBold = color.New(color.FgGreen, color.Bold)
 	WhiteBold = color.New(color.FgHiWhite, color.Bold)
 	Red = color.New(color.FgRed)
-	Client = githubLogin.CreateClient()
 }
 
 // ListUser gets information about user from Github
-func ListUser(username string) {
+func ListUser(svc *service.Service, username string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: List user information")
-	username = CheckUsername(username)
-	githubUser := GetGithubUser(username)
-	PrintUserInfo(githubUser)
+	username, err := CheckUsername(svc, username)
+	if err != nil {
+		return err
+	}
+	githubUser, err := GetGithubUser(svc, username)
+	if err != nil {
+		return err
+	}
+	info, err := GetUserInfo(svc, githubUser)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(info)
+	}
+	PrintUser(info)
+	return nil
 }
 
 // CheckUsername checks flag input and if none was set prompts user
-func CheckUsername(username string) string {
+func CheckUsername(svc *service.Service, username string) (string, error) {
 	if username != "" {
-		check := createUser.CheckIfUserExists(username)
+		check, err := createUser.CheckIfUserExists(svc, username)
+		if err != nil {
+			return "", err
+		}
 		if check == true {
-			return username
+			return username, nil
 		} else {
-			username = PromptUsername()
-			return username
+			return PromptUsername(svc)
 		}
 	} else {
-		username = PromptUsername()
-		return username
+		return PromptUsername(svc)
 	}
 }
 
 // PromptUsername asks to enter user to be listed, and checks they exist
-func PromptUsername() string {
+func PromptUsername(svc *service.Service) (string, error) {
 	validate := func(input string) error {
 		if input == "" {
 			return errors.New("Enter a user to be listed")
 		}
-		check := createUser.CheckIfUserExists(input)
+		check, err := createUser.CheckIfUserExists(svc, input)
+		if err != nil {
+			return err
+		}
 		switch check {
 		case true:
 			return nil
@@ -74,37 +95,39 @@ func PromptUsername() string {
 		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
 // GetGithubUser returns a github user when entering the username
-func GetGithubUser(username string) *github.User {
-	user, _, err := Client.Users.Get(context.Background(), username)
+func GetGithubUser(svc *service.Service, username string) (*github.User, error) {
+	user, _, err := svc.Client.Users.Get(context.Background(), username)
 	if err != nil {
-		log.Fatalln("Error getting stats about user:", err)
+		return nil, errs.FromGithub(err, "error getting stats about user '%v'", username)
 	}
-	return user
+	return user, nil
 }
 
 // GetOrgsForUser lists all orgs which the user is a member of
-func GetOrgsForUser(username string) map[string]createUser.Org {
-	allOrgs := createUser.GetAllOrgs()
+func GetOrgsForUser(svc *service.Service, username string) (map[string]createUser.Org, error) {
+	allOrgs, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		return nil, err
+	}
 	UserOrgs := make(map[string]createUser.Org)
 
 	for k, _ := range allOrgs {
-		isMember, _, err := Client.Organizations.IsMember(context.Background(), k, username)
+		isMember, _, err := svc.Client.Organizations.IsMember(context.Background(), k, username)
 		if err != nil {
-			log.Fatalln("Error listing orgs for user:", err)
+			return nil, errs.FromGithub(err, "error listing orgs for user '%v'", username)
 		}
 		switch isMember {
 		case true:
 			value, _ := allOrgs[k]
 			UserOrgs[k] = value
-			teams := GetTeamsForUser(username, k)
+			teams, err := GetTeamsForUser(svc, username, k)
+			if err != nil {
+				return nil, err
+			}
 			value.Teams = teams
 			UserOrgs[k] = value
 			continue
@@ -112,18 +135,21 @@ func GetOrgsForUser(username string) map[string]createUser.Org {
 			continue
 		}
 	}
-	return UserOrgs
+	return UserOrgs, nil
 }
 
 // GetTeamsForUser lists all teams which user is a member of in specific org
-func GetTeamsForUser(username string, org string) []createUser.Team {
-	allTeams := createUser.GetTeamsForOrg(org)
+func GetTeamsForUser(svc *service.Service, username string, org string) ([]createUser.Team, error) {
+	allTeams, err := createUser.GetTeamsForOrg(svc, org)
+	if err != nil {
+		return nil, err
+	}
 	var userTeams []createUser.Team
 
 	for _, v := range allTeams {
-		isMember, _, err := Client.Teams.IsTeamMember(context.Background(), v.ID, username)
+		isMember, _, err := svc.Client.Teams.IsTeamMember(context.Background(), v.ID, username)
 		if err != nil {
-			log.Fatalln("Error listing teams for user:", err)
+			return nil, errs.FromGithub(err, "error listing teams for user '%v'", username)
 		}
 		switch isMember {
 		case true:
@@ -133,36 +159,72 @@ func GetTeamsForUser(username string, org string) []createUser.Team {
 			continue
 		}
 	}
-	return userTeams
+	return userTeams, nil
+}
+
+// GetUserInfo gets the user's id, email, orgs, teams etc
+func GetUserInfo(svc *service.Service, user *github.User) (result.User, error) {
+	info := result.User{
+		Login:     user.GetLogin(),
+		ID:        user.GetID(),
+		Name:      user.GetName(),
+		Email:     user.GetEmail(),
+		SiteAdmin: user.GetSiteAdmin(),
+		UpdatedAt: user.GetUpdatedAt().Time,
+		CreatedAt: user.GetCreatedAt().Time,
+		Orgs:      []string{},
+		Teams:     []string{},
+	}
+	userOrgs, err := GetOrgsForUser(svc, user.GetLogin())
+	if err != nil {
+		return info, err
+	}
+	for k := range userOrgs {
+		info.Orgs = append(info.Orgs, k)
+	}
+	sort.Strings(info.Orgs)
+	for _, org := range info.Orgs {
+		for _, team := range userOrgs[org].Teams {
+			info.Teams = append(info.Teams, org+"/"+team.Name)
+		}
+	}
+	return info, nil
 }
 
-// PrintUserInfo gets user object from Github and gets their id, email, orgs etc
-func PrintUserInfo(user *github.User) {
-	var OrgCount int
+// PrintUserInfo gets user object from Github and prints their id, email, orgs etc
+func PrintUserInfo(svc *service.Service, user *github.User) error {
+	info, err := GetUserInfo(svc, user)
+	if err != nil {
+		return err
+	}
+	PrintUser(info)
+	return nil
+}
 
+// PrintUser prints the user's information as colourised text
+func PrintUser(info result.User) {
 	GreenBold.Print("Login ")
-	fmt.Println(user.GetLogin())
+	fmt.Println(info.Login)
 	GreenBold.Print("ID ")
-	fmt.Println(user.GetID())
+	fmt.Println(info.ID)
 	GreenBold.Print("Username ")
-	fmt.Println(user.GetName())
+	fmt.Println(info.Name)
 	GreenBold.Print("Email ")
-	fmt.Println(user.GetEmail())
+	fmt.Println(info.Email)
 	GreenBold.Print("Is admin ")
-	fmt.Println(user.GetSiteAdmin())
+	fmt.Println(info.SiteAdmin)
 	GreenBold.Print("Time last updated ")
-	fmt.Println(user.GetUpdatedAt())
+	fmt.Println(info.UpdatedAt)
 	GreenBold.Print("Time created ")
-	fmt.Println(user.GetCreatedAt())
-	// IsOrgMember(user.GetLogin())
-	userOrgs := GetOrgsForUser(user.GetLogin())
-	for _, v := range userOrgs {
-		OrgCount++
-		GreenBold.Print("Organisation ", OrgCount, " ")
-		fmt.Println(v.Name, " ")
-		for _, vv := range v.Teams {
-			GreenBold.Print("Team ")
-			fmt.Println(vv.Name, " ")
+	fmt.Println(info.CreatedAt)
+	for i, org := range info.Orgs {
+		GreenBold.Print("Organisation ", i+1, " ")
+		fmt.Println(org, " ")
+		for _, team := range info.Teams {
+			if strings.HasPrefix(team, org+"/") {
+				GreenBold.Print("Team ")
+				fmt.Println(strings.TrimPrefix(team, org+"/"), " ")
+			}
 		}
 	}
 }
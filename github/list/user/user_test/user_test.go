@@ -3,21 +3,62 @@ package user_test
 import (
 	"github.com/google/go-github/github"
 	"github.com/stretchr/testify/assert"
+	"omniactl/errs"
 	createUser "omniactl/github/create/user"
 	listUser "omniactl/github/list/user"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	"omniactl/interactive"
+	"os"
 	"testing"
 )
 
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package
+	svc *service.Service
+	// teamID is the ID of the team 'test_team' created on the fake server
+	teamID int64
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddUser("e666666", "test6@statestreet.com")
+	server.AddUser("e777777", "test7@statestreet.com")
+	server.AddOrg("testing")
+	server.AddOrg("other")
+	teamID = server.AddTeam("testing", "test_team").ID
+	server.AddTeam("testing", "other_team")
+	server.AddTeamMember(teamID, "e666666")
+	svc = service.New(server.Client())
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
 func TestCheckUsername(t *testing.T) {
+	interactive.Disabled = true
+	defer func() { interactive.Disabled = false }()
+
 	tests := []struct {
 		data   string
 		result string
+		valid  bool
 	}{
-		{"e666666", "e666666"},
-		{"fake_user", ""},
+		{"e666666", "e666666", true},
+		{"e777777", "e777777", true},
+		// An unknown user would be prompted for, which fails without a terminal
+		{"fake_user", "", false},
 	}
 	for _, test := range tests {
-		result := listUser.CheckUsername(test.data)
+		result, err := listUser.CheckUsername(svc, test.data)
+		if test.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.True(t, errs.Is(err, errs.Validation), "Expected '%v' error Got '%v'", errs.Validation, err)
+		}
 		assert.Equal(t, test.result, result)
 	}
 }
@@ -33,7 +74,8 @@ func TestGetGithubUser(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := listUser.GetGithubUser(test.data)
+		result, err := listUser.GetGithubUser(svc, test.data)
+		assert.NoError(t, err)
 		assert.IsType(t, test.result, result)
 		assert.Equal(t, test.data, result.GetLogin())
 	}
@@ -52,8 +94,10 @@ func TestGetOrgsForUser(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := listUser.GetOrgsForUser(test.username)
+		result, err := listUser.GetOrgsForUser(svc, test.username)
+		assert.NoError(t, err)
 		assert.IsType(t, test.result, result)
+		assert.Len(t, result, 1)
 		for _, v := range result {
 			assert.Equal(t, test.org, v.Name)
 			for _, vv := range v.Teams {
@@ -73,12 +117,14 @@ func TestGetTeamsForUser(t *testing.T) {
 		id       int64
 		result   []createUser.Team
 	}{
-		{"e666666", "testing", "test_team", 90, teamMap},
+		{"e666666", "testing", "test_team", teamID, teamMap},
 	}
 
 	for _, test := range tests {
-		result := listUser.GetTeamsForUser(test.username, test.org)
+		result, err := listUser.GetTeamsForUser(svc, test.username, test.org)
+		assert.NoError(t, err)
 		assert.IsType(t, test.result, result)
+		assert.Len(t, result, 1)
 		for _, v := range result {
 			assert.Equal(t, test.team, v.Name)
 			assert.Equal(t, test.id, v.ID)
@@ -3,58 +3,92 @@ package users
 import (
 	"fmt"
 	"github.com/fatih/color"
-	"github.com/google/go-github/github"
 	"github.com/manifoldco/promptui"
-	"log"
-	createUser "omniactl/github/create/user"
 	listUser "omniactl/github/list/user"
+	"omniactl/github/result"
+	"omniactl/github/service"
+	"omniactl/interactive"
+	"omniactl/output"
 )
 
-// Client which interacts with Github API
-var Client *github.Client
-
 // ListUsers lists information about multiple users
-func ListUsers(usernames []string) {
+func ListUsers(svc *service.Service, usernames []string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: List information for multiple users")
-	var username string
-	switch len(usernames) {
-	case 0:
-		username := createUser.GetUsername(username)
-		githubUser := listUser.GetGithubUser(username)
-		listUser.PrintUserInfo(githubUser)
-		fmt.Println("")
-		PromptAnotherUser()
-	default:
-		for _, username := range usernames {
-			username = createUser.GetUsername(username)
-			githubUser := listUser.GetGithubUser(username)
-			listUser.PrintUserInfo(githubUser)
-			fmt.Println("")
+	if len(usernames) == 0 {
+		// When rendering results, only the prompted user is listed
+		if !output.IsText() {
+			info, err := GetUser(svc, "")
+			if err != nil {
+				return err
+			}
+			return output.Print([]result.User{info})
+		}
+		if err := ListOneUser(svc, ""); err != nil {
+			return err
+		}
+		return PromptAnotherUser(svc)
+	}
+
+	var infos []result.User
+	for _, username := range usernames {
+		info, err := GetUser(svc, username)
+		if err != nil {
+			return err
 		}
+		infos = append(infos, info)
 	}
+	if !output.IsText() {
+		return output.Print(infos)
+	}
+	for _, info := range infos {
+		listUser.PrintUser(info)
+		fmt.Println("")
+	}
+	return nil
+}
+
+// GetUser checks the username, prompting for it if necessary, and gets information about the user
+func GetUser(svc *service.Service, username string) (result.User, error) {
+	username, err := listUser.CheckUsername(svc, username)
+	if err != nil {
+		return result.User{}, err
+	}
+	githubUser, err := listUser.GetGithubUser(svc, username)
+	if err != nil {
+		return result.User{}, err
+	}
+	return listUser.GetUserInfo(svc, githubUser)
+}
+
+// ListOneUser checks the username, prompting for it if necessary, and prints information about the user
+func ListOneUser(svc *service.Service, username string) error {
+	info, err := GetUser(svc, username)
+	if err != nil {
+		return err
+	}
+	listUser.PrintUser(info)
+	fmt.Println("")
+	return nil
 }
 
 // PromptAnotherUser asks if info about another user should be fetched
-func PromptAnotherUser() {
-	var username string
+func PromptAnotherUser(svc *service.Service) error {
 	prompt := promptui.Select{
 		Label: "List another user?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
+	answer, err := interactive.Select(prompt, "no")
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return err
 	}
 
-	if result == "yes" {
-		username := createUser.GetUsername(username)
-		githubUser := listUser.GetGithubUser(username)
-		listUser.PrintUserInfo(githubUser)
-		fmt.Println("")
-		PromptAnotherUser()
-	} else if result == "no" {
-		return
+	if answer == "yes" {
+		if err := ListOneUser(svc, ""); err != nil {
+			return err
+		}
+		return PromptAnotherUser(svc)
 	}
+	return nil
 }
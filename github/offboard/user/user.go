@@ -0,0 +1,469 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	listUser "omniactl/github/list/user"
+	"omniactl/github/service"
+	"omniactl/github/snapshot"
+	suspendUser "omniactl/github/suspend/user"
+	"omniactl/interactive"
+	"omniactl/login/credentials"
+	"omniactl/output"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/github"
+	"github.com/manifoldco/promptui"
+	"golang.org/x/oauth2"
+)
+
+// Statuses of an offboarding step
+const (
+	StatusDone   = "done"
+	StatusFailed = "failed"
+)
+
+// Step is an action taken during offboarding and its outcome
+type Step struct {
+	Action string `json:"action" yaml:"action"`
+	Target string `json:"target" yaml:"target"`
+	Status string `json:"status" yaml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Report records the access a user had before offboarding and every step taken.
+// It is signed with an HMAC-SHA256 of its JSON encoding without the signature,
+// so that it can be kept as evidence for audits.
+type Report struct {
+	Username   string                `json:"username" yaml:"username"`
+	Reason     string                `json:"reason" yaml:"reason"`
+	Operator   string                `json:"operator" yaml:"operator"`
+	StartedAt  time.Time             `json:"started_at" yaml:"started_at"`
+	FinishedAt time.Time             `json:"finished_at" yaml:"finished_at"`
+	Orgs       []snapshot.Membership `json:"orgs" yaml:"orgs"`
+	Repos      []string              `json:"repos" yaml:"repos"`
+	Keys       []int64               `json:"keys" yaml:"keys"`
+	Steps      []Step                `json:"steps" yaml:"steps"`
+	// DryRun is set if the steps were printed with --dry-run instead of sent
+	DryRun    bool   `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	Signature string `json:"signature" yaml:"signature"`
+}
+
+// OffboardUser lists everything a user has access to, then transfers the repositories
+// they own to transferTo or archives them, removes them from their teams and orgs,
+// deletes their SSH keys and suspends their account, which also stops their personal
+// access tokens from authenticating.
+// A snapshot of their memberships is saved so that they can be restored by unsuspending them.
+// The signed report is written to reportFile, or to a file named after the user
+// and the time of offboarding if reportFile is empty.
+func OffboardUser(svc *service.Service, username string, reason string, transferTo string, reportFile string, key []byte) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Offboard user from Github")
+	if len(key) == 0 {
+		return errs.New(errs.Config, "a signing key is required for the offboarding report")
+	}
+	username, err := listUser.CheckUsername(svc, username)
+	if err != nil {
+		return err
+	}
+	reason, err = suspendUser.CheckReason(reason)
+	if err != nil {
+		return err
+	}
+
+	operator, _, err := svc.Client.Users.Get(context.Background(), "")
+	if err != nil {
+		return errs.FromGithub(err, "error getting the authenticated user")
+	}
+	report := &Report{
+		Username:  username,
+		Reason:    reason,
+		Operator:  operator.GetLogin(),
+		StartedAt: time.Now().UTC(),
+		DryRun:    dryrun.Enabled,
+	}
+	if err := GetAccess(svc, report); err != nil {
+		return err
+	}
+	if output.IsText() {
+		PrintAccess(report)
+	}
+
+	check, err := PromptOffboard(username)
+	if err != nil {
+		return err
+	}
+	if check != "yes" {
+		return nil
+	}
+	if err := GetPrivateRepos(svc, report); err != nil {
+		return err
+	}
+	if len(report.Repos) != 0 && transferTo == "" {
+		transferTo, err = PromptTransfer()
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := snapshot.Save(&snapshot.Snapshot{Username: username, Reason: reason, TakenAt: report.StartedAt, Orgs: report.Orgs}); err != nil {
+		return err
+	}
+	RevokeAccess(svc, report, transferTo)
+	report.FinishedAt = time.Now().UTC()
+	if err := Sign(report, key); err != nil {
+		return err
+	}
+	if reportFile == "" {
+		reportFile = fmt.Sprintf("offboard-%v-%v.json", username, report.StartedAt.Format("20060102T150405Z"))
+	}
+	if err := WriteReport(report, reportFile); err != nil {
+		return err
+	}
+
+	if !output.IsText() {
+		if err := output.Print(*report); err != nil {
+			return err
+		}
+	} else {
+		PrintSteps(report)
+		fmt.Println("")
+		color.New(color.FgHiWhite, color.Bold).Printf("Signed offboarding report written to '%v'.\n", reportFile)
+	}
+
+	failed := 0
+	for _, step := range report.Steps {
+		if step.Status == StatusFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errs.New(errs.Internal, "%v of %v offboarding steps for '%v' failed, see '%v'", failed, len(report.Steps), username, reportFile)
+	}
+	return nil
+}
+
+// SigningKey retrieves the key offboarding reports are signed with from the
+// credential provider, i.e. the signing_key secret of 'audit'
+func SigningKey() ([]byte, error) {
+	provider, err := credentials.NewProvider()
+	if err != nil {
+		return nil, errs.Wrap(errs.Config, err, "failure retrieving signing key")
+	}
+	secrets, err := provider.GetSecrets("audit")
+	if err != nil {
+		return nil, errs.Wrap(errs.Config, err, "failure retrieving signing key")
+	}
+	key := secrets["signing_key"]
+	if key == "" {
+		return nil, errs.New(errs.Config, "no signing_key found in the audit credentials")
+	}
+	return []byte(key), nil
+}
+
+// GetAccess records the orgs and teams the user belongs to with their roles,
+// the public repositories they own and their SSH keys in the report. Github only
+// lists the public repositories of other users, even to site admins; the private
+// and internal ones are added by GetPrivateRepos once offboarding is confirmed.
+func GetAccess(svc *service.Service, report *Report) error {
+	username := report.Username
+
+	memberships, err := snapshot.Take(svc, username, report.Reason)
+	if err != nil {
+		return err
+	}
+	report.Orgs = memberships.Orgs
+
+	var repos []*github.Repository
+	if err := svc.List(fmt.Sprintf("users/%v/repos?type=owner", username), 0, &repos); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error listing repos owned by '%v'", username)
+	}
+	report.Repos = []string{}
+	for _, v := range repos {
+		report.Repos = append(report.Repos, v.GetFullName())
+	}
+
+	var keys []*github.Key
+	if err := svc.List(fmt.Sprintf("users/%v/keys", username), 0, &keys); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error listing SSH keys of '%v'", username)
+	}
+	report.Keys = []int64{}
+	for _, v := range keys {
+		report.Keys = append(report.Keys, v.GetID())
+	}
+	return nil
+}
+
+// GetPrivateRepos replaces the repositories in the report with all repositories the
+// user owns, including private and internal ones. They are listed as the user with an
+// impersonation token, which is deleted straight after; if it cannot be deleted, a
+// failed step is recorded in the report. With --dry-run no token is created and only
+// the public repositories are kept.
+func GetPrivateRepos(svc *service.Service, report *Report) error {
+	username := report.Username
+	if dryrun.Enabled {
+		if output.IsText() {
+			color.New(color.FgYellow, color.Bold).Println("No impersonation token is created in a dry run, so only the public repos of the user are listed")
+		}
+		return nil
+	}
+	token, err := impersonate(svc, username)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("admin/users/%v/authorizations", username)
+	defer func() {
+		if err := deleteAdmin(svc, url); err != nil {
+			report.record("delete impersonation token", username, err)
+		}
+	}()
+
+	tc := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	client, err := github.NewEnterpriseClient(svc.Client.BaseURL.String(), svc.Client.UploadURL.String(), tc)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "creation of impersonated Github client failed")
+	}
+	var repos []*github.Repository
+	if err := service.New(client).List("user/repos?affiliation=owner&visibility=all", 0, &repos); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error listing repos owned by '%v'", username)
+	}
+
+	public := make(map[string]bool)
+	for _, repo := range report.Repos {
+		public[repo] = true
+	}
+	report.Repos = []string{}
+	for _, v := range repos {
+		report.Repos = append(report.Repos, v.GetFullName())
+		if !public[v.GetFullName()] && output.IsText() {
+			color.New(color.FgGreen, color.Bold).Print("Owned private repo ")
+			fmt.Println(v.GetFullName())
+		}
+	}
+	return nil
+}
+
+// impersonate creates an impersonation token of the user, which can read their
+// repositories
+func impersonate(svc *service.Service, username string) (string, error) {
+	url := fmt.Sprintf("admin/users/%v/authorizations", username)
+	req, err := svc.Client.NewRequest("POST", url, map[string][]string{"scopes": {"repo"}})
+	if err != nil {
+		return "", errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	authorization := &github.Authorization{}
+	if _, err := svc.Client.Do(context.Background(), req, authorization); err != nil {
+		return "", errs.FromGithub(err, "error creating an impersonation token of '%v'", username)
+	}
+	if authorization.GetToken() == "" {
+		return "", errs.New(errs.Internal, "no impersonation token of '%v' was returned", username)
+	}
+	return authorization.GetToken(), nil
+}
+
+// PrintAccess prints everything the user has access to
+func PrintAccess(report *Report) {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	fmt.Println("")
+	for _, m := range report.Orgs {
+		greenBold.Print("Organisation ")
+		fmt.Printf("%v (%v)\n", m.Org, m.Role)
+		for _, team := range m.Teams {
+			greenBold.Print("Team ")
+			fmt.Printf("%v (%v)\n", team.Name, team.Role)
+		}
+	}
+	for _, repo := range report.Repos {
+		greenBold.Print("Owned repo ")
+		fmt.Println(repo)
+	}
+	if !report.DryRun {
+		fmt.Println("Private repos are listed once offboarding is confirmed.")
+	}
+	greenBold.Print("SSH keys ")
+	fmt.Println(len(report.Keys))
+	fmt.Println("")
+}
+
+// PromptTransfer asks whether the user's repositories are archived or transferred,
+// returning the new owner or an empty string to archive them
+func PromptTransfer() (string, error) {
+	prompt := promptui.Select{
+		Label: "Archive or transfer the repos owned by the user?",
+		Items: []string{"archive", "transfer"},
+	}
+	action, err := interactive.Select(prompt, "archive")
+	if err != nil || action == "archive" {
+		return "", err
+	}
+
+	templates := &promptui.PromptTemplates{
+		Success: "{{ . | green | bold }} ",
+	}
+	ownerPrompt := promptui.Prompt{
+		Label:     "New owner of the repos (user or organisation)",
+		Templates: templates,
+	}
+	return interactive.Prompt(ownerPrompt)
+}
+
+// PromptOffboard asks for confirmation before revoking the user's access
+func PromptOffboard(username string) (string, error) {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Offboard '%v'? This removes all of their access and suspends the account", username),
+		Items: []string{"yes", "no"},
+	}
+	return interactive.Select(prompt, "yes")
+}
+
+// RevokeAccess transfers or archives the user's repositories, removes them from their
+// teams and orgs, deletes their SSH keys and suspends them, recording each step in the
+// report. Failed steps do not stop the remaining steps. Github has no API deleting the
+// personal access tokens of another user, but those of a suspended user are refused.
+func RevokeAccess(svc *service.Service, report *Report, transferTo string) {
+	ctx := context.Background()
+	username := report.Username
+	record := report.record
+
+	for _, repo := range report.Repos {
+		owner, name := splitFullName(repo)
+		if transferTo != "" {
+			_, _, err := svc.Client.Repositories.Transfer(ctx, owner, name, github.TransferRequest{NewOwner: transferTo})
+			// Github accepts transfers asynchronously
+			if _, ok := err.(*github.AcceptedError); ok {
+				err = nil
+			}
+			record("transfer repo to "+transferTo, repo, errs.FromGithub(err, "error transferring repo"))
+			continue
+		}
+		_, _, err := svc.Client.Repositories.Edit(ctx, owner, name, &github.Repository{Archived: github.Bool(true)})
+		record("archive repo", repo, errs.FromGithub(err, "error archiving repo"))
+	}
+
+	for _, m := range report.Orgs {
+		for _, team := range m.Teams {
+			_, err := svc.Client.Teams.RemoveTeamMembership(ctx, team.ID, username)
+			record("remove from team", m.Org+"/"+team.Name, errs.FromGithub(err, "error removing user from team"))
+		}
+		_, err := svc.Client.Organizations.RemoveOrgMembership(ctx, username, m.Org)
+		record("remove from org", m.Org, errs.FromGithub(err, "error removing user from organisation"))
+	}
+
+	for _, id := range report.Keys {
+		record("delete SSH key", fmt.Sprint(id), deleteAdmin(svc, fmt.Sprintf("admin/keys/%v", id)))
+	}
+	record("suspend, blocking all tokens", username, suspendUser.SuspendFromGithub(svc, username, report.Reason))
+}
+
+// record adds a step to the report, which failed if err is set
+func (report *Report) record(action string, target string, err error) {
+	step := Step{Action: action, Target: target, Status: StatusDone}
+	if err != nil {
+		step.Status = StatusFailed
+		step.Error = err.Error()
+	}
+	report.Steps = append(report.Steps, step)
+}
+
+// deleteAdmin sends a DELETE request to a Github Enterprise admin endpoint
+func deleteAdmin(svc *service.Service, url string) error {
+	req, err := svc.Client.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	_, err = svc.Client.Do(context.Background(), req, nil)
+	return errs.FromGithub(err, "error sending request to '%v'", url)
+}
+
+// splitFullName splits the full name of a repo into its owner and name
+func splitFullName(fullName string) (string, string) {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 {
+		return fullName, ""
+	}
+	return parts[0], parts[1]
+}
+
+// PrintSteps prints the outcome of every offboarding step
+func PrintSteps(report *Report) {
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	greenBold := color.New(color.FgGreen, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+	fmt.Println("")
+	whiteBold.Println("Offboarding steps:")
+	for _, step := range report.Steps {
+		if step.Status == StatusDone {
+			greenBold.Printf("%-8v", step.Status)
+			fmt.Printf("%v '%v'\n", step.Action, step.Target)
+		} else {
+			red.Printf("%-8v", step.Status)
+			fmt.Printf("%v '%v': %v\n", step.Action, step.Target, step.Error)
+		}
+	}
+}
+
+// Sign sets the signature of the report to the hex encoded HMAC-SHA256 of its
+// JSON encoding with an empty signature
+func Sign(report *Report, key []byte) error {
+	signature, err := signature(report, key)
+	if err != nil {
+		return err
+	}
+	report.Signature = signature
+	return nil
+}
+
+// Verify reports whether the signature of the report matches its content
+func Verify(report *Report, key []byte) bool {
+	expected, err := signature(report, key)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(report.Signature))
+}
+
+func signature(report *Report, key []byte) (string, error) {
+	unsigned := *report
+	unsigned.Signature = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", errs.Wrap(errs.Internal, err, "error encoding offboarding report")
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// WriteReport writes the report to file as indented JSON
+func WriteReport(report *Report, file string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error encoding offboarding report")
+	}
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		return errs.Wrap(errs.Internal, err, "error writing offboarding report")
+	}
+	return nil
+}
+
+// ReadReport reads a report written by WriteReport
+func ReadReport(file string) (*Report, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error reading offboarding report")
+	}
+	report := &Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error parsing offboarding report '%v'", file)
+	}
+	return report, nil
+}
@@ -0,0 +1,173 @@
+package user_test
+
+import (
+	"io/ioutil"
+	"omniactl/errs"
+	"omniactl/github/fake"
+	offboardUser "omniactl/github/offboard/user"
+	"omniactl/github/service"
+	"omniactl/github/snapshot"
+	"omniactl/interactive"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package
+	svc *service.Service
+	// dir holds the reports written by the tests
+	dir string
+	// key signs the reports written by the tests
+	key = []byte("test-signing-key")
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddOrg("testing")
+	server.AddOrg("archive")
+	svc = service.New(server.Client())
+	interactive.Disabled = true
+
+	var err error
+	dir, err = ioutil.TempDir("", "offboard_test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(snapshot.EnvDir, dir)
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// addLeaver adds a user who belongs to an org and team, owns a public and a private repo
+// and has SSH keys and a token
+func addLeaver(login string) {
+	server.AddUser(login, login+"@statestreet.com")
+	server.AddOrgMember("testing", login, "admin")
+	team := server.AddTeam("testing", login+"_team")
+	server.AddTeamMember(team.ID, login)
+	server.AddRepo(login, "tools")
+	server.AddRepo(login, "secrets").Private = true
+	server.AddKey(login, "ssh-rsa AAAA1")
+	server.AddKey(login, "ssh-rsa AAAA2")
+	server.AddToken(login)
+}
+
+func TestOffboardUser(t *testing.T) {
+	addLeaver("e700001")
+	reportFile := filepath.Join(dir, "e700001.json")
+
+	err := offboardUser.OffboardUser(svc, "e700001", "left the company", "", reportFile, key)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	user := server.User("e700001")
+	if !user.Suspended || user.SuspendedReason != "left the company" {
+		t.Errorf("User was not suspended with the reason: %+v", user)
+	}
+	if len(user.Keys) != 0 {
+		t.Errorf("Expected SSH keys to be deleted Got %v keys", len(user.Keys))
+	}
+	if user.Impersonation != "" || user.Impersonations != 1 {
+		t.Errorf("Expected one impersonation token, deleted after listing the user's repos Got %v, '%v'", user.Impersonations, user.Impersonation)
+	}
+	if _, ok := server.Org("testing").Members["e700001"]; ok {
+		t.Error("User was not removed from organisation 'testing'")
+	}
+	if _, ok := server.Team("testing", "e700001_team").Members["e700001"]; ok {
+		t.Error("User was not removed from team 'e700001_team'")
+	}
+	for _, name := range []string{"e700001/tools", "e700001/secrets"} {
+		if repo := server.Repo(name); repo == nil || !repo.Archived {
+			t.Errorf("Repo '%v' was not archived", name)
+		}
+	}
+
+	saved, err := snapshot.Load("e700001")
+	if err != nil || len(saved.Orgs) != 1 || saved.Orgs[0].Org != "testing" {
+		t.Errorf("Snapshot of memberships was not saved: %+v %v", saved, err)
+	}
+
+	report, err := offboardUser.ReadReport(reportFile)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !offboardUser.Verify(report, key) {
+		t.Error("Signature of the report does not match its content")
+	}
+	if len(report.Orgs) != 1 || report.Orgs[0].Role != "admin" || len(report.Orgs[0].Teams) != 1 {
+		t.Errorf("Access before offboarding was not recorded: %+v", report.Orgs)
+	}
+	if report.Operator != fake.AdminLogin || len(report.Repos) != 2 || len(report.Keys) != 2 || len(report.Steps) != 7 {
+		t.Errorf("Report is incomplete: %+v", report)
+	}
+	for _, step := range report.Steps {
+		if step.Status != offboardUser.StatusDone {
+			t.Errorf("Step '%v %v' failed: %v", step.Action, step.Target, step.Error)
+		}
+	}
+
+	report.Reason = "tampered"
+	if offboardUser.Verify(report, key) {
+		t.Error("Signature matches a tampered report")
+	}
+}
+
+func TestOffboardUserTransfer(t *testing.T) {
+	addLeaver("e700002")
+	reportFile := filepath.Join(dir, "e700002.json")
+
+	err := offboardUser.OffboardUser(svc, "e700002", "moved teams", "archive", reportFile, key)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for _, name := range []string{"tools", "secrets"} {
+		if server.Repo("archive/"+name) == nil || server.Repo("e700002/"+name) != nil {
+			t.Errorf("Repo 'e700002/%v' was not transferred to 'archive'", name)
+		}
+	}
+}
+
+func TestGetAccessCreatesNoToken(t *testing.T) {
+	addLeaver("e700003")
+	report := &offboardUser.Report{Username: "e700003", Reason: "not confirmed yet"}
+
+	// Before offboarding is confirmed, only the public repos are listed
+	if err := offboardUser.GetAccess(svc, report); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(report.Repos) != 1 || report.Repos[0] != "e700003/tools" {
+		t.Errorf("Expected only the public repo Got %v", report.Repos)
+	}
+	if user := server.User("e700003"); user.Impersonations != 0 {
+		t.Errorf("Expected no impersonation token before confirmation Got %v", user.Impersonations)
+	}
+
+	if err := offboardUser.GetPrivateRepos(svc, report); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(report.Repos) != 2 {
+		t.Errorf("Expected the public and the private repo Got %v", report.Repos)
+	}
+	if user := server.User("e700003"); user.Impersonation != "" {
+		t.Error("The impersonation token was not deleted")
+	}
+}
+
+func TestOffboardUserErrors(t *testing.T) {
+	err := offboardUser.OffboardUser(svc, "e700001", "no key", "", "", nil)
+	if !errs.Is(err, errs.Config) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Config, err)
+	}
+
+	err = offboardUser.OffboardUser(svc, "e799999", "unknown", "", filepath.Join(dir, "unknown.json"), key)
+	if err == nil {
+		t.Error("Expected an error for an unknown user")
+	}
+}
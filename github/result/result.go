@@ -0,0 +1,57 @@
+// Package result holds the structs returned by the github list commands.
+// They are rendered by the output package in the format selected through
+// the global --output flag.
+package result
+
+import "time"
+
+// User describes a Github user with the organisations and teams they belong to.
+// Teams are listed as 'org/team'.
+type User struct {
+	Login     string    `json:"login" yaml:"login"`
+	ID        int64     `json:"id" yaml:"id"`
+	Name      string    `json:"name" yaml:"name"`
+	Email     string    `json:"email" yaml:"email"`
+	SiteAdmin bool      `json:"site_admin" yaml:"site_admin"`
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	Orgs      []string  `json:"orgs" yaml:"orgs"`
+	Teams     []string  `json:"teams" yaml:"teams"`
+}
+
+// Member describes a member of a Github organisation or team
+type Member struct {
+	Login     string `json:"login" yaml:"login"`
+	ID        int64  `json:"id" yaml:"id"`
+	Name      string `json:"name" yaml:"name"`
+	SiteAdmin bool   `json:"site_admin" yaml:"site_admin"`
+}
+
+// Org describes a Github organisation
+type Org struct {
+	Name         string    `json:"name" yaml:"name"`
+	ID           int64     `json:"id" yaml:"id"`
+	PrivateRepos int       `json:"private_repos" yaml:"private_repos"`
+	PublicRepos  int       `json:"public_repos" yaml:"public_repos"`
+	CreatedAt    time.Time `json:"created_at" yaml:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" yaml:"updated_at"`
+}
+
+// Team describes a Github team
+type Team struct {
+	Org         string `json:"org" yaml:"org"`
+	Name        string `json:"name" yaml:"name"`
+	ID          int64  `json:"id" yaml:"id"`
+	Description string `json:"description" yaml:"description"`
+	Permission  string `json:"permission" yaml:"permission"`
+	Privacy     string `json:"privacy" yaml:"privacy"`
+	Repos       int    `json:"repos" yaml:"repos"`
+}
+
+// Repo describes a Github repository
+type Repo struct {
+	Name      string    `json:"name" yaml:"name"`
+	ID        int64     `json:"id" yaml:"id"`
+	Private   bool      `json:"private" yaml:"private"`
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
+}
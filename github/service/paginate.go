@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"omniactl/errs"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PerPage is the number of items requested per page from Github list endpoints
+const PerPage = 100
+
+// List gets every page of the list endpoint at path, e.g. "orgs/MSF/members",
+// and appends the decoded items to the slice pointed to by v.
+// The pages are followed through the rel="next" links of Github's Link header,
+// which works for endpoints paginated by page number as well as by 'since'.
+// If limit is greater than 0, no more than limit items are appended.
+func (s *Service) List(path string, limit int, v interface{}) error {
+	items := reflect.ValueOf(v)
+	if items.Kind() != reflect.Ptr || items.Elem().Kind() != reflect.Slice {
+		return errs.New(errs.Internal, "cannot list '%v' into %T, a pointer to a slice is required", path, v)
+	}
+	items = items.Elem()
+
+	next, err := withPerPage(path)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error parsing path '%v'", path)
+	}
+	for next != "" {
+		req, err := s.Client.NewRequest("GET", next, nil)
+		if err != nil {
+			return errs.Wrap(errs.Internal, err, "error creating HTTP request")
+		}
+		page := reflect.New(items.Type())
+		resp, err := s.Client.Do(context.Background(), req, page.Interface())
+		if err != nil {
+			return errs.FromGithub(err, "error listing '%v'", path)
+		}
+		items.Set(reflect.AppendSlice(items, page.Elem()))
+
+		if limit > 0 && items.Len() >= limit {
+			items.Set(items.Slice(0, limit))
+			return nil
+		}
+		next = NextLink(resp.Header.Get("Link"))
+	}
+	return nil
+}
+
+// withPerPage adds the per_page query parameter to path unless it is already set
+func withPerPage(path string) (string, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if q.Get("per_page") == "" {
+		q.Set("per_page", strconv.Itoa(PerPage))
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
+
+// NextLink returns the URL of the rel="next" link in a Link header,
+// or an empty string if there is no next page
+func NextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		segments := strings.Split(strings.TrimSpace(link), ";")
+		if len(segments) < 2 {
+			continue
+		}
+		href := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(href, "<") || !strings.HasSuffix(href, ">") {
+			continue
+		}
+		for _, segment := range segments[1:] {
+			if strings.TrimSpace(segment) == `rel="next"` {
+				return href[1 : len(href)-1]
+			}
+		}
+	}
+	return ""
+}
+
+// Truncate returns the length a list of n items is cut to by limit,
+// where a limit of 0 or less means no limit
+func Truncate(n, limit int) int {
+	if limit > 0 && limit < n {
+		return limit
+	}
+	return n
+}
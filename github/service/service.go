@@ -0,0 +1,19 @@
+package service
+
+import (
+	"github.com/google/go-github/github"
+)
+
+// Service holds the Github client shared by the github sub-packages.
+// It is created once by the cobra layer and passed to every function
+// which interacts with the Github API.
+type Service struct {
+	Client *github.Client
+	// Limit is the maximum number of items returned by list commands, 0 means no limit
+	Limit int
+}
+
+// New returns a Service using the provided Github client
+func New(client *github.Client) *Service {
+	return &Service{Client: client}
+}
@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"fmt"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestList(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddOrg("MSF")
+	for i := 0; i < 250; i++ {
+		login := fmt.Sprintf("e%06d", i)
+		server.AddUser(login, login+"@statestreet.com")
+		server.AddOrgMember("MSF", login, "member")
+	}
+	svc := service.New(server.Client())
+
+	type test struct {
+		limit  int
+		answer int
+	}
+
+	tests := []test{
+		test{0, 250},
+		test{120, 120},
+		test{20, 20},
+		test{500, 250},
+	}
+
+	for _, v := range tests {
+		var members []*github.User
+		if err := svc.List("orgs/MSF/members", v.limit, &members); err != nil {
+			t.Error("Unexpected error:", err)
+		}
+		if len(members) != v.answer {
+			t.Errorf("Limit %v: Expected %v members Got %v", v.limit, v.answer, len(members))
+		}
+		if len(members) > 0 && members[len(members)-1].GetLogin() != fmt.Sprintf("e%06d", len(members)-1) {
+			t.Errorf("Limit %v: Members are not listed in page order", v.limit)
+		}
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	type test struct {
+		header string
+		answer string
+	}
+
+	tests := []test{
+		test{`<https://ghe/api/v3/orgs/MSF/members?page=2&per_page=100>; rel="next", <https://ghe/api/v3/orgs/MSF/members?page=3&per_page=100>; rel="last"`, "https://ghe/api/v3/orgs/MSF/members?page=2&per_page=100"},
+		test{`<https://ghe/api/v3/organizations?since=135>; rel="next"`, "https://ghe/api/v3/organizations?since=135"},
+		test{`<https://ghe/api/v3/orgs/MSF/members?page=1&per_page=100>; rel="first"`, ""},
+		test{"", ""},
+	}
+
+	for _, v := range tests {
+		x := service.NextLink(v.header)
+		if x != v.answer {
+			t.Errorf("Expected '%v' Got '%v'", v.answer, x)
+		}
+	}
+}
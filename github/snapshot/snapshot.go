@@ -0,0 +1,184 @@
+// Package snapshot records the org and team memberships of a Github user when
+// they are suspended, so that they can be restored when the user is unsuspended.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	listUser "omniactl/github/list/user"
+	"omniactl/github/service"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/github"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// EnvDir overrides the directory snapshots are saved in, by default ~/.omniactl.d/snapshots
+const EnvDir = "OMNIACTL_SNAPSHOT_DIR"
+
+// Membership is the role of a user in an organisation and the teams they belong to in it
+type Membership struct {
+	Org   string           `json:"org" yaml:"org"`
+	Role  string           `json:"role" yaml:"role"`
+	Teams []TeamMembership `json:"teams" yaml:"teams"`
+}
+
+// TeamMembership is the role, i.e. member or maintainer, of a user in a team
+type TeamMembership struct {
+	Name string `json:"name" yaml:"name"`
+	ID   int64  `json:"id" yaml:"id"`
+	Role string `json:"role" yaml:"role"`
+}
+
+// Snapshot holds the memberships of a user at the time it was taken
+type Snapshot struct {
+	Username string       `json:"username" yaml:"username"`
+	Reason   string       `json:"reason" yaml:"reason"`
+	TakenAt  time.Time    `json:"taken_at" yaml:"taken_at"`
+	Orgs     []Membership `json:"orgs" yaml:"orgs"`
+}
+
+// Take gets the orgs and teams the user belongs to with their roles
+func Take(svc *service.Service, username string, reason string) (*Snapshot, error) {
+	ctx := context.Background()
+	userOrgs, err := listUser.GetOrgsForUser(svc, username)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{Username: username, Reason: reason, TakenAt: time.Now().UTC(), Orgs: []Membership{}}
+	for org, v := range userOrgs {
+		membership, _, err := svc.Client.Organizations.GetOrgMembership(ctx, username, org)
+		if err != nil {
+			return nil, errs.FromGithub(err, "error getting role of '%v' in organisation '%v'", username, org)
+		}
+		m := Membership{Org: org, Role: membership.GetRole(), Teams: []TeamMembership{}}
+		for _, team := range v.Teams {
+			teamMembership, _, err := svc.Client.Teams.GetTeamMembership(ctx, team.ID, username)
+			if err != nil {
+				return nil, errs.FromGithub(err, "error getting role of '%v' in team '%v'", username, team.Name)
+			}
+			m.Teams = append(m.Teams, TeamMembership{Name: team.Name, ID: team.ID, Role: teamMembership.GetRole()})
+		}
+		sort.Slice(m.Teams, func(i, j int) bool { return m.Teams[i].Name < m.Teams[j].Name })
+		s.Orgs = append(s.Orgs, m)
+	}
+	sort.Slice(s.Orgs, func(i, j int) bool { return s.Orgs[i].Org < s.Orgs[j].Org })
+	return s, nil
+}
+
+// Dir returns the directory snapshots are saved in
+func Dir() (string, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", errs.Wrap(errs.Config, err, "error finding home directory")
+	}
+	return filepath.Join(home, ".omniactl.d", "snapshots"), nil
+}
+
+// Save writes the snapshot to the snapshot directory and returns the path of the
+// file. An earlier snapshot of the same user is kept as '<username>-<time>.json',
+// named after the time it was taken, so that the memberships a user had before
+// being suspended again are not lost.
+// With --dry-run, nothing is written, so that an earlier snapshot is kept.
+func Save(s *Snapshot) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, s.Username+".json")
+	if dryrun.Enabled {
+		color.New(color.FgYellow, color.Bold).Printf("DRY RUN: snapshot not written to '%v'\n", file)
+		return file, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", errs.Wrap(errs.Config, err, "error creating snapshot directory")
+	}
+	if err := keep(s.Username, file); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", errs.Wrap(errs.Internal, err, "error encoding snapshot")
+	}
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		return "", errs.Wrap(errs.Internal, err, "error writing snapshot")
+	}
+	return file, nil
+}
+
+// keep renames the earlier snapshot of the user in file, if there is one, after the
+// time it was taken
+func keep(username string, file string) error {
+	earlier, err := Load(username)
+	if errs.Is(err, errs.NotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	kept := filepath.Join(filepath.Dir(file), fmt.Sprintf("%v-%v.json", username, earlier.TakenAt.UTC().Format("20060102T150405Z")))
+	if err := os.Rename(file, kept); err != nil {
+		return errs.Wrap(errs.Internal, err, "error keeping earlier snapshot of '%v'", username)
+	}
+	fmt.Printf("Earlier snapshot of '%v' kept as '%v'.\n", username, kept)
+	return nil
+}
+
+// Load reads the latest snapshot of the user from the snapshot directory
+func Load(username string) (*Snapshot, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(dir, username+".json")
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, errs.New(errs.NotFound, "no snapshot of the memberships of '%v' found in '%v'", username, dir)
+	}
+	if err != nil {
+		return nil, errs.Wrap(errs.Internal, err, "error reading snapshot")
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error parsing snapshot '%v'", file)
+	}
+	return s, nil
+}
+
+// Restore adds the user back to the orgs and teams of the snapshot with their previous roles
+func Restore(svc *service.Service, s *Snapshot) error {
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	ctx := context.Background()
+
+	for _, m := range s.Orgs {
+		_, _, err := svc.Client.Organizations.EditOrgMembership(ctx, s.Username, m.Org, &github.Membership{Role: github.String(m.Role)})
+		if err != nil {
+			return errs.FromGithub(err, "error adding '%v' to Github organisation '%v'", s.Username, m.Org)
+		}
+		whiteBold.Printf("User '%v' added to Github organisation '%v' as '%v'.", s.Username, m.Org, m.Role)
+		fmt.Println("")
+
+		for _, team := range m.Teams {
+			options := &github.TeamAddTeamMembershipOptions{Role: team.Role}
+			_, _, err := svc.Client.Teams.AddTeamMembership(ctx, team.ID, s.Username, options)
+			if err != nil {
+				return errs.FromGithub(err, "error adding '%v' to Github team '%v'", s.Username, team.Name)
+			}
+			whiteBold.Printf("User '%v' added to Github team '%v' as '%v'.", s.Username, team.Name, team.Role)
+			fmt.Println("")
+		}
+	}
+	return nil
+}
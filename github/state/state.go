@@ -0,0 +1,671 @@
+// Package state keeps the layout of Github organisations, teams, memberships and
+// team repository permissions in a YAML file. Plan diffs the file against the live
+// instance and Apply executes the resulting changes.
+//
+// Only the orgs listed in the file are managed. Within an org, members, team members
+// and team repos are authoritative when listed: entries missing from the file are
+// removed. If a list is omitted, it is not managed. Orgs, teams and repos are only
+// ever created, never deleted.
+package state
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	createOrg "omniactl/github/create/org"
+	createRepo "omniactl/github/create/repo"
+	createTeam "omniactl/github/create/team"
+	createUser "omniactl/github/create/user"
+	listTeam "omniactl/github/list/team"
+	"omniactl/github/service"
+	"omniactl/interactive"
+	"omniactl/output"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/github"
+	"github.com/manifoldco/promptui"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// State is the desired layout of the managed Github organisations
+type State struct {
+	Orgs []Org `yaml:"orgs"`
+}
+
+// Org is a managed organisation. Members map logins to their role, i.e. member or admin.
+type Org struct {
+	Name    string            `yaml:"name"`
+	Profile string            `yaml:"profile"`
+	Members map[string]string `yaml:"members"`
+	Teams   []Team            `yaml:"teams"`
+	Repos   []Repo            `yaml:"repos"`
+}
+
+// Team is a managed team. Members map logins to their role, i.e. member or maintainer,
+// and repos map repository names, either 'repo' in the team's org or 'owner/repo',
+// to the team's permission, i.e. pull, push or admin.
+type Team struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Privacy     string            `yaml:"privacy"`
+	Members     map[string]string `yaml:"members"`
+	Repos       map[string]string `yaml:"repos"`
+}
+
+// Repo is a repository which is created in its org if it does not exist.
+// Collaborators map the logins of direct collaborators, who get access without
+// a team, to their permission, i.e. pull, push or admin.
+type Repo struct {
+	Name          string            `yaml:"name"`
+	Description   string            `yaml:"description"`
+	Private       bool              `yaml:"private"`
+	Collaborators map[string]string `yaml:"collaborators"`
+}
+
+// Actions of a change
+const (
+	Create = "create"
+	Update = "update"
+	Remove = "remove"
+)
+
+// Resources a change applies to
+const (
+	OrgResource          = "org"
+	RepoResource         = "repo"
+	TeamResource         = "team"
+	OrgMemberResource    = "org member"
+	TeamMemberResource   = "team member"
+	TeamRepoResource     = "team repo"
+	CollaboratorResource = "collaborator"
+)
+
+// Change is a single difference between the state file and the live instance.
+// Name is the login of a member or collaborator or the full name of a repo,
+// From and To hold roles or permissions.
+type Change struct {
+	Action   string `json:"action" yaml:"action"`
+	Resource string `json:"resource" yaml:"resource"`
+	Org      string `json:"org" yaml:"org"`
+	Team     string `json:"team" yaml:"team"`
+	Repo     string `json:"repo" yaml:"repo"`
+	Name     string `json:"name" yaml:"name"`
+	From     string `json:"from" yaml:"from"`
+	To       string `json:"to" yaml:"to"`
+
+	// profile, repo and team hold the settings of orgs, repos and teams to be created
+	profile string
+	repo    Repo
+	team    Team
+}
+
+// String describes the change in a single line, e.g. "+ create team MSF/devops"
+func (c Change) String() string {
+	symbols := map[string]string{Create: "+", Update: "~", Remove: "-"}
+	target := c.Org
+	if c.Team != "" {
+		target += "/" + c.Team
+	}
+	if c.Repo != "" {
+		target += "/" + c.Repo
+	}
+	if c.Name != "" {
+		target += " " + c.Name
+	}
+	s := fmt.Sprintf("%v %v %v %v", symbols[c.Action], c.Action, c.Resource, target)
+	switch {
+	case c.Action == Update:
+		s += fmt.Sprintf(": %v -> %v", c.From, c.To)
+	case c.Action == Create && c.To != "":
+		s += fmt.Sprintf(" (%v)", c.To)
+	}
+	return s
+}
+
+var (
+	orgRoles        = []string{"member", "admin"}
+	teamRoles       = []string{"member", "maintainer"}
+	teamPermissions = []string{"pull", "push", "admin"}
+	teamPrivacies   = []string{"", "secret", "closed"}
+)
+
+// Load reads and validates a state file
+func Load(file string) (*State, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error reading state file")
+	}
+	s := &State{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "error parsing state file '%v'", file)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, errs.Wrap(errs.Validation, err, "invalid state file '%v'", file)
+	}
+	return s, nil
+}
+
+// Validate checks names, roles and permissions and normalises team repos to their full names
+func (s *State) Validate() error {
+	orgs := make(map[string]bool)
+	for i := range s.Orgs {
+		org := &s.Orgs[i]
+		if org.Name == "" {
+			return errs.New(errs.Validation, "org %v has no name", i+1)
+		}
+		if orgs[org.Name] {
+			return errs.New(errs.Validation, "org '%v' is listed twice", org.Name)
+		}
+		orgs[org.Name] = true
+		for login, role := range org.Members {
+			if !contains(orgRoles, role) {
+				return errs.New(errs.Validation, "role '%v' of '%v' in org '%v' must be one of: %v", role, login, org.Name, strings.Join(orgRoles, ", "))
+			}
+		}
+
+		repos := make(map[string]bool)
+		for _, repo := range org.Repos {
+			if repo.Name == "" || strings.Contains(repo.Name, "/") {
+				return errs.New(errs.Validation, "repo '%v' in org '%v' must be named without its owner", repo.Name, org.Name)
+			}
+			if repos[repo.Name] {
+				return errs.New(errs.Validation, "repo '%v' is listed twice in org '%v'", repo.Name, org.Name)
+			}
+			repos[repo.Name] = true
+			for login, permission := range repo.Collaborators {
+				if !contains(teamPermissions, permission) {
+					return errs.New(errs.Validation, "permission '%v' of collaborator '%v' on '%v' must be one of: %v", permission, login, repo.Name, strings.Join(teamPermissions, ", "))
+				}
+			}
+		}
+
+		teams := make(map[string]bool)
+		for j := range org.Teams {
+			team := &org.Teams[j]
+			if team.Name == "" {
+				return errs.New(errs.Validation, "team %v in org '%v' has no name", j+1, org.Name)
+			}
+			if teams[team.Name] {
+				return errs.New(errs.Validation, "team '%v' is listed twice in org '%v'", team.Name, org.Name)
+			}
+			teams[team.Name] = true
+			if !contains(teamPrivacies, team.Privacy) {
+				return errs.New(errs.Validation, "privacy '%v' of team '%v' must be secret or closed", team.Privacy, team.Name)
+			}
+			for login, role := range team.Members {
+				if !contains(teamRoles, role) {
+					return errs.New(errs.Validation, "role '%v' of '%v' in team '%v' must be one of: %v", role, login, team.Name, strings.Join(teamRoles, ", "))
+				}
+				if _, ok := org.Members[login]; org.Members != nil && !ok {
+					return errs.New(errs.Validation, "'%v' is a member of team '%v' but not of org '%v'", login, team.Name, org.Name)
+				}
+			}
+			if team.Repos != nil {
+				normalised := make(map[string]string)
+				for name, permission := range team.Repos {
+					if !contains(teamPermissions, permission) {
+						return errs.New(errs.Validation, "permission '%v' of team '%v' on '%v' must be one of: %v", permission, team.Name, name, strings.Join(teamPermissions, ", "))
+					}
+					if !strings.Contains(name, "/") {
+						name = org.Name + "/" + name
+					}
+					normalised[name] = permission
+				}
+				team.Repos = normalised
+			}
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan diffs the state against the live instance and returns the changes in the
+// order they are applied
+func Plan(svc *service.Service, s *State) ([]Change, error) {
+	allOrgs, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes, removals []Change
+	for _, org := range s.Orgs {
+		_, exists := allOrgs[org.Name]
+		live := &liveOrg{members: map[string]string{}, teams: map[string]createUser.Team{}, repos: map[string]bool{}}
+		if exists {
+			live, err = getLiveOrg(svc, org.Name)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			admin := firstAdmin(org.Members)
+			if admin == "" {
+				return nil, errs.New(errs.Validation, "org '%v' does not exist and needs an admin member to be created", org.Name)
+			}
+			changes = append(changes, Change{Action: Create, Resource: OrgResource, Org: org.Name, To: admin, profile: org.Profile})
+		}
+
+		for _, repo := range org.Repos {
+			if !live.repos[repo.Name] {
+				changes = append(changes, Change{Action: Create, Resource: RepoResource, Org: org.Name, Name: org.Name + "/" + repo.Name, repo: repo})
+			}
+		}
+
+		if org.Members != nil {
+			orgChanges, orgRemovals := diffMembers(OrgMemberResource, org.Name, "", org.Members, live.members)
+			changes = append(changes, orgChanges...)
+			removals = append(removals, orgRemovals...)
+		}
+
+		for _, repo := range org.Repos {
+			if repo.Collaborators == nil {
+				continue
+			}
+			liveCollaborators := map[string]string{}
+			if live.repos[repo.Name] {
+				liveCollaborators, err = GetCollaborators(svc, org.Name+"/"+repo.Name)
+				if err != nil {
+					return nil, err
+				}
+			}
+			collaboratorChanges, collaboratorRemovals := diffMembers(CollaboratorResource, org.Name, "", repo.Collaborators, liveCollaborators)
+			for _, c := range append(collaboratorChanges, collaboratorRemovals...) {
+				c.Repo = repo.Name
+				changes = append(changes, c)
+			}
+		}
+
+		for _, team := range org.Teams {
+			current, exists := live.teams[team.Name]
+			liveMembers := map[string]string{}
+			liveRepos := map[string]string{}
+			if exists {
+				liveMembers, err = GetTeamMembers(svc, current.ID)
+				if err != nil {
+					return nil, err
+				}
+				liveRepos, err = GetTeamRepos(svc, current.ID)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				changes = append(changes, Change{Action: Create, Resource: TeamResource, Org: org.Name, Team: team.Name, team: team})
+			}
+			if team.Members != nil {
+				teamChanges, teamRemovals := diffMembers(TeamMemberResource, org.Name, team.Name, team.Members, liveMembers)
+				changes = append(changes, teamChanges...)
+				changes = append(changes, teamRemovals...)
+			}
+			if team.Repos != nil {
+				repoChanges, repoRemovals := diffMembers(TeamRepoResource, org.Name, team.Name, team.Repos, liveRepos)
+				changes = append(changes, repoChanges...)
+				changes = append(changes, repoRemovals...)
+			}
+		}
+	}
+	// Org members are removed last, since this also removes them from their teams
+	return append(changes, removals...), nil
+}
+
+// diffMembers compares desired and live maps of names to roles or permissions and
+// returns the changes to create or update entries and the changes to remove entries
+func diffMembers(resource string, org string, team string, desired map[string]string, live map[string]string) ([]Change, []Change) {
+	var changes, removals []Change
+	for _, name := range sortedKeys(desired) {
+		current, ok := live[name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Action: Create, Resource: resource, Org: org, Team: team, Name: name, To: desired[name]})
+		case current != desired[name]:
+			changes = append(changes, Change{Action: Update, Resource: resource, Org: org, Team: team, Name: name, From: current, To: desired[name]})
+		}
+	}
+	for _, name := range sortedKeys(live) {
+		if _, ok := desired[name]; !ok {
+			removals = append(removals, Change{Action: Remove, Resource: resource, Org: org, Team: team, Name: name, From: live[name]})
+		}
+	}
+	return changes, removals
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func firstAdmin(members map[string]string) string {
+	for _, login := range sortedKeys(members) {
+		if members[login] == "admin" {
+			return login
+		}
+	}
+	return ""
+}
+
+// liveOrg holds the members with their roles, the teams and the repos of an org
+type liveOrg struct {
+	members map[string]string
+	teams   map[string]createUser.Team
+	repos   map[string]bool
+}
+
+func getLiveOrg(svc *service.Service, org string) (*liveOrg, error) {
+	live := &liveOrg{repos: make(map[string]bool)}
+	members, err := GetOrgMembers(svc, org)
+	if err != nil {
+		return nil, err
+	}
+	live.members = members
+
+	teams, err := createUser.GetTeamsForOrg(svc, org)
+	if err != nil {
+		return nil, err
+	}
+	live.teams = teams
+
+	repos, err := listTeam.ListRepos(svc, fmt.Sprintf("orgs/%v/repos", org))
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range repos {
+		live.repos[v.Name] = true
+	}
+	return live, nil
+}
+
+// GetOrgMembers maps the logins of the members of an org to their role, i.e. member or admin
+func GetOrgMembers(svc *service.Service, org string) (map[string]string, error) {
+	live := make(map[string]string)
+	for _, role := range orgRoles {
+		var members []*github.User
+		if err := svc.List(fmt.Sprintf("orgs/%v/members?role=%v", org, role), 0, &members); err != nil {
+			return nil, errs.Wrap(errs.KindOf(err), err, "error getting members of organisation '%v'", org)
+		}
+		for _, v := range members {
+			live[v.GetLogin()] = role
+		}
+	}
+	return live, nil
+}
+
+// GetTeamMembers maps the logins of the members of a team to their role, i.e. member or maintainer
+func GetTeamMembers(svc *service.Service, teamID int64) (map[string]string, error) {
+	live := make(map[string]string)
+	for _, role := range teamRoles {
+		var members []*github.User
+		if err := svc.List(fmt.Sprintf("teams/%v/members?role=%v", teamID, role), 0, &members); err != nil {
+			return nil, errs.Wrap(errs.KindOf(err), err, "error getting info about team members")
+		}
+		for _, v := range members {
+			live[v.GetLogin()] = role
+		}
+	}
+	return live, nil
+}
+
+// GetTeamRepos maps the full names of the repos of a team to the team's permission, i.e. pull, push or admin
+func GetTeamRepos(svc *service.Service, teamID int64) (map[string]string, error) {
+	repos := listTeam.Repos{}
+	if err := svc.List(fmt.Sprintf("teams/%v/repos", teamID), 0, &repos); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error retrieving repos for team")
+	}
+	live := make(map[string]string)
+	for _, v := range repos {
+		switch {
+		case v.Permissions.Admin:
+			live[v.FullName] = "admin"
+		case v.Permissions.Push:
+			live[v.FullName] = "push"
+		default:
+			live[v.FullName] = "pull"
+		}
+	}
+	return live, nil
+}
+
+// GetCollaborators maps the logins of the direct collaborators of a repo, who have
+// access without a team, to their permission, i.e. pull, push or admin
+func GetCollaborators(svc *service.Service, fullName string) (map[string]string, error) {
+	var collaborators []*github.User
+	if err := svc.List(fmt.Sprintf("repos/%v/collaborators?affiliation=direct", fullName), 0, &collaborators); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting collaborators of '%v'", fullName)
+	}
+	live := make(map[string]string)
+	for _, v := range collaborators {
+		permissions := map[string]bool{}
+		if v.Permissions != nil {
+			permissions = *v.Permissions
+		}
+		switch {
+		case permissions["admin"]:
+			live[v.GetLogin()] = "admin"
+		case permissions["push"]:
+			live[v.GetLogin()] = "push"
+		default:
+			live[v.GetLogin()] = "pull"
+		}
+	}
+	return live, nil
+}
+
+// PlanFile loads the state file and prints or renders the changes needed to reach it
+func PlanFile(svc *service.Service, file string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Plan changes to Github")
+	s, err := Load(file)
+	if err != nil {
+		return err
+	}
+	changes, err := Plan(svc, s)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(changes)
+	}
+	PrintChanges(changes)
+	return nil
+}
+
+// ApplyFile loads the state file, prints the changes needed to reach it and,
+// once confirmed, applies them
+func ApplyFile(svc *service.Service, file string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Apply changes to Github")
+	s, err := Load(file)
+	if err != nil {
+		return err
+	}
+	changes, err := Plan(svc, s)
+	if err != nil {
+		return err
+	}
+	PrintChanges(changes)
+	if len(changes) == 0 {
+		return nil
+	}
+	check, err := PromptApply()
+	if err != nil {
+		return err
+	}
+	if check != "yes" {
+		return nil
+	}
+	return Apply(svc, changes)
+}
+
+// PrintChanges prints one line per change and a summary
+func PrintChanges(changes []Change) {
+	colours := map[string]*color.Color{
+		Create: color.New(color.FgGreen),
+		Update: color.New(color.FgYellow),
+		Remove: color.New(color.FgRed),
+	}
+	counts := make(map[string]int)
+	fmt.Fprintln(color.Output, "")
+	for _, c := range changes {
+		colours[c.Action].Println(c.String())
+		counts[c.Action]++
+	}
+	if len(changes) == 0 {
+		color.New(color.FgHiWhite, color.Bold).Println("No changes. Github matches the state file.")
+		return
+	}
+	fmt.Fprintln(color.Output, "")
+	color.New(color.FgHiWhite, color.Bold).Printf("Plan: %v to create, %v to update, %v to remove.\n", counts[Create], counts[Update], counts[Remove])
+}
+
+// PromptApply asks for confirmation before applying the changes
+func PromptApply() (string, error) {
+	prompt := promptui.Select{
+		Label: "Apply these changes?",
+		Items: []string{"yes", "no"},
+	}
+	return interactive.Select(prompt, "yes")
+}
+
+// Apply executes the changes in order, stopping at the first failure
+func Apply(svc *service.Service, changes []Change) error {
+	teams := make(map[string]map[string]createUser.Team)
+	// created holds the teams created by a dry run, which cannot be looked up
+	created := make(map[string]bool)
+	teamID := func(org string, team string) (int64, error) {
+		if created[org+"/"+team] {
+			return 0, nil
+		}
+		if _, ok := teams[org]; !ok {
+			allTeams, err := createUser.GetTeamsForOrg(svc, org)
+			if err != nil {
+				return 0, err
+			}
+			teams[org] = allTeams
+		}
+		t, ok := teams[org][team]
+		if !ok {
+			return 0, errs.New(errs.NotFound, "Github team '%v' does not exist in organisation '%v'", team, org)
+		}
+		return t.ID, nil
+	}
+
+	for _, c := range changes {
+		var err error
+		switch c.Resource {
+		case OrgResource:
+			err = createOrg.CreateGithubOrg(svc, c.Org, c.profile, c.To)
+		case RepoResource:
+			_, _, err = createRepo.CreateGithubRepo(svc, c.repo.Name, c.Org, nil, c.repo.Description, c.repo.Private)
+		case TeamResource:
+			privacy := c.team.Privacy
+			if privacy == "" {
+				privacy = "closed"
+			}
+			err = createTeam.CreateGithubTeam(svc, c.Team, c.Org, c.team.Description, nil, privacy)
+			// The team's ID is looked up again once it is needed
+			delete(teams, c.Org)
+			created[c.Org+"/"+c.Team] = dryrun.Enabled
+		case OrgMemberResource:
+			err = applyOrgMember(svc, c)
+		case CollaboratorResource:
+			err = applyCollaborator(svc, c)
+		case TeamMemberResource, TeamRepoResource:
+			var id int64
+			id, err = teamID(c.Org, c.Team)
+			if err == nil && c.Resource == TeamMemberResource {
+				err = applyTeamMember(svc, c, id)
+			} else if err == nil {
+				err = applyTeamRepo(svc, c, id)
+			}
+		}
+		if err != nil {
+			return errs.Wrap(errs.KindOf(err), err, "error applying '%v'", c)
+		}
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Apply complete: %v changes applied.\n", len(changes))
+	return nil
+}
+
+func applyOrgMember(svc *service.Service, c Change) error {
+	if c.Action != Remove {
+		return createUser.AddUserToOrgs(svc, c.Name, map[string]createUser.Org{c.Org: createUser.Org{Name: c.Org, Role: c.To}})
+	}
+	_, err := svc.Client.Organizations.RemoveOrgMembership(context.Background(), c.Name, c.Org)
+	if err != nil {
+		return errs.FromGithub(err, "error removing '%v' from Github organisation '%v'", c.Name, c.Org)
+	}
+	color.New(color.FgHiWhite, color.Bold).Printf("User '%v' removed from Github organisation '%v'.\n", c.Name, c.Org)
+	return nil
+}
+
+func applyCollaborator(svc *service.Service, c Change) error {
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	if c.Action != Remove {
+		options := &github.RepositoryAddCollaboratorOptions{Permission: c.To}
+		_, err := svc.Client.Repositories.AddCollaborator(context.Background(), c.Org, c.Repo, c.Name, options)
+		if err != nil {
+			return errs.FromGithub(err, "error adding '%v' as collaborator to '%v/%v'", c.Name, c.Org, c.Repo)
+		}
+		whiteBold.Printf("User '%v' given '%v' access to '%v/%v'.\n", c.Name, c.To, c.Org, c.Repo)
+		return nil
+	}
+	_, err := svc.Client.Repositories.RemoveCollaborator(context.Background(), c.Org, c.Repo, c.Name)
+	if err != nil {
+		return errs.FromGithub(err, "error removing collaborator '%v' from '%v/%v'", c.Name, c.Org, c.Repo)
+	}
+	whiteBold.Printf("Collaborator '%v' removed from '%v/%v'.\n", c.Name, c.Org, c.Repo)
+	return nil
+}
+
+func applyTeamMember(svc *service.Service, c Change, teamID int64) error {
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	if c.Action != Remove {
+		options := &github.TeamAddTeamMembershipOptions{Role: c.To}
+		_, _, err := svc.Client.Teams.AddTeamMembership(context.Background(), teamID, c.Name, options)
+		if err != nil {
+			return errs.FromGithub(err, "error adding '%v' to Github team '%v'", c.Name, c.Team)
+		}
+		whiteBold.Printf("User '%v' added to Github team '%v' as '%v'.\n", c.Name, c.Team, c.To)
+		return nil
+	}
+	_, err := svc.Client.Teams.RemoveTeamMembership(context.Background(), teamID, c.Name)
+	if err != nil {
+		return errs.FromGithub(err, "error removing '%v' from Github team '%v'", c.Name, c.Team)
+	}
+	whiteBold.Printf("User '%v' removed from Github team '%v'.\n", c.Name, c.Team)
+	return nil
+}
+
+func applyTeamRepo(svc *service.Service, c Change, teamID int64) error {
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	parts := strings.SplitN(c.Name, "/", 2)
+	if c.Action != Remove {
+		options := &github.TeamAddTeamRepoOptions{Permission: c.To}
+		_, err := svc.Client.Teams.AddTeamRepo(context.Background(), teamID, parts[0], parts[1], options)
+		if err != nil {
+			return errs.FromGithub(err, "error giving Github team '%v' access to '%v'", c.Team, c.Name)
+		}
+		whiteBold.Printf("Github team '%v' given '%v' access to '%v'.\n", c.Team, c.To, c.Name)
+		return nil
+	}
+	_, err := svc.Client.Teams.RemoveTeamRepo(context.Background(), teamID, parts[0], parts[1])
+	if err != nil {
+		return errs.FromGithub(err, "error removing access of Github team '%v' to '%v'", c.Team, c.Name)
+	}
+	whiteBold.Printf("Access of Github team '%v' to '%v' removed.\n", c.Team, c.Name)
+	return nil
+}
@@ -0,0 +1,201 @@
+package state_test
+
+import (
+	"io/ioutil"
+	"omniactl/errs"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	"omniactl/github/state"
+	"omniactl/interactive"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package
+	svc *service.Service
+	// dir holds the state files written by the tests
+	dir string
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	for _, login := range []string{"e100001", "e100002", "e100003", "e100004"} {
+		server.AddUser(login, login+"@statestreet.com")
+	}
+	server.AddOrg("MSF")
+	server.AddOrgMember("MSF", "e100001", "admin")
+	server.AddOrgMember("MSF", "e100002", "member")
+	server.AddOrgMember("MSF", "e100004", "member")
+	devops := server.AddTeam("MSF", "devops")
+	server.AddTeamMember(devops.ID, "e100002")
+	server.AddTeamMember(devops.ID, "e100004")
+	server.AddRepo("MSF", "pipeline")
+	server.AddRepo("MSF", "legacy")
+	server.AddTeamRepo(devops.ID, "MSF/pipeline", "pull")
+	server.AddTeamRepo(devops.ID, "MSF/legacy", "push")
+	server.AddCollaborator("MSF/pipeline", "e100004", "push")
+
+	svc = service.New(server.Client())
+	interactive.Disabled = true
+
+	var err error
+	dir, err = ioutil.TempDir("", "state_test")
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+const stateFile = `
+orgs:
+  - name: MSF
+    members:
+      e100001: admin
+      e100002: admin
+      e100003: member
+    repos:
+      - name: pipeline
+        collaborators:
+          e100003: pull
+      - name: website
+        description: Public website
+    teams:
+      - name: devops
+        members:
+          e100002: maintainer
+          e100003: member
+        repos:
+          pipeline: push
+      - name: security
+        description: Security reviewers
+        privacy: secret
+        members:
+          e100001: maintainer
+  - name: CTO
+    profile: Office of the CTO
+    members:
+      e100001: admin
+`
+
+// writeFile writes a state file to the test directory, replacing the previous one
+func writeFile(t *testing.T, content string) string {
+	file := filepath.Join(dir, "github.yaml")
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	return file
+}
+
+func TestPlan(t *testing.T) {
+	s, err := state.Load(writeFile(t, stateFile))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	changes, err := state.Plan(svc, s)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	want := []string{
+		"+ create repo MSF MSF/website",
+		"~ update org member MSF e100002: member -> admin",
+		"+ create org member MSF e100003 (member)",
+		"+ create collaborator MSF/pipeline e100003 (pull)",
+		"- remove collaborator MSF/pipeline e100004",
+		"~ update team member MSF/devops e100002: member -> maintainer",
+		"+ create team member MSF/devops e100003 (member)",
+		"- remove team member MSF/devops e100004",
+		"~ update team repo MSF/devops MSF/pipeline: pull -> push",
+		"- remove team repo MSF/devops MSF/legacy",
+		"+ create team MSF/security",
+		"+ create team member MSF/security e100001 (maintainer)",
+		"+ create org CTO (e100001)",
+		"+ create org member CTO e100001 (admin)",
+		"- remove org member MSF e100004",
+	}
+	got := []string{}
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Plan() got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestApply(t *testing.T) {
+	file := writeFile(t, stateFile)
+	if err := state.ApplyFile(svc, file); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	s, err := state.Load(file)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	changes, err := state.Plan(svc, s)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Plan() after Apply() got %v changes, want none: %v", len(changes), changes)
+	}
+
+	if org := server.Org("CTO"); org == nil || org.ProfileName != "Office of the CTO" {
+		t.Errorf("Org 'CTO' got %v, want profile 'Office of the CTO'", org)
+	}
+	if team := server.Team("MSF", "security"); team == nil || team.Privacy != "secret" || team.Description != "Security reviewers" {
+		t.Errorf("Team 'security' got %v, want a secret team described as 'Security reviewers'", team)
+	}
+	if repo := server.Repo("MSF/website"); repo == nil || repo.Description != "Public website" {
+		t.Errorf("Repo 'MSF/website' got %v, want description 'Public website'", repo)
+	}
+	if _, ok := server.Org("MSF").Members["e100004"]; ok {
+		t.Error("User 'e100004' is still a member of 'MSF'")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	type test struct {
+		name    string
+		content string
+	}
+
+	tests := []test{
+		{"unknown key", "orgs:\n  - name: MSF\n    admins: [e100001]\n"},
+		{"org without name", "orgs:\n  - members:\n      e100001: admin\n"},
+		{"duplicate org", "orgs:\n  - name: MSF\n  - name: MSF\n"},
+		{"invalid org role", "orgs:\n  - name: MSF\n    members:\n      e100001: owner\n"},
+		{"invalid team role", "orgs:\n  - name: MSF\n    teams:\n      - name: devops\n        members:\n          e100001: admin\n"},
+		{"invalid permission", "orgs:\n  - name: MSF\n    teams:\n      - name: devops\n        repos:\n          pipeline: write\n"},
+		{"invalid privacy", "orgs:\n  - name: MSF\n    teams:\n      - name: devops\n        privacy: public\n"},
+		{"team member not in org", "orgs:\n  - name: MSF\n    members:\n      e100001: admin\n    teams:\n      - name: devops\n        members:\n          e100002: member\n"},
+		{"invalid collaborator permission", "orgs:\n  - name: MSF\n    repos:\n      - name: pipeline\n        collaborators:\n          e100001: write\n"},
+		{"repo with owner", "orgs:\n  - name: MSF\n    repos:\n      - name: MSF/pipeline\n"},
+	}
+
+	for _, tc := range tests {
+		_, err := state.Load(writeFile(t, tc.content))
+		if !errs.Is(err, errs.Validation) {
+			t.Errorf("Load() with %v got error %v, want a validation error", tc.name, err)
+		}
+	}
+}
+
+func TestPlanNewOrgWithoutAdmin(t *testing.T) {
+	s, err := state.Load(writeFile(t, "orgs:\n  - name: Nowhere\n    members:\n      e100001: member\n"))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, err := state.Plan(svc, s); !errs.Is(err, errs.Validation) {
+		t.Errorf("Plan() got error %v, want a validation error", err)
+	}
+}
@@ -2,48 +2,85 @@ package user
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/fatih/color"
-	"github.com/google/go-github/github"
 	"github.com/manifoldco/promptui"
-	"log"
+	"omniactl/errs"
 	listUser "omniactl/github/list/user"
-	githubLogin "omniactl/login/github"
+	"omniactl/github/service"
+	"omniactl/github/snapshot"
+	"omniactl/interactive"
 )
 
-var Client *github.Client
-
-func SuspendUser(username string, reason string) {
+func SuspendUser(svc *service.Service, username string, reason string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: Suspend user from Github")
-	username = listUser.CheckUsername(username)
-	githubUser := listUser.GetGithubUser(username)
-	listUser.PrintUserInfo(githubUser)
-	reason = CheckReason(reason)
-	check := PromptSuspend(username)
+	username, err := listUser.CheckUsername(svc, username)
+	if err != nil {
+		return err
+	}
+	githubUser, err := listUser.GetGithubUser(svc, username)
+	if err != nil {
+		return err
+	}
+	if err := listUser.PrintUserInfo(svc, githubUser); err != nil {
+		return err
+	}
+	reason, err = CheckReason(reason)
+	if err != nil {
+		return err
+	}
+	check, err := PromptSuspend(username)
+	if err != nil {
+		return err
+	}
 	switch check {
 	case "yes":
-		SuspendFromGithub(username, reason)
-	case "no":
-		return
+		if err := SaveSnapshot(svc, username, reason); err != nil {
+			return err
+		}
+		return SuspendFromGithub(svc, username, reason)
+	}
+	return nil
+}
+
+// SaveSnapshot saves the org and team memberships of the user,
+// so that they can be restored when the user is unsuspended
+func SaveSnapshot(svc *service.Service, username string, reason string) error {
+	s, err := snapshot.Take(svc, username, reason)
+	if err != nil {
+		return err
 	}
+	file, err := snapshot.Save(s)
+	if err != nil {
+		return err
+	}
+	fmt.Println("")
+	fmt.Printf("Memberships of '%v' saved to '%v'.\n", username, file)
+	return nil
 }
 
-func CheckReason(reason string) string {
+// CheckReason checks flag input and if none was set prompts for the reason of the suspension
+func CheckReason(reason string) (string, error) {
+	return CheckReasonFor(reason, "suspension")
+}
+
+// CheckReasonFor checks flag input and if none was set prompts for the reason of
+// the action, e.g. suspension or unsuspension
+func CheckReasonFor(reason string, action string) (string, error) {
 	switch reason {
 	case "":
-		reason := PromptReason()
-		return reason
+		return PromptReason(action)
 	default:
-		return reason
+		return reason, nil
 	}
 }
 
-func PromptReason() string {
+// PromptReason asks for the reason of the action, e.g. suspension or unsuspension
+func PromptReason(action string) (string, error) {
 	validate := func(input string) error {
 		if input == "" {
-			return errors.New("Enter a reason for the user's suspension")
+			return fmt.Errorf("Enter a reason for the user's %v", action)
 		}
 		return nil
 	}
@@ -53,28 +90,20 @@ func PromptReason() string {
 	}
 
 	prompt := promptui.Prompt{
-		Label:     "Reason for suspension",
+		Label:     "Reason for " + action,
 		Validate:  validate,
 		Templates: templates,
 	}
-	result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Prompt(prompt)
 }
 
-func PromptSuspend(username string) string {
+func PromptSuspend(username string) (string, error) {
 	prompt := promptui.Select{
 		Label: "Suspend the user? ",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "yes")
 }
 
 type Reason struct {
@@ -82,22 +111,22 @@ type Reason struct {
 }
 
 // SuspendFromGithub suspends an account
-func SuspendFromGithub(username string, reason string) {
+func SuspendFromGithub(svc *service.Service, username string, reason string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	Client := githubLogin.CreateClient()
 	body := Reason{reason}
 
-	url := fmt.Sprintf("https://github.dev.us-east-1.aws.galleon.c.statestr.com/api/v3/users/%v/suspended", username)
+	url := fmt.Sprintf("users/%v/suspended", username)
 
-	req, err := Client.NewRequest("PUT", url, body)
+	req, err := svc.Client.NewRequest("PUT", url, body)
 	if err != nil {
-		log.Fatalln("Error creating HTTP request:\n", err)
+		return errs.Wrap(errs.Internal, err, "error creating HTTP request")
 	}
-	_, err = Client.Do(context.Background(), req, nil)
+	_, err = svc.Client.Do(context.Background(), req, nil)
 	if err != nil {
-		log.Fatalln("Error suspending user", err)
+		return errs.FromGithub(err, "error suspending user '%v'", username)
 	}
 	fmt.Println("")
 	whiteBold.Printf("User '%v' suspended.", username)
 	fmt.Println("")
+	return nil
 }
@@ -0,0 +1,130 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+	"omniactl/errs"
+	listUser "omniactl/github/list/user"
+	"omniactl/github/service"
+	"omniactl/github/snapshot"
+	suspendUser "omniactl/github/suspend/user"
+	"omniactl/interactive"
+)
+
+// UnsuspendUser checks flags, gets user info and, once confirmed, unsuspends the user.
+// If restore is set, the org and team memberships saved when the user was suspended
+// are restored; otherwise restoring them is prompted for if a snapshot exists.
+func UnsuspendUser(svc *service.Service, username string, reason string, restore bool) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Unsuspend user in Github")
+	username, err := listUser.CheckUsername(svc, username)
+	if err != nil {
+		return err
+	}
+	githubUser, err := listUser.GetGithubUser(svc, username)
+	if err != nil {
+		return err
+	}
+	if err := listUser.PrintUserInfo(svc, githubUser); err != nil {
+		return err
+	}
+	if githubUser.SuspendedAt == nil {
+		return errs.New(errs.Validation, "Github user '%v' is not suspended", username)
+	}
+
+	var memberships *snapshot.Snapshot
+	if restore {
+		memberships, err = snapshot.Load(username)
+		if errs.Is(err, errs.NotFound) {
+			return errs.Wrap(errs.NotFound, err, "'%v' was suspended without a snapshot of their memberships, e.g. "+
+				"on Github directly, so they cannot be restored; unsuspend without --restore and add the memberships again", username)
+		}
+		if err != nil {
+			return err
+		}
+	} else if s, err := snapshot.Load(username); err == nil {
+		check, err := PromptRestore(s)
+		if err != nil {
+			return err
+		}
+		if check == "yes" {
+			memberships = s
+		}
+	}
+
+	reason, err = suspendUser.CheckReasonFor(reason, "unsuspension")
+	if err != nil {
+		return err
+	}
+	check, err := PromptUnsuspend(username)
+	if err != nil {
+		return err
+	}
+	switch check {
+	case "yes":
+		if err := UnsuspendFromGithub(svc, username, reason); err != nil {
+			return err
+		}
+		if memberships != nil {
+			return snapshot.Restore(svc, memberships)
+		}
+	}
+	return nil
+}
+
+// PromptRestore asks whether the memberships of the snapshot are restored
+func PromptRestore(s *snapshot.Snapshot) (string, error) {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	fmt.Println("")
+	fmt.Printf("Memberships saved on %v when '%v' was suspended:\n", s.TakenAt.Format("2006-01-02 15:04"), s.Username)
+	for _, m := range s.Orgs {
+		greenBold.Print("Organisation ")
+		fmt.Printf("%v (%v)\n", m.Org, m.Role)
+		for _, team := range m.Teams {
+			greenBold.Print("Team ")
+			fmt.Printf("%v (%v)\n", team.Name, team.Role)
+		}
+	}
+
+	prompt := promptui.Select{
+		Label: "Restore these memberships?",
+		Items: []string{"yes", "no"},
+	}
+	return interactive.Select(prompt, "no")
+}
+
+func PromptUnsuspend(username string) (string, error) {
+	prompt := promptui.Select{
+		Label: "Unsuspend the user? ",
+		Items: []string{"yes", "no"},
+	}
+
+	return interactive.Select(prompt, "yes")
+}
+
+type Reason struct {
+	Reason string `json:"reason"`
+}
+
+// UnsuspendFromGithub unsuspends an account
+func UnsuspendFromGithub(svc *service.Service, username string, reason string) error {
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	body := Reason{reason}
+
+	url := fmt.Sprintf("users/%v/suspended", username)
+
+	req, err := svc.Client.NewRequest("DELETE", url, body)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	_, err = svc.Client.Do(context.Background(), req, nil)
+	if err != nil {
+		return errs.FromGithub(err, "error unsuspending user '%v'", username)
+	}
+	fmt.Println("")
+	whiteBold.Printf("User '%v' unsuspended.", username)
+	fmt.Println("")
+	return nil
+}
@@ -0,0 +1,159 @@
+package user_test
+
+import (
+	"context"
+	"io/ioutil"
+	"omniactl/errs"
+	"omniactl/github/fake"
+	"omniactl/github/service"
+	"omniactl/github/snapshot"
+	suspendUser "omniactl/github/suspend/user"
+	unsuspendUser "omniactl/github/unsuspend/user"
+	"omniactl/interactive"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	// server is the fake Github Enterprise instance used by all tests in this package
+	server *fake.Server
+	// svc is the Github service used by all tests in this package
+	svc *service.Service
+	// teamID is the ID of the team the suspended users belong to
+	teamID int64
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddOrg("testing")
+	teamID = server.AddTeam("testing", "test_team").ID
+	svc = service.New(server.Client())
+	interactive.Disabled = true
+
+	dir, err := ioutil.TempDir("", "unsuspend_test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(snapshot.EnvDir, dir)
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// addContractor adds a user who is an admin of the org and maintainer of the team
+func addContractor(login string) {
+	server.AddUser(login, login+"@statestreet.com")
+	server.AddOrgMember("testing", login, "admin")
+	server.AddTeamMember(teamID, login)
+}
+
+func TestUnsuspendUserRestore(t *testing.T) {
+	addContractor("e800001")
+	if err := suspendUser.SuspendUser(svc, "e800001", "contract ended"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	// Memberships are removed by hand while the user is suspended
+	if _, err := svc.Client.Organizations.RemoveOrgMembership(context.Background(), "e800001", "testing"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if err := unsuspendUser.UnsuspendUser(svc, "e800001", "contract renewed", true); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	user := server.User("e800001")
+	if user.Suspended || user.UnsuspendedReason != "contract renewed" {
+		t.Errorf("User was not unsuspended with the reason: %+v", user)
+	}
+	if role := server.Org("testing").Members["e800001"]; role != "admin" {
+		t.Errorf("Expected role 'admin' in organisation 'testing' Got '%v'", role)
+	}
+	if role := server.Team("testing", "test_team").Members["e800001"]; role != "member" {
+		t.Errorf("Expected role 'member' in team 'test_team' Got '%v'", role)
+	}
+}
+
+func TestUnsuspendUserWithoutRestore(t *testing.T) {
+	addContractor("e800002")
+	if err := suspendUser.SuspendUser(svc, "e800002", "contract ended"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, err := svc.Client.Organizations.RemoveOrgMembership(context.Background(), "e800002", "testing"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if err := unsuspendUser.UnsuspendUser(svc, "e800002", "contract renewed", false); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if server.User("e800002").Suspended {
+		t.Error("User was not unsuspended")
+	}
+	if _, ok := server.Org("testing").Members["e800002"]; ok {
+		t.Error("Memberships were restored although restoring was not requested")
+	}
+}
+
+func TestUnsuspendUserNoSnapshot(t *testing.T) {
+	// The user is suspended by hand, so no snapshot was saved
+	server.AddUser("e800003", "e800003@statestreet.com").Suspended = true
+
+	err := unsuspendUser.UnsuspendUser(svc, "e800003", "suspended by hand", true)
+	if !errs.Is(err, errs.NotFound) || !strings.Contains(err.Error(), "without a snapshot") {
+		t.Errorf("Expected '%v' error explaining there is no snapshot Got '%v'", errs.NotFound, err)
+	}
+	if !server.User("e800003").Suspended {
+		t.Error("User was unsuspended although the memberships cannot be restored")
+	}
+}
+
+func TestUnsuspendUserNotSuspended(t *testing.T) {
+	server.AddUser("e800004", "e800004@statestreet.com")
+
+	err := unsuspendUser.UnsuspendUser(svc, "e800004", "never suspended", false)
+	if !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Validation, err)
+	}
+	if user := server.User("e800004"); user.UnsuspendedReason != "" {
+		t.Errorf("User was unsuspended although not suspended: %+v", user)
+	}
+}
+
+func TestSuspendAgainKeepsEarlierSnapshot(t *testing.T) {
+	addContractor("e800005")
+	if err := suspendUser.SuspendUser(svc, "e800005", "contract ended"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := unsuspendUser.UnsuspendUser(svc, "e800005", "contract renewed", false); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, err := svc.Client.Organizations.RemoveOrgMembership(context.Background(), "e800005", "testing"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := suspendUser.SuspendUser(svc, "e800005", "contract ended again"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	latest, err := snapshot.Load("e800005")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if latest.Reason != "contract ended again" || len(latest.Orgs) != 0 {
+		t.Errorf("Expected the latest snapshot without memberships Got %+v", latest)
+	}
+	dir, _ := snapshot.Dir()
+	kept, _ := filepath.Glob(filepath.Join(dir, "e800005-*.json"))
+	if len(kept) != 1 {
+		t.Fatalf("Expected one earlier snapshot Got %v", kept)
+	}
+	data, err := ioutil.ReadFile(kept[0])
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !strings.Contains(string(data), `"org": "testing"`) {
+		t.Errorf("Earlier snapshot lost the memberships: %v", string(data))
+	}
+}
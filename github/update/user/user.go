@@ -4,62 +4,75 @@ import (
 	"context"
 	"fmt"
 	"github.com/fatih/color"
-	"github.com/google/go-github/github"
 	"github.com/manifoldco/promptui"
-	"log"
+	"omniactl/errs"
 	createUser "omniactl/github/create/user"
 	listUser "omniactl/github/list/user"
-	githubLogin "omniactl/login/github"
+	"omniactl/github/service"
+	"omniactl/interactive"
 )
 
-// Client which interacts with Github API
-var Client *github.Client
-
 // UpdateUser gets info about user and allows to make changes to their status, membership
-func UpdateUser(username string) {
+func UpdateUser(svc *service.Service, username string) error {
 	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
 	magentaBold.Println("Action selected: Update Github user")
-	username = listUser.CheckUsername(username)
-	githubUser := listUser.GetGithubUser(username)
-	listUser.PrintUserInfo(githubUser)
+	username, err := listUser.CheckUsername(svc, username)
+	if err != nil {
+		return err
+	}
+	githubUser, err := listUser.GetGithubUser(svc, username)
+	if err != nil {
+		return err
+	}
+	if err := listUser.PrintUserInfo(svc, githubUser); err != nil {
+		return err
+	}
 	// EmptyMap is a necessary placeholder for AddUserToOrgs function,
 	// which in original function removes certain orgs from selection,
 	// this is not desired in the update user package
 	emptyMap := make(map[string]createUser.Org)
 
-	result := PromptUpdate()
+	result, err := PromptUpdate()
+	if err != nil {
+		return err
+	}
 	switch result {
 	case "yes":
-		result = PromptAction()
+		result, err = PromptAction()
+		if err != nil {
+			return err
+		}
 		switch result {
 		case "Add to Github organizations/ teams":
-			newOrgs := createUser.SelectOrgs(emptyMap)
-			createUser.AddUserToOrgs(username, newOrgs)
+			newOrgs, err := createUser.SelectOrgs(svc, emptyMap)
+			if err != nil {
+				return err
+			}
+			return createUser.AddUserToOrgs(svc, username, newOrgs)
 		case "Make site admin":
-			MakeSiteAdmin(username)
+			return MakeSiteAdmin(svc, username)
 		case "Remove from Github organization":
-			RemoveOrgMember(username)
+			return RemoveOrgMember(svc, username)
 		}
 	case "no":
 		fmt.Println("")
-		return
 	}
+	return nil
 }
 
 // MakeSiteAdmin promotes user to site admin
-func MakeSiteAdmin(username string) {
+func MakeSiteAdmin(svc *service.Service, username string) error {
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	Client := githubLogin.CreateClient()
 
-	url := fmt.Sprintf("https://github.dev.us-east-1.aws.galleon.c.statestr.com/api/v3/users/%v/site_admin", username)
+	url := fmt.Sprintf("users/%v/site_admin", username)
 
-	req, err := Client.NewRequest("PUT", url, nil)
+	req, err := svc.Client.NewRequest("PUT", url, nil)
 	if err != nil {
-		log.Fatalln("Error creating HTTP request:\n", err)
+		return errs.Wrap(errs.Internal, err, "error creating HTTP request")
 	}
-	resp, err := Client.Do(context.Background(), req, nil)
+	resp, err := svc.Client.Do(context.Background(), req, nil)
 	if err != nil {
-		log.Fatalln("Error making user site admin", err)
+		return errs.FromGithub(err, "error making user '%v' site admin", username)
 	}
 
 	if resp.StatusCode == 204 {
@@ -67,12 +80,13 @@ func MakeSiteAdmin(username string) {
 		whiteBold.Printf("User '%v' promoted to site administrator.", username)
 		fmt.Println("")
 	} else {
-		log.Fatalln("User was not promoted to site admin:", resp.Status)
+		return errs.New(errs.FromStatus(resp.StatusCode, resp.Status), "user was not promoted to site admin: %v", resp.Status)
 	}
+	return nil
 }
 
 // PromptOrg asks to choose which org the user should be removed from
-func PromptOrg(userOrgs map[string]createUser.Org) string {
+func PromptOrg(userOrgs map[string]createUser.Org) (string, error) {
 	var orgsSlice []string
 
 	for k := range userOrgs {
@@ -83,72 +97,77 @@ func PromptOrg(userOrgs map[string]createUser.Org) string {
 		Label: "Select the organisation from which the user should be removed",
 		Items: orgsSlice,
 	}
-	_, org, err := prompt.Run()
+	// In non-interactive mode the only organisation of the user is chosen
+	answer := ""
+	if len(orgsSlice) == 1 {
+		answer = orgsSlice[0]
+	}
+	org, err := interactive.Select(prompt, answer)
 	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
+		return "", err
 	}
 	fmt.Println(org)
-	return org
+	return org, nil
 }
 
 // RemoveOrgMember removes a user from a selected org
-func RemoveOrgMember(username string) {
-	Client = githubLogin.CreateClient()
-	userOrgs := GetOrgsForUser(username)
+func RemoveOrgMember(svc *service.Service, username string) error {
+	userOrgs, err := GetOrgsForUser(svc, username)
+	if err != nil {
+		return err
+	}
 	whiteBold := color.New(color.FgHiWhite, color.Bold)
-	org := PromptOrg(userOrgs)
+	org, err := PromptOrg(userOrgs)
+	if err != nil {
+		return err
+	}
 
-	resp, err := Client.Organizations.RemoveMember(context.Background(), org, username)
+	resp, err := svc.Client.Organizations.RemoveMember(context.Background(), org, username)
 	if err != nil {
-		log.Fatalln("Error removing member for organisation:", err)
+		return errs.FromGithub(err, "error removing member from organisation '%v'", org)
 	}
 	if resp.StatusCode == 204 {
 		fmt.Println("")
 		whiteBold.Printf("User '%v' removed from Github organisation '%v'.", username, org)
 		fmt.Println("")
 	} else {
-		log.Fatalln("User was not removed:", resp.Status)
+		return errs.New(errs.FromStatus(resp.StatusCode, resp.Status), "user was not removed: %v", resp.Status)
 	}
+	return nil
 }
 
 // PromptUpdate asks if a user should be updated
-func PromptUpdate() string {
+func PromptUpdate() (string, error) {
 	prompt := promptui.Select{
 		Label: "Update this user?",
 		Items: []string{"yes", "no"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "yes")
 }
 
 // PromptAction asks in which way user should be updated
-func PromptAction() string {
+func PromptAction() (string, error) {
 	prompt := promptui.Select{
 		Label: "Select action",
 		Items: []string{"Add to Github organizations/ teams", "Remove from Github organization", "Make site admin"},
 	}
 
-	_, result, err := prompt.Run()
-	if err != nil {
-		log.Fatalf("Prompt failed %v\n", err)
-	}
-	return result
+	return interactive.Select(prompt, "")
 }
 
 // GetOrgsForUser gets the user's current orgs
-func GetOrgsForUser(username string) map[string]createUser.Org {
-	Client = githubLogin.CreateClient()
-	allOrgs := createUser.GetAllOrgs()
+func GetOrgsForUser(svc *service.Service, username string) (map[string]createUser.Org, error) {
+	allOrgs, err := createUser.GetAllOrgs(svc)
+	if err != nil {
+		return nil, err
+	}
 	userOrgs := make(map[string]createUser.Org)
 
 	for org := range allOrgs {
-		isMember, _, err := Client.Organizations.IsMember(context.Background(), org, username)
+		isMember, _, err := svc.Client.Organizations.IsMember(context.Background(), org, username)
 		if err != nil {
-			log.Fatalln("Error getting orgs for user:", err)
+			return nil, errs.FromGithub(err, "error getting orgs for user '%v'", username)
 		}
 		switch isMember {
 		case true:
@@ -158,5 +177,5 @@ func GetOrgsForUser(username string) map[string]createUser.Org {
 			continue
 		}
 	}
-	return userOrgs
+	return userOrgs, nil
 }
@@ -0,0 +1,21 @@
+// Package glob matches names against the shell patterns accepted by the list commands.
+package glob
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether any of the values matches the glob, e.g. 'MSF*', ignoring case.
+// An empty glob matches every value.
+func Match(glob string, values ...string) bool {
+	if glob == "" {
+		return true
+	}
+	for _, v := range values {
+		if ok, _ := path.Match(strings.ToLower(glob), strings.ToLower(v)); ok {
+			return true
+		}
+	}
+	return false
+}
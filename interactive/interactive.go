@@ -0,0 +1,59 @@
+package interactive
+
+import (
+	"omniactl/errs"
+
+	"github.com/manifoldco/promptui"
+)
+
+// Disabled is set through the global --non-interactive/--yes flag. When set,
+// no prompts are shown: missing or invalid input makes the command fail with
+// a validation error, and questions with a default answer, such as
+// confirmations, are answered automatically.
+var Disabled bool
+
+// Select shows a select prompt and returns the chosen item. In non-interactive
+// mode answer is returned instead, or an error if answer is empty.
+func Select(prompt promptui.Select, answer string) (string, error) {
+	if Disabled {
+		if answer == "" {
+			return "", Fail(prompt.Label)
+		}
+		return answer, nil
+	}
+
+	_, result, err := prompt.Run()
+	if err != nil {
+		return "", promptError(err)
+	}
+	return result, nil
+}
+
+// Prompt shows an input prompt and returns the input. In non-interactive mode
+// an error is returned, as the input was either missing or invalid.
+func Prompt(prompt promptui.Prompt) (string, error) {
+	if Disabled {
+		return "", Fail(prompt.Label)
+	}
+
+	result, err := prompt.Run()
+	if err != nil {
+		return "", promptError(err)
+	}
+	return result, nil
+}
+
+// Fail returns an error reporting that the input for label is missing or invalid
+// and cannot be prompted for in non-interactive mode
+func Fail(label interface{}) error {
+	return errs.New(errs.Validation, "input for '%v' is missing or invalid and cannot be prompted for in non-interactive mode; "+
+		"provide it through the command's flags", label)
+}
+
+// promptError classifies an error returned when running a prompt
+func promptError(err error) error {
+	if err == promptui.ErrInterrupt || err == promptui.ErrEOF || err == promptui.ErrAbort {
+		return errs.Wrap(errs.Aborted, err, "prompt aborted")
+	}
+	return errs.Wrap(errs.Internal, err, "prompt failed")
+}
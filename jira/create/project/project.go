@@ -0,0 +1,229 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"omniactl/errs"
+	"omniactl/interactive"
+	createRole "omniactl/jira/create/role"
+	listProject "omniactl/jira/list/project"
+	listRole "omniactl/jira/list/role"
+	listScheme "omniactl/jira/list/scheme"
+	listUser "omniactl/jira/list/user"
+	"omniactl/jira/service"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// DefaultType is the type of new projects if neither a type nor a template is provided
+const DefaultType = "software"
+
+// keyFormat is the default format of Jira project keys: an uppercase letter
+// followed by uppercase letters, digits or underscores
+var keyFormat = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,9}$`)
+
+// Template is a Jira project template and the project type it belongs to
+type Template struct {
+	Type string
+	Key  string
+}
+
+// Templates maps the short names accepted by --template to Jira project templates.
+// Any other template can be given by its full key, e.g. com.pyxis.greenhopper.jira:gh-scrum-template.
+var Templates = map[string]Template{
+	"scrum":        {"software", "com.pyxis.greenhopper.jira:gh-scrum-template"},
+	"kanban":       {"software", "com.pyxis.greenhopper.jira:gh-kanban-template"},
+	"basic":        {"software", "com.pyxis.greenhopper.jira:basic-software-development-template"},
+	"business":     {"business", "com.atlassian.jira-core-project-templates:jira-core-project-management"},
+	"service_desk": {"service_desk", "com.atlassian.servicedesk:itil-v2-service-desk-project"},
+}
+
+// Project holds the values a Jira project is created with. Schemes are given
+// by name or ID; Developers and Administrators hold users or groups.
+type Project struct {
+	Name               string
+	Key                string
+	Lead               string
+	Type               string
+	Template           string
+	PermissionScheme   string
+	NotificationScheme string
+	Developers         []string
+	Administrators     []string
+}
+
+// Request is the body of a request to create a Jira project
+type Request struct {
+	Key                string `json:"key"`
+	Name               string `json:"name"`
+	ProjectTypeKey     string `json:"projectTypeKey"`
+	ProjectTemplateKey string `json:"projectTemplateKey,omitempty"`
+	Lead               string `json:"lead"`
+	PermissionScheme   int64  `json:"permissionScheme,omitempty"`
+	NotificationScheme int64  `json:"notificationScheme,omitempty"`
+}
+
+// CreateProject creates a Jira project, then assigns the standard Developers and
+// Administrators project roles. The lead defaults to the user omniactl is logged in
+// as and the type to software. Missing name and key are prompted for. All input is
+// checked before the project is created.
+func CreateProject(svc *service.Service, p Project) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Create new Jira project")
+
+	name, err := CheckName(svc, p.Name)
+	if err != nil {
+		return err
+	}
+	key, err := CheckKey(svc, p.Key)
+	if err != nil {
+		return err
+	}
+	req := Request{Key: key, Name: name, Lead: p.Lead}
+	if req.Lead == "" {
+		req.Lead = svc.Username
+	}
+	exists, err := listUser.CheckIfUserExists(svc, req.Lead)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.New(errs.NotFound, "project lead '%v' is not a Jira user", req.Lead)
+	}
+	req.ProjectTypeKey, req.ProjectTemplateKey, err = CheckTemplate(p.Type, p.Template)
+	if err != nil {
+		return err
+	}
+	if p.PermissionScheme != "" {
+		scheme, err := listScheme.FindPermissionScheme(svc, p.PermissionScheme)
+		if err != nil {
+			return err
+		}
+		req.PermissionScheme = scheme.ID
+	}
+	if p.NotificationScheme != "" {
+		scheme, err := listScheme.FindNotificationScheme(svc, p.NotificationScheme)
+		if err != nil {
+			return err
+		}
+		req.NotificationScheme = scheme.ID
+	}
+
+	// Users and groups are resolved before the project is created, so that a
+	// mistyped name does not leave a project without its roles
+	roles := []string{createRole.DeveloperRole, createRole.AdministratorRole}
+	names := map[string][]string{createRole.DeveloperRole: p.Developers, createRole.AdministratorRole: p.Administrators}
+	actors := make(map[string]createRole.Actors)
+	for _, role := range roles {
+		if actors[role], err = createRole.ResolveActors(svc, names[role]); err != nil {
+			return err
+		}
+	}
+
+	if err := CreateJiraProject(svc, req); err != nil {
+		return err
+	}
+	for _, name := range roles {
+		if len(names[name]) == 0 {
+			continue
+		}
+		role, err := listRole.FindRole(svc, name)
+		if err != nil {
+			return err
+		}
+		if err := createRole.AssignRole(svc, key, role, actors[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckName checks flag input, prompting if none was set, and that no project has the name yet
+func CheckName(svc *service.Service, name string) (string, error) {
+	if name == "" {
+		prompt := promptui.Prompt{
+			Label: "Jira project name",
+			Validate: func(input string) error {
+				if input == "" {
+					return errors.New("Enter a name for the new project")
+				}
+				return nil
+			},
+		}
+		var err error
+		if name, err = interactive.Prompt(prompt); err != nil {
+			return "", err
+		}
+	}
+	if _, err := listProject.FindProject(svc, name); err == nil {
+		return "", errs.New(errs.AlreadyExists, "Jira project '%v' already exists", name)
+	} else if !errs.Is(err, errs.NotFound) {
+		return "", err
+	}
+	return name, nil
+}
+
+// CheckKey checks flag input, prompting if none or an invalid key was set,
+// and that no project has the key yet
+func CheckKey(svc *service.Service, key string) (string, error) {
+	validate := func(input string) error {
+		if !keyFormat.MatchString(input) {
+			return errors.New("Enter 2 to 10 uppercase letters, digits or underscores, starting with a letter, e.g. 'MSF'")
+		}
+		return nil
+	}
+	if validate(key) != nil {
+		prompt := promptui.Prompt{
+			Label:    "Jira project key",
+			Validate: validate,
+		}
+		var err error
+		if key, err = interactive.Prompt(prompt); err != nil {
+			return "", err
+		}
+	}
+	if _, err := listProject.FindProject(svc, key); err == nil {
+		return "", errs.New(errs.AlreadyExists, "Jira project with key '%v' already exists", key)
+	} else if !errs.Is(err, errs.NotFound) {
+		return "", err
+	}
+	return key, nil
+}
+
+// CheckTemplate returns the project type and template key for the template, given by
+// short name or full key. A short name sets the type; a full key needs the type to be
+// provided unless it is software.
+func CheckTemplate(projectType string, template string) (string, string, error) {
+	if t, ok := Templates[template]; ok {
+		if projectType != "" && projectType != t.Type {
+			return "", "", errs.New(errs.Validation, "template '%v' is for '%v' projects, not '%v'", template, t.Type, projectType)
+		}
+		return t.Type, t.Key, nil
+	}
+	if template != "" && !strings.Contains(template, ":") {
+		names := []string{}
+		for name := range Templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", "", errs.New(errs.Validation, "unknown template '%v', use one of %v or a full template key", template, strings.Join(names, ", "))
+	}
+	if projectType == "" {
+		projectType = DefaultType
+	}
+	return projectType, template, nil
+}
+
+// CreateJiraProject sends an HTTP Post request to create the project
+func CreateJiraProject(svc *service.Service, req Request) error {
+	if err := svc.Do("POST", "project", req, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error creating Jira project '%v'", req.Name)
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Jira project '%v' created with key '%v' and lead '%v'.\n", req.Name, req.Key, req.Lead)
+	return nil
+}
@@ -0,0 +1,134 @@
+package project_test
+
+import (
+	"omniactl/errs"
+	"omniactl/interactive"
+	createProject "omniactl/jira/create/project"
+	createRole "omniactl/jira/create/role"
+	"omniactl/jira/fake"
+	"omniactl/jira/service"
+	"os"
+	"testing"
+)
+
+var (
+	// server is the fake Jira instance used by all tests in this package
+	server *fake.Server
+	// svc is the Jira service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddProject("MSF", "Market Surveillance")
+	server.AddUser("e900010", "Project Lead", "e900010@statestreet.com")
+	server.AddUser("e900011", "Developer", "e900011@statestreet.com")
+	server.AddGroup("msf-admins", "e900010")
+	svc = service.New(server.URL, server.Client())
+	svc.Username = fake.AdminLogin
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestCreateProject(t *testing.T) {
+	type test struct {
+		key      string
+		name     string
+		lead     string
+		wantLead string
+	}
+	tests := []test{
+		{"GAL", "Galleon Engineering", "", fake.AdminLogin},
+		{"OPS", "Operations", "e900010", "e900010"},
+	}
+	for _, tc := range tests {
+		if err := createProject.CreateProject(svc, createProject.Project{Name: tc.name, Key: tc.key, Lead: tc.lead}); err != nil {
+			t.Fatalf("Unexpected error creating '%v': %v", tc.name, err)
+		}
+		project := server.Project(tc.key)
+		if project == nil || project.Name != tc.name || project.Lead != tc.wantLead || project.Type != "software" {
+			t.Errorf("Project '%v' was not created as expected: %+v", tc.key, project)
+		}
+	}
+}
+
+func TestCreateProjectFromTemplate(t *testing.T) {
+	schemeID := server.AddPermissionScheme("Restricted Permission Scheme")
+	p := createProject.Project{
+		Name:               "Trading Platform",
+		Key:                "TRD",
+		Template:           "scrum",
+		PermissionScheme:   "restricted permission scheme",
+		NotificationScheme: "Default Notification Scheme",
+		Developers:         []string{"e900011"},
+		Administrators:     []string{"e900010", "msf-admins"},
+	}
+	if err := createProject.CreateProject(svc, p); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	project := server.Project("TRD")
+	if project == nil {
+		t.Fatal("Project 'TRD' was not created")
+	}
+	if project.Type != "software" || project.Template != createProject.Templates["scrum"].Key {
+		t.Errorf("Expected the scrum template Got type '%v' and template '%v'", project.Type, project.Template)
+	}
+	if project.PermissionScheme != schemeID || project.NotificationScheme == 0 {
+		t.Errorf("Expected permission scheme %v and a notification scheme Got %v and %v", schemeID, project.PermissionScheme, project.NotificationScheme)
+	}
+	developers := server.Role("Developers").ID
+	if users := project.Roles[developers]; len(users) != 1 || users[0] != "e900011" {
+		t.Errorf("Expected developers [e900011] Got %v", users)
+	}
+	administrators := server.Role("Administrators").ID
+	if users, groups := project.Roles[administrators], project.Groups[administrators]; len(users) != 1 || len(groups) != 1 || groups[0] != "msf-admins" {
+		t.Errorf("Expected administrators [e900010] and groups [msf-admins] Got %v and %v", users, groups)
+	}
+}
+
+func TestCreateProjectErrors(t *testing.T) {
+	type test struct {
+		name    string
+		project createProject.Project
+		want    errs.Kind
+	}
+	tests := []test{
+		{"existing name", createProject.Project{Name: "Market Surveillance", Key: "NEW"}, errs.AlreadyExists},
+		{"existing key", createProject.Project{Name: "New Project", Key: "MSF"}, errs.AlreadyExists},
+		{"invalid key", createProject.Project{Name: "New Project", Key: "new"}, errs.Validation},
+		{"unknown lead", createProject.Project{Name: "New Project", Key: "NEW", Lead: "e999999"}, errs.NotFound},
+		{"unknown template", createProject.Project{Name: "New Project", Key: "NEW", Template: "waterfall"}, errs.Validation},
+		{"template of other type", createProject.Project{Name: "New Project", Key: "NEW", Type: "business", Template: "kanban"}, errs.Validation},
+		{"unknown permission scheme", createProject.Project{Name: "New Project", Key: "NEW", PermissionScheme: "Open"}, errs.NotFound},
+		{"unknown notification scheme", createProject.Project{Name: "New Project", Key: "NEW", NotificationScheme: "99"}, errs.NotFound},
+		{"unknown developer", createProject.Project{Name: "New Project", Key: "NEW", Developers: []string{"nobody"}}, errs.NotFound},
+	}
+	for _, tc := range tests {
+		err := createProject.CreateProject(svc, tc.project)
+		if !errs.Is(err, tc.want) {
+			t.Errorf("Creating project with %v: expected '%v' error Got '%v'", tc.name, tc.want, err)
+		}
+	}
+	if server.Project("NEW") != nil {
+		t.Error("Project 'NEW' was created despite invalid input")
+	}
+}
+
+func TestCreateRole(t *testing.T) {
+	if err := createRole.CreateRole(svc, "Testers", "Test the project"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	role := server.Role("Testers")
+	if role == nil || role.Description != "Test the project" {
+		t.Errorf("Role 'Testers' was not created as expected: %+v", role)
+	}
+
+	err := createRole.CreateRole(svc, "Developers", "")
+	if !errs.Is(err, errs.AlreadyExists) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.AlreadyExists, err)
+	}
+}
@@ -0,0 +1,125 @@
+package role
+
+import (
+	"errors"
+	"fmt"
+	"omniactl/errs"
+	"omniactl/interactive"
+	listGroup "omniactl/jira/list/group"
+	listRole "omniactl/jira/list/role"
+	listUser "omniactl/jira/list/user"
+	"omniactl/jira/result"
+	"omniactl/jira/service"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// Standard project roles assigned when a project is created
+const (
+	DeveloperRole     = "Developers"
+	AdministratorRole = "Administrators"
+)
+
+// Actors are the users and groups assigned to a project role
+type Actors struct {
+	User  []string `json:"user,omitempty"`
+	Group []string `json:"group,omitempty"`
+}
+
+// Role is the body of a request to create a Jira project role
+type Role struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CreateRole creates a Jira project role, prompting for the name if none was provided
+func CreateRole(svc *service.Service, name string, description string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Create new Jira role")
+
+	name, err := CheckName(svc, name)
+	if err != nil {
+		return err
+	}
+	return CreateJiraRole(svc, name, description)
+}
+
+// CheckName checks flag input, prompting if none was set, and that the role does not exist yet
+func CheckName(svc *service.Service, name string) (string, error) {
+	if name == "" {
+		prompt := promptui.Prompt{
+			Label: "Jira role name",
+			Validate: func(input string) error {
+				if input == "" {
+					return errors.New("Enter a name for the new role")
+				}
+				return nil
+			},
+		}
+		var err error
+		if name, err = interactive.Prompt(prompt); err != nil {
+			return "", err
+		}
+	}
+	if _, err := listRole.FindRole(svc, name); err == nil {
+		return "", errs.New(errs.AlreadyExists, "Jira role '%v' already exists", name)
+	} else if !errs.Is(err, errs.NotFound) {
+		return "", err
+	}
+	return name, nil
+}
+
+// CreateJiraRole sends an HTTP Post request to create the project role
+func CreateJiraRole(svc *service.Service, name string, description string) error {
+	if err := svc.Do("POST", "role", Role{Name: name, Description: description}, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error creating Jira role '%v'", name)
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Jira role '%v' created.\n", name)
+	return nil
+}
+
+// ResolveActors sorts the names into users and groups, checking users first,
+// and returns a NotFound error for a name which is neither
+func ResolveActors(svc *service.Service, names []string) (Actors, error) {
+	actors := Actors{}
+	for _, name := range names {
+		isUser, err := listUser.CheckIfUserExists(svc, name)
+		if err != nil {
+			return actors, err
+		}
+		if isUser {
+			actors.User = append(actors.User, name)
+			continue
+		}
+		isGroup, err := listGroup.CheckIfGroupExists(svc, name)
+		if err != nil {
+			return actors, err
+		}
+		if !isGroup {
+			return actors, errs.New(errs.NotFound, "'%v' is neither a Jira user nor a Jira group", name)
+		}
+		actors.Group = append(actors.Group, name)
+	}
+	return actors, nil
+}
+
+// AssignRole adds the users and groups to the project role of the project
+func AssignRole(svc *service.Service, projectKey string, role *result.Role, actors Actors) error {
+	if len(actors.User) == 0 && len(actors.Group) == 0 {
+		return nil
+	}
+	url := fmt.Sprintf("project/%v/role/%v", projectKey, role.ID)
+	if err := svc.Do("POST", url, actors, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error assigning role '%v' in Jira project '%v'", role.Name, projectKey)
+	}
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	for _, name := range actors.User {
+		whiteBold.Printf("User '%v' added to Jira project '%v' as '%v'.\n", name, projectKey, role.Name)
+	}
+	for _, name := range actors.Group {
+		whiteBold.Printf("Group '%v' added to Jira project '%v' as '%v'.\n", name, projectKey, role.Name)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"omniactl/errs"
+	"omniactl/interactive"
+	listProject "omniactl/jira/list/project"
+	listRole "omniactl/jira/list/role"
+	listUser "omniactl/jira/list/user"
+	"omniactl/jira/service"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// DefaultRole is the project role new users are added with if none is provided
+const DefaultRole = "Users"
+
+// User is the body of a request to create a Jira user
+type User struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+// AddUser creates a Jira user and adds them to the project, given by key or name,
+// with the project role. Missing input is prompted for.
+func AddUser(svc *service.Service, username string, name string, email string, project string, role string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Add new user to Jira")
+
+	username, err := CheckUsername(svc, username)
+	if err != nil {
+		return err
+	}
+	name, err = check(name, "Full name", "Enter the full name of the user")
+	if err != nil {
+		return err
+	}
+	email, err = check(email, "Email", "Enter the email address of the user")
+	if err != nil {
+		return err
+	}
+	project, err = check(project, "Jira project key or name", "Enter the project the user is added to")
+	if err != nil {
+		return err
+	}
+	p, err := listProject.FindProject(svc, project)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		role = DefaultRole
+	}
+	r, err := listRole.FindRole(svc, role)
+	if err != nil {
+		return err
+	}
+
+	check, err := PromptAddUser(username, p.Key, r.Name)
+	if err != nil {
+		return err
+	}
+	if check != "yes" {
+		return nil
+	}
+	if err := CreateUser(svc, username, name, email); err != nil {
+		return err
+	}
+	return AddUserToProject(svc, username, p.Key, r.ID, r.Name)
+}
+
+// CheckUsername checks flag input, prompting if none was set, and that the user does not exist yet
+func CheckUsername(svc *service.Service, username string) (string, error) {
+	username, err := check(username, "Username", "Enter the username of the new user, i.e. their State Street Lan ID")
+	if err != nil {
+		return "", err
+	}
+	exists, err := listUser.CheckIfUserExists(svc, username)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", errs.New(errs.AlreadyExists, "Jira user '%v' already exists", username)
+	}
+	return username, nil
+}
+
+// check returns value if set, otherwise prompts for it
+func check(value string, label string, hint string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	validate := func(input string) error {
+		if input == "" {
+			return errors.New(hint)
+		}
+		return nil
+	}
+	prompt := promptui.Prompt{
+		Label:    label,
+		Validate: validate,
+	}
+	return interactive.Prompt(prompt)
+}
+
+// PromptAddUser asks for confirmation before the user is created
+func PromptAddUser(username string, project string, role string) (string, error) {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Create Jira user '%v' and add them to project '%v' as '%v'?", username, project, role),
+		Items: []string{"yes", "no"},
+	}
+	return interactive.Select(prompt, "yes")
+}
+
+// CreateUser sends an HTTP Post request to create the Jira user.
+// Jira emails the user a link to set their password.
+func CreateUser(svc *service.Service, username string, name string, email string) error {
+	body := User{Name: username, DisplayName: name, EmailAddress: email}
+	if err := svc.Do("POST", "user", body, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error creating Jira user '%v'", username)
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Jira user '%v' created.\n", username)
+	return nil
+}
+
+// AddUserToProject adds the user to the project with the project role
+func AddUserToProject(svc *service.Service, username string, projectKey string, roleID int64, roleName string) error {
+	body := map[string][]string{"user": {username}}
+	url := fmt.Sprintf("project/%v/role/%v", projectKey, roleID)
+	if err := svc.Do("POST", url, body, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error adding '%v' to Jira project '%v'", username, projectKey)
+	}
+	color.New(color.FgHiWhite, color.Bold).Printf("User '%v' added to Jira project '%v' as '%v'.\n", username, projectKey, roleName)
+	return nil
+}
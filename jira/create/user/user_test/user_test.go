@@ -0,0 +1,91 @@
+package user_test
+
+import (
+	"omniactl/errs"
+	"omniactl/interactive"
+	createUser "omniactl/jira/create/user"
+	"omniactl/jira/fake"
+	"omniactl/jira/service"
+	"os"
+	"testing"
+)
+
+var (
+	// server is the fake Jira instance used by all tests in this package
+	server *fake.Server
+	// svc is the Jira service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	server.AddProject("MSF", "Market Surveillance")
+	svc = service.New(server.URL, server.Client())
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestAddUser(t *testing.T) {
+	type test struct {
+		username string
+		project  string
+		role     string
+		wantRole string
+	}
+	tests := []test{
+		{"e900001", "MSF", "", "Users"},
+		{"e900002", "Market Surveillance", "Developers", "Developers"},
+	}
+	for _, tc := range tests {
+		err := createUser.AddUser(svc, tc.username, "Test User", tc.username+"@statestreet.com", tc.project, tc.role)
+		if err != nil {
+			t.Fatalf("Unexpected error adding '%v': %v", tc.username, err)
+		}
+		user := server.User(tc.username)
+		if user == nil || !user.Active || user.Email != tc.username+"@statestreet.com" {
+			t.Errorf("User '%v' was not created: %+v", tc.username, user)
+		}
+		role := server.Role(tc.wantRole)
+		if !contains(server.Project("MSF").Roles[role.ID], tc.username) {
+			t.Errorf("User '%v' was not added to project 'MSF' as '%v'", tc.username, tc.wantRole)
+		}
+	}
+}
+
+func TestAddUserErrors(t *testing.T) {
+	server.AddUser("e900003", "Existing User", "e900003@statestreet.com")
+
+	type test struct {
+		username string
+		project  string
+		role     string
+		want     errs.Kind
+	}
+	tests := []test{
+		{"e900003", "MSF", "", errs.AlreadyExists},
+		{"e900004", "NOPE", "", errs.NotFound},
+		{"e900005", "MSF", "Nobody", errs.NotFound},
+		{"", "MSF", "", errs.Validation},
+	}
+	for _, tc := range tests {
+		err := createUser.AddUser(svc, tc.username, "Test User", "test@statestreet.com", tc.project, tc.role)
+		if !errs.Is(err, tc.want) {
+			t.Errorf("Adding '%v' to '%v' as '%v': expected '%v' error Got '%v'", tc.username, tc.project, tc.role, tc.want, err)
+		}
+	}
+	if server.User("e900004") != nil || server.User("e900005") != nil {
+		t.Error("User was created although the project or role does not exist")
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,625 @@
+// Package fake provides an in-process fake of the Jira Server REST API v2 for tests.
+// It keeps users, projects and project roles in memory, so tests can run against it
+// without network access.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Credentials of the administrator the fake client authenticates as
+const (
+	AdminLogin    = "admin"
+	AdminPassword = "secret"
+)
+
+// SessionID is the value of the JSESSIONID cookie of the session created for the
+// administrator
+const SessionID = "fake-jira-session"
+
+// Version is the Jira version reported by the fake server
+const Version = "7.13.0"
+
+// User is a Jira user held by the fake server
+type User struct {
+	Name        string
+	DisplayName string
+	Email       string
+	Active      bool
+}
+
+// Project is a Jira project held by the fake server, with the users and groups in each
+// project role mapped by role ID
+type Project struct {
+	ID                 int64
+	Key                string
+	Name               string
+	Type               string
+	Lead               string
+	Template           string
+	PermissionScheme   int64
+	NotificationScheme int64
+	Roles              map[int64][]string
+	Groups             map[int64][]string
+}
+
+// Role is a Jira project role held by the fake server
+type Role struct {
+	ID          int64
+	Name        string
+	Description string
+}
+
+// Server is an in-process fake Jira Server instance
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	nextID   int64
+	users    map[string]*User
+	projects map[string]*Project
+	roles    map[int64]*Role
+	groups   map[string][]string
+	// permissionSchemes and notificationSchemes map scheme IDs to names
+	permissionSchemes   map[int64]string
+	notificationSchemes map[int64]string
+	routes              []route
+}
+
+// NewServer starts a fake Jira server containing the administrator, the default
+// project roles: Administrators, Developers and Users, and the default permission
+// and notification schemes.
+// The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		nextID:   10000,
+		users:    make(map[string]*User),
+		projects: make(map[string]*Project),
+		roles:    make(map[int64]*Role),
+		groups:   make(map[string][]string),
+
+		permissionSchemes:   make(map[int64]string),
+		notificationSchemes: make(map[int64]string),
+	}
+	s.registerRoutes()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.AddUser(AdminLogin, "Administrator", "admin@statestreet.com")
+	for _, role := range []string{"Administrators", "Developers", "Users"} {
+		s.AddRole(role)
+	}
+	s.AddPermissionScheme("Default Permission Scheme")
+	s.AddNotificationScheme("Default Notification Scheme")
+	return s
+}
+
+// Client returns an HTTP client authenticating as the administrator
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: &basicAuth{AdminLogin, AdminPassword}}
+}
+
+// basicAuth sets the credentials of every request it sends
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (b *basicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	clone.SetBasicAuth(b.username, b.password)
+	return http.DefaultTransport.RoundTrip(clone)
+}
+
+// AddUser adds an active user to the fake server
+func (s *Server) AddUser(name string, displayName string, email string) *User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := &User{Name: name, DisplayName: displayName, Email: email, Active: true}
+	s.users[name] = u
+	return u
+}
+
+// AddProject adds a software project led by the administrator to the fake server
+func (s *Server) AddProject(key string, name string) *Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addProject(key, name, "software", AdminLogin)
+}
+
+// AddRole adds a project role to the fake server
+func (s *Server) AddRole(name string) *Role {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addRole(name, "")
+}
+
+// AddGroup adds a group with the provided members to the fake server
+func (s *Server) AddGroup(name string, members ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[name] = members
+}
+
+// AddPermissionScheme adds a permission scheme to the fake server and returns its ID
+func (s *Server) AddPermissionScheme(name string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.id()
+	s.permissionSchemes[id] = name
+	return id
+}
+
+// AddNotificationScheme adds a notification scheme to the fake server and returns its ID
+func (s *Server) AddNotificationScheme(name string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.id()
+	s.notificationSchemes[id] = name
+	return id
+}
+
+// User returns a copy of the user with the provided name, or nil if it does not exist
+func (s *Server) User(name string) *User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[name]
+	if !ok {
+		return nil
+	}
+	c := *u
+	return &c
+}
+
+// Project returns a copy of the project with the provided key, or nil if it does not exist
+func (s *Server) Project(key string) *Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[key]
+	if !ok {
+		return nil
+	}
+	c := *p
+	c.Roles = make(map[int64][]string)
+	for id, users := range p.Roles {
+		c.Roles[id] = append([]string{}, users...)
+	}
+	c.Groups = make(map[int64][]string)
+	for id, groups := range p.Groups {
+		c.Groups[id] = append([]string{}, groups...)
+	}
+	return &c
+}
+
+// Role returns a copy of the role with the provided name, or nil if it does not exist
+func (s *Server) Role(name string) *Role {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := s.findRole(name); r != nil {
+		c := *r
+		return &c
+	}
+	return nil
+}
+
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) addProject(key string, name string, projectType string, lead string) *Project {
+	p := &Project{ID: s.id(), Key: key, Name: name, Type: projectType, Lead: lead, Roles: make(map[int64][]string), Groups: make(map[int64][]string)}
+	s.projects[key] = p
+	return p
+}
+
+func (s *Server) addRole(name string, description string) *Role {
+	r := &Role{ID: s.id(), Name: name, Description: description}
+	s.roles[r.ID] = r
+	return r
+}
+
+func (s *Server) findRole(name string) *Role {
+	for _, r := range s.roles {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// findProject returns the project with the provided key or ID
+func (s *Server) findProject(keyOrID string) *Project {
+	if p, ok := s.projects[keyOrID]; ok {
+		return p
+	}
+	for _, p := range s.projects {
+		if strconv.FormatInt(p.ID, 10) == keyOrID {
+			return p
+		}
+	}
+	return nil
+}
+
+// authenticated reports whether the request has the credentials or the session
+// cookie of the administrator
+func authenticated(r *http.Request) bool {
+	if c, err := r.Cookie("JSESSIONID"); err == nil {
+		return c.Value == SessionID
+	}
+	username, password, ok := r.BasicAuth()
+	return ok && username == AdminLogin && password == AdminPassword
+}
+
+// serveSession creates a session for the administrator, as Jira does for
+// POST rest/auth/1/session
+func (s *Server) serveSession(w http.ResponseWriter, r *http.Request) {
+	credentials := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&credentials)
+	if credentials.Username != AdminLogin || credentials.Password != AdminPassword {
+		writeError(w, http.StatusUnauthorized, "Login failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"session":   map[string]string{"name": "JSESSIONID", "value": SessionID},
+		"loginInfo": map[string]int{"loginCount": 1, "previousLoginTime": 0},
+	})
+}
+
+// route maps a method and path pattern, e.g. "project/{project}/role", to a handler
+type route struct {
+	method  string
+	pattern []string
+	handler func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+func (s *Server) handle(method string, pattern string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string)) {
+	s.routes = append(s.routes, route{method, strings.Split(pattern, "/"), handler})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" && r.URL.Path == "/rest/auth/1/session" {
+		s.serveSession(w, r)
+		return
+	}
+	if !authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "You are not authenticated")
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/rest/api/2"), "/")
+	segments := strings.Split(path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rt := range s.routes {
+		if rt.method != r.Method || len(rt.pattern) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		match := true
+		for i, p := range rt.pattern {
+			if strings.HasPrefix(p, "{") {
+				params[strings.Trim(p, "{}")] = segments[i]
+			} else if p != segments[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			rt.handler(w, r, params)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format of the Jira REST API
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"errorMessages": []string{message}, "errors": map[string]string{}})
+}
+
+func decode(r *http.Request, v interface{}) {
+	json.NewDecoder(r.Body).Decode(v)
+}
+
+func userJSON(u *User) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         u.Name,
+		"key":          u.Name,
+		"displayName":  u.DisplayName,
+		"emailAddress": u.Email,
+		"active":       u.Active,
+	}
+}
+
+func (s *Server) projectJSON(p *Project) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             strconv.FormatInt(p.ID, 10),
+		"key":            p.Key,
+		"name":           p.Name,
+		"projectTypeKey": p.Type,
+		"lead":           userJSON(s.users[p.Lead]),
+	}
+}
+
+// schemesJSON returns the schemes sorted by ID
+func schemesJSON(schemes map[int64]string) []interface{} {
+	ids := []int64{}
+	for id := range schemes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	items := []interface{}{}
+	for _, id := range ids {
+		items = append(items, map[string]interface{}{"id": id, "name": schemes[id]})
+	}
+	return items
+}
+
+func roleJSON(r *Role) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          r.ID,
+		"name":        r.Name,
+		"description": r.Description,
+	}
+}
+
+func (s *Server) registerRoutes() {
+	s.handle("GET", "myself", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		writeJSON(w, http.StatusOK, userJSON(s.users[AdminLogin]))
+	})
+	s.handle("GET", "serverInfo", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"version": Version, "deploymentType": "Server"})
+	})
+
+	// Users
+	user := func(w http.ResponseWriter, r *http.Request) *User {
+		u, ok := s.users[r.URL.Query().Get("username")]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("The user named '%v' does not exist", r.URL.Query().Get("username")))
+			return nil
+		}
+		return u
+	}
+	s.handle("GET", "user", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if u := user(w, r); u != nil {
+			writeJSON(w, http.StatusOK, userJSON(u))
+		}
+	})
+	s.handle("POST", "user", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		body := struct {
+			Name         string `json:"name"`
+			DisplayName  string `json:"displayName"`
+			EmailAddress string `json:"emailAddress"`
+		}{}
+		decode(r, &body)
+		if _, ok := s.users[body.Name]; ok {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errorMessages": []string{}, "errors": map[string]string{"username": "A user with that username already exists."}})
+			return
+		}
+		if body.Name == "" || body.EmailAddress == "" {
+			writeError(w, http.StatusBadRequest, "You must specify a username and an email address")
+			return
+		}
+		u := &User{Name: body.Name, DisplayName: body.DisplayName, Email: body.EmailAddress, Active: true}
+		s.users[u.Name] = u
+		writeJSON(w, http.StatusCreated, userJSON(u))
+	})
+	s.handle("PUT", "user", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if u := user(w, r); u != nil {
+			body := struct {
+				Active *bool `json:"active"`
+			}{}
+			decode(r, &body)
+			if body.Active != nil {
+				u.Active = *body.Active
+			}
+			writeJSON(w, http.StatusOK, userJSON(u))
+		}
+	})
+	s.handle("GET", "user/search", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		query := strings.ToLower(r.URL.Query().Get("username"))
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		maxResults, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		if maxResults < 1 {
+			maxResults = 50
+		}
+		names := []string{}
+		for name, u := range s.users {
+			for _, field := range []string{u.Name, u.DisplayName, u.Email} {
+				if strings.HasPrefix(strings.ToLower(field), query) {
+					names = append(names, name)
+					break
+				}
+			}
+		}
+		sort.Strings(names)
+		items := []interface{}{}
+		for i := startAt; i < len(names) && i < startAt+maxResults; i++ {
+			items = append(items, userJSON(s.users[names[i]]))
+		}
+		writeJSON(w, http.StatusOK, items)
+	})
+
+	// Projects
+	s.handle("GET", "project", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		keys := []string{}
+		for key := range s.projects {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := []interface{}{}
+		for _, key := range keys {
+			items = append(items, s.projectJSON(s.projects[key]))
+		}
+		writeJSON(w, http.StatusOK, items)
+	})
+	s.handle("POST", "project", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		body := struct {
+			Key                string `json:"key"`
+			Name               string `json:"name"`
+			ProjectTypeKey     string `json:"projectTypeKey"`
+			ProjectTemplateKey string `json:"projectTemplateKey"`
+			Lead               string `json:"lead"`
+			PermissionScheme   int64  `json:"permissionScheme"`
+			NotificationScheme int64  `json:"notificationScheme"`
+		}{}
+		decode(r, &body)
+		errors := map[string]string{}
+		if _, ok := s.projects[body.Key]; ok {
+			errors["projectKey"] = "A project with that project key already exists."
+		}
+		if body.Key == "" || body.Name == "" || body.ProjectTypeKey == "" {
+			errors["project"] = "You must specify a key, a name and a project type."
+		}
+		if _, ok := s.users[body.Lead]; !ok {
+			errors["projectLead"] = "The project lead must be an existing user."
+		}
+		if _, ok := s.permissionSchemes[body.PermissionScheme]; body.PermissionScheme != 0 && !ok {
+			errors["permissionScheme"] = "The permission scheme does not exist."
+		}
+		if _, ok := s.notificationSchemes[body.NotificationScheme]; body.NotificationScheme != 0 && !ok {
+			errors["notificationScheme"] = "The notification scheme does not exist."
+		}
+		if len(errors) > 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errorMessages": []string{}, "errors": errors})
+			return
+		}
+		project := s.addProject(body.Key, body.Name, body.ProjectTypeKey, body.Lead)
+		project.Template = body.ProjectTemplateKey
+		project.PermissionScheme = body.PermissionScheme
+		project.NotificationScheme = body.NotificationScheme
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"id": project.ID, "key": project.Key})
+	})
+	project := func(w http.ResponseWriter, p map[string]string) *Project {
+		project := s.findProject(p["project"])
+		if project == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No project could be found with key '%v'.", p["project"]))
+		}
+		return project
+	}
+	s.handle("GET", "project/{project}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if project := project(w, p); project != nil {
+			writeJSON(w, http.StatusOK, s.projectJSON(project))
+		}
+	})
+	s.handle("GET", "project/{project}/role/{role}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if project := project(w, p); project != nil {
+			id, _ := strconv.ParseInt(p["role"], 10, 64)
+			role, ok := s.roles[id]
+			if !ok {
+				writeError(w, http.StatusNotFound, "Role not found")
+				return
+			}
+			actors := []interface{}{}
+			for _, name := range project.Roles[id] {
+				actors = append(actors, map[string]interface{}{"name": name, "type": "atlassian-user-role-actor"})
+			}
+			for _, name := range project.Groups[id] {
+				actors = append(actors, map[string]interface{}{"name": name, "type": "atlassian-group-role-actor"})
+			}
+			v := roleJSON(role)
+			v["actors"] = actors
+			writeJSON(w, http.StatusOK, v)
+		}
+	})
+	s.handle("POST", "project/{project}/role/{role}", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		if project := project(w, p); project != nil {
+			id, _ := strconv.ParseInt(p["role"], 10, 64)
+			role, ok := s.roles[id]
+			if !ok {
+				writeError(w, http.StatusNotFound, "Role not found")
+				return
+			}
+			body := struct {
+				User  []string `json:"user"`
+				Group []string `json:"group"`
+			}{}
+			decode(r, &body)
+			for _, name := range body.User {
+				if _, ok := s.users[name]; !ok {
+					writeError(w, http.StatusBadRequest, fmt.Sprintf("The user '%v' does not exist", name))
+					return
+				}
+			}
+			for _, name := range body.Group {
+				if _, ok := s.groups[name]; !ok {
+					writeError(w, http.StatusBadRequest, fmt.Sprintf("The group '%v' does not exist", name))
+					return
+				}
+			}
+			project.Roles[id] = append(project.Roles[id], body.User...)
+			project.Groups[id] = append(project.Groups[id], body.Group...)
+			writeJSON(w, http.StatusOK, roleJSON(role))
+		}
+	})
+
+	// Groups
+	s.handle("GET", "group/member", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		members, ok := s.groups[r.URL.Query().Get("groupname")]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("The group '%v' does not exist", r.URL.Query().Get("groupname")))
+			return
+		}
+		values := []interface{}{}
+		for _, name := range members {
+			if u, ok := s.users[name]; ok {
+				values = append(values, userJSON(u))
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"startAt": 0, "total": len(values), "isLast": true, "values": values})
+	})
+
+	// Schemes
+	s.handle("GET", "permissionscheme", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"permissionSchemes": schemesJSON(s.permissionSchemes)})
+	})
+	s.handle("GET", "notificationscheme", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		values := schemesJSON(s.notificationSchemes)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"startAt": 0, "total": len(values), "isLast": true, "values": values})
+	})
+
+	// Project roles
+	s.handle("GET", "role", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		ids := []int64{}
+		for id := range s.roles {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		items := []interface{}{}
+		for _, id := range ids {
+			items = append(items, roleJSON(s.roles[id]))
+		}
+		writeJSON(w, http.StatusOK, items)
+	})
+	s.handle("POST", "role", func(w http.ResponseWriter, r *http.Request, p map[string]string) {
+		body := struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		}{}
+		decode(r, &body)
+		if body.Name == "" {
+			writeError(w, http.StatusBadRequest, "You must specify a name for the project role")
+			return
+		}
+		if s.findRole(body.Name) != nil {
+			writeError(w, http.StatusConflict, fmt.Sprintf("A project role with name '%v' already exists.", body.Name))
+			return
+		}
+		writeJSON(w, http.StatusOK, roleJSON(s.addRole(body.Name, body.Description)))
+	})
+}
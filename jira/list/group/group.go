@@ -0,0 +1,18 @@
+package group
+
+import (
+	"omniactl/errs"
+	"omniactl/jira/service"
+)
+
+// CheckIfGroupExists checks if a Jira group with the provided name exists
+func CheckIfGroupExists(svc *service.Service, name string) (bool, error) {
+	err := svc.Do("GET", service.Query("group/member", map[string]string{"groupname": name, "maxResults": "1"}), nil, nil)
+	if errs.Is(err, errs.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errs.Wrap(errs.KindOf(err), err, "error getting Jira group '%v'", name)
+	}
+	return true, nil
+}
@@ -0,0 +1,87 @@
+package project
+
+import (
+	"fmt"
+	"omniactl/errs"
+	"omniactl/glob"
+	"omniactl/jira/result"
+	"omniactl/jira/service"
+	"omniactl/output"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Project is a project as returned by the Jira REST API
+type Project struct {
+	ID             string `json:"id"`
+	Key            string `json:"key"`
+	Name           string `json:"name"`
+	ProjectTypeKey string `json:"projectTypeKey"`
+	Lead           struct {
+		Name string `json:"name"`
+	} `json:"lead"`
+}
+
+// Result returns the project as rendered by the list commands
+func (p Project) Result() result.Project {
+	return result.Project{Key: p.Key, Name: p.Name, ID: p.ID, Type: p.ProjectTypeKey, Lead: p.Lead.Name}
+}
+
+// ListProject lists the projects whose key or name match the glob, e.g. 'MSF*'.
+// All projects are listed if the glob is empty.
+func ListProject(svc *service.Service, glob string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: List Jira projects")
+	projects, err := GetProjects(svc, glob)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(projects)
+	}
+	if len(projects) == 0 {
+		color.New(color.FgRed).Printf("No Jira projects found matching '%v'.\n", glob)
+		return nil
+	}
+	PrintProjects(projects)
+	return nil
+}
+
+// GetProjects returns the projects whose key or name match the pattern
+func GetProjects(svc *service.Service, pattern string) ([]result.Project, error) {
+	all := []Project{}
+	if err := svc.Do("GET", "project", nil, &all); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting Jira projects")
+	}
+	projects := []result.Project{}
+	for _, p := range all {
+		if glob.Match(pattern, p.Key, p.Name) {
+			projects = append(projects, p.Result())
+		}
+	}
+	return projects, nil
+}
+
+// FindProject returns the project with the provided key or name, or a NotFound error
+func FindProject(svc *service.Service, keyOrName string) (*result.Project, error) {
+	projects, err := GetProjects(svc, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		if p.Key == keyOrName || strings.EqualFold(p.Name, keyOrName) {
+			return &p, nil
+		}
+	}
+	return nil, errs.New(errs.NotFound, "Jira project '%v' does not exist", keyOrName)
+}
+
+// PrintProjects prints projects as colourised text
+func PrintProjects(projects []result.Project) {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	for i, p := range projects {
+		greenBold.Printf("%-5v", i+1)
+		fmt.Printf("Key: %-10v | Name: %-40v | Type: %-12v | Lead: %v\n", p.Key, p.Name, p.Type, p.Lead)
+	}
+}
@@ -0,0 +1,75 @@
+package role
+
+import (
+	"fmt"
+	"omniactl/errs"
+	"omniactl/glob"
+	"omniactl/jira/result"
+	"omniactl/jira/service"
+	"omniactl/output"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ListRole lists the project roles whose name matches the glob, e.g. 'Dev*'.
+// A name without wildcards matches every role containing it. All roles are
+// listed if the glob is empty.
+func ListRole(svc *service.Service, glob string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: List Jira roles")
+	if glob != "" && !strings.ContainsAny(glob, "*?[") {
+		glob = "*" + glob + "*"
+	}
+	roles, err := GetRoles(svc, glob)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(roles)
+	}
+	if len(roles) == 0 {
+		color.New(color.FgRed).Printf("No Jira roles found matching '%v'.\n", glob)
+		return nil
+	}
+	PrintRoles(roles)
+	return nil
+}
+
+// GetRoles returns the project roles whose name matches the pattern
+func GetRoles(svc *service.Service, pattern string) ([]result.Role, error) {
+	all := []result.Role{}
+	if err := svc.Do("GET", "role", nil, &all); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting Jira roles")
+	}
+	roles := []result.Role{}
+	for _, r := range all {
+		if glob.Match(pattern, r.Name) {
+			roles = append(roles, r)
+		}
+	}
+	return roles, nil
+}
+
+// FindRole returns the project role with the provided name, or a NotFound error
+func FindRole(svc *service.Service, name string) (*result.Role, error) {
+	roles, err := GetRoles(svc, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		if strings.EqualFold(r.Name, name) {
+			return &r, nil
+		}
+	}
+	return nil, errs.New(errs.NotFound, "Jira role '%v' does not exist", name)
+}
+
+// PrintRoles prints roles as colourised text
+func PrintRoles(roles []result.Role) {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	for i, r := range roles {
+		greenBold.Printf("%-5v", i+1)
+		fmt.Printf("Name: %-30v | ID: %-10v | Description: %v\n", r.Name, r.ID, r.Description)
+	}
+}
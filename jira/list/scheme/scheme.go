@@ -0,0 +1,72 @@
+package scheme
+
+import (
+	"omniactl/errs"
+	"omniactl/jira/result"
+	"omniactl/jira/service"
+	"strconv"
+	"strings"
+)
+
+// PageSize is the number of notification schemes requested per page
+const PageSize = 50
+
+// GetPermissionSchemes returns all permission schemes
+func GetPermissionSchemes(svc *service.Service) ([]result.Scheme, error) {
+	page := struct {
+		PermissionSchemes []result.Scheme `json:"permissionSchemes"`
+	}{}
+	if err := svc.Do("GET", "permissionscheme", nil, &page); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting Jira permission schemes")
+	}
+	return page.PermissionSchemes, nil
+}
+
+// GetNotificationSchemes returns all notification schemes, following every page
+func GetNotificationSchemes(svc *service.Service) ([]result.Scheme, error) {
+	schemes := []result.Scheme{}
+	for startAt := 0; ; startAt += PageSize {
+		page := struct {
+			IsLast bool            `json:"isLast"`
+			Values []result.Scheme `json:"values"`
+		}{}
+		path := service.Query("notificationscheme", map[string]string{
+			"startAt":    strconv.Itoa(startAt),
+			"maxResults": strconv.Itoa(PageSize),
+		})
+		if err := svc.Do("GET", path, nil, &page); err != nil {
+			return nil, errs.Wrap(errs.KindOf(err), err, "error getting Jira notification schemes")
+		}
+		schemes = append(schemes, page.Values...)
+		if page.IsLast || len(page.Values) < PageSize {
+			return schemes, nil
+		}
+	}
+}
+
+// FindPermissionScheme returns the permission scheme with the provided name or ID, or a NotFound error
+func FindPermissionScheme(svc *service.Service, nameOrID string) (*result.Scheme, error) {
+	schemes, err := GetPermissionSchemes(svc)
+	if err != nil {
+		return nil, err
+	}
+	return find(schemes, nameOrID, "permission")
+}
+
+// FindNotificationScheme returns the notification scheme with the provided name or ID, or a NotFound error
+func FindNotificationScheme(svc *service.Service, nameOrID string) (*result.Scheme, error) {
+	schemes, err := GetNotificationSchemes(svc)
+	if err != nil {
+		return nil, err
+	}
+	return find(schemes, nameOrID, "notification")
+}
+
+func find(schemes []result.Scheme, nameOrID string, kind string) (*result.Scheme, error) {
+	for _, s := range schemes {
+		if strings.EqualFold(s.Name, nameOrID) || strconv.FormatInt(s.ID, 10) == nameOrID {
+			return &s, nil
+		}
+	}
+	return nil, errs.New(errs.NotFound, "Jira %v scheme '%v' does not exist", kind, nameOrID)
+}
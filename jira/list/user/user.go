@@ -0,0 +1,128 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"omniactl/jira/result"
+	"omniactl/jira/service"
+	"omniactl/output"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// PageSize is the number of users requested per page when searching
+const PageSize = 50
+
+// User is a user as returned by the Jira REST API
+type User struct {
+	Name         string `json:"name"`
+	Key          string `json:"key"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Active       bool   `json:"active"`
+}
+
+// Result returns the user as rendered by the list commands
+func (u User) Result() result.User {
+	return result.User{Name: u.Name, DisplayName: u.DisplayName, Email: u.EmailAddress, Active: u.Active}
+}
+
+// ListUser lists the users whose username, name or email address start with username
+func ListUser(svc *service.Service, username string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: List Jira users")
+	username, err := CheckUsername(username)
+	if err != nil {
+		return err
+	}
+	users, err := SearchUsers(svc, username)
+	if err != nil {
+		return err
+	}
+	if !output.IsText() {
+		return output.Print(users)
+	}
+	if len(users) == 0 {
+		color.New(color.FgRed).Printf("No Jira users found matching '%v'.\n", username)
+		return nil
+	}
+	PrintUsers(users)
+	return nil
+}
+
+// CheckUsername checks flag input and if none was set prompts user
+func CheckUsername(username string) (string, error) {
+	if username != "" {
+		return username, nil
+	}
+	return PromptUsername()
+}
+
+// PromptUsername asks to enter the username, name or email address to search for
+func PromptUsername() (string, error) {
+	validate := func(input string) error {
+		if input == "" {
+			return errors.New("Enter a username, name or email address")
+		}
+		return nil
+	}
+
+	prompt := promptui.Prompt{
+		Label:    "Jira user to list",
+		Validate: validate,
+	}
+	return interactive.Prompt(prompt)
+}
+
+// GetUser returns the Jira user with the provided username, or a NotFound error
+func GetUser(svc *service.Service, username string) (*User, error) {
+	u := &User{}
+	if err := svc.Do("GET", service.Query("user", map[string]string{"username": username}), nil, u); err != nil {
+		return nil, errs.Wrap(errs.KindOf(err), err, "error getting Jira user '%v'", username)
+	}
+	return u, nil
+}
+
+// CheckIfUserExists checks if the Jira user exists
+func CheckIfUserExists(svc *service.Service, username string) (bool, error) {
+	_, err := GetUser(svc, username)
+	if errs.Is(err, errs.NotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SearchUsers returns every user whose username, name or email address start with query,
+// following the pages of results
+func SearchUsers(svc *service.Service, query string) ([]result.User, error) {
+	users := []result.User{}
+	for startAt := 0; ; startAt += PageSize {
+		page := []User{}
+		path := service.Query("user/search", map[string]string{
+			"username":   query,
+			"startAt":    fmt.Sprint(startAt),
+			"maxResults": fmt.Sprint(PageSize),
+		})
+		if err := svc.Do("GET", path, nil, &page); err != nil {
+			return nil, errs.Wrap(errs.KindOf(err), err, "error searching Jira users")
+		}
+		for _, u := range page {
+			users = append(users, u.Result())
+		}
+		if len(page) < PageSize {
+			return users, nil
+		}
+	}
+}
+
+// PrintUsers prints users as colourised text
+func PrintUsers(users []result.User) {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	for i, u := range users {
+		greenBold.Printf("%-5v", i+1)
+		fmt.Printf("Username: %-15v | Name: %-30v | Email: %-40v | Active: %v\n", u.Name, u.DisplayName, u.Email, u.Active)
+	}
+}
@@ -0,0 +1,98 @@
+package user_test
+
+import (
+	"fmt"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"omniactl/jira/fake"
+	listProject "omniactl/jira/list/project"
+	listUser "omniactl/jira/list/user"
+	"omniactl/jira/service"
+	"os"
+	"testing"
+)
+
+var (
+	// server is the fake Jira instance used by all tests in this package
+	server *fake.Server
+	// svc is the Jira service used by all tests in this package
+	svc *service.Service
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	// More users than fit on a page, so that searches are paginated
+	for i := 0; i < listUser.PageSize+5; i++ {
+		login := fmt.Sprintf("e91%04d", i)
+		server.AddUser(login, "Test User", login+"@statestreet.com")
+	}
+	server.AddUser("jmoores", "Jim Moores", "jmoores@statestreet.com")
+	server.AddProject("MSF", "Market Surveillance")
+	server.AddProject("MSG", "Messaging")
+	server.AddProject("GAL", "Galleon Engineering")
+	svc = service.New(server.URL, server.Client())
+	interactive.Disabled = true
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestSearchUsers(t *testing.T) {
+	type test struct {
+		query string
+		want  int
+	}
+	tests := []test{
+		{"e91", listUser.PageSize + 5},
+		{"e910001", 1},
+		{"jmoores", 1},
+		{"nobody", 0},
+	}
+	for _, tc := range tests {
+		users, err := listUser.SearchUsers(svc, tc.query)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if len(users) != tc.want {
+			t.Errorf("Searching '%v': expected %v users Got %v", tc.query, tc.want, len(users))
+		}
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	user, err := listUser.GetUser(svc, "jmoores")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if user.DisplayName != "Jim Moores" || !user.Active {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+
+	_, err = listUser.GetUser(svc, "e999999")
+	if !errs.Is(err, errs.NotFound) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.NotFound, err)
+	}
+}
+
+func TestGetProjects(t *testing.T) {
+	type test struct {
+		glob string
+		want int
+	}
+	tests := []test{
+		{"", 3},
+		{"MS*", 2},
+		{"galleon*", 1},
+		{"XYZ", 0},
+	}
+	for _, tc := range tests {
+		projects, err := listProject.GetProjects(svc, tc.glob)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if len(projects) != tc.want {
+			t.Errorf("Listing '%v': expected %v projects Got %v", tc.glob, tc.want, len(projects))
+		}
+	}
+}
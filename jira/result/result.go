@@ -0,0 +1,34 @@
+// Package result holds the structs returned by the jira list commands.
+// They are rendered by the output package in the format selected through
+// the global --output flag.
+package result
+
+// User describes a Jira user
+type User struct {
+	Name        string `json:"name" yaml:"name"`
+	DisplayName string `json:"display_name" yaml:"display_name"`
+	Email       string `json:"email" yaml:"email"`
+	Active      bool   `json:"active" yaml:"active"`
+}
+
+// Project describes a Jira project
+type Project struct {
+	Key  string `json:"key" yaml:"key"`
+	Name string `json:"name" yaml:"name"`
+	ID   string `json:"id" yaml:"id"`
+	Type string `json:"type" yaml:"type"`
+	Lead string `json:"lead" yaml:"lead"`
+}
+
+// Role describes a Jira project role
+type Role struct {
+	Name        string `json:"name" yaml:"name"`
+	ID          int64  `json:"id" yaml:"id"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// Scheme describes a Jira permission or notification scheme
+type Scheme struct {
+	Name string `json:"name" yaml:"name"`
+	ID   int64  `json:"id" yaml:"id"`
+}
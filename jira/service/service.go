@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"omniactl/errs"
+	"sort"
+	"strings"
+)
+
+// API is the path of the Jira Server REST API below the Jira URL
+const API = "rest/api/2/"
+
+// SessionPath is the path of the Jira session resource below the Jira URL
+const SessionPath = "rest/auth/1/session"
+
+// Service holds the Jira client shared by the jira sub-packages.
+// It is created once by the cobra layer and passed to every function
+// which interacts with the Jira API.
+type Service struct {
+	// Client sends the requests, authenticating them
+	Client *http.Client
+	// URL is the Jira URL from the config file, e.g. https://jira.example.com
+	URL string
+	// Username is the login requests are authenticated as
+	Username string
+}
+
+// New returns a Service sending requests to the Jira instance at jiraURL with the provided client
+func New(jiraURL string, client *http.Client) *Service {
+	return &Service{Client: client, URL: strings.TrimSuffix(jiraURL, "/")}
+}
+
+// Login creates a Jira session for the user and returns its cookie, e.g.
+// "JSESSIONID=...", which authenticates later requests instead of the password
+func Login(client *http.Client, jiraURL string, username string, password string) (string, error) {
+	var buf bytes.Buffer
+	credentials := map[string]string{"username": username, "password": password}
+	if err := json.NewEncoder(&buf).Encode(credentials); err != nil {
+		return "", errs.Wrap(errs.Internal, err, "error encoding request")
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(jiraURL, "/")+"/"+SessionPath, &buf)
+	if err != nil {
+		return "", errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errs.Wrap(errs.Unavailable, err, "error sending request to Jira")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errs.Wrap(errs.Unavailable, err, "error reading response from Jira")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := errorMessage(data)
+		if message == "" {
+			message = resp.Status
+		}
+		return "", errs.New(errs.FromStatus(resp.StatusCode, message), "Jira login as '%v' failed: %v", username, message)
+	}
+	session := struct {
+		Session struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"session"`
+	}{}
+	if err := json.Unmarshal(data, &session); err != nil || session.Session.Value == "" {
+		return "", errs.New(errs.Internal, "Jira login as '%v' returned no session", username)
+	}
+	return session.Session.Name + "=" + session.Session.Value, nil
+}
+
+// Do sends a request to the Jira REST API, encoding body as JSON if it is not nil,
+// and decodes the JSON response into result if it is not nil. path is relative to
+// the REST API, e.g. "user?username=e123456".
+func (s *Service) Do(method string, path string, body interface{}, result interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return errs.Wrap(errs.Internal, err, "error encoding request")
+		}
+	}
+	req, err := http.NewRequest(method, s.URL+"/"+API+path, &buf)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error creating HTTP request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return errs.Wrap(errs.Unavailable, err, "error sending request to Jira")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errs.Wrap(errs.Unavailable, err, "error reading response from Jira")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := errorMessage(data)
+		if message == "" {
+			message = resp.Status
+		}
+		return errs.New(errs.FromStatus(resp.StatusCode, message), "%v", message)
+	}
+	if result == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return errs.Wrap(errs.Internal, err, "error decoding response from Jira")
+	}
+	return nil
+}
+
+// errorMessage returns the messages of a Jira error response, e.g.
+// {"errorMessages": ["..."], "errors": {"name": "..."}}
+func errorMessage(data []byte) string {
+	jiraErr := struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}{}
+	if err := json.Unmarshal(data, &jiraErr); err != nil {
+		return ""
+	}
+	messages := jiraErr.ErrorMessages
+	fields := []string{}
+	for field := range jiraErr.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		messages = append(messages, fmt.Sprintf("%v: %v", field, jiraErr.Errors[field]))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Query returns path with the values appended as an escaped query string
+func Query(path string, values map[string]string) string {
+	v := url.Values{}
+	for key, value := range values {
+		v.Set(key, value)
+	}
+	return path + "?" + v.Encode()
+}
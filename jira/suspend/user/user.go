@@ -0,0 +1,84 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"omniactl/errs"
+	"omniactl/interactive"
+	listUser "omniactl/jira/list/user"
+	"omniactl/jira/result"
+	"omniactl/jira/service"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+)
+
+// SuspendUser deactivates the Jira user once confirmed. Jira keeps no reason for
+// deactivating a user, so the reason is shown in the confirmation only.
+func SuspendUser(svc *service.Service, username string, reason string) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Suspend user from Jira")
+
+	username, err := listUser.CheckUsername(username)
+	if err != nil {
+		return err
+	}
+	u, err := listUser.GetUser(svc, username)
+	if err != nil {
+		return err
+	}
+	listUser.PrintUsers([]result.User{u.Result()})
+	if !u.Active {
+		color.New(color.FgRed).Printf("Jira user '%v' is already inactive.\n", username)
+		return nil
+	}
+	reason, err = CheckReason(reason)
+	if err != nil {
+		return err
+	}
+	check, err := PromptSuspend(username, reason)
+	if err != nil {
+		return err
+	}
+	if check != "yes" {
+		return nil
+	}
+	return SuspendFromJira(svc, username)
+}
+
+// CheckReason checks flag input and if none was set prompts user
+func CheckReason(reason string) (string, error) {
+	if reason != "" {
+		return reason, nil
+	}
+	prompt := promptui.Prompt{
+		Label: "Reason for suspension",
+		Validate: func(input string) error {
+			if input == "" {
+				return errors.New("Enter a reason for suspending the user")
+			}
+			return nil
+		},
+	}
+	return interactive.Prompt(prompt)
+}
+
+// PromptSuspend asks for confirmation before the user is deactivated
+func PromptSuspend(username string, reason string) (string, error) {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Suspend Jira user '%v' because '%v'?", username, reason),
+		Items: []string{"yes", "no"},
+	}
+	return interactive.Select(prompt, "yes")
+}
+
+// SuspendFromJira deactivates the user, so that they can no longer log in
+func SuspendFromJira(svc *service.Service, username string) error {
+	body := map[string]bool{"active": false}
+	if err := svc.Do("PUT", service.Query("user", map[string]string{"username": username}), body, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "error suspending Jira user '%v'", username)
+	}
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Printf("Jira user '%v' suspended.\n", username)
+	return nil
+}
@@ -0,0 +1,78 @@
+package user_test
+
+import (
+	"io/ioutil"
+	"omniactl/auditlog"
+	"omniactl/errs"
+	"omniactl/interactive"
+	"omniactl/jira/fake"
+	"omniactl/jira/service"
+	suspendUser "omniactl/jira/suspend/user"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	// server is the fake Jira instance used by all tests in this package
+	server *fake.Server
+	// svc is the Jira service used by all tests in this package
+	svc *service.Service
+	// dir holds the audit log written by the tests
+	dir string
+)
+
+func TestMain(m *testing.M) {
+	server = fake.NewServer()
+	client := server.Client()
+	client.Transport = &auditlog.Transport{Base: client.Transport, System: "jira", Account: fake.AdminLogin}
+	svc = service.New(server.URL, client)
+	interactive.Disabled = true
+
+	var err error
+	dir, err = ioutil.TempDir("", "jira_suspend_test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(auditlog.EnvFile, filepath.Join(dir, "audit.log"))
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestSuspendUser(t *testing.T) {
+	server.AddUser("e920001", "Contractor", "e920001@statestreet.com")
+	if err := suspendUser.SuspendUser(svc, "e920001", "contract ended"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if server.User("e920001").Active {
+		t.Error("User 'e920001' is still active")
+	}
+
+	entries, err := auditlog.Read(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	matches := auditlog.Filter(entries, auditlog.Query{User: "e920001"})
+	if len(matches) != 1 || matches[0].Action != "PUT /rest/api/2/user" || matches[0].Outcome != auditlog.Success {
+		t.Errorf("Expected one successful audit log entry for 'e920001' Got %+v", matches)
+	}
+}
+
+func TestSuspendUserErrors(t *testing.T) {
+	err := suspendUser.SuspendUser(svc, "e999999", "contract ended")
+	if !errs.Is(err, errs.NotFound) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.NotFound, err)
+	}
+
+	server.AddUser("e920002", "Contractor", "e920002@statestreet.com")
+	err = suspendUser.SuspendUser(svc, "e920002", "")
+	if !errs.Is(err, errs.Validation) {
+		t.Errorf("Expected '%v' error without a reason Got '%v'", errs.Validation, err)
+	}
+	if !server.User("e920002").Active {
+		t.Error("User 'e920002' was suspended without a reason")
+	}
+}
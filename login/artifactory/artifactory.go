@@ -0,0 +1,135 @@
+package artifactory
+
+import (
+	"fmt"
+	"net/http"
+	"omniactl/artifactory/service"
+	"omniactl/auditlog"
+	"omniactl/config"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"omniactl/login/basicauth"
+	"omniactl/login/bearer"
+	"omniactl/login/credentials"
+	"omniactl/login/session"
+
+	"github.com/fatih/color"
+)
+
+// GetArtifactoryCredentials retrieves the Artifactory admin credentials from the credential provider,
+// i.e. Vault or a local credentials file, and the Artifactory URL from the config file
+func GetArtifactoryCredentials() (string, string, string, error) {
+	address, err := config.GetURL("artifactory")
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.Config, err, "failure retrieving Artifactory URL from config file, "+
+			"this file can be created or updated using the './omniactl config' command")
+	}
+	provider, err := credentials.NewProvider()
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.Config, err, "failure retrieving credentials")
+	}
+	secrets, err := provider.GetSecrets("artifactory")
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.KindOf(err), err, "failure retrieving credentials")
+	}
+	username := secrets["username"]
+	password := secrets["password"]
+	if username == "" || password == "" {
+		return "", "", "", errs.New(errs.Config, "Artifactory username or password missing from credentials")
+	}
+	return username, password, address, nil
+}
+
+// CreateClient creates a service for interaction with Artifactory, authorized using the
+// access token saved by 'omniactl login artifactory' or, without one, the admin username
+// and password
+func CreateClient() (*service.Service, error) {
+	if address, err := config.GetURL("artifactory"); err == nil {
+		if s, ok := session.Get("artifactory", address); ok {
+			return NewTokenClient(s.Username, s.Secret, s.URL), nil
+		}
+	}
+	username, password, address, err := GetArtifactoryCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(username, password, address), nil
+}
+
+// NewClient creates a service for interaction with the Artifactory instance at address,
+// authorized using the username and password
+func NewClient(username string, password string, address string) *service.Service {
+	return newClient(username, address, &basicauth.Transport{Username: username, Password: password})
+}
+
+// NewTokenClient creates a service for interaction with the Artifactory instance at address,
+// authorized using the access token of the user
+func NewTokenClient(username string, token string, address string) *service.Service {
+	return newClient(username, address, &bearer.Transport{Token: token})
+}
+
+func newClient(username string, address string, auth http.RoundTripper) *service.Service {
+	// With --dry-run, requests which change anything are printed instead of sent,
+	// otherwise they are recorded in the audit log
+	client := &http.Client{
+		Transport: &auditlog.Transport{
+			Base:    &dryrun.Transport{Base: auth},
+			System:  "artifactory",
+			Account: username,
+		},
+	}
+	svc := service.New(address, client)
+	svc.Username = username
+	return svc
+}
+
+// ArtifactoryLogin checks the Artifactory login. With "login", an access token expiring
+// after --ttl is created with the credentials from the credential provider and saved
+// as the session used by later commands.
+func ArtifactoryLogin(s string) error {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	var svc *service.Service
+	var sess session.Session
+	if s == "login" {
+		if err := session.Delete("artifactory"); err != nil {
+			return err
+		}
+		username, password, address, err := GetArtifactoryCredentials()
+		if err != nil {
+			return err
+		}
+		// The token is requested with a plain client, as logging in changes nothing
+		token, expiry, err := service.Token(http.DefaultClient, address, username, password, session.TTL)
+		if err != nil {
+			return err
+		}
+		sess = session.Session{System: "artifactory", URL: address, Username: username, Secret: token, Expiry: expiry}
+		svc = NewTokenClient(username, token, address)
+	} else {
+		var err error
+		if svc, err = CreateClient(); err != nil {
+			return err
+		}
+	}
+	if err := CheckArtifactoryLogin(svc); err != nil {
+		return err
+	}
+	if s == "login" {
+		if err := session.Save(sess); err != nil {
+			return err
+		}
+		fmt.Printf("Successfully logged into %v as user %v.\n", svc.URL, svc.Username)
+	} else if s == "check" {
+		greenBold.Print("Artifactory Login Status ")
+		fmt.Println("OK")
+	}
+	return nil
+}
+
+// CheckArtifactoryLogin checks if Artifactory returns data for the authenticated user to verify login
+func CheckArtifactoryLogin(svc *service.Service) error {
+	if err := svc.Do("GET", "security/users/"+svc.Username, nil, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "Artifactory login failed: error retrieving current user information from Artifactory")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package artifactory
+
+import (
+	"net/http"
+	"omniactl/artifactory/fake"
+	"omniactl/artifactory/service"
+	"omniactl/errs"
+	"testing"
+	"time"
+)
+
+func TestCheckLogin(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	svc := service.New(server.URL+"/artifactory", server.Client())
+	svc.Username = fake.AdminLogin
+	if err := CheckArtifactoryLogin(svc); err != nil {
+		t.Errorf("Artifactory login check failed: %v", err)
+	}
+
+	svc.Client = http.DefaultClient
+	err := CheckArtifactoryLogin(svc)
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error without credentials Got '%v'", errs.PermissionDenied, err)
+	}
+
+	server.Close()
+	svc.Client = server.Client()
+	err = CheckArtifactoryLogin(svc)
+	if !errs.Is(err, errs.Unavailable) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Unavailable, err)
+	}
+}
+
+func TestTokenClient(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	token, expiry, err := service.Token(http.DefaultClient, server.URL, fake.AdminLogin, fake.AdminPassword, time.Hour)
+	if err != nil || token != fake.AccessToken {
+		t.Fatalf("Expected token '%v' Got '%v', error '%v'", fake.AccessToken, token, err)
+	}
+	if expiry.Before(time.Now().Add(59*time.Minute)) || expiry.After(time.Now().Add(time.Hour)) {
+		t.Errorf("Expected the token to expire in an hour Got %v", expiry)
+	}
+	if err := CheckArtifactoryLogin(NewTokenClient(fake.AdminLogin, token, server.URL)); err != nil {
+		t.Errorf("Artifactory login check with the token failed: %v", err)
+	}
+
+	_, _, err = service.Token(http.DefaultClient, server.URL, fake.AdminLogin, "wrong", time.Hour)
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error with a wrong password Got '%v'", errs.PermissionDenied, err)
+	}
+	err = CheckArtifactoryLogin(NewTokenClient(fake.AdminLogin, "expired", server.URL))
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error with an expired token Got '%v'", errs.PermissionDenied, err)
+	}
+}
@@ -0,0 +1,24 @@
+// Package basicauth authenticates the requests sent to APIs which accept a username
+// and password, such as Jira and Confluence.
+package basicauth
+
+import "net/http"
+
+// Transport is an http.RoundTripper which sets the username and password of every request
+type Transport struct {
+	Username string
+	Password string
+	// Base is the transport requests are sent with, http.DefaultTransport if nil
+	Base http.RoundTripper
+}
+
+// RoundTrip sends the request with basic authentication
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	clone := req.Clone(req.Context())
+	clone.SetBasicAuth(t.Username, t.Password)
+	return base.RoundTrip(clone)
+}
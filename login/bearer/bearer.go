@@ -0,0 +1,23 @@
+// Package bearer authenticates the requests sent to APIs which accept an access
+// token obtained by logging in, such as Concourse.
+package bearer
+
+import "net/http"
+
+// Transport is an http.RoundTripper which sets the access token of every request
+type Transport struct {
+	Token string
+	// Base is the transport requests are sent with, http.DefaultTransport if nil
+	Base http.RoundTripper
+}
+
+// RoundTrip sends the request with the access token as bearer token
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+t.Token)
+	return base.RoundTrip(clone)
+}
@@ -0,0 +1,129 @@
+package concourse
+
+import (
+	"fmt"
+	"net/http"
+	"omniactl/auditlog"
+	"omniactl/concourse/service"
+	"omniactl/config"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"omniactl/login/bearer"
+	"omniactl/login/credentials"
+	"omniactl/login/session"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// GetConcourseCredentials retrieves the Concourse admin credentials from the credential provider,
+// i.e. Vault or a local credentials file, and the Concourse URL from the config file
+func GetConcourseCredentials() (string, string, string, error) {
+	address, err := config.GetURL("concourse")
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.Config, err, "failure retrieving Concourse URL from config file, "+
+			"this file can be created or updated using the './omniactl config' command")
+	}
+	provider, err := credentials.NewProvider()
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.Config, err, "failure retrieving credentials")
+	}
+	secrets, err := provider.GetSecrets("concourse")
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.KindOf(err), err, "failure retrieving credentials")
+	}
+	username := secrets["username"]
+	password := secrets["password"]
+	if username == "" || password == "" {
+		return "", "", "", errs.New(errs.Config, "Concourse username or password missing from credentials")
+	}
+	return username, password, address, nil
+}
+
+// GetConcourseSession returns the access token saved by 'omniactl login concourse' or,
+// without one, logs into Concourse with the admin username and password. The session
+// expires with the token.
+func GetConcourseSession() (*session.Session, error) {
+	if address, err := config.GetURL("concourse"); err == nil {
+		if s, ok := session.Get("concourse", address); ok {
+			return s, nil
+		}
+	}
+	username, password, address, err := GetConcourseCredentials()
+	if err != nil {
+		return nil, err
+	}
+	// The token is requested with a plain client, as logging in changes nothing
+	token, expiry, err := service.Token(http.DefaultClient, address, username, password)
+	if err != nil {
+		return nil, err
+	}
+	return &session.Session{System: "concourse", URL: address, Username: username, Secret: token, Expiry: expiry}, nil
+}
+
+// CreateClient creates a service for interaction with Concourse, authorized using the
+// access token of the admin user
+func CreateClient() (*service.Service, error) {
+	s, err := GetConcourseSession()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(s.Username, s.Secret, s.URL), nil
+}
+
+// NewClient creates a service for interaction with the Concourse instance at address,
+// authorized using the access token of the user
+func NewClient(username string, token string, address string) *service.Service {
+	// With --dry-run, requests which change anything are printed instead of sent,
+	// otherwise they are recorded in the audit log
+	client := &http.Client{
+		Transport: &auditlog.Transport{
+			Base:    &dryrun.Transport{Base: &bearer.Transport{Token: token}},
+			System:  "concourse",
+			Account: username,
+		},
+	}
+	svc := service.New(address, client)
+	svc.Username = username
+	return svc
+}
+
+// ConcourseLogin checks the Concourse login. With "login", a new access token is
+// requested and saved as the session used by later commands until it expires.
+func ConcourseLogin(s string) error {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	if s == "login" {
+		if err := session.Delete("concourse"); err != nil {
+			return err
+		}
+	}
+	sess, err := GetConcourseSession()
+	if err != nil {
+		return err
+	}
+	svc := NewClient(sess.Username, sess.Secret, sess.URL)
+	if err := CheckConcourseLogin(svc); err != nil {
+		return err
+	}
+	if s == "login" {
+		if ttl := time.Now().Add(session.TTL); sess.Expiry.IsZero() || ttl.Before(sess.Expiry) {
+			sess.Expiry = ttl
+		}
+		if err := session.Save(*sess); err != nil {
+			return err
+		}
+		fmt.Printf("Successfully logged into %v as user %v.\n", svc.URL, svc.Username)
+	} else if s == "check" {
+		greenBold.Print("Concourse Login Status ")
+		fmt.Println("OK")
+	}
+	return nil
+}
+
+// CheckConcourseLogin checks if Concourse returns data for the authenticated user to verify login
+func CheckConcourseLogin(svc *service.Service) error {
+	if err := svc.Do("GET", "user", nil, nil); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "Concourse login failed: error retrieving current user information from Concourse")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package concourse
+
+import (
+	"net/http"
+	"omniactl/concourse/fake"
+	"omniactl/concourse/service"
+	"omniactl/errs"
+	"testing"
+	"time"
+)
+
+func TestToken(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	token, expiry, err := service.Token(http.DefaultClient, server.URL, fake.AdminLogin, fake.AdminPassword)
+	if err != nil || token != fake.Token {
+		t.Errorf("Expected token '%v' Got '%v', error '%v'", fake.Token, token, err)
+	}
+	if expiry.Before(time.Now().Add(23 * time.Hour)) {
+		t.Errorf("Expected the token to expire in a day Got %v", expiry)
+	}
+	_, _, err = service.Token(http.DefaultClient, server.URL, fake.AdminLogin, "wrong")
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error with a wrong password Got '%v'", errs.PermissionDenied, err)
+	}
+}
+
+func TestCheckLogin(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	svc := service.New(server.URL, server.Client())
+	if err := CheckConcourseLogin(svc); err != nil {
+		t.Errorf("Concourse login check failed: %v", err)
+	}
+
+	svc.Client = http.DefaultClient
+	err := CheckConcourseLogin(svc)
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error without token Got '%v'", errs.PermissionDenied, err)
+	}
+
+	server.Close()
+	svc.Client = server.Client()
+	err = CheckConcourseLogin(svc)
+	if !errs.Is(err, errs.Unavailable) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Unavailable, err)
+	}
+}
@@ -0,0 +1,147 @@
+package confluence
+
+import (
+	"fmt"
+	"net/http"
+	"omniactl/auditlog"
+	"omniactl/config"
+	"omniactl/confluence/service"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"omniactl/login/basicauth"
+	"omniactl/login/cookie"
+	"omniactl/login/credentials"
+	"omniactl/login/session"
+
+	"github.com/fatih/color"
+)
+
+// CurrentUser is the user Confluence authenticated the request as
+type CurrentUser struct {
+	Type        string `json:"type"`
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName"`
+}
+
+// GetConfluenceCredentials retrieves the Confluence admin credentials from the credential provider,
+// i.e. Vault or a local credentials file, and the Confluence URL from the config file
+func GetConfluenceCredentials() (string, string, string, error) {
+	address, err := config.GetURL("confluence")
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.Config, err, "failure retrieving Confluence URL from config file, "+
+			"this file can be created or updated using the './omniactl config' command")
+	}
+	provider, err := credentials.NewProvider()
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.Config, err, "failure retrieving credentials")
+	}
+	secrets, err := provider.GetSecrets("confluence")
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.KindOf(err), err, "failure retrieving credentials")
+	}
+	username := secrets["username"]
+	password := secrets["password"]
+	if username == "" || password == "" {
+		return "", "", "", errs.New(errs.Config, "Confluence username or password missing from credentials")
+	}
+	return username, password, address, nil
+}
+
+// CreateClient creates a service for interaction with Confluence, authorized using the
+// session cookie saved by 'omniactl login confluence' or, without one, the admin username
+// and password
+func CreateClient() (*service.Service, error) {
+	if address, err := config.GetURL("confluence"); err == nil {
+		if s, ok := session.Get("confluence", address); ok {
+			return NewSessionClient(s.Username, s.Secret, s.URL), nil
+		}
+	}
+	username, password, address, err := GetConfluenceCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(username, password, address), nil
+}
+
+// NewClient creates a service for interaction with the Confluence instance at address,
+// authorized using the username and password
+func NewClient(username string, password string, address string) *service.Service {
+	return newClient(username, address, &basicauth.Transport{Username: username, Password: password})
+}
+
+// NewSessionClient creates a service for interaction with the Confluence instance at address,
+// authorized using the session cookie of the user
+func NewSessionClient(username string, sessionCookie string, address string) *service.Service {
+	return newClient(username, address, &cookie.Transport{Cookie: sessionCookie})
+}
+
+func newClient(username string, address string, auth http.RoundTripper) *service.Service {
+	// With --dry-run, requests which change anything are printed instead of sent,
+	// otherwise they are recorded in the audit log
+	client := &http.Client{
+		Transport: &auditlog.Transport{
+			Base:    &dryrun.Transport{Base: auth},
+			System:  "confluence",
+			Account: username,
+		},
+	}
+	svc := service.New(address, client)
+	svc.Username = username
+	return svc
+}
+
+// ConfluenceLogin checks the Confluence login. With "login", the credentials from the credential
+// provider are checked and the session cookie Confluence sets is saved as the session
+// used by later commands.
+func ConfluenceLogin(s string) error {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	var svc *service.Service
+	var sess session.Session
+	if s == "login" {
+		if err := session.Delete("confluence"); err != nil {
+			return err
+		}
+		username, password, address, err := GetConfluenceCredentials()
+		if err != nil {
+			return err
+		}
+		// The session cookie is requested with a plain client, as logging in changes nothing
+		sessionCookie, err := service.Login(http.DefaultClient, address, username, password)
+		if err != nil {
+			return err
+		}
+		sess = session.Session{System: "confluence", URL: address, Username: username, Secret: sessionCookie}
+		svc = NewSessionClient(username, sessionCookie, address)
+	} else {
+		var err error
+		if svc, err = CreateClient(); err != nil {
+			return err
+		}
+	}
+	if err := CheckConfluenceLogin(svc); err != nil {
+		return err
+	}
+	if s == "login" {
+		if err := session.Save(sess); err != nil {
+			return err
+		}
+		fmt.Printf("Successfully logged into %v as user %v.\n", svc.URL, svc.Username)
+	} else if s == "check" {
+		greenBold.Print("Confluence Login Status ")
+		fmt.Println("OK")
+	}
+	return nil
+}
+
+// CheckConfluenceLogin checks if Confluence returns data for the authenticated user to verify login
+func CheckConfluenceLogin(svc *service.Service) error {
+	user := CurrentUser{}
+	if err := svc.Do("GET", "user/current", nil, &user); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "Confluence login failed: error retrieving current user information from Confluence")
+	}
+	// Confluence answers requests without credentials as the anonymous user
+	if user.Type == "anonymous" {
+		return errs.New(errs.PermissionDenied, "Confluence login failed: not authenticated")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package confluence
+
+import (
+	"net/http"
+	"omniactl/confluence/fake"
+	"omniactl/confluence/service"
+	"omniactl/errs"
+	"testing"
+)
+
+func TestCheckLogin(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	err := CheckConfluenceLogin(service.New(server.URL, server.Client()))
+	if err != nil {
+		t.Errorf("Confluence login check failed: %v", err)
+	}
+
+	err = CheckConfluenceLogin(service.New(server.URL, http.DefaultClient))
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error without credentials Got '%v'", errs.PermissionDenied, err)
+	}
+
+	server.Close()
+	err = CheckConfluenceLogin(service.New(server.URL, server.Client()))
+	if !errs.Is(err, errs.Unavailable) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Unavailable, err)
+	}
+}
+
+func TestSessionClient(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	sessionCookie, err := service.Login(http.DefaultClient, server.URL, fake.AdminLogin, fake.AdminPassword)
+	if err != nil || sessionCookie != "JSESSIONID="+fake.SessionID {
+		t.Fatalf("Expected session cookie 'JSESSIONID=%v' Got '%v', error '%v'", fake.SessionID, sessionCookie, err)
+	}
+	if err := CheckConfluenceLogin(NewSessionClient(fake.AdminLogin, sessionCookie, server.URL)); err != nil {
+		t.Errorf("Confluence login check with the session failed: %v", err)
+	}
+
+	_, err = service.Login(http.DefaultClient, server.URL, fake.AdminLogin, "wrong")
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error with a wrong password Got '%v'", errs.PermissionDenied, err)
+	}
+	err = CheckConfluenceLogin(NewSessionClient(fake.AdminLogin, "JSESSIONID=expired", server.URL))
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error with an expired session Got '%v'", errs.PermissionDenied, err)
+	}
+}
@@ -0,0 +1,24 @@
+// Package cookie authenticates the requests sent to APIs which accept the session
+// cookie obtained by logging in, such as Jira and Confluence.
+package cookie
+
+import "net/http"
+
+// Transport is an http.RoundTripper which sets the session cookie of every request
+type Transport struct {
+	// Cookie is the name and value of the session cookie, e.g. "JSESSIONID=..."
+	Cookie string
+	// Base is the transport requests are sent with, http.DefaultTransport if nil
+	Base http.RoundTripper
+}
+
+// RoundTrip sends the request with the session cookie
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Cookie", t.Cookie)
+	return base.RoundTrip(clone)
+}
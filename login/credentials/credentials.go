@@ -0,0 +1,231 @@
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"omniactl/config"
+	"omniactl/login/session"
+	"os"
+	"strings"
+	"time"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// Environment variables used to select and configure the credential provider
+const (
+	// EnvFile selects the local file provider when set to the path of a credentials file
+	EnvFile = "OMNIACTL_CREDENTIALS_FILE"
+	// EnvVaultToken authenticates against Vault with a token
+	EnvVaultToken = "VAULT_TOKEN"
+	// EnvVaultRoleID and EnvVaultSecretID authenticate against Vault using AppRole
+	EnvVaultRoleID   = "VAULT_ROLE_ID"
+	EnvVaultSecretID = "VAULT_SECRET_ID"
+	// EnvVaultMount is the mount point of the KV v2 secrets engine, defaults to "secret"
+	EnvVaultMount = "VAULT_KV_MOUNT"
+	// EnvVaultPath is the path below the mount holding the omniactl secrets, defaults to "omniactl"
+	EnvVaultPath = "VAULT_KV_PATH"
+)
+
+// Provider retrieves the secrets for an API, e.g. "github", as key/value pairs
+type Provider interface {
+	GetSecrets(name string) (map[string]string, error)
+}
+
+// NewProvider returns the local file provider if a credentials file is set
+// through OMNIACTL_CREDENTIALS_FILE, otherwise the Vault provider using the
+// Vault URL from the config file
+func NewProvider() (Provider, error) {
+	if path := os.Getenv(EnvFile); path != "" {
+		return &FileProvider{Path: path}, nil
+	}
+	return NewVaultProvider()
+}
+
+// NewVaultProvider returns the Vault provider using the Vault URL from the config
+// file. Without VAULT_TOKEN, the token saved by 'omniactl login vault' is used if
+// it has not expired.
+func NewVaultProvider() (*VaultProvider, error) {
+	address, err := config.GetURL("vault")
+	if err != nil {
+		return nil, fmt.Errorf("%v (or set %v to use a local credentials file)", err, EnvFile)
+	}
+	v := &VaultProvider{
+		Address:  address,
+		Token:    os.Getenv(EnvVaultToken),
+		RoleID:   os.Getenv(EnvVaultRoleID),
+		SecretID: os.Getenv(EnvVaultSecretID),
+		Mount:    os.Getenv(EnvVaultMount),
+		Path:     os.Getenv(EnvVaultPath),
+	}
+	if s, ok := session.Get("vault", address); ok && v.Token == "" {
+		v.Token = s.Secret
+	}
+	return v, nil
+}
+
+// FileProvider reads secrets from the [auth] section of a local INI file,
+// where keys are prefixed with the name of the API, e.g. github_token.
+// It is meant for development only.
+type FileProvider struct {
+	Path string
+}
+
+// GetSecrets returns all keys of the file belonging to the API, without prefix
+func (f *FileProvider) GetSecrets(name string) (map[string]string, error) {
+	cfg, err := ini.Load(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading credentials file '%v': %v", f.Path, err)
+	}
+
+	prefix := name + "_"
+	secrets := make(map[string]string)
+	for _, key := range cfg.Section("auth").Keys() {
+		if strings.HasPrefix(key.Name(), prefix) {
+			secrets[strings.TrimPrefix(key.Name(), prefix)] = key.String()
+		}
+	}
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("no %v credentials found in '%v'", name, f.Path)
+	}
+	return secrets, nil
+}
+
+// VaultProvider reads secrets from a HashiCorp Vault KV v2 secrets engine,
+// authenticating with either a token or an AppRole role ID and secret ID
+type VaultProvider struct {
+	Address  string
+	Token    string
+	RoleID   string
+	SecretID string
+	Mount    string
+	Path     string
+	Client   *http.Client
+}
+
+// GetSecrets reads the secret <mount>/data/<path>/<name> from Vault
+func (v *VaultProvider) GetSecrets(name string) (map[string]string, error) {
+	if v.Token == "" {
+		if err := v.Login(); err != nil {
+			return nil, err
+		}
+	}
+
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	path := v.Path
+	if path == "" {
+		path = "omniactl"
+	}
+
+	secret := struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}{}
+	url := fmt.Sprintf("v1/%v/data/%v/%v", strings.Trim(mount, "/"), strings.Trim(path, "/"), name)
+	err := v.do("GET", url, nil, &secret)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %v credentials from Vault: %v", name, err)
+	}
+
+	secrets := make(map[string]string)
+	for k, value := range secret.Data.Data {
+		secrets[k] = fmt.Sprint(value)
+	}
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("no %v credentials found in Vault", name)
+	}
+	return secrets, nil
+}
+
+// Login exchanges the AppRole role ID and secret ID for a Vault token
+func (v *VaultProvider) Login() error {
+	if v.RoleID == "" || v.SecretID == "" {
+		return fmt.Errorf("Vault authentication requires %v, or %v and %v", EnvVaultToken, EnvVaultRoleID, EnvVaultSecretID)
+	}
+
+	body := map[string]string{"role_id": v.RoleID, "secret_id": v.SecretID}
+	login := struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}{}
+	err := v.do("POST", "v1/auth/approle/login", body, &login)
+	if err != nil {
+		return fmt.Errorf("Vault AppRole login failed: %v", err)
+	}
+	if login.Auth.ClientToken == "" {
+		return errors.New("Vault AppRole login failed: no token returned")
+	}
+	v.Token = login.Auth.ClientToken
+	return nil
+}
+
+// LookupSelf returns the display name of the Vault token, logging in with AppRole
+// if there is no token, and how long the token is valid for, 0 if it does not expire
+func (v *VaultProvider) LookupSelf() (string, time.Duration, error) {
+	if v.Token == "" {
+		if err := v.Login(); err != nil {
+			return "", 0, err
+		}
+	}
+	lookup := struct {
+		Data struct {
+			DisplayName string `json:"display_name"`
+			TTL         int64  `json:"ttl"`
+		} `json:"data"`
+	}{}
+	if err := v.do("GET", "v1/auth/token/lookup-self", nil, &lookup); err != nil {
+		return "", 0, fmt.Errorf("error looking up Vault token: %v", err)
+	}
+	return lookup.Data.DisplayName, time.Duration(lookup.Data.TTL) * time.Second, nil
+}
+
+// do sends a request to the Vault API and decodes the JSON response into v
+func (v *VaultProvider) do(method string, path string, body interface{}, result interface{}) error {
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	url := strings.TrimSuffix(v.Address, "/") + "/" + path
+	req, err := http.NewRequest(method, url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.Token != "" {
+		req.Header.Set("X-Vault-Token", v.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		vaultErr := struct {
+			Errors []string `json:"errors"`
+		}{}
+		json.NewDecoder(resp.Body).Decode(&vaultErr)
+		if len(vaultErr.Errors) != 0 {
+			return fmt.Errorf("%v: %v", resp.Status, strings.Join(vaultErr.Errors, ", "))
+		}
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,137 @@
+package credentials
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVault stands in for a Vault server with the KV v2 engine mounted at "secret"
+// and AppRole authentication enabled
+func fakeVault(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != "POST" || body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+		w.Write([]byte(`{"auth":{"client_token":"approle-token"}}`))
+	})
+	mux.HandleFunc("/v1/secret/data/omniactl/github", func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-Vault-Token")
+		if token != "root-token" && token != "approle-token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"username":"admin","token":"abc123","team":42}}}`))
+	})
+	mux.HandleFunc("/v1/auth/token/lookup-self", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "approle-token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"display_name":"approle","ttl":3600}}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestVaultProviderToken(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	v := &VaultProvider{Address: server.URL, Token: "root-token"}
+	secrets, err := v.GetSecrets("github")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", secrets["username"])
+	assert.Equal(t, "abc123", secrets["token"])
+	assert.Equal(t, "42", secrets["team"])
+}
+
+func TestVaultProviderAppRole(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	v := &VaultProvider{Address: server.URL, RoleID: "role", SecretID: "secret"}
+	secrets, err := v.GetSecrets("github")
+	assert.NoError(t, err)
+	assert.Equal(t, "approle-token", v.Token)
+	assert.Equal(t, "abc123", secrets["token"])
+
+	v = &VaultProvider{Address: server.URL, RoleID: "role", SecretID: "wrong"}
+	_, err = v.GetSecrets("github")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid role or secret ID")
+}
+
+func TestVaultProviderLookupSelf(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	v := &VaultProvider{Address: server.URL, RoleID: "role", SecretID: "secret"}
+	name, ttl, err := v.LookupSelf()
+	assert.NoError(t, err)
+	assert.Equal(t, "approle", name)
+	assert.Equal(t, time.Hour, ttl)
+
+	v = &VaultProvider{Address: server.URL, Token: "root-token"}
+	_, _, err = v.LookupSelf()
+	assert.Error(t, err)
+}
+
+func TestVaultProviderErrors(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	tests := []struct {
+		provider *VaultProvider
+		name     string
+	}{
+		{&VaultProvider{Address: server.URL}, "github"},
+		{&VaultProvider{Address: server.URL, Token: "wrong"}, "github"},
+		{&VaultProvider{Address: server.URL, Token: "root-token"}, "jira"},
+	}
+	for _, test := range tests {
+		_, err := test.provider.GetSecrets(test.name)
+		assert.Error(t, err)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	file, err := ioutil.TempFile("", "omniactl-credentials")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	file.WriteString("[auth]\ngithub_username=admin\ngithub_token=abc123\njira_username=jira-admin\n")
+	file.Close()
+
+	f := &FileProvider{Path: file.Name()}
+	secrets, err := f.GetSecrets("github")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "admin", "token": "abc123"}, secrets)
+
+	_, err = f.GetSecrets("confluence")
+	assert.Error(t, err)
+
+	f = &FileProvider{Path: file.Name() + ".missing"}
+	_, err = f.GetSecrets("github")
+	assert.Error(t, err)
+}
+
+func TestNewProviderFile(t *testing.T) {
+	os.Setenv(EnvFile, "/tmp/credentials")
+	defer os.Unsetenv(EnvFile)
+
+	provider, err := NewProvider()
+	assert.NoError(t, err)
+	assert.Equal(t, &FileProvider{Path: "/tmp/credentials"}, provider)
+}
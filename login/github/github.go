@@ -2,43 +2,58 @@ package github
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"log"
+	"omniactl/auditlog"
+	"omniactl/config"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"omniactl/login/credentials"
+	"omniactl/login/session"
+	"strconv"
 
 	"github.com/fatih/color"
 	"github.com/google/go-github/github"
 	"golang.org/x/oauth2"
-	ini "gopkg.in/ini.v1"
 )
 
-// GetGithubTokens logs into Github with admin priviledges and retrieves Personal Access
-// Check how to pass in structs
-func GetGithubTokens() (string, string, string, int64, string) {
-	cfg, err := ini.Load("/Users/alex/go/src/omniactl/.fake_vault")
+// GetGithubTokens retrieves the Github admin credentials from the credential provider,
+// i.e. Vault or a local credentials file, and the Github URL from the config file.
+// Github Enterprise issues no session tokens, so the personal access token is never
+// cached; 'omniactl login vault' saves the Vault session it is read with instead.
+func GetGithubTokens() (string, string, string, int64, string, error) {
+	address, err := config.GetURL("github")
 	if err != nil {
-		log.Fatalln("Failure retrieving tokens from Vault:", err)
+		return "", "", "", 0, "", errs.Wrap(errs.Config, err, "failure retrieving Github URL from config file, "+
+			"this file can be created or updated using the './omniactl config' command")
 	}
-	username := cfg.Section("auth").Key("github_username").String()
-	password := cfg.Section("auth").Key("github_password").String()
-	token := cfg.Section("auth").Key("github_token").String()
-	teamID, _ := cfg.Section("auth").Key("github_team").Int64()
-	team := int64(teamID)
-
-	cfg2, err := ini.Load("/Users/alex/go/src/omniactl/.omniactl")
+	provider, err := credentials.NewProvider()
 	if err != nil {
-		log.Fatalln("Failure retrieving URLs from .omniactl config file."+
-			"\nThis file can be created or updated using the './omniactl config' command.", err)
+		return "", "", "", 0, "", errs.Wrap(errs.Config, err, "failure retrieving tokens")
 	}
-	address := fmt.Sprint(cfg2.Section("config").Key("github").String())
-
-	return username, password, token, team, address
+	secrets, err := provider.GetSecrets("github")
+	if err != nil {
+		return "", "", "", 0, "", errs.Wrap(errs.KindOf(err), err, "failure retrieving tokens")
+	}
+	username := secrets["username"]
+	password := secrets["password"]
+	token := secrets["token"]
+	team, _ := strconv.ParseInt(secrets["team"], 10, 64)
+	return username, password, token, team, address, nil
 }
 
-// GithubLogin logs user into github
-func GithubLogin(s string) {
+// GithubLogin checks the Github token. Unlike the other systems, "login" saves no
+// session, as Github issues none; it removes a token saved by an earlier version.
+func GithubLogin(s string) error {
 	greenBold := color.New(color.FgGreen, color.Bold)
-	username, _, token, _, address := GetGithubTokens()
+	if s == "login" {
+		if err := session.Delete("github"); err != nil {
+			return err
+		}
+	}
+	username, _, token, _, address, err := GetGithubTokens()
+	if err != nil {
+		return err
+	}
 	// create authenticated Github client
 	ctx := context.Background()
 	ts := oauth2.StaticTokenSource(
@@ -47,58 +62,65 @@ func GithubLogin(s string) {
 	tc := oauth2.NewClient(ctx, ts)
 	client, err := github.NewEnterpriseClient(address, address, tc)
 	if err != nil {
-		log.Fatalln("Creation of Github client failed:", err)
+		return errs.Wrap(errs.Config, err, "creation of Github client failed")
 	}
 
-	_, resp, err := client.Users.Get(ctx, "")
-	if err != nil {
-		log.Fatalln("Github login failed:", err)
+	if err := CheckGithubLogin(client); err != nil {
+		return err
 	}
 
-	if resp.StatusCode == 200 && s == "login" {
+	if s == "login" {
 		fmt.Printf("Successfully logged into %v as user %v.\n", address, username)
-	} else if resp.StatusCode == 200 && s == "check" {
+		fmt.Println("Github issues no session, so later commands read the token from the credential provider.")
+	} else if s == "check" {
 		greenBold.Print("Github Login Status ")
 		fmt.Println("OK")
 	}
+	return nil
 }
 
 // CheckGithubLogin checks if Github returns data for authenticated user to verify login
-func CheckGithubLogin() error {
-	client := CreateClient()
-	ctx := context.Background()
-	_, resp, err := client.Users.Get(ctx, "")
-	if err != nil {
-		err := errors.New("Github login failed: Could not create Github client")
-		return err
-	}
+func CheckGithubLogin(client *github.Client) error {
+	_, _, _, err := WhoAmI(context.Background(), client)
+	return err
+}
 
-	_, resp, err = client.Users.Get(ctx, "")
+// WhoAmI returns the login of the authenticated user, the OAuth scopes of the token
+// and the Github Enterprise version, all from a single request for the user
+func WhoAmI(ctx context.Context, client *github.Client) (string, string, string, error) {
+	user, resp, err := client.Users.Get(ctx, "")
 	if err != nil {
-		err := errors.New("Github login failed: Error retrieving current user information from Github")
-		return err
+		return "", "", "", errs.FromGithub(err, "Github login failed: error retrieving current user information from Github")
 	}
-
 	if resp.StatusCode != 200 {
-		err := errors.New("Github login failed: Error retrieving current user information from Github")
-		return err
+		return "", "", "", errs.New(errs.FromStatus(resp.StatusCode, ""), "Github login failed: error retrieving current user information from Github")
 	}
-	return nil
+	return user.GetLogin(), resp.Header.Get("X-OAuth-Scopes"), resp.Header.Get("X-GitHub-Enterprise-Version"), nil
 }
 
 // CreateClient creates a client for interaction with github, authorized using token
-func CreateClient() *github.Client {
-	_, _, token, _, address := GetGithubTokens()
+func CreateClient() (*github.Client, error) {
+	username, _, token, _, address, err := GetGithubTokens()
+	if err != nil {
+		return nil, err
+	}
 	// create authenticated Github client
 	ctx := context.Background()
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
+	// With --dry-run, requests which change anything are printed instead of sent,
+	// otherwise they are recorded in the audit log
+	tc.Transport = &auditlog.Transport{
+		Base:    &dryrun.Transport{Base: tc.Transport},
+		System:  "github",
+		Account: username,
+	}
 
 	client, err := github.NewEnterpriseClient(address, address, tc)
 	if err != nil {
-		log.Fatalln("Creation of Github client failed:", err)
+		return nil, errs.Wrap(errs.Config, err, "creation of Github client failed")
 	}
-	return client
+	return client, nil
 }
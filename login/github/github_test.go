@@ -1,12 +1,33 @@
 package github
 
-// import (
-// 	"testing"
-// )
-
-// func TestCheckLogin(t *testing.T) {
-// 	err := CheckGithubLogin()
-// 	if err != nil {
-// 		t.Error("Github login check failed.")
-// 	}
-// }
+import (
+	"context"
+	"omniactl/errs"
+	"omniactl/github/fake"
+	"testing"
+)
+
+func TestCheckLogin(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	err := CheckGithubLogin(server.Client())
+	if err != nil {
+		t.Error("Github login check failed.")
+	}
+
+	login, scopes, version, err := WhoAmI(context.Background(), server.Client())
+	if err != nil || login != fake.AdminLogin || scopes != fake.Scopes || version != fake.Version {
+		t.Errorf("Expected '%v' with scopes '%v' on %v Got '%v' with scopes '%v' on %v, error '%v'",
+			fake.AdminLogin, fake.Scopes, fake.Version, login, scopes, version, err)
+	}
+
+	server.Close()
+	err = CheckGithubLogin(server.Client())
+	if err == nil {
+		t.Error("Github login check succeeded against an unreachable server.")
+	}
+	if !errs.Is(err, errs.Unavailable) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Unavailable, err)
+	}
+}
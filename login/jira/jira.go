@@ -0,0 +1,142 @@
+package jira
+
+import (
+	"fmt"
+	"net/http"
+	"omniactl/auditlog"
+	"omniactl/config"
+	"omniactl/dryrun"
+	"omniactl/errs"
+	"omniactl/jira/service"
+	"omniactl/login/basicauth"
+	"omniactl/login/cookie"
+	"omniactl/login/credentials"
+	"omniactl/login/session"
+
+	"github.com/fatih/color"
+)
+
+// Myself is the user Jira authenticated the request as
+type Myself struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// GetJiraCredentials retrieves the Jira admin credentials from the credential provider,
+// i.e. Vault or a local credentials file, and the Jira URL from the config file
+func GetJiraCredentials() (string, string, string, error) {
+	address, err := config.GetURL("jira")
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.Config, err, "failure retrieving Jira URL from config file, "+
+			"this file can be created or updated using the './omniactl config' command")
+	}
+	provider, err := credentials.NewProvider()
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.Config, err, "failure retrieving credentials")
+	}
+	secrets, err := provider.GetSecrets("jira")
+	if err != nil {
+		return "", "", "", errs.Wrap(errs.KindOf(err), err, "failure retrieving credentials")
+	}
+	username := secrets["username"]
+	password := secrets["password"]
+	if username == "" || password == "" {
+		return "", "", "", errs.New(errs.Config, "Jira username or password missing from credentials")
+	}
+	return username, password, address, nil
+}
+
+// CreateClient creates a service for interaction with Jira, authorized using the
+// session cookie saved by 'omniactl login jira' or, without one, the admin username
+// and password
+func CreateClient() (*service.Service, error) {
+	if address, err := config.GetURL("jira"); err == nil {
+		if s, ok := session.Get("jira", address); ok {
+			return NewSessionClient(s.Username, s.Secret, s.URL), nil
+		}
+	}
+	username, password, address, err := GetJiraCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(username, password, address), nil
+}
+
+// NewClient creates a service for interaction with the Jira instance at address,
+// authorized using the username and password
+func NewClient(username string, password string, address string) *service.Service {
+	return newClient(username, address, &basicauth.Transport{Username: username, Password: password})
+}
+
+// NewSessionClient creates a service for interaction with the Jira instance at address,
+// authorized using the session cookie of the user
+func NewSessionClient(username string, sessionCookie string, address string) *service.Service {
+	return newClient(username, address, &cookie.Transport{Cookie: sessionCookie})
+}
+
+func newClient(username string, address string, auth http.RoundTripper) *service.Service {
+	// With --dry-run, requests which change anything are printed instead of sent,
+	// otherwise they are recorded in the audit log
+	client := &http.Client{
+		Transport: &auditlog.Transport{
+			Base:    &dryrun.Transport{Base: auth},
+			System:  "jira",
+			Account: username,
+		},
+	}
+	svc := service.New(address, client)
+	svc.Username = username
+	return svc
+}
+
+// JiraLogin checks the Jira login. With "login", a new Jira session is created with
+// the credentials from the credential provider and its cookie is saved as the session
+// used by later commands.
+func JiraLogin(s string) error {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	var svc *service.Service
+	var sess session.Session
+	if s == "login" {
+		if err := session.Delete("jira"); err != nil {
+			return err
+		}
+		username, password, address, err := GetJiraCredentials()
+		if err != nil {
+			return err
+		}
+		// The session is requested with a plain client, as logging in changes nothing
+		sessionCookie, err := service.Login(http.DefaultClient, address, username, password)
+		if err != nil {
+			return err
+		}
+		sess = session.Session{System: "jira", URL: address, Username: username, Secret: sessionCookie}
+		svc = NewSessionClient(username, sessionCookie, address)
+	} else {
+		var err error
+		if svc, err = CreateClient(); err != nil {
+			return err
+		}
+	}
+	if err := CheckJiraLogin(svc); err != nil {
+		return err
+	}
+	if s == "login" {
+		if err := session.Save(sess); err != nil {
+			return err
+		}
+		fmt.Printf("Successfully logged into %v as user %v.\n", svc.URL, svc.Username)
+	} else if s == "check" {
+		greenBold.Print("Jira Login Status ")
+		fmt.Println("OK")
+	}
+	return nil
+}
+
+// CheckJiraLogin checks if Jira returns data for the authenticated user to verify login
+func CheckJiraLogin(svc *service.Service) error {
+	myself := Myself{}
+	if err := svc.Do("GET", "myself", nil, &myself); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "Jira login failed: error retrieving current user information from Jira")
+	}
+	return nil
+}
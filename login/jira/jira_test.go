@@ -0,0 +1,86 @@
+package jira
+
+import (
+	"io/ioutil"
+	"net/http"
+	"omniactl/config"
+	"omniactl/errs"
+	"omniactl/jira/fake"
+	"omniactl/jira/service"
+	"omniactl/login/credentials"
+	"omniactl/login/session"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLogin(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	err := CheckJiraLogin(service.New(server.URL, server.Client()))
+	if err != nil {
+		t.Errorf("Jira login check failed: %v", err)
+	}
+
+	err = CheckJiraLogin(service.New(server.URL, http.DefaultClient))
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error without credentials Got '%v'", errs.PermissionDenied, err)
+	}
+
+	server.Close()
+	err = CheckJiraLogin(service.New(server.URL, server.Client()))
+	if !errs.Is(err, errs.Unavailable) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Unavailable, err)
+	}
+}
+
+func TestJiraLogin(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "jira-login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := filepath.Join(dir, config.FileName)
+	ioutil.WriteFile(cfg, []byte("profiles:\n  default:\n    jira: "+server.URL+"\n"), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "credentials"), []byte("[auth]\njira_username = admin\njira_password = secret\n"), 0600)
+	os.Setenv(config.EnvConfig, cfg)
+	defer os.Unsetenv(config.EnvConfig)
+	if err := config.Init(); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(credentials.EnvFile, filepath.Join(dir, "credentials"))
+	defer os.Unsetenv(credentials.EnvFile)
+	os.Setenv(session.EnvFile, filepath.Join(dir, "cache", "sessions"))
+	defer os.Unsetenv(session.EnvFile)
+	os.Setenv(session.EnvKeyFile, filepath.Join(dir, "session.key"))
+	defer os.Unsetenv(session.EnvKeyFile)
+
+	if err := JiraLogin("login"); err != nil {
+		t.Fatalf("Jira login failed: %v", err)
+	}
+	// The session cookie is cached, not the password
+	s, ok := session.Get("jira", server.URL)
+	if !ok || s.Secret != "JSESSIONID="+fake.SessionID {
+		t.Errorf("Expected the session cookie to be saved Got %+v", s)
+	}
+	svc, err := CreateClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckJiraLogin(svc); err != nil {
+		t.Errorf("Jira login check with the session failed: %v", err)
+	}
+
+	_, err = service.Login(http.DefaultClient, server.URL, fake.AdminLogin, "wrong")
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error with a wrong password Got '%v'", errs.PermissionDenied, err)
+	}
+	err = CheckJiraLogin(NewSessionClient(fake.AdminLogin, "JSESSIONID=expired", server.URL))
+	if !errs.Is(err, errs.PermissionDenied) {
+		t.Errorf("Expected '%v' error with an expired session Got '%v'", errs.PermissionDenied, err)
+	}
+}
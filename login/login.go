@@ -1,125 +1,56 @@
 package login
 
 import (
-	"bufio"
-	"fmt"
-	"log"
+	"omniactl/errs"
+	"omniactl/interactive"
+	artifactoryLogin "omniactl/login/artifactory"
+	concourseLogin "omniactl/login/concourse"
+	confluenceLogin "omniactl/login/confluence"
 	githubLogin "omniactl/login/github"
-	"os"
-	"strings"
+	jiraLogin "omniactl/login/jira"
+	vaultLogin "omniactl/login/vault"
 
-	flags "github.com/jessevdk/go-flags"
+	"github.com/manifoldco/promptui"
 )
 
-// Logins sets CLI flags, use `./omniactl login -g` to login to Github
-var Logins struct {
-	Github      bool `short:"g" long:"--github" description:"Set flag to log into Github" command:"login"`
-	Jira        bool `short:"j" long:"--jira" description:"Set flag to log into Jira" command:"login"`
-	Confluence  bool `short:"f" long:"--confluence" description:"Set flag to log into Confluence" command:"login"`
-	Artifactory bool `short:"a" long:"--artifactory" description:"Set flag to log into Artifactory" command:"login"`
-	Concourse   bool `short:"c" long:"--concourse" description:"Set flag to log into Concourse" command:"login"`
-	Help        bool `short:"h" long:"--help" description:"Show help for login package." command:"login"`
-}
-
-// Login gets access tokens, usernames etc from vault for each API endpoint specified by flag input
-func Login() {
-	ParseFlags()
-	input := CheckInput()
-	SelectLogin(input)
-}
+// Systems are the systems omniactl logs into, in the order they are offered
+var Systems = []string{"github", "jira", "confluence", "artifactory", "concourse", "vault"}
 
-// ParseFlags enables parsing with flags from Options struct
-func ParseFlags() {
-	p := flags.NewParser(&Logins, flags.PrintErrors|flags.PassDoubleDash)
-	p.Parse()
-	// err, _ := p.Parse()
-	// if err != nil {
-	// 	log.Fatalln("Error occured while parsing flags:", err)
-	// }
-	if Logins.Help == true {
-		PrintFlags()
-		os.Exit(1)
-	}
-}
-
-// CheckInput interprets flag input
-func CheckInput() string {
-	if Logins.Github == true {
-		return "github"
-	} else if Logins.Jira == true {
-		return "jira"
-	} else if Logins.Artifactory == true {
-		return "artifactory"
-	} else if Logins.Concourse == true {
-		return "concourse"
-	} else if Logins.Confluence == true {
-		return "confluence"
-	} else {
-		fmt.Print("Required flag not entered.\n")
-		i := PromptInput()
-		return i
+// Login logs into the system, prompting for it if none was provided
+func Login(input string) error {
+	if input == "" {
+		var err error
+		if input, err = PromptInput(); err != nil {
+			return err
+		}
 	}
+	return SelectLogin(input)
 }
 
 // PromptInput prompts User to select which API they want to log in to
-func PromptInput() string {
-	inputs := map[string]bool{
-		"github":      true,
-		"artifactory": true,
-		"concourse":   true,
-		"confluence":  true,
-		"jira":        true,
-	}
-
-	fmt.Print("Please specify which API you would like to log in to: ")
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSuffix(input, "\n")
-
-	// Checks if input is in accepted inputs, otherwise, prompts User for input
-	_, ok := inputs[input]
-	if ok {
-		fmt.Printf("'%v' selected.\n", input)
-		return input
-	} else {
-		fmt.Printf("'%v' is not a valid option. Choose among the following:\n\n", input)
-		for k := range inputs {
-			fmt.Print("'"+k+"'", "\n")
-		}
-		fmt.Println("")
-		i := PromptInput()
-		return i
+func PromptInput() (string, error) {
+	prompt := promptui.Select{
+		Label: "Select the API to log in to",
+		Items: Systems,
 	}
+	return interactive.Select(prompt, "")
 }
 
-// SelectLogin uses flag or prompt input to initiate login
-func SelectLogin(input string) {
+// SelectLogin checks the credentials of the system and saves them as the session
+// used by later commands
+func SelectLogin(input string) error {
 	if input == "github" {
-		githubLogin.GithubLogin("login")
+		return githubLogin.GithubLogin("login")
 	} else if input == "jira" {
-		// get Jira values from Vault
-		fmt.Printf("'" + input + "' login is not set up yet.\n")
+		return jiraLogin.JiraLogin("login")
 	} else if input == "confluence" {
-		// get Confluence values from Vault
-		fmt.Printf("'" + input + "' login is not set up yet.\n")
+		return confluenceLogin.ConfluenceLogin("login")
 	} else if input == "concourse" {
-		// get Concourse values from Vault
-		fmt.Printf("'" + input + "' login is not set up yet.\n")
+		return concourseLogin.ConcourseLogin("login")
 	} else if input == "artifactory" {
-		// get Artifactory values from Vault
-		fmt.Printf("'" + input + "' login is not set up yet.\n")
-	} else {
-		log.Fatalln("Error selecting login.")
+		return artifactoryLogin.ArtifactoryLogin("login")
+	} else if input == "vault" {
+		return vaultLogin.VaultLogin("login")
 	}
-}
-
-// PrintFlags prints out existing flags, short and long form
-func PrintFlags() {
-	fmt.Print("\n'Login' command line flags:\n")
-	fmt.Print("-g\t--github\tLog into Github\n" +
-		"-j\t--jira\t\tLog into Jira\n" +
-		"-f\t--confluence\tLog into Confluence\n" +
-		"-a\t--artifactory\tLog into Artifactory\n" +
-		"-c\t--concourse\tLog into Concourse\n" +
-		"E.g. 'omniactl login -g' to log into Github.\n\n")
+	return errs.New(errs.Validation, "error selecting login: '%v' is not a valid option", input)
 }
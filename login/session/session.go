@@ -0,0 +1,284 @@
+// Package session caches the credentials and short-lived tokens checked by
+// 'omniactl login', so that later commands use them without fetching them from
+// Vault or logging in again until they expire. Only tokens and session cookies
+// issued by the systems are cached, never passwords. The cache is a single file
+// encrypted with AES-256-GCM, using a random key kept in the user's config
+// directory, away from the cache, or a key derived with scrypt from the passphrase
+// in OMNIACTL_SESSION_KEY.
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"omniactl/errs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Environment variables overriding the location and key of the cache
+const (
+	// EnvFile overrides the cache file, by default ~/.omniactl.d/sessions
+	EnvFile = "OMNIACTL_SESSION_FILE"
+	// EnvKey is a passphrase the cache key is derived from, instead of the key file
+	EnvKey = "OMNIACTL_SESSION_KEY"
+	// EnvKeyFile overrides the key file, by default omniactl/session.key in the
+	// user's config directory, e.g. ~/.config/omniactl/session.key
+	EnvKeyFile = "OMNIACTL_SESSION_KEY_FILE"
+)
+
+// saltSize is the size of the random salt the passphrase key is derived with. It
+// is stored at the start of the cache, followed by the nonce and the ciphertext.
+const saltSize = 16
+
+// DefaultTTL is how long a session is used for if the system does not set an expiry
+const DefaultTTL = 8 * time.Hour
+
+// TTL is how long sessions saved by 'omniactl login' are used for, set by --ttl
+var TTL = DefaultTTL
+
+// Session holds what a command needs to authenticate to a system without logging in
+type Session struct {
+	System   string `json:"system"`
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	// Secret is the token or session cookie requests are authenticated with
+	Secret string    `json:"secret"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// Expired reports whether the session must not be used any more
+func (s Session) Expired() bool {
+	return !time.Now().Before(s.Expiry)
+}
+
+// mu serialises reads and writes of the cache, as clients may be created concurrently
+var mu sync.Mutex
+
+// File returns the path of the cache
+func File() (string, error) {
+	if file := os.Getenv(EnvFile); file != "" {
+		return file, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", errs.Wrap(errs.Config, err, "error finding home directory")
+	}
+	return filepath.Join(home, ".omniactl.d", "sessions"), nil
+}
+
+// KeyFile returns the path of the key the cache is encrypted with, unless a
+// passphrase is set. It must not be in the directory of the cache, so that a copy
+// of that directory cannot be decrypted.
+func KeyFile() (string, error) {
+	file, err := File()
+	if err != nil {
+		return "", err
+	}
+	keyFile := os.Getenv(EnvKeyFile)
+	if keyFile == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", errs.Wrap(errs.Config, err, "error finding config directory")
+		}
+		keyFile = filepath.Join(dir, "omniactl", "session.key")
+	}
+	fileDir, _ := filepath.Abs(filepath.Dir(file))
+	keyDir, _ := filepath.Abs(filepath.Dir(keyFile))
+	if fileDir == keyDir {
+		return "", errs.New(errs.Config, "session key '%v' must not be kept in the directory of the session cache, "+
+			"set %v to another directory or set %v", keyFile, EnvKeyFile, EnvKey)
+	}
+	return keyFile, nil
+}
+
+// Get returns the session of the system, if one was saved for the URL and has not
+// expired. A cache which cannot be read is treated as empty.
+func Get(system string, url string) (*Session, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	sessions, err := load()
+	if err != nil {
+		return nil, false
+	}
+	s, ok := sessions[system]
+	if !ok || s.URL != url || s.Expired() {
+		return nil, false
+	}
+	return &s, true
+}
+
+// Save stores the session, replacing any session of the same system. Its expiry is
+// set from TTL unless the system set one. Expired sessions are dropped.
+func Save(s Session) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if s.Expiry.IsZero() {
+		s.Expiry = time.Now().Add(TTL)
+	}
+	sessions, err := load()
+	if err != nil {
+		sessions = map[string]Session{}
+	}
+	sessions[s.System] = s
+	return store(sessions)
+}
+
+// Delete removes the session of the system, if any
+func Delete(system string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	sessions, err := load()
+	if err != nil {
+		return nil
+	}
+	if _, ok := sessions[system]; !ok {
+		return nil
+	}
+	delete(sessions, system)
+	return store(sessions)
+}
+
+// List returns the sessions of every system which have not expired
+func List() (map[string]Session, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return load()
+}
+
+// load decrypts the cache and returns the sessions which have not expired
+func load() (map[string]Session, error) {
+	sessions := map[string]Session{}
+	file, err := File()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return sessions, nil
+	}
+	if err != nil {
+		return nil, errs.Wrap(errs.Config, err, "error reading session cache")
+	}
+	if len(data) < saltSize {
+		return nil, errs.New(errs.Config, "session cache '%v' is corrupt", file)
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+	gcm, err := newGCM(salt, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errs.New(errs.Config, "session cache '%v' is corrupt", file)
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errs.New(errs.Config, "session cache '%v' cannot be decrypted with the session key", file)
+	}
+	if err := json.Unmarshal(plain, &sessions); err != nil {
+		return nil, errs.Wrap(errs.Config, err, "error decoding session cache")
+	}
+	for system, s := range sessions {
+		if s.Expired() {
+			delete(sessions, system)
+		}
+	}
+	return sessions, nil
+}
+
+// store encrypts the sessions with a new salt and nonce and writes the cache
+func store(sessions map[string]Session) error {
+	file, err := File()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return errs.Wrap(errs.Config, err, "error creating session cache directory")
+	}
+	plain, err := json.Marshal(sessions)
+	if err != nil {
+		return errs.Wrap(errs.Internal, err, "error encoding session cache")
+	}
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return errs.Wrap(errs.Internal, err, "error generating salt")
+	}
+	gcm, err := newGCM(salt, true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errs.Wrap(errs.Internal, err, "error generating nonce")
+	}
+	data := append(salt, gcm.Seal(nonce, nonce, plain, nil)...)
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		return errs.Wrap(errs.Config, err, "error writing session cache")
+	}
+	// Earlier versions kept the key next to the cache
+	os.Remove(file + ".key")
+	return nil
+}
+
+// newGCM returns the cipher of the cache, creating the key file if create is set
+// and no passphrase is set
+func newGCM(salt []byte, create bool) (cipher.AEAD, error) {
+	key, err := getKey(salt, create)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errs.Wrap(errs.Internal, err, "error creating session cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errs.Wrap(errs.Internal, err, "error creating session cipher")
+	}
+	return gcm, nil
+}
+
+// getKey returns the 256 bit key derived with scrypt from OMNIACTL_SESSION_KEY and
+// the salt of the cache, or read from the key file
+func getKey(salt []byte, create bool) ([]byte, error) {
+	if passphrase := os.Getenv(EnvKey); passphrase != "" {
+		key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+		if err != nil {
+			return nil, errs.Wrap(errs.Internal, err, "error deriving session key")
+		}
+		return key, nil
+	}
+	keyFile, err := KeyFile()
+	if err != nil {
+		return nil, err
+	}
+	key, err := ioutil.ReadFile(keyFile)
+	if err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errs.Wrap(errs.Config, err, "error reading session key")
+	}
+	if !create {
+		return nil, errs.New(errs.Config, "session key '%v' is missing or invalid", keyFile)
+	}
+	key = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, errs.Wrap(errs.Internal, err, "error generating session key")
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return nil, errs.Wrap(errs.Config, err, "error creating session key directory")
+	}
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		return nil, errs.Wrap(errs.Config, err, "error writing session key")
+	}
+	return key, nil
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"bytes"
+	"io/ioutil"
+	"omniactl/errs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useTempCache points the cache and its key at a temporary directory and returns
+// a function removing it
+func useTempCache(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "omniactl-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "cache", "sessions")
+	os.Setenv(EnvFile, file)
+	os.Setenv(EnvKeyFile, filepath.Join(dir, "config", "session.key"))
+	return file, func() {
+		os.Unsetenv(EnvFile)
+		os.Unsetenv(EnvKeyFile)
+		os.Unsetenv(EnvKey)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSaveAndGet(t *testing.T) {
+	file, cleanup := useTempCache(t)
+	defer cleanup()
+
+	_, ok := Get("jira", "https://jira.example.com")
+	assert.False(t, ok, "no session before saving one")
+
+	err := Save(Session{System: "jira", URL: "https://jira.example.com", Username: "admin", Secret: "s3cr3t-token"})
+	assert.NoError(t, err)
+	s, ok := Get("jira", "https://jira.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "admin", s.Username)
+	assert.Equal(t, "s3cr3t-token", s.Secret)
+	assert.WithinDuration(t, time.Now().Add(DefaultTTL), s.Expiry, time.Minute)
+
+	_, ok = Get("jira", "https://other.example.com")
+	assert.False(t, ok, "session of another URL")
+	_, ok = Get("confluence", "https://jira.example.com")
+	assert.False(t, ok, "session of another system")
+
+	data, err := ioutil.ReadFile(file)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(data, []byte("s3cr3t-token")), "cache is not encrypted")
+	info, err := os.Stat(file)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestExpiryAndDelete(t *testing.T) {
+	_, cleanup := useTempCache(t)
+	defer cleanup()
+
+	assert.NoError(t, Save(Session{System: "concourse", URL: "u", Secret: "old", Expiry: time.Now().Add(-time.Second)}))
+	_, ok := Get("concourse", "u")
+	assert.False(t, ok, "expired session")
+
+	assert.NoError(t, Save(Session{System: "concourse", URL: "u", Secret: "token"}))
+	assert.NoError(t, Save(Session{System: "github", URL: "u", Secret: "token"}))
+	assert.NoError(t, Delete("concourse"))
+	_, ok = Get("concourse", "u")
+	assert.False(t, ok, "deleted session")
+	sessions, err := List()
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Contains(t, sessions, "github")
+}
+
+func TestKey(t *testing.T) {
+	_, cleanup := useTempCache(t)
+	defer cleanup()
+
+	os.Setenv(EnvKey, "passphrase")
+	assert.NoError(t, Save(Session{System: "vault", URL: "u", Secret: "token"}))
+	_, ok := Get("vault", "u")
+	assert.True(t, ok)
+
+	// A cache encrypted with another key is treated as empty and replaced on save
+	os.Setenv(EnvKey, "another passphrase")
+	_, ok = Get("vault", "u")
+	assert.False(t, ok, "session decrypted with the wrong key")
+	_, err := List()
+	assert.Error(t, err)
+	assert.NoError(t, Save(Session{System: "jira", URL: "u", Secret: "password"}))
+	_, ok = Get("jira", "u")
+	assert.True(t, ok)
+
+	// The passphrase key is derived with a new salt each time the cache is written
+	first, err := ioutil.ReadFile(os.Getenv(EnvFile))
+	assert.NoError(t, err)
+	assert.NoError(t, Save(Session{System: "jira", URL: "u", Secret: "password"}))
+	second, err := ioutil.ReadFile(os.Getenv(EnvFile))
+	assert.NoError(t, err)
+	assert.NotEqual(t, first[:saltSize], second[:saltSize])
+}
+
+func TestKeyFile(t *testing.T) {
+	file, cleanup := useTempCache(t)
+	defer cleanup()
+
+	assert.NoError(t, Save(Session{System: "vault", URL: "u", Secret: "token"}))
+	_, err := os.Stat(os.Getenv(EnvKeyFile))
+	assert.NoError(t, err, "key file created")
+	entries, err := ioutil.ReadDir(filepath.Dir(file))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "only the cache is kept in its directory")
+
+	// A key next to the cache is refused
+	os.Setenv(EnvKeyFile, file+".key")
+	err = Save(Session{System: "vault", URL: "u", Secret: "token"})
+	assert.True(t, errs.Is(err, errs.Config), "Expected '%v' error Got '%v'", errs.Config, err)
+}
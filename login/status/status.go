@@ -0,0 +1,346 @@
+// Package status probes every system omniactl is configured for and reports whether
+// it can be reached, who omniactl is authenticated as, what the token may do or when
+// it expires, and the version of the system.
+package status
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"omniactl/config"
+	"omniactl/errs"
+	artifactoryLogin "omniactl/login/artifactory"
+	concourseLogin "omniactl/login/concourse"
+	confluenceLogin "omniactl/login/confluence"
+	"omniactl/login/credentials"
+	githubLogin "omniactl/login/github"
+	jiraLogin "omniactl/login/jira"
+	"omniactl/login/session"
+	"omniactl/output"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// DefaultTimeout is how long each system is given to answer
+const DefaultTimeout = 10 * time.Second
+
+// Possible values of Status.Status
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Status is the outcome of probing a single system
+type Status struct {
+	System    string    `json:"system"`
+	URL       string    `json:"url"`
+	Status    string    `json:"status"`
+	Reachable bool      `json:"reachable"`
+	Identity  string    `json:"identity"`
+	Scopes    string    `json:"scopes"`
+	Expiry    time.Time `json:"expiry"`
+	Version   string    `json:"version"`
+	Error     string    `json:"error"`
+	err       error
+}
+
+// probe checks a single system, giving up after the timeout
+type probe struct {
+	system string
+	check  func(s *Status, timeout time.Duration) error
+}
+
+// probes are run for every system, in the order they are reported
+var probes = []probe{
+	{"github", checkGithub},
+	{"jira", checkJira},
+	{"confluence", checkConfluence},
+	{"artifactory", checkArtifactory},
+	{"concourse", checkConcourse},
+	{"vault", checkVault},
+}
+
+// ShowStatus probes every configured system concurrently and prints a table of the
+// results. If any system failed, an error of the kind of the first failure is returned.
+func ShowStatus(timeout time.Duration) error {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: Check the login status of all systems")
+
+	statuses := CheckAll(timeout)
+	if output.IsText() {
+		PrintStatuses(statuses)
+	} else if err := output.Print(statuses); err != nil {
+		return err
+	}
+
+	failed := 0
+	var first error
+	for _, s := range statuses {
+		if s.Status == StatusFailed {
+			failed++
+			if first == nil {
+				first = s.err
+			}
+		}
+	}
+	if failed > 0 {
+		return errs.Wrap(errs.KindOf(first), first, "%v of %v systems failed the login check", failed, len(statuses))
+	}
+	return nil
+}
+
+// CheckAll probes every system concurrently, each for at most the timeout
+func CheckAll(timeout time.Duration) []Status {
+	statuses := make([]Status, len(probes))
+	done := make(chan int, len(probes))
+	for i, p := range probes {
+		go func(i int, p probe) {
+			statuses[i] = run(p, timeout)
+			done <- i
+		}(i, p)
+	}
+	for range probes {
+		<-done
+	}
+	return statuses
+}
+
+// run probes the system, reporting it as unreachable if it does not answer in time
+func run(p probe, timeout time.Duration) Status {
+	result := make(chan Status, 1)
+	go func() {
+		s := Status{System: p.system}
+		url, err := config.GetURL(p.system)
+		if err != nil {
+			s.Status = StatusSkipped
+			s.Error = "not configured"
+			result <- s
+			return
+		}
+		s.URL = url
+		if err := p.check(&s, timeout); err != nil {
+			s.Status = StatusFailed
+			s.Error = err.Error()
+			s.err = err
+			result <- s
+			return
+		}
+		s.Status = StatusOK
+		s.Reachable = true
+		if sess, ok := session.Get(p.system, url); ok && s.Expiry.IsZero() {
+			s.Expiry = sess.Expiry
+		}
+		result <- s
+	}()
+
+	select {
+	case s := <-result:
+		return s
+	case <-time.After(timeout):
+		url, _ := config.GetURL(p.system)
+		err := errs.New(errs.Unavailable, "no answer within %v", timeout)
+		return Status{System: p.system, URL: url, Status: StatusFailed, Error: err.Error(), err: err}
+	}
+}
+
+// reached reports whether a request failed only after the system answered it
+func reached(err error) bool {
+	return !errs.Is(err, errs.Unavailable) && !errs.Is(err, errs.Config)
+}
+
+func checkGithub(s *Status, timeout time.Duration) error {
+	client, err := githubLogin.CreateClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	s.Identity, s.Scopes, s.Version, err = githubLogin.WhoAmI(ctx, client)
+	s.Reachable = err == nil || reached(err)
+	return err
+}
+
+func checkJira(s *Status, timeout time.Duration) error {
+	svc, err := jiraLogin.CreateClient()
+	if err != nil {
+		return err
+	}
+	svc.Client.Timeout = timeout
+	myself := jiraLogin.Myself{}
+	if err := svc.Do("GET", "myself", nil, &myself); err != nil {
+		s.Reachable = reached(err)
+		return errs.Wrap(errs.KindOf(err), err, "Jira login failed")
+	}
+	s.Identity = myself.Name
+	info := struct {
+		Version string `json:"version"`
+	}{}
+	if err := svc.Do("GET", "serverInfo", nil, &info); err == nil {
+		s.Version = info.Version
+	}
+	return nil
+}
+
+func checkConfluence(s *Status, timeout time.Duration) error {
+	svc, err := confluenceLogin.CreateClient()
+	if err != nil {
+		return err
+	}
+	svc.Client.Timeout = timeout
+	user := confluenceLogin.CurrentUser{}
+	if err := svc.Do("GET", "user/current", nil, &user); err != nil {
+		s.Reachable = reached(err)
+		return errs.Wrap(errs.KindOf(err), err, "Confluence login failed")
+	}
+	if user.Type == "anonymous" {
+		s.Reachable = true
+		return errs.New(errs.PermissionDenied, "Confluence login failed: not authenticated")
+	}
+	s.Identity = user.Username
+	return nil
+}
+
+func checkArtifactory(s *Status, timeout time.Duration) error {
+	svc, err := artifactoryLogin.CreateClient()
+	if err != nil {
+		return err
+	}
+	svc.Client.Timeout = timeout
+	if err := artifactoryLogin.CheckArtifactoryLogin(svc); err != nil {
+		s.Reachable = reached(err)
+		return err
+	}
+	s.Identity = svc.Username
+	info := struct {
+		Version string `json:"version"`
+	}{}
+	if err := svc.Do("GET", "system/version", nil, &info); err == nil {
+		s.Version = info.Version
+	}
+	return nil
+}
+
+func checkConcourse(s *Status, timeout time.Duration) error {
+	// The version is public, so it is read before logging in
+	info := struct {
+		Version string `json:"version"`
+	}{}
+	if err := getJSON(strings.TrimSuffix(s.URL, "/")+"/api/v1/info", timeout, &info); err != nil {
+		return err
+	}
+	s.Reachable = true
+	s.Version = info.Version
+
+	sess, err := concourseLogin.GetConcourseSession()
+	if err != nil {
+		return err
+	}
+	svc := concourseLogin.NewClient(sess.Username, sess.Secret, sess.URL)
+	svc.Client.Timeout = timeout
+	user := struct {
+		UserName string `json:"user_name"`
+	}{}
+	if err := svc.Do("GET", "user", nil, &user); err != nil {
+		return errs.Wrap(errs.KindOf(err), err, "Concourse login failed")
+	}
+	s.Identity = user.UserName
+	s.Expiry = sess.Expiry
+	return nil
+}
+
+func checkVault(s *Status, timeout time.Duration) error {
+	// Vault reports its version on the health endpoint, with a status code
+	// depending on whether it is sealed or on standby
+	health := struct {
+		Version string `json:"version"`
+	}{}
+	if err := getJSON(strings.TrimSuffix(s.URL, "/")+"/v1/sys/health", timeout, &health); err != nil && !reached(err) {
+		return err
+	}
+	s.Reachable = true
+	s.Version = health.Version
+
+	if file := os.Getenv(credentials.EnvFile); file != "" {
+		s.Identity = "not used, credentials are read from " + file
+		return nil
+	}
+	v, err := credentials.NewVaultProvider()
+	if err != nil {
+		return errs.Wrap(errs.Config, err, "failure creating Vault client")
+	}
+	v.Client = &http.Client{Timeout: timeout}
+	name, ttl, err := v.LookupSelf()
+	if err != nil {
+		return errs.Wrap(errs.PermissionDenied, err, "Vault login failed")
+	}
+	s.Identity = name
+	if ttl > 0 {
+		s.Expiry = time.Now().Add(ttl).Truncate(time.Second)
+	}
+	return nil
+}
+
+// getJSON sends an unauthenticated GET request and decodes the JSON response,
+// even if its status is an error
+func getJSON(url string, timeout time.Duration, result interface{}) error {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return errs.Wrap(errs.Unavailable, err, "error sending request")
+	}
+	defer resp.Body.Close()
+	json.NewDecoder(resp.Body).Decode(result)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errs.New(errs.FromStatus(resp.StatusCode, ""), "%v", resp.Status)
+	}
+	return nil
+}
+
+// PrintStatuses prints the statuses as a table, coloured by outcome
+func PrintStatuses(statuses []Status) {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SYSTEM\tSTATUS\tREACHABLE\tIDENTITY\tSCOPES / EXPIRY\tVERSION\tURL\tERROR")
+	for _, s := range statuses {
+		access := []string{}
+		if s.Scopes != "" {
+			access = append(access, s.Scopes)
+		}
+		if !s.Expiry.IsZero() {
+			access = append(access, "expires "+s.Expiry.Local().Format("2006-01-02 15:04"))
+		}
+		reachable := "no"
+		if s.Reachable {
+			reachable = "yes"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", s.System, s.Status, reachable, dash(s.Identity), dash(strings.Join(access, "; ")), dash(s.Version), dash(s.URL), s.Error)
+	}
+	w.Flush()
+
+	colours := map[string]*color.Color{
+		StatusOK:      color.New(color.FgGreen),
+		StatusFailed:  color.New(color.FgRed),
+		StatusSkipped: color.New(color.FgYellow),
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	fmt.Println("")
+	color.New(color.FgHiWhite, color.Bold).Println(lines[0])
+	for i, line := range lines[1:] {
+		colours[statuses[i].Status].Println(line)
+	}
+	fmt.Println("")
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package status
+
+import (
+	"fmt"
+	"io/ioutil"
+	artifactoryFake "omniactl/artifactory/fake"
+	concourseFake "omniactl/concourse/fake"
+	"omniactl/config"
+	"omniactl/errs"
+	githubFake "omniactl/github/fake"
+	jiraFake "omniactl/jira/fake"
+	"omniactl/login/credentials"
+	"omniactl/login/session"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckAll(t *testing.T) {
+	github := githubFake.NewServer()
+	defer github.Close()
+	jira := jiraFake.NewServer()
+	defer jira.Close()
+	artifactory := artifactoryFake.NewServer()
+	defer artifactory.Close()
+	concourse := concourseFake.NewServer()
+	defer concourse.Close()
+	vault := githubFake.NewServer()
+	vault.Close()
+
+	dir, err := ioutil.TempDir("", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(dir)
+
+	// Confluence is left out of the config, so it is skipped
+	cfg := fmt.Sprintf("profiles:\n  default:\n    github: %v/\n    jira: %v\n    artifactory: %v\n    concourse: %v\n    vault: %v\n",
+		github.URL, jira.URL, artifactory.URL, concourse.URL, vault.URL)
+	auth := "[auth]\ngithub_username = admin\ngithub_token = token\n" +
+		"jira_username = admin\njira_password = secret\n" +
+		"artifactory_username = admin\nartifactory_password = secret\n" +
+		"concourse_username = admin\nconcourse_password = secret\n"
+	ioutil.WriteFile(config.FileName, []byte(cfg), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "credentials"), []byte(auth), 0600)
+	os.Setenv(config.EnvConfig, config.FileName)
+	defer os.Unsetenv(config.EnvConfig)
+	if err := config.Init(); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(credentials.EnvFile, filepath.Join(dir, "credentials"))
+	defer os.Unsetenv(credentials.EnvFile)
+	os.Setenv(session.EnvFile, filepath.Join(dir, "sessions"))
+	defer os.Unsetenv(session.EnvFile)
+
+	type test struct {
+		system    string
+		status    string
+		reachable bool
+		identity  string
+		version   string
+	}
+	tests := []test{
+		{"github", StatusOK, true, githubFake.AdminLogin, githubFake.Version},
+		{"jira", StatusOK, true, jiraFake.AdminLogin, jiraFake.Version},
+		{"confluence", StatusSkipped, false, "", ""},
+		{"artifactory", StatusOK, true, artifactoryFake.AdminLogin, artifactoryFake.Version},
+		{"concourse", StatusOK, true, concourseFake.AdminLogin, concourseFake.Version},
+		{"vault", StatusFailed, false, "", ""},
+	}
+
+	statuses := CheckAll(time.Second)
+	if len(statuses) != len(tests) {
+		t.Fatalf("Expected %v statuses Got %v", len(tests), len(statuses))
+	}
+	for i, test := range tests {
+		s := statuses[i]
+		if s.System != test.system || s.Status != test.status || s.Reachable != test.reachable ||
+			s.Identity != test.identity || s.Version != test.version {
+			t.Errorf("Expected %+v Got %+v", test, s)
+		}
+	}
+	if statuses[0].Scopes != githubFake.Scopes {
+		t.Errorf("Expected Github scopes '%v' Got '%v'", githubFake.Scopes, statuses[0].Scopes)
+	}
+	if statuses[4].Expiry.Before(time.Now().Add(23 * time.Hour)) {
+		t.Errorf("Expected the Concourse token to expire in a day Got %v", statuses[4].Expiry)
+	}
+	if !errs.Is(statuses[5].err, errs.Unavailable) {
+		t.Errorf("Expected '%v' error for Vault Got '%v'", errs.Unavailable, statuses[5].err)
+	}
+
+	err = ShowStatus(time.Second)
+	if !errs.Is(err, errs.Unavailable) {
+		t.Errorf("Expected '%v' error Got '%v'", errs.Unavailable, err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(dir)
+	ioutil.WriteFile(config.FileName, []byte("profiles:\n  default:\n    jira: http://jira\n"), 0600)
+	os.Setenv(config.EnvConfig, config.FileName)
+	defer os.Unsetenv(config.EnvConfig)
+	if err := config.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	slow := probe{"jira", func(s *Status, timeout time.Duration) error {
+		time.Sleep(time.Second)
+		return nil
+	}}
+	s := run(slow, 10*time.Millisecond)
+	if s.Status != StatusFailed || s.Reachable || !errs.Is(s.err, errs.Unavailable) {
+		t.Errorf("Expected an unreachable system after the timeout Got %+v", s)
+	}
+}
@@ -0,0 +1,49 @@
+package vault
+
+import (
+	"fmt"
+	"omniactl/errs"
+	"omniactl/login/credentials"
+	"omniactl/login/session"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// VaultLogin checks the Vault token, logging in with AppRole if VAULT_TOKEN is not set.
+// With "login", the token is saved as the session used by later commands to read
+// credentials, until the token or the session expires.
+func VaultLogin(s string) error {
+	greenBold := color.New(color.FgGreen, color.Bold)
+	if os.Getenv(credentials.EnvFile) != "" {
+		return errs.New(errs.Config, "%v is set, so credentials are read from a local file rather than Vault", credentials.EnvFile)
+	}
+	if s == "login" {
+		if err := session.Delete("vault"); err != nil {
+			return err
+		}
+	}
+	v, err := credentials.NewVaultProvider()
+	if err != nil {
+		return errs.Wrap(errs.Config, err, "failure creating Vault client")
+	}
+	name, ttl, err := v.LookupSelf()
+	if err != nil {
+		return errs.Wrap(errs.PermissionDenied, err, "Vault login failed")
+	}
+	if s == "login" {
+		expiry := time.Now().Add(session.TTL)
+		if ttl > 0 && ttl < session.TTL {
+			expiry = time.Now().Add(ttl)
+		}
+		if err := session.Save(session.Session{System: "vault", URL: v.Address, Username: name, Secret: v.Token, Expiry: expiry}); err != nil {
+			return err
+		}
+		fmt.Printf("Successfully logged into %v as %v.\n", v.Address, name)
+	} else if s == "check" {
+		greenBold.Print("Vault Login Status ")
+		fmt.Println("OK")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package output renders the results of list commands in the format selected
+// through the global --output flag.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"omniactl/errs"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Supported output formats
+const (
+	Text  = "text"
+	JSON  = "json"
+	YAML  = "yaml"
+	CSV   = "csv"
+	Table = "table"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{Text, JSON, YAML, CSV, Table}
+
+// Format is set through the global --output flag. The default, text, prints
+// the colourised output meant for people reading it in a terminal.
+var Format = Text
+
+// Writer is where results are rendered to
+var Writer io.Writer = os.Stdout
+
+// Validate checks that format is one of the supported output formats
+func Validate(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return errs.New(errs.Validation, "unknown output format '%v', use one of: %v", format, strings.Join(Formats, ", "))
+}
+
+// IsText reports whether the colourised text output was selected
+func IsText() bool {
+	return Format == Text
+}
+
+// Print renders v to Writer in the selected format.
+// v is either a result struct or a slice of result structs.
+func Print(v interface{}) error {
+	return Render(Writer, Format, v)
+}
+
+// Render renders v to w in the given format. Structs are rendered as a single
+// row in CSV and table format, slices of structs as one row per element.
+func Render(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return errs.Wrap(errs.Internal, enc.Encode(v), "error rendering JSON output")
+	case YAML:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return errs.Wrap(errs.Internal, err, "error rendering YAML output")
+		}
+		_, err = w.Write(out)
+		return errs.Wrap(errs.Internal, err, "error writing YAML output")
+	case CSV:
+		header, rows, err := tabulate(v)
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+		return errs.Wrap(errs.Internal, cw.Error(), "error rendering CSV output")
+	case Table, Text:
+		header, rows, err := tabulate(v)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for i := range header {
+			header[i] = strings.ToUpper(header[i])
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return errs.Wrap(errs.Internal, tw.Flush(), "error rendering table output")
+	}
+	return Validate(format)
+}
+
+// tabulate returns the column names and the rows of cells of v,
+// using the JSON field names of the result struct as column names.
+// Pointers to structs are rendered as the structs they point to.
+func tabulate(v interface{}) ([]string, [][]string, error) {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if !value.IsValid() {
+		return nil, nil, errs.New(errs.Internal, "cannot render %v as rows, expected a struct or a slice of structs", v)
+	}
+	if value.Kind() != reflect.Slice {
+		slice := reflect.MakeSlice(reflect.SliceOf(value.Type()), 1, 1)
+		slice.Index(0).Set(value)
+		value = slice
+	}
+
+	elem := value.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, nil, errs.New(errs.Internal, "cannot render %v as rows, expected a struct or a slice of structs", value.Type())
+	}
+	var header []string
+	var fields []int
+	for i := 0; i < elem.NumField(); i++ {
+		name := strings.Split(elem.Field(i).Tag.Get("json"), ",")[0]
+		if name == "-" || elem.Field(i).PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = elem.Field(i).Name
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+
+	rows := make([][]string, value.Len())
+	for r := 0; r < value.Len(); r++ {
+		row := value.Index(r)
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				rows[r] = make([]string, len(fields))
+				continue
+			}
+			row = row.Elem()
+		}
+		for _, i := range fields {
+			rows[r] = append(rows[r], cell(row.Field(i)))
+		}
+	}
+	return header, rows, nil
+}
+
+// cell formats a single field of a result struct
+func cell(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format(time.RFC3339)
+	case []string:
+		return strings.Join(value, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type row struct {
+	Name    string    `json:"name" yaml:"name"`
+	ID      int64     `json:"id" yaml:"id"`
+	Teams   []string  `json:"teams" yaml:"teams"`
+	Created time.Time `json:"created_at" yaml:"created_at"`
+}
+
+var rows = []row{
+	{"testing", 1, []string{"test_team", "team2"}, time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)},
+	{"other", 2, nil, time.Time{}},
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format string
+		data   interface{}
+		result string
+	}{
+		{CSV, rows, "name,id,teams,created_at\n" +
+			"testing,1,\"test_team,team2\",2019-03-01T12:00:00Z\n" +
+			"other,2,,\n"},
+		{Table, rows, "NAME     ID  TEAMS            CREATED_AT\n" +
+			"testing  1   test_team,team2  2019-03-01T12:00:00Z\n" +
+			"other    2                    \n"},
+		{JSON, rows[1], "{\n  \"name\": \"other\",\n  \"id\": 2,\n  \"teams\": null,\n  \"created_at\": \"0001-01-01T00:00:00Z\"\n}\n"},
+		{YAML, []row{{Name: "other", ID: 2}}, "- name: other\n  id: 2\n  teams: []\n  created_at: 0001-01-01T00:00:00Z\n"},
+		{CSV, rows[1], "name,id,teams,created_at\nother,2,,\n"},
+		{CSV, &rows[1], "name,id,teams,created_at\nother,2,,\n"},
+		{Table, []*row{&rows[1]}, "NAME   ID  TEAMS  CREATED_AT\nother  2          \n"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, test.format, test.data); err != nil {
+			t.Errorf("Rendering %v failed: %v", test.format, err)
+		}
+		if buf.String() != test.result {
+			t.Errorf("Rendering %v: Expected\n%q\nGot\n%q", test.format, test.result, buf.String())
+		}
+	}
+}
+
+func TestRenderSkipsUnexportedFields(t *testing.T) {
+	type change struct {
+		Action string `json:"action"`
+		detail string
+	}
+	var buf bytes.Buffer
+	if err := Render(&buf, CSV, []change{{"create", "ignored"}}); err != nil {
+		t.Fatal("Rendering failed:", err)
+	}
+	if result := "action\ncreate\n"; buf.String() != result {
+		t.Errorf("Rendering CSV: Expected\n%q\nGot\n%q", result, buf.String())
+	}
+}
+
+func TestRenderNonStruct(t *testing.T) {
+	for _, data := range []interface{}{"name", []string{"name"}, (*row)(nil)} {
+		for _, format := range []string{CSV, Table} {
+			var buf bytes.Buffer
+			if err := Render(&buf, format, data); err == nil {
+				t.Errorf("Rendering %T as %v: Expected error Got none", data, format)
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, format := range Formats {
+		if err := Validate(format); err != nil {
+			t.Errorf("Expected '%v' to be valid Got '%v'", format, err)
+		}
+	}
+	if err := Validate("xml"); err == nil {
+		t.Error("Expected 'xml' to be invalid")
+	}
+}
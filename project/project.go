@@ -0,0 +1,332 @@
+package project
+
+import (
+	"fmt"
+	addArtifactoryUser "omniactl/artifactory/add/user"
+	createPermission "omniactl/artifactory/create/permission"
+	createArtifactoryRepo "omniactl/artifactory/create/repo"
+	artifactoryService "omniactl/artifactory/service"
+	createConcourseTeam "omniactl/concourse/create/team"
+	concourseService "omniactl/concourse/service"
+	setPipeline "omniactl/concourse/set/pipeline"
+	createSpace "omniactl/confluence/create/space"
+	"omniactl/confluence/grant"
+	listPermission "omniactl/confluence/list/permission"
+	confluenceService "omniactl/confluence/service"
+	"omniactl/errs"
+	createRepo "omniactl/github/create/repo"
+	createTeam "omniactl/github/create/team"
+	createUser "omniactl/github/create/user"
+	"omniactl/github/service"
+	createJiraProject "omniactl/jira/create/project"
+	createJiraUser "omniactl/jira/create/user"
+	jiraService "omniactl/jira/service"
+
+	"github.com/fatih/color"
+)
+
+// Project holds the values required to on-board a new project across all systems
+type Project struct {
+	Username               string
+	Name                   string
+	Email                  string
+	Org                    string
+	Team                   string
+	CoreProjectName        string
+	JiraProjectName        string
+	JiraProjectKey         string
+	JiraTemplate           string
+	ConfluenceSpaceName    string
+	ConfluenceSpaceKey     string
+	ArtifactoryGroup       string
+	ArtifactoryPackageType string
+	ConcourseRequired      bool
+}
+
+// Result records the outcome of a single on-boarding step
+type Result struct {
+	System string
+	Step   string
+	Status string
+	Err    error
+}
+
+// Possible values of Result.Status
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Services holds the clients of the systems a project is on-boarded to.
+// Jira, Confluence, Artifactory and Concourse may be nil if they are not part of the on-boarding.
+type Services struct {
+	Github      *service.Service
+	Jira        *jiraService.Service
+	Confluence  *confluenceService.Service
+	Artifactory *artifactoryService.Service
+	Concourse   *concourseService.Service
+}
+
+// step is a single action of the on-boarding workflow
+type step struct {
+	system string
+	name   string
+	run    func(s Services, p Project) error
+	// skip returns a reason for skipping the step, or "" if the step should run
+	skip func(p Project) string
+}
+
+var steps = []step{
+	{
+		system: "Github",
+		name:   "create team",
+		run: func(s Services, p Project) error {
+			return createTeam.CreateTeam(s.Github, p.Team, p.Org, "", []string{}, "closed")
+		},
+		skip: func(p Project) string {
+			if p.Team == "" {
+				return "no team provided"
+			}
+			return ""
+		},
+	},
+	{
+		system: "Github",
+		name:   "create user",
+		run: func(s Services, p Project) error {
+			teams := []string{}
+			if p.Team != "" {
+				teams = append(teams, p.Team)
+			}
+			return createUser.AddUser(s.Github, p.Username, p.Email, p.Org, "member", teams)
+		},
+	},
+	{
+		system: "Github",
+		name:   "create repository",
+		run: func(s Services, p Project) error {
+			return createRepo.CreateRepo(s.Github, p.CoreProjectName, p.Org, p.Team, "", true)
+		},
+		skip: func(p Project) string {
+			if p.CoreProjectName == "" {
+				return "no core project name provided"
+			}
+			return ""
+		},
+	},
+	{
+		system: "Jira",
+		name:   "create project",
+		run: func(s Services, p Project) error {
+			return createJiraProject.CreateProject(s.Jira, createJiraProject.Project{
+				Name:     p.JiraProjectName,
+				Key:      p.JiraProjectKey,
+				Template: p.JiraTemplate,
+			})
+		},
+		skip: func(p Project) string {
+			if p.JiraProjectName == "" {
+				return "no Jira project name provided"
+			}
+			return ""
+		},
+	},
+	{
+		system: "Jira",
+		name:   "add user",
+		run: func(s Services, p Project) error {
+			return createJiraUser.AddUser(s.Jira, p.Username, p.Name, p.Email, p.JiraProjectName, createJiraUser.DefaultRole)
+		},
+		skip: func(p Project) string {
+			if p.JiraProjectName == "" {
+				return "no Jira project name provided"
+			}
+			return ""
+		},
+	},
+	{
+		system: "Confluence",
+		name:   "create space",
+		run: func(s Services, p Project) error {
+			return createSpace.CreateSpace(s.Confluence, p.ConfluenceSpaceKey, p.ConfluenceSpaceName, "")
+		},
+		skip: func(p Project) string {
+			if p.ConfluenceSpaceName == "" {
+				return "no Confluence space name provided"
+			}
+			return ""
+		},
+	},
+	{
+		system: "Confluence",
+		name:   "grant user",
+		run: func(s Services, p Project) error {
+			return grant.Grant(s.Confluence, p.ConfluenceSpaceKey, []string{p.Username}, []string{}, listPermission.Edit)
+		},
+		skip: func(p Project) string {
+			if p.ConfluenceSpaceName == "" {
+				return "no Confluence space name provided"
+			}
+			return ""
+		},
+	},
+	{
+		system: "Artifactory",
+		name:   "create repositories",
+		run: func(s Services, p Project) error {
+			return createArtifactoryRepo.CreateRepos(s.Artifactory, p.Team, p.ArtifactoryPackageType, createArtifactoryRepo.Classes, "")
+		},
+		skip: skipArtifactoryRepos,
+	},
+	{
+		system: "Artifactory",
+		name:   "create permission",
+		run: func(s Services, p Project) error {
+			users, groups := []string{p.Username}, []string{}
+			if p.ArtifactoryGroup != "" {
+				users, groups = []string{}, []string{p.ArtifactoryGroup}
+			}
+			return createPermission.CreatePermission(s.Artifactory, p.Team, []string{}, users, groups, createPermission.DefaultActions)
+		},
+		skip: skipArtifactoryRepos,
+	},
+	{
+		system: "Artifactory",
+		name:   "add user to group",
+		run: func(s Services, p Project) error {
+			return addArtifactoryUser.AddUsers(s.Artifactory, p.ArtifactoryGroup, []string{p.Username})
+		},
+		skip: func(p Project) string {
+			if p.ArtifactoryGroup == "" {
+				return "no Artifactory group provided"
+			}
+			return ""
+		},
+	},
+	{
+		system: "Concourse",
+		name:   "create team",
+		run: func(s Services, p Project) error {
+			roles := map[string][]string{createConcourseTeam.DefaultRole: {p.Team}}
+			return createConcourseTeam.CreateTeam(s.Concourse, p.Team, p.Org, roles)
+		},
+		skip: skipConcourse,
+	},
+	{
+		system: "Concourse",
+		name:   "set pipeline",
+		run: func(s Services, p Project) error {
+			gitURI, err := setPipeline.GitURI(s.Github.Client.BaseURL.String(), p.Org, p.CoreProjectName)
+			if err != nil {
+				return err
+			}
+			return setPipeline.SetPipeline(s.Concourse, setPipeline.DefaultTemplate, "", setPipeline.Params{
+				Team:   p.Team,
+				Name:   p.CoreProjectName,
+				Org:    p.Org,
+				Repo:   p.CoreProjectName,
+				GitURI: gitURI,
+			})
+		},
+		skip: func(p Project) string {
+			if reason := skipConcourse(p); reason != "" {
+				return reason
+			}
+			if p.CoreProjectName == "" {
+				return "no core project name provided"
+			}
+			return ""
+		},
+	},
+}
+
+// skipConcourse skips the Concourse steps unless Concourse is required and a Github
+// team was provided, which the Concourse team is named after and owned by
+func skipConcourse(p Project) string {
+	if !p.ConcourseRequired {
+		return "Concourse not required"
+	}
+	if p.Team == "" {
+		return "no Github team to map the Concourse team to"
+	}
+	return ""
+}
+
+// skipArtifactoryRepos skips the Artifactory repository steps unless a package type
+// and a team to name the repositories after were provided
+func skipArtifactoryRepos(p Project) string {
+	if p.ArtifactoryPackageType == "" {
+		return "no Artifactory package type provided"
+	}
+	if p.Team == "" {
+		return "no team to name the Artifactory repositories after"
+	}
+	return ""
+}
+
+// CreateProject on-boards a new project by running every step of the workflow
+// in turn and printing a summary of the outcome for each system.
+// If any step failed, the error of the first failed step is returned.
+func CreateProject(svc Services, p Project) ([]Result, error) {
+	magentaBold := color.New(color.FgMagenta, color.Bold, color.Underline)
+	magentaBold.Println("Action selected: On-board a new project")
+
+	results := RunSteps(svc, p)
+	PrintSummary(results)
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			return results, errs.Wrap(errs.KindOf(r.Err), r.Err, "%v step '%v' failed", r.System, r.Step)
+		}
+	}
+	return results, nil
+}
+
+// RunSteps runs each on-boarding step and collects the results.
+// A failed step does not stop the remaining steps from running.
+func RunSteps(svc Services, p Project) []Result {
+	var results []Result
+	for _, s := range steps {
+		result := Result{System: s.system, Step: s.name}
+		if s.skip != nil {
+			if reason := s.skip(p); reason != "" {
+				result.Status = StatusSkipped
+				result.Err = fmt.Errorf("%v", reason)
+				results = append(results, result)
+				continue
+			}
+		}
+		fmt.Println("")
+		if err := s.run(svc, p); err != nil {
+			result.Status = StatusFailed
+			result.Err = err
+		} else {
+			result.Status = StatusOK
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// PrintSummary prints the outcome of every on-boarding step
+func PrintSummary(results []Result) {
+	whiteBold := color.New(color.FgHiWhite, color.Bold)
+	greenBold := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed, color.Bold)
+
+	fmt.Println("")
+	whiteBold.Println("On-boarding summary:")
+	for _, r := range results {
+		fmt.Printf("%-15v %-20v ", r.System, r.Step)
+		switch r.Status {
+		case StatusOK:
+			greenBold.Println("OK")
+		case StatusSkipped:
+			yellow.Printf("SKIPPED (%v)\n", r.Err)
+		default:
+			red.Printf("FAILED (%v)\n", r.Err)
+		}
+	}
+	fmt.Println("")
+}